@@ -18,6 +18,8 @@ type Routes struct {
 	API        chi.Router // 'api/v1'
 	Users      chi.Router // 'api/v1/users'
 	User       chi.Router // 'api/v1/users/{user_id:[A-Za-z0-9]+}'
+	Wishlists  chi.Router // 'api/v1/users/wishlists'
+	Wishlist   chi.Router // 'api/v1/users/wishlists/{wishlist_id:[A-Za-z0-9]+}'
 	Products   chi.Router // 'api/v1/products'
 	Product    chi.Router // 'api/v1/products/{product_id:[A-Za-z0-9]+}'
 	Orders     chi.Router // 'api/v1/orders'
@@ -30,6 +32,22 @@ type Routes struct {
 	Tag        chi.Router // 'api/v1/tags/{tag_id:[A-Za-z0-9]+}'
 	Promotions chi.Router // 'api/v1/promotions'
 	Promotion  chi.Router // 'api/v1/promotions/{promo_code:[A-Za-z0-9]+}'
+	Reviews    chi.Router // 'api/v1/reviews'
+	Questions  chi.Router // 'api/v1/questions'
+	Jobs       chi.Router // 'api/v1/jobs'
+	Job        chi.Router // 'api/v1/jobs/{job_id:[A-Za-z0-9]+}'
+	Webhooks   chi.Router // 'api/v1/webhooks'
+	Webhook    chi.Router // 'api/v1/webhooks/{webhook_id:[A-Za-z0-9]+}'
+	Roles      chi.Router // 'api/v1/roles'
+	Role       chi.Router // 'api/v1/roles/{role_id:[A-Za-z0-9]+}'
+	OAuth      chi.Router // 'api/v1/oauth'
+	APIKeys    chi.Router // 'api/v1/api-keys'
+	APIKey     chi.Router // 'api/v1/api-keys/{api_key_id:[A-Za-z0-9]+}'
+	Audit      chi.Router // 'api/v1/audit'
+	Dev        chi.Router // 'api/v1/dev'
+
+	EmailTemplates chi.Router // 'api/v1/email-templates'
+	EmailTemplate  chi.Router // 'api/v1/email-templates/{template_name:[a-z_]+}/{template_locale:[A-Za-z-]+}'
 }
 
 // Init inits the API
@@ -40,14 +58,18 @@ func Init(a *app.App, r *chi.Mux) {
 	}
 
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
+	r.Use(api.RealIP)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
+	r.Use(api.Localize)
+	r.Use(api.RateLimit)
 
 	api.Routes.Root = r
 	api.Routes.API = api.Routes.Root.Route("/api/v1", nil)
 	api.Routes.Users = api.Routes.API.Route("/users", nil)
 	api.Routes.User = api.Routes.Users.Route("/{user_id:[A-Za-z0-9]+}", nil)
+	api.Routes.Wishlists = api.Routes.Users.Route("/wishlists", nil)
+	api.Routes.Wishlist = api.Routes.Wishlists.Route("/{wishlist_id:[A-Za-z0-9]+}", nil)
 	api.Routes.Products = api.Routes.API.Route("/products", nil)
 	api.Routes.Product = api.Routes.Products.Route("/{product_id:[A-Za-z0-9]+}", nil)
 	api.Routes.Orders = api.Routes.API.Route("/orders", nil)
@@ -60,12 +82,39 @@ func Init(a *app.App, r *chi.Mux) {
 	api.Routes.Tag = api.Routes.Tags.Route("/{tag_id:[A-Za-z0-9]+}", nil)
 	api.Routes.Promotions = api.Routes.API.Route("/promotions", nil)
 	api.Routes.Promotion = api.Routes.Promotions.Route("/{promo_code:[A-Za-z0-9_]+}", nil)
+	api.Routes.Reviews = api.Routes.API.Route("/reviews", nil)
+	api.Routes.Questions = api.Routes.API.Route("/questions", nil)
+	api.Routes.Jobs = api.Routes.API.Route("/jobs", nil)
+	api.Routes.Job = api.Routes.Jobs.Route("/{job_id:[A-Za-z0-9]+}", nil)
+	api.Routes.Webhooks = api.Routes.API.Route("/webhooks", nil)
+	api.Routes.Webhook = api.Routes.Webhooks.Route("/{webhook_id:[A-Za-z0-9]+}", nil)
+	api.Routes.Roles = api.Routes.API.Route("/roles", nil)
+	api.Routes.Role = api.Routes.Roles.Route("/{role_id:[A-Za-z0-9]+}", nil)
+	api.Routes.OAuth = api.Routes.API.Route("/oauth", nil)
+	api.Routes.APIKeys = api.Routes.API.Route("/api-keys", nil)
+	api.Routes.APIKey = api.Routes.APIKeys.Route("/{api_key_id:[A-Za-z0-9]+}", nil)
+	api.Routes.Audit = api.Routes.API.Route("/audit", nil)
+	api.Routes.Dev = api.Routes.API.Route("/dev", nil)
+	api.Routes.EmailTemplates = api.Routes.API.Route("/email-templates", nil)
+	api.Routes.EmailTemplate = api.Routes.EmailTemplates.Route("/{template_name:[a-z_]+}/{template_locale:[A-Za-z-]+}", nil)
 
 	InitUser(api)
+	InitTwoFactor(api)
+	InitOAuth(api)
 	InitProducts(api)
 	InitOrder(api)
 	InitCategories(api)
 	InitBrands(api)
 	InitTags(api)
 	InitPromotions(api)
+	InitReviews(api)
+	InitQuestions(api)
+	InitWishlists(api)
+	InitJobs(api)
+	InitWebhooks(api)
+	InitEmailTemplates(api)
+	InitRoles(api)
+	InitAPIKeys(api)
+	InitAudit(api)
+	InitDev(api)
 }
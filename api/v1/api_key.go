@@ -0,0 +1,111 @@
+package apiv1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/dankobgd/ecommerce-shop/utils/pagination"
+	"github.com/go-chi/chi"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgAPIKeyURLParamErr = &i18n.Message{ID: "api.api_key.url.params.app_error", Other: "invalid api key url param"}
+	msgAPIKeyFromJSON    = &i18n.Message{ID: "api.api_key.create_api_key.json.app_error", Other: "could not decode api key json data"}
+)
+
+// InitAPIKeys inits the api key routes, only the admins can manage the keys
+func InitAPIKeys(a *API) {
+	a.Routes.APIKeys.Get("/", a.AdminSessionRequired(a.getAPIKeys))
+	a.Routes.APIKeys.Post("/", a.AdminSessionRequired(a.createAPIKey))
+	a.Routes.APIKey.Get("/", a.AdminSessionRequired(a.getAPIKey))
+	a.Routes.APIKey.Delete("/", a.AdminSessionRequired(a.revokeAPIKey))
+	a.Routes.APIKey.Get("/usage", a.AdminSessionRequired(a.getAPIKeyUsage))
+}
+
+func (a *API) createAPIKey(w http.ResponseWriter, r *http.Request) {
+	k, e := model.APIKeyFromJSON(r.Body)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("createAPIKey", model.ErrInternal, locale.GetUserLocalizer("en"), msgAPIKeyFromJSON, http.StatusInternalServerError, nil))
+		return
+	}
+
+	uid := a.app.GetUserIDFromContext(r.Context())
+	created, err := a.audited(r).CreateAPIKey(uid, k)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, created)
+}
+
+func (a *API) getAPIKeys(w http.ResponseWriter, r *http.Request) {
+	pages := pagination.NewFromRequest(r)
+	keys, err := a.app.GetAPIKeys(pages.Limit(), pages.Offset())
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	totalCount := -1
+	if len(keys) > 0 {
+		totalCount = keys[0].TotalCount
+	}
+	pages.SetData(keys, totalCount)
+
+	respondJSON(w, http.StatusOK, pages)
+}
+
+func (a *API) getAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, e := strconv.ParseInt(chi.URLParam(r, "api_key_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("getAPIKey", model.ErrInternal, locale.GetUserLocalizer("en"), msgAPIKeyURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	k, err := a.app.GetAPIKey(id)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, k)
+}
+
+func (a *API) revokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, e := strconv.ParseInt(chi.URLParam(r, "api_key_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("revokeAPIKey", model.ErrInternal, locale.GetUserLocalizer("en"), msgAPIKeyURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	if err := a.audited(r).RevokeAPIKey(id); err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondOK(w)
+}
+
+func (a *API) getAPIKeyUsage(w http.ResponseWriter, r *http.Request) {
+	id, e := strconv.ParseInt(chi.URLParam(r, "api_key_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("getAPIKeyUsage", model.ErrInternal, locale.GetUserLocalizer("en"), msgAPIKeyURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	pages := pagination.NewFromRequest(r)
+	usage, err := a.app.GetAPIKeyUsage(id, pages.Limit(), pages.Offset())
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	totalCount := -1
+	if len(usage) > 0 {
+		totalCount = usage[0].TotalCount
+	}
+	pages.SetData(usage, totalCount)
+
+	respondJSON(w, http.StatusOK, pages)
+}
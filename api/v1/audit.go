@@ -0,0 +1,111 @@
+package apiv1
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/app"
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/dankobgd/ecommerce-shop/utils/pagination"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgAuditFilterErr = &i18n.Message{ID: "api.audit.filter.app_error", Other: "invalid audit log filter, ids must be numbers and dates RFC3339 timestamps"}
+)
+
+// InitAudit inits the audit log routes, only the admins can read the log
+func InitAudit(a *API) {
+	a.Routes.Audit.Get("/", a.AdminSessionRequired(a.getAuditLog))
+	a.Routes.Audit.Get("/export", a.AdminSessionRequired(a.exportAuditLog))
+}
+
+// audited returns the app which records the changes it makes as made by the request's actor
+func (a *API) audited(r *http.Request) *app.App {
+	return a.app.WithActor(a.app.AuditActorFromRequest(r))
+}
+
+func (a *API) getAuditLog(w http.ResponseWriter, r *http.Request) {
+	f, e := auditFilterFromQuery(r.URL.Query())
+	if e != nil {
+		respondError(w, r, model.NewAppErr("getAuditLog", model.ErrInvalid, locale.GetUserLocalizer("en"), msgAuditFilterErr, http.StatusBadRequest, nil))
+		return
+	}
+
+	pages := pagination.NewFromRequest(r)
+	entries, err := a.app.GetAuditLog(f, pages.Limit(), pages.Offset())
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	totalCount := -1
+	if len(entries) > 0 {
+		totalCount = entries[0].TotalCount
+	}
+	pages.SetData(entries, totalCount)
+
+	respondJSON(w, http.StatusOK, pages)
+}
+
+func (a *API) exportAuditLog(w http.ResponseWriter, r *http.Request) {
+	f, e := auditFilterFromQuery(r.URL.Query())
+	if e != nil {
+		respondError(w, r, model.NewAppErr("exportAuditLog", model.ErrInvalid, locale.GetUserLocalizer("en"), msgAuditFilterErr, http.StatusBadRequest, nil))
+		return
+	}
+
+	csv, err := a.app.ExportAuditLog(f)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	filename := fmt.Sprintf("audit-log-%s.csv", time.Now().UTC().Format("2006-01-02-150405"))
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	io.Copy(w, bytes.NewReader(csv.Bytes()))
+}
+
+func auditFilterFromQuery(q url.Values) (*model.AuditFilter, error) {
+	f := &model.AuditFilter{
+		Action:       q.Get("action"),
+		ResourceType: q.Get("resource_type"),
+		ResourceID:   q.Get("resource_id"),
+		RequestID:    q.Get("request_id"),
+	}
+
+	var err error
+	if v := q.Get("actor_id"); v != "" {
+		if f.ActorID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	if v := q.Get("api_key_id"); v != "" {
+		if f.APIKeyID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	if v := q.Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, err
+		}
+		f.From = &t
+	}
+	if v := q.Get("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, err
+		}
+		f.To = &t
+	}
+	return f, nil
+}
@@ -0,0 +1,58 @@
+package apiv1
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+)
+
+func TestUserChangesAreAudited(t *testing.T) {
+	st, users := crossUserFixture()
+	ts := newTestServer(t, st, newTestConfig())
+
+	req, err := http.NewRequest(http.MethodDelete, ts.URL+"/api/v1/users/addresses/30", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.AddCookie(&http.Cookie{Name: model.AccessCookieName, Value: testAccessToken(t, users["bob"])})
+	req.Header.Set("X-Request-Id", strings.Repeat("r", 1000))
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("deleting the address = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	if len(st.audit) != 1 {
+		t.Fatalf("%d audit entries, want 1", len(st.audit))
+	}
+	e := st.audit[0]
+	if e.Action != model.AuditActionDelete || e.ResourceType != model.AuditResourceAddress || e.ResourceID != "30" {
+		t.Errorf("the entry is %s %s %s, want the deleted address", e.Action, e.ResourceType, e.ResourceID)
+	}
+	if e.ActorID == nil || *e.ActorID != 2 {
+		t.Errorf("the entry actor is %v, want bob", e.ActorID)
+	}
+	if len(e.RequestID) != model.AuditRequestIDMaxLength {
+		t.Errorf("the request id is %d characters, want it cut to %d", len(e.RequestID), model.AuditRequestIDMaxLength)
+	}
+}
+
+// the entry is saved after the change is committed, so the failed recording must not report the change as failed
+func TestFailedAuditKeepsTheChange(t *testing.T) {
+	st, users := crossUserFixture()
+	st.auditFails = true
+	ts := newTestServer(t, st, newTestConfig())
+
+	resp := doRequest(t, ts, http.MethodDelete, "/api/v1/products/5/reviews/40/media/50", testAccessToken(t, users["bob"]), "")
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("deleting the photo without the audit entry = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if _, ok := st.media[50]; ok {
+		t.Error("the photo was not deleted")
+	}
+}
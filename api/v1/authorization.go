@@ -19,17 +19,17 @@ func (a *API) SessionRequired(next http.HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		err := a.app.TokenValid(r)
 		if err != nil {
-			respondError(w, err)
+			respondError(w, r, err)
 			return
 		}
 
 		ad, err := a.app.ExtractTokenMetadata(r)
 		if err != nil {
-			respondError(w, err)
+			respondError(w, r, err)
 			return
 		}
 		if _, err := a.app.GetAuth(ad); err != nil {
-			respondError(w, err)
+			respondError(w, r, err)
 			return
 		}
 
@@ -40,27 +40,54 @@ func (a *API) SessionRequired(next http.HandlerFunc) http.HandlerFunc {
 	})
 }
 
+// SessionOptional puts the access data of the logged in user in the context, the anonymous requests pass through without it
+func (a *API) SessionOptional(next http.HandlerFunc) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token, loc := app.ExtractAuthTokenFromRequest(r); token == "" || loc == model.TokenLocationAPIKey {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ad, err := a.app.ExtractTokenMetadata(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if _, err := a.app.GetAuth(ad); err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), app.AccessDataCtxKey, ad)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 // AdminSessionRequired requires admin role to access the resource
 func (a *API) AdminSessionRequired(next http.HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		err := a.app.TokenValid(r)
 		if err != nil {
-			respondError(w, err)
+			respondError(w, r, err)
 			return
 		}
 
 		ad, err := a.app.ExtractTokenMetadata(r)
 		if err != nil {
-			respondError(w, err)
+			respondError(w, r, err)
 			return
 		}
 		if _, err := a.app.GetAuth(ad); err != nil {
-			respondError(w, err)
+			respondError(w, r, err)
 			return
 		}
 
 		if ad.Role != model.AdminRole {
-			respondError(w, model.NewAppErr("AdminSessionRequired", model.ErrUnauthorized, locale.GetUserLocalizer("en"), msgAdminRequired, http.StatusForbidden, nil))
+			respondError(w, r, model.NewAppErr("AdminSessionRequired", model.ErrUnauthorized, locale.GetUserLocalizer("en"), msgAdminRequired, http.StatusForbidden, nil))
+			return
+		}
+		if err := a.app.CheckAdminTwoFactor(ad); err != nil {
+			respondError(w, r, err)
 			return
 		}
 
@@ -70,3 +97,49 @@ func (a *API) AdminSessionRequired(next http.HandlerFunc) http.HandlerFunc {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// APIKeyRequired requires the valid api key to access the resource, every request made with the key is recorded
+func (a *API) APIKeyRequired(next http.HandlerFunc) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, _ := app.ExtractAuthTokenFromRequest(r)
+		ad, err := a.app.AuthenticateAPIKey(key)
+		if err != nil {
+			respondError(w, r, err)
+			return
+		}
+		if err := a.app.RecordAPIKeyUsage(ad, r); err != nil {
+			respondError(w, r, err)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), app.AccessDataCtxKey, ad)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequirePermission requires the session of the user or the api key that is granted the permission to access the resource
+func (a *API) RequirePermission(permission string, next http.HandlerFunc) http.HandlerFunc {
+	checkPermission := func(w http.ResponseWriter, r *http.Request) {
+		ad := a.app.GetAccessDataFromContext(r.Context())
+		if !ad.HasPermission(permission) {
+			respondError(w, r, model.NewAppErr("RequirePermission", model.ErrUnauthorized, locale.GetUserLocalizer("en"), msgAdminRequired, http.StatusForbidden, map[string]string{"permission": permission}))
+			return
+		}
+		if err := a.app.CheckAdminTwoFactor(ad); err != nil {
+			respondError(w, r, err)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, loc := app.ExtractAuthTokenFromRequest(r); loc == model.TokenLocationAPIKey {
+			a.APIKeyRequired(checkPermission)(w, r)
+			return
+		}
+		a.SessionRequired(checkPermission)(w, r)
+	})
+}
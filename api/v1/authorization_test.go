@@ -0,0 +1,157 @@
+package apiv1
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+)
+
+// crossUserFixture is the store where bob owns the order, the address and the review with the photo
+func crossUserFixture() (*testStore, map[string]*model.User) {
+	users := map[string]*model.User{
+		"alice":     {ID: 1, Role: model.UserRole},
+		"bob":       {ID: 2, Role: model.UserRole},
+		"moderator": {ID: 3, Role: model.UserRole},
+		"admin":     {ID: 4, Role: model.AdminRole},
+	}
+
+	st := newTestStore()
+	for _, u := range users {
+		st.users[u.ID] = u
+	}
+	st.orders[20] = &model.Order{ID: 20, UserID: 2, Status: model.OrderStatusSuccess.String()}
+	st.addresses[30] = 2
+	st.reviews[40] = &model.ProductReview{ID: 40, ProductID: 5, UserID: 2, Status: model.ReviewStatusApproved}
+	st.media[50] = &model.ProductReviewMedia{ID: 50, ReviewID: 40}
+	return st, users
+}
+
+func TestCrossUserAccessDenied(t *testing.T) {
+	st, users := crossUserFixture()
+	ts := newTestServer(t, st, newTestConfig())
+	alice := testAccessToken(t, users["alice"])
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"order", http.MethodGet, "/api/v1/orders/20", "", http.StatusForbidden},
+		{"order details", http.MethodGet, "/api/v1/orders/20/details", "", http.StatusForbidden},
+		{"order details pdf", http.MethodGet, "/api/v1/orders/20/details/pdf", "", http.StatusForbidden},
+		{"cancel order", http.MethodPost, "/api/v1/orders/20/cancel", "", http.StatusForbidden},
+		{"refund order", http.MethodPost, "/api/v1/orders/20/refund", "", http.StatusForbidden},
+		{"user orders", http.MethodGet, "/api/v1/users/2/orders", "", http.StatusForbidden},
+		{"profile", http.MethodGet, "/api/v1/users/2", "", http.StatusForbidden},
+		{"patch profile", http.MethodPatch, "/api/v1/users/2", "", http.StatusForbidden},
+		{"delete account", http.MethodDelete, "/api/v1/users/2", "", http.StatusForbidden},
+		{"address", http.MethodGet, "/api/v1/users/addresses/30", "", http.StatusNotFound},
+		{"patch address", http.MethodPatch, "/api/v1/users/addresses/30", "{}", http.StatusNotFound},
+		{"delete address", http.MethodDelete, "/api/v1/users/addresses/30", "", http.StatusNotFound},
+		{"patch review", http.MethodPatch, "/api/v1/products/5/reviews/40", "{}", http.StatusForbidden},
+		{"delete review", http.MethodDelete, "/api/v1/products/5/reviews/40", "", http.StatusForbidden},
+		{"delete review photo", http.MethodDelete, "/api/v1/products/5/reviews/40/media/50", "", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := doRequest(t, ts, tt.method, tt.path, alice, tt.body)
+			if resp.StatusCode != tt.want {
+				t.Errorf("%s %s = %d, want %d", tt.method, tt.path, resp.StatusCode, tt.want)
+			}
+		})
+	}
+
+	if _, ok := st.addresses[30]; !ok {
+		t.Error("bob's address was deleted by alice")
+	}
+	if _, ok := st.media[50]; !ok {
+		t.Error("bob's review photo was deleted by alice")
+	}
+}
+
+func TestCrossUserAccessAllowed(t *testing.T) {
+	st, users := crossUserFixture()
+	ts := newTestServer(t, st, newTestConfig())
+
+	tests := []struct {
+		name  string
+		token string
+		path  string
+	}{
+		{"owner reads the order", testAccessToken(t, users["bob"]), "/api/v1/orders/20"},
+		{"owner reads the address", testAccessToken(t, users["bob"]), "/api/v1/users/addresses/30"},
+		{"owner reads the profile", testAccessToken(t, users["bob"]), "/api/v1/users/2"},
+		{"staff reads the order", testAccessToken(t, users["alice"], model.PermissionOrderRead), "/api/v1/orders/20"},
+		{"admin reads the order", testAccessToken(t, users["admin"]), "/api/v1/orders/20"},
+		{"admin reads the profile", testAccessToken(t, users["admin"]), "/api/v1/users/2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if resp := doRequest(t, ts, http.MethodGet, tt.path, tt.token, ""); resp.StatusCode != http.StatusOK {
+				t.Errorf("GET %s = %d, want %d", tt.path, resp.StatusCode, http.StatusOK)
+			}
+		})
+	}
+}
+
+func TestReviewPhotoModeratorBypass(t *testing.T) {
+	st, users := crossUserFixture()
+	ts := newTestServer(t, st, newTestConfig())
+	moderator := testAccessToken(t, users["moderator"], model.PermissionReviewModerate)
+
+	if resp := doRequest(t, ts, http.MethodDelete, "/api/v1/products/5/reviews/40/media/50", moderator, ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("moderator deleting the photo = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if _, ok := st.media[50]; ok {
+		t.Error("the photo was not deleted")
+	}
+}
+
+func TestAnonymousAccessDenied(t *testing.T) {
+	st, _ := crossUserFixture()
+	ts := newTestServer(t, st, newTestConfig())
+
+	for _, path := range []string{"/api/v1/orders/20", "/api/v1/users/2", "/api/v1/users/addresses/30"} {
+		if resp := doRequest(t, ts, http.MethodGet, path, "", ""); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("anonymous GET %s = %d, want %d", path, resp.StatusCode, http.StatusUnauthorized)
+		}
+	}
+}
+
+func TestStaffCannotChangeAdminAccount(t *testing.T) {
+	st, users := crossUserFixture()
+	ts := newTestServer(t, st, newTestConfig())
+	staff := testAccessToken(t, users["alice"], model.PermissionUserRead, model.PermissionUserWrite)
+
+	if resp := doRequest(t, ts, http.MethodDelete, "/api/v1/users/4", staff, ""); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("staff deleting the admin = %d, want %d", resp.StatusCode, http.StatusForbidden)
+	}
+	if resp := doRequest(t, ts, http.MethodDelete, "/api/v1/users/bulk", staff, "[2, 4]"); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("staff bulk deleting the admin = %d, want %d", resp.StatusCode, http.StatusForbidden)
+	}
+}
+
+func TestAdminWithoutTwoFactorDenied(t *testing.T) {
+	st, users := crossUserFixture()
+	cfg := newTestConfig()
+	cfg.AuthSettings.TwoFactorRequiredForAdmins = true
+	ts := newTestServer(t, st, cfg)
+	admin := testAccessToken(t, users["admin"])
+
+	if resp := doRequest(t, ts, http.MethodGet, "/api/v1/orders/20", admin, ""); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("admin without two-factor reading the order = %d, want %d", resp.StatusCode, http.StatusForbidden)
+	}
+	if resp := doRequest(t, ts, http.MethodGet, "/api/v1/users/4", admin, ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("admin without two-factor reading the own profile = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	st.twoFactor[4] = true
+	if resp := doRequest(t, ts, http.MethodGet, "/api/v1/orders/20", admin, ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("admin with two-factor reading the order = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
@@ -20,23 +20,29 @@ var (
 	msgBrandDeleteerr        = &i18n.Message{ID: "api.brand.delete_brand.app_error", Other: "could not delete brand"}
 	msgBrandMultipartErr     = &i18n.Message{ID: "api.brand.create_brand.multipart.app_error", Other: "could not decode brand multipart data"}
 	msgBrandURLParamErr      = &i18n.Message{ID: "api.brand.url.params.app_error", Other: "could not parse URL params"}
-	msgBrandPatchFromJSONErr = &i18n.Message{ID: "api.brand.patch_brand.app_error", Other: "could not decode brand patch data"}
+	msgBrandPatchFromJSONErr = &i18n.Message{ID: "api.brand.patch_brand.json.app_error", Other: "could not decode brand patch data"}
 )
 
 // InitBrands inits the brand routes
 func InitBrands(a *API) {
-	a.Routes.Brands.Post("/", a.AdminSessionRequired(a.createBrand))
+	a.Routes.Brands.Post("/", a.RequirePermission(model.PermissionBrandWrite, a.createBrand))
 	a.Routes.Brands.Get("/count", a.getBrandsCount)
 	a.Routes.Brands.Get("/", a.getBrands)
-	a.Routes.Brands.Delete("/bulk", a.deleteBrands)
+	a.Routes.Brands.Delete("/bulk", a.RequirePermission(model.PermissionBrandWrite, a.deleteBrands))
 	a.Routes.Brand.Get("/", a.getBrand)
-	a.Routes.Brand.Patch("/", a.AdminSessionRequired(a.patchBrand))
-	a.Routes.Brand.Delete("/", a.AdminSessionRequired(a.deleteBrand))
+	a.Routes.Brand.Patch("/", a.RequirePermission(model.PermissionBrandWrite, a.patchBrand))
+	a.Routes.Brand.Delete("/", a.RequirePermission(model.PermissionBrandWrite, a.deleteBrand))
+
+	// translations
+	a.Routes.Brand.Get("/translations", a.RequirePermission(model.PermissionBrandWrite, a.getTranslations(model.TranslationResourceBrand, "brand_id")))
+	a.Routes.Brand.Get("/translations/{translation_locale:[A-Za-z-]+}", a.RequirePermission(model.PermissionBrandWrite, a.getTranslation(model.TranslationResourceBrand, "brand_id")))
+	a.Routes.Brand.Put("/translations/{translation_locale:[A-Za-z-]+}", a.RequirePermission(model.PermissionBrandWrite, a.saveTranslation(model.TranslationResourceBrand, "brand_id")))
+	a.Routes.Brand.Delete("/translations/{translation_locale:[A-Za-z-]+}", a.RequirePermission(model.PermissionBrandWrite, a.deleteTranslation(model.TranslationResourceBrand, "brand_id")))
 }
 
 func (a *API) createBrand(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseMultipartForm(model.FileUploadSizeLimit); err != nil {
-		respondError(w, model.NewAppErr("createBrand", model.ErrInternal, locale.GetUserLocalizer("en"), msgBrandMultipartErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("createBrand", model.ErrInternal, locale.GetUserLocalizer("en"), msgBrandMultipartErr, http.StatusInternalServerError, nil))
 		return
 	}
 
@@ -45,7 +51,7 @@ func (a *API) createBrand(w http.ResponseWriter, r *http.Request) {
 
 	b := &model.Brand{}
 	if err := model.SchemaDecoder.Decode(b, mpf.Value); err != nil {
-		respondError(w, model.NewAppErr("createBrand", model.ErrInternal, locale.GetUserLocalizer("en"), msgBrandMultipartErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("createBrand", model.ErrInternal, locale.GetUserLocalizer("en"), msgBrandMultipartErr, http.StatusInternalServerError, nil))
 		return
 	}
 
@@ -54,9 +60,9 @@ func (a *API) createBrand(w http.ResponseWriter, r *http.Request) {
 		fh = mpf.File["logo"][0]
 	}
 
-	brand, bErr := a.app.CreateBrand(b, fh)
+	brand, bErr := a.audited(r).CreateBrand(b, fh)
 	if bErr != nil {
-		respondError(w, bErr)
+		respondError(w, r, bErr)
 		return
 	}
 	respondJSON(w, http.StatusCreated, brand)
@@ -65,12 +71,16 @@ func (a *API) createBrand(w http.ResponseWriter, r *http.Request) {
 func (a *API) getBrand(w http.ResponseWriter, r *http.Request) {
 	bid, e := strconv.ParseInt(chi.URLParam(r, "brand_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("getBrand", model.ErrInternal, locale.GetUserLocalizer("en"), msgBrandURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("getBrand", model.ErrInternal, locale.GetUserLocalizer("en"), msgBrandURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 	b, err := a.app.GetBrand(bid)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
+		return
+	}
+	if err := a.app.TranslateBrand(b, locale.LocaleFromContext(r.Context())); err != nil {
+		respondError(w, r, err)
 		return
 	}
 	respondJSON(w, http.StatusOK, b)
@@ -85,7 +95,11 @@ func (a *API) getBrands(w http.ResponseWriter, r *http.Request) {
 	pages := pagination.NewFromRequest(r)
 	brands, err := a.app.GetBrands(pages.Limit(), pages.Offset())
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
+		return
+	}
+	if err := a.app.TranslateBrands(brands, locale.LocaleFromContext(r.Context())); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
@@ -101,12 +115,12 @@ func (a *API) getBrands(w http.ResponseWriter, r *http.Request) {
 func (a *API) patchBrand(w http.ResponseWriter, r *http.Request) {
 	bid, err := strconv.ParseInt(chi.URLParam(r, "brand_id"), 10, 64)
 	if err != nil {
-		respondError(w, model.NewAppErr("patchBrand", model.ErrInternal, locale.GetUserLocalizer("en"), msgBrandURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("patchBrand", model.ErrInternal, locale.GetUserLocalizer("en"), msgBrandURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 
 	if err := r.ParseMultipartForm(model.FileUploadSizeLimit); err != nil {
-		respondError(w, model.NewAppErr("patchBrand", model.ErrInternal, locale.GetUserLocalizer("en"), msgBrandMultipartErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("patchBrand", model.ErrInternal, locale.GetUserLocalizer("en"), msgBrandMultipartErr, http.StatusInternalServerError, nil))
 		return
 	}
 
@@ -115,7 +129,7 @@ func (a *API) patchBrand(w http.ResponseWriter, r *http.Request) {
 
 	patch := &model.BrandPatch{}
 	if err := model.SchemaDecoder.Decode(patch, mpf.Value); err != nil {
-		respondError(w, model.NewAppErr("patchBrand", model.ErrInternal, locale.GetUserLocalizer("en"), msgBrandMultipartErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("patchBrand", model.ErrInternal, locale.GetUserLocalizer("en"), msgBrandMultipartErr, http.StatusInternalServerError, nil))
 		return
 	}
 
@@ -124,9 +138,9 @@ func (a *API) patchBrand(w http.ResponseWriter, r *http.Request) {
 		image = mpf.File["logo"][0]
 	}
 
-	ubrand, bErr := a.app.PatchBrand(bid, patch, image)
+	ubrand, bErr := a.audited(r).PatchBrand(bid, patch, image)
 	if err != nil {
-		respondError(w, bErr)
+		respondError(w, r, bErr)
 		return
 	}
 
@@ -136,11 +150,11 @@ func (a *API) patchBrand(w http.ResponseWriter, r *http.Request) {
 func (a *API) deleteBrand(w http.ResponseWriter, r *http.Request) {
 	bid, err := strconv.ParseInt(chi.URLParam(r, "brand_id"), 10, 64)
 	if err != nil {
-		respondError(w, model.NewAppErr("deleteBrand", model.ErrInternal, locale.GetUserLocalizer("en"), msgBrandURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("deleteBrand", model.ErrInternal, locale.GetUserLocalizer("en"), msgBrandURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
-	if err := a.app.DeleteBrand(bid); err != nil {
-		respondError(w, err)
+	if err := a.audited(r).DeleteBrand(bid); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
@@ -150,8 +164,8 @@ func (a *API) deleteBrand(w http.ResponseWriter, r *http.Request) {
 func (a *API) deleteBrands(w http.ResponseWriter, r *http.Request) {
 	ids := model.IntSliceFromJSON(r.Body)
 
-	if err := a.app.DeleteBrands(ids); err != nil {
-		respondError(w, err)
+	if err := a.audited(r).DeleteBrands(ids); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
@@ -25,19 +25,25 @@ var (
 
 // InitCategories inits the category routes
 func InitCategories(a *API) {
-	a.Routes.Categories.Post("/", a.AdminSessionRequired(a.createCategory))
+	a.Routes.Categories.Post("/", a.RequirePermission(model.PermissionCategoryWrite, a.createCategory))
 	a.Routes.Categories.Get("/count", a.getCategoriesCount)
 	a.Routes.Categories.Get("/", a.getCategories)
 	a.Routes.Categories.Get("/featured", a.getFeaturedCategories)
-	a.Routes.Categories.Delete("/bulk", a.deleteCategories)
+	a.Routes.Categories.Delete("/bulk", a.RequirePermission(model.PermissionCategoryWrite, a.deleteCategories))
 	a.Routes.Category.Get("/", a.getCategory)
-	a.Routes.Category.Patch("/", a.AdminSessionRequired(a.patchCategory))
-	a.Routes.Category.Delete("/", a.AdminSessionRequired(a.deleteCategory))
+	a.Routes.Category.Patch("/", a.RequirePermission(model.PermissionCategoryWrite, a.patchCategory))
+	a.Routes.Category.Delete("/", a.RequirePermission(model.PermissionCategoryWrite, a.deleteCategory))
+
+	// translations
+	a.Routes.Category.Get("/translations", a.RequirePermission(model.PermissionCategoryWrite, a.getTranslations(model.TranslationResourceCategory, "category_id")))
+	a.Routes.Category.Get("/translations/{translation_locale:[A-Za-z-]+}", a.RequirePermission(model.PermissionCategoryWrite, a.getTranslation(model.TranslationResourceCategory, "category_id")))
+	a.Routes.Category.Put("/translations/{translation_locale:[A-Za-z-]+}", a.RequirePermission(model.PermissionCategoryWrite, a.saveTranslation(model.TranslationResourceCategory, "category_id")))
+	a.Routes.Category.Delete("/translations/{translation_locale:[A-Za-z-]+}", a.RequirePermission(model.PermissionCategoryWrite, a.deleteTranslation(model.TranslationResourceCategory, "category_id")))
 }
 
 func (a *API) createCategory(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseMultipartForm(model.FileUploadSizeLimit); err != nil {
-		respondError(w, model.NewAppErr("createCategory", model.ErrInternal, locale.GetUserLocalizer("en"), msgCategoryMultipartErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("createCategory", model.ErrInternal, locale.GetUserLocalizer("en"), msgCategoryMultipartErr, http.StatusInternalServerError, nil))
 		return
 	}
 
@@ -46,7 +52,7 @@ func (a *API) createCategory(w http.ResponseWriter, r *http.Request) {
 
 	c := &model.Category{}
 	if err := model.SchemaDecoder.Decode(c, mpf.Value); err != nil {
-		respondError(w, model.NewAppErr("createCategory", model.ErrInternal, locale.GetUserLocalizer("en"), msgCategoryMultipartErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("createCategory", model.ErrInternal, locale.GetUserLocalizer("en"), msgCategoryMultipartErr, http.StatusInternalServerError, nil))
 		return
 	}
 
@@ -55,9 +61,9 @@ func (a *API) createCategory(w http.ResponseWriter, r *http.Request) {
 		fh = mpf.File["logo"][0]
 	}
 
-	category, cErr := a.app.CreateCategory(c, fh)
+	category, cErr := a.audited(r).CreateCategory(c, fh)
 	if cErr != nil {
-		respondError(w, cErr)
+		respondError(w, r, cErr)
 		return
 	}
 	respondJSON(w, http.StatusCreated, category)
@@ -66,12 +72,16 @@ func (a *API) createCategory(w http.ResponseWriter, r *http.Request) {
 func (a *API) getCategory(w http.ResponseWriter, r *http.Request) {
 	cid, e := strconv.ParseInt(chi.URLParam(r, "category_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("getCategory", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("getCategory", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 	c, err := a.app.GetCategory(cid)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
+		return
+	}
+	if err := a.app.TranslateCategory(c, locale.LocaleFromContext(r.Context())); err != nil {
+		respondError(w, r, err)
 		return
 	}
 	respondJSON(w, http.StatusOK, c)
@@ -86,7 +96,11 @@ func (a *API) getCategories(w http.ResponseWriter, r *http.Request) {
 	pages := pagination.NewFromRequest(r)
 	categories, err := a.app.GetCategories(pages.Limit(), pages.Offset())
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
+		return
+	}
+	if err := a.app.TranslateCategories(categories, locale.LocaleFromContext(r.Context())); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
@@ -102,12 +116,12 @@ func (a *API) getCategories(w http.ResponseWriter, r *http.Request) {
 func (a *API) patchCategory(w http.ResponseWriter, r *http.Request) {
 	cid, err := strconv.ParseInt(chi.URLParam(r, "category_id"), 10, 64)
 	if err != nil {
-		respondError(w, model.NewAppErr("patchCategory", model.ErrInternal, locale.GetUserLocalizer("en"), msgCategoryURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("patchCategory", model.ErrInternal, locale.GetUserLocalizer("en"), msgCategoryURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 
 	if err := r.ParseMultipartForm(model.FileUploadSizeLimit); err != nil {
-		respondError(w, model.NewAppErr("patchCategory", model.ErrInternal, locale.GetUserLocalizer("en"), msgCategoryMultipartErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("patchCategory", model.ErrInternal, locale.GetUserLocalizer("en"), msgCategoryMultipartErr, http.StatusInternalServerError, nil))
 		return
 	}
 
@@ -116,7 +130,7 @@ func (a *API) patchCategory(w http.ResponseWriter, r *http.Request) {
 
 	patch := &model.CategoryPatch{}
 	if err := model.SchemaDecoder.Decode(patch, mpf.Value); err != nil {
-		respondError(w, model.NewAppErr("patchCategory", model.ErrInternal, locale.GetUserLocalizer("en"), msgCategoryMultipartErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("patchCategory", model.ErrInternal, locale.GetUserLocalizer("en"), msgCategoryMultipartErr, http.StatusInternalServerError, nil))
 		return
 	}
 	patch.SetProperties(patch.PropertiesText)
@@ -126,9 +140,9 @@ func (a *API) patchCategory(w http.ResponseWriter, r *http.Request) {
 		image = mpf.File["logo"][0]
 	}
 
-	ucat, cErr := a.app.PatchCategory(cid, patch, image)
+	ucat, cErr := a.audited(r).PatchCategory(cid, patch, image)
 	if err != nil {
-		respondError(w, cErr)
+		respondError(w, r, cErr)
 		return
 	}
 
@@ -138,11 +152,11 @@ func (a *API) patchCategory(w http.ResponseWriter, r *http.Request) {
 func (a *API) deleteCategory(w http.ResponseWriter, r *http.Request) {
 	cid, err := strconv.ParseInt(chi.URLParam(r, "category_id"), 10, 64)
 	if err != nil {
-		respondError(w, model.NewAppErr("deleteCategory", model.ErrInternal, locale.GetUserLocalizer("en"), msgCategoryURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("deleteCategory", model.ErrInternal, locale.GetUserLocalizer("en"), msgCategoryURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
-	if err := a.app.DeleteCategory(cid); err != nil {
-		respondError(w, err)
+	if err := a.audited(r).DeleteCategory(cid); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
@@ -153,7 +167,11 @@ func (a *API) getFeaturedCategories(w http.ResponseWriter, r *http.Request) {
 	pages := pagination.NewFromRequest(r)
 	featured, err := a.app.GetFeaturedCategories(pages.Limit(), pages.Offset())
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
+		return
+	}
+	if err := a.app.TranslateCategories(featured, locale.LocaleFromContext(r.Context())); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
@@ -169,8 +187,8 @@ func (a *API) getFeaturedCategories(w http.ResponseWriter, r *http.Request) {
 func (a *API) deleteCategories(w http.ResponseWriter, r *http.Request) {
 	ids := model.IntSliceFromJSON(r.Body)
 
-	if err := a.app.DeleteCategories(ids); err != nil {
-		respondError(w, err)
+	if err := a.audited(r).DeleteCategories(ids); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
@@ -0,0 +1,80 @@
+package apiv1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/go-chi/chi"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgMailURLParamErr = &i18n.Message{ID: "api.dev.mailbox.url.params.app_error", Other: "invalid mail url param"}
+)
+
+// InitDev inits the development helper routes, they are only registered in the development mode
+func InitDev(a *API) {
+	if !a.app.IsDev() {
+		return
+	}
+
+	a.Routes.Dev.Get("/mailbox", a.getMailbox)
+	a.Routes.Dev.Delete("/mailbox", a.clearMailbox)
+	a.Routes.Dev.Get("/mailbox/{mail_id:[0-9]+}", a.getMailboxMail)
+	a.Routes.Dev.Get("/mailbox/{mail_id:[0-9]+}/html", a.getMailboxMailHTML)
+}
+
+func (a *API) getMailbox(w http.ResponseWriter, r *http.Request) {
+	mails, err := a.app.GetCapturedMails()
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, mails)
+}
+
+func (a *API) getMailboxMail(w http.ResponseWriter, r *http.Request) {
+	id, e := strconv.ParseInt(chi.URLParam(r, "mail_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("getMailboxMail", model.ErrInternal, locale.GetUserLocalizer("en"), msgMailURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	mail, err := a.app.GetCapturedMail(id)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, mail)
+}
+
+func (a *API) getMailboxMailHTML(w http.ResponseWriter, r *http.Request) {
+	id, e := strconv.ParseInt(chi.URLParam(r, "mail_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("getMailboxMailHTML", model.ErrInternal, locale.GetUserLocalizer("en"), msgMailURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	mail, err := a.app.GetCapturedMail(id)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(mail.HTML))
+}
+
+func (a *API) clearMailbox(w http.ResponseWriter, r *http.Request) {
+	if err := a.app.ClearCapturedMails(); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondOK(w)
+}
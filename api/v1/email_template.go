@@ -0,0 +1,108 @@
+package apiv1
+
+import (
+	"net/http"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/go-chi/chi"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgEmailTemplateFromJSON = &i18n.Message{ID: "api.email_template.save_email_template.json.app_error", Other: "could not decode email template json data"}
+)
+
+// InitEmailTemplates inits the email template routes
+func InitEmailTemplates(a *API) {
+	a.Routes.EmailTemplates.Get("/", a.RequirePermission(model.PermissionEmailTemplateManage, a.getEmailTemplates))
+	a.Routes.EmailTemplate.Get("/", a.RequirePermission(model.PermissionEmailTemplateManage, a.getEmailTemplate))
+	a.Routes.EmailTemplate.Put("/", a.RequirePermission(model.PermissionEmailTemplateManage, a.saveEmailTemplate))
+	a.Routes.EmailTemplate.Delete("/", a.RequirePermission(model.PermissionEmailTemplateManage, a.deleteEmailTemplate))
+	a.Routes.EmailTemplate.Post("/preview", a.RequirePermission(model.PermissionEmailTemplateManage, a.previewEmailTemplate))
+	a.Routes.EmailTemplate.Get("/preview", a.RequirePermission(model.PermissionEmailTemplateManage, a.previewEmailTemplateHTML))
+}
+
+func (a *API) getEmailTemplates(w http.ResponseWriter, r *http.Request) {
+	overrides, err := a.app.GetEmailTemplateOverrides()
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"names":     model.EmailTemplateNames,
+		"overrides": overrides,
+	})
+}
+
+func (a *API) getEmailTemplate(w http.ResponseWriter, r *http.Request) {
+	et, err := a.app.GetEmailTemplate(chi.URLParam(r, "template_name"), chi.URLParam(r, "template_locale"))
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, et)
+}
+
+func (a *API) saveEmailTemplate(w http.ResponseWriter, r *http.Request) {
+	et, e := model.EmailTemplateFromJSON(r.Body)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("saveEmailTemplate", model.ErrInternal, locale.GetUserLocalizer("en"), msgEmailTemplateFromJSON, http.StatusInternalServerError, nil))
+		return
+	}
+	et.Name = chi.URLParam(r, "template_name")
+	et.Locale = chi.URLParam(r, "template_locale")
+
+	saved, err := a.audited(r).SaveEmailTemplate(et)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, saved)
+}
+
+func (a *API) deleteEmailTemplate(w http.ResponseWriter, r *http.Request) {
+	if err := a.audited(r).DeleteEmailTemplate(chi.URLParam(r, "template_name"), chi.URLParam(r, "template_locale")); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondOK(w)
+}
+
+// previewEmailTemplate renders the draft from the body without saving it, or the current template if the body is empty
+func (a *API) previewEmailTemplate(w http.ResponseWriter, r *http.Request) {
+	var draft *model.EmailTemplate
+	if r.ContentLength != 0 {
+		et, e := model.EmailTemplateFromJSON(r.Body)
+		if e != nil {
+			respondError(w, r, model.NewAppErr("previewEmailTemplate", model.ErrInternal, locale.GetUserLocalizer("en"), msgEmailTemplateFromJSON, http.StatusInternalServerError, nil))
+			return
+		}
+		draft = et
+	}
+
+	preview, err := a.app.PreviewEmailTemplate(chi.URLParam(r, "template_name"), chi.URLParam(r, "template_locale"), draft)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, preview)
+}
+
+// previewEmailTemplateHTML renders the current template as the html page so it can be opened in the browser
+func (a *API) previewEmailTemplateHTML(w http.ResponseWriter, r *http.Request) {
+	preview, err := a.app.PreviewEmailTemplate(chi.URLParam(r, "template_name"), chi.URLParam(r, "template_locale"), nil)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(preview.HTML))
+}
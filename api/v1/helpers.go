@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
 )
 
 func respondJSON(w http.ResponseWriter, code int, obj interface{}) error {
@@ -20,12 +22,18 @@ func respondJSON(w http.ResponseWriter, code int, obj interface{}) error {
 	return err
 }
 
-func respondError(w http.ResponseWriter, appErr *model.AppErr) error {
+// respondError sends the error translated to the request locale
+func respondError(w http.ResponseWriter, r *http.Request, appErr *model.AppErr) error {
+	appErr.Localize(locale.FromContext(r.Context()))
+
 	b, err := json.Marshal(appErr)
 	if err != nil {
 		return fmt.Errorf("could not encode json response: %v - %v", appErr, err)
 	}
 	w.Header().Set("Content-Type", "application/json")
+	if ra, ok := appErr.Details.(*model.RetryAfter); ok {
+		w.Header().Set(model.HeaderRetryAfter, strconv.Itoa(ra.Seconds))
+	}
 	w.WriteHeader(appErr.StatusCode)
 	_, err = w.Write(b)
 
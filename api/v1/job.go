@@ -0,0 +1,86 @@
+package apiv1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/dankobgd/ecommerce-shop/utils/pagination"
+	"github.com/go-chi/chi"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgJobURLParamErr = &i18n.Message{ID: "api.job.url.params.app_error", Other: "invalid job url param"}
+)
+
+// InitJobs inits the background job status routes
+func InitJobs(a *API) {
+	a.Routes.Jobs.Get("/", a.RequirePermission(model.PermissionJobManage, a.getJobs))
+	a.Routes.Jobs.Get("/stats", a.RequirePermission(model.PermissionJobManage, a.getJobStats))
+	a.Routes.Job.Get("/", a.RequirePermission(model.PermissionJobManage, a.getJob))
+	a.Routes.Job.Post("/retry", a.RequirePermission(model.PermissionJobManage, a.retryJob))
+}
+
+func (a *API) getJobs(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	jobType := r.URL.Query().Get("type")
+
+	pages := pagination.NewFromRequest(r)
+	jobs, err := a.app.GetJobs(status, jobType, pages.Limit(), pages.Offset())
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	totalCount := -1
+	if len(jobs) > 0 {
+		totalCount = jobs[0].TotalCount
+	}
+	pages.SetData(jobs, totalCount)
+
+	respondJSON(w, http.StatusOK, pages)
+}
+
+func (a *API) getJobStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := a.app.GetJobStats()
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, stats)
+}
+
+func (a *API) getJob(w http.ResponseWriter, r *http.Request) {
+	id, e := strconv.ParseInt(chi.URLParam(r, "job_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("getJob", model.ErrInternal, locale.GetUserLocalizer("en"), msgJobURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	job, err := a.app.GetJob(id)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, job)
+}
+
+func (a *API) retryJob(w http.ResponseWriter, r *http.Request) {
+	id, e := strconv.ParseInt(chi.URLParam(r, "job_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("retryJob", model.ErrInternal, locale.GetUserLocalizer("en"), msgJobURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	job, err := a.audited(r).RetryJob(id)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, job)
+}
@@ -0,0 +1,18 @@
+package apiv1
+
+import (
+	"net/http"
+
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+)
+
+// Localize resolves the request locale and puts it in the request context together with its localizer
+func (a *API) Localize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang := a.app.ResolveLocale(r)
+		w.Header().Set("Content-Language", lang)
+		ctx := locale.WithLocale(r.Context(), lang)
+		ctx = locale.WithLocalizer(ctx, locale.GetUserLocalizer(lang))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,349 @@
+package apiv1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/app"
+	"github.com/dankobgd/ecommerce-shop/config"
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/store"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/dankobgd/ecommerce-shop/zlog"
+	"github.com/dgrijalva/jwt-go/v4"
+	"github.com/go-chi/chi"
+)
+
+const testAccessTokenSecret = "test-access-token-secret"
+
+func TestMain(m *testing.M) {
+	// the translations are loaded relative to the repository root
+	if err := os.Chdir("../.."); err != nil {
+		panic(err)
+	}
+	locale.InitTranslations()
+	os.Exit(m.Run())
+}
+
+// testStore is the in memory store with just enough of the stores for the api tests,
+// calling the store that is not implemented panics so the tests notice it
+type testStore struct {
+	store.Store
+	users     map[int64]*model.User
+	orders    map[int64]*model.Order
+	addresses map[int64]int64
+	reviews   map[int64]*model.ProductReview
+	media     map[int64]*model.ProductReviewMedia
+	twoFactor map[int64]bool
+	// the oauth logins
+	states     map[string]*model.OAuthState
+	identities map[string]int64
+	locked     map[string]bool
+	tokens     []*model.Token
+	jobs       []*model.Job
+	// the audit log, the entries can't be saved when auditFails is set
+	audit      []*model.AuditEntry
+	auditFails bool
+}
+
+func newTestStore() *testStore {
+	return &testStore{
+		users:     make(map[int64]*model.User),
+		orders:    make(map[int64]*model.Order),
+		addresses: make(map[int64]int64),
+		reviews:   make(map[int64]*model.ProductReview),
+		media:     make(map[int64]*model.ProductReviewMedia),
+		twoFactor: make(map[int64]bool),
+
+		states:     make(map[string]*model.OAuthState),
+		identities: make(map[string]int64),
+		locked:     make(map[string]bool),
+	}
+}
+
+func (s *testStore) AccessToken() store.AccessTokenStore { return testAccessTokenStore{} }
+func (s *testStore) Session() store.SessionStore         { return testSessionStore{} }
+func (s *testStore) User() store.UserStore               { return testUserStore{s: s} }
+func (s *testStore) Order() store.OrderStore             { return testOrderStore{s: s} }
+func (s *testStore) Address() store.AddressStore         { return testAddressStore{s: s} }
+func (s *testStore) ProductReview() store.ProductReviewStore {
+	return testProductReviewStore{s: s}
+}
+func (s *testStore) ProductReviewMedia() store.ProductReviewMediaStore {
+	return testProductReviewMediaStore{s: s}
+}
+func (s *testStore) Job() store.JobStore { return testJobStore{s: s} }
+func (s *testStore) TwoFactor() store.TwoFactorStore {
+	return testTwoFactorStore{s: s}
+}
+func (s *testStore) OAuthState() store.OAuthStateStore { return testOAuthStateStore{s: s} }
+func (s *testStore) UserIdentity() store.UserIdentityStore {
+	return testUserIdentityStore{s: s}
+}
+func (s *testStore) Attempt() store.AttemptStore { return testAttemptStore{s: s} }
+func (s *testStore) Token() store.TokenStore     { return testTokenStore{s: s} }
+func (s *testStore) Audit() store.AuditStore     { return testAuditStore{s: s} }
+
+func testNotFound(where string) *model.AppErr {
+	return model.NewAppErr(where, model.ErrNotFound, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusNotFound, nil)
+}
+
+type testAccessTokenStore struct{ store.AccessTokenStore }
+
+func (testAccessTokenStore) GetAuth(ad *model.AccessData) (int64, *model.AppErr) {
+	return ad.UserID, nil
+}
+
+type testSessionStore struct{ store.SessionStore }
+
+func (testSessionStore) Touch(sessionID string) (bool, *model.AppErr) { return true, nil }
+
+type testUserStore struct {
+	store.UserStore
+	s *testStore
+}
+
+func (us testUserStore) Get(id int64) (*model.User, *model.AppErr) {
+	if u, ok := us.s.users[id]; ok {
+		c := *u
+		return &c, nil
+	}
+	return nil, testNotFound("testUserStore.Get")
+}
+
+func (us testUserStore) GetAllOrders(uid int64, limit, offset int) ([]*model.Order, *model.AppErr) {
+	orders := make([]*model.Order, 0)
+	for _, o := range us.s.orders {
+		if o.UserID == uid {
+			orders = append(orders, o)
+		}
+	}
+	return orders, nil
+}
+
+type testOrderStore struct {
+	store.OrderStore
+	s *testStore
+}
+
+func (ost testOrderStore) Get(id int64) (*model.Order, *model.AppErr) {
+	if o, ok := ost.s.orders[id]; ok {
+		c := *o
+		return &c, nil
+	}
+	return nil, testNotFound("testOrderStore.Get")
+}
+
+// testAddressStore keeps the owner of each address, the lookups are scoped to the user like the sql ones
+type testAddressStore struct {
+	store.AddressStore
+	s *testStore
+}
+
+func (as testAddressStore) Get(userID, addressID int64) (*model.Address, *model.AppErr) {
+	if owner, ok := as.s.addresses[addressID]; ok && owner == userID {
+		return &model.Address{ID: addressID}, nil
+	}
+	return nil, testNotFound("testAddressStore.Get")
+}
+
+func (as testAddressStore) Update(addressID int64, addr *model.Address) (*model.Address, *model.AppErr) {
+	return addr, nil
+}
+
+func (as testAddressStore) Delete(addressID int64) *model.AppErr {
+	delete(as.s.addresses, addressID)
+	return nil
+}
+
+type testProductReviewStore struct {
+	store.ProductReviewStore
+	s *testStore
+}
+
+func (rs testProductReviewStore) Get(pid, rid int64) (*model.ProductReview, *model.AppErr) {
+	if rev, ok := rs.s.reviews[rid]; ok && rev.ProductID == pid {
+		c := *rev
+		return &c, nil
+	}
+	return nil, testNotFound("testProductReviewStore.Get")
+}
+
+type testProductReviewMediaStore struct {
+	store.ProductReviewMediaStore
+	s *testStore
+}
+
+func (ms testProductReviewMediaStore) Get(rid, id int64) (*model.ProductReviewMedia, *model.AppErr) {
+	if m, ok := ms.s.media[id]; ok && m.ReviewID == rid {
+		return m, nil
+	}
+	return nil, testNotFound("testProductReviewMediaStore.Get")
+}
+
+func (ms testProductReviewMediaStore) Delete(rid, id int64) *model.AppErr {
+	delete(ms.s.media, id)
+	return nil
+}
+
+type testTwoFactorStore struct {
+	store.TwoFactorStore
+	s *testStore
+}
+
+func (ts testTwoFactorStore) Get(userID int64) (*model.TwoFactor, *model.AppErr) {
+	if enabled, ok := ts.s.twoFactor[userID]; ok {
+		return &model.TwoFactor{UserID: userID, Enabled: enabled}, nil
+	}
+	return nil, nil
+}
+
+type testOAuthStateStore struct {
+	store.OAuthStateStore
+	s *testStore
+}
+
+func (ss testOAuthStateStore) Take(key string) (*model.OAuthState, *model.AppErr) {
+	state := ss.s.states[key]
+	delete(ss.s.states, key)
+	return state, nil
+}
+
+// testUserIdentityStore links the provider subjects to the users
+type testUserIdentityStore struct {
+	store.UserIdentityStore
+	s *testStore
+}
+
+func (is testUserIdentityStore) GetByProvider(provider, subject string) (*model.UserIdentity, *model.AppErr) {
+	if uid, ok := is.s.identities[provider+":"+subject]; ok {
+		return &model.UserIdentity{ID: uid, UserID: uid, Provider: provider, Subject: subject}, nil
+	}
+	return nil, nil
+}
+
+func (is testUserIdentityStore) UpdateLastLogin(id int64, email *string) *model.AppErr {
+	return nil
+}
+
+type testAttemptStore struct {
+	store.AttemptStore
+	s *testStore
+}
+
+func (as testAttemptStore) LockedFor(key string) (time.Duration, *model.AppErr) {
+	if as.s.locked[key] {
+		return time.Minute, nil
+	}
+	return 0, nil
+}
+
+type testTokenStore struct {
+	store.TokenStore
+	s *testStore
+}
+
+func (ts testTokenStore) Save(token *model.Token) *model.AppErr {
+	ts.s.tokens = append(ts.s.tokens, token)
+	return nil
+}
+
+type testAuditStore struct {
+	store.AuditStore
+	s *testStore
+}
+
+func (as testAuditStore) Save(e *model.AuditEntry) (*model.AuditEntry, *model.AppErr) {
+	if as.s.auditFails {
+		return nil, model.NewAppErr("testAuditStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil)
+	}
+	as.s.audit = append(as.s.audit, e)
+	return e, nil
+}
+
+type testJobStore struct {
+	store.JobStore
+	s *testStore
+}
+
+func (js testJobStore) Save(job *model.Job) (*model.Job, *model.AppErr) {
+	js.s.jobs = append(js.s.jobs, job)
+	return job, nil
+}
+
+func newTestConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.ENV = "test"
+	cfg.AuthSettings.AccessTokenSecret = testAccessTokenSecret
+	cfg.RateLimitSettings.Disabled = true
+	return cfg
+}
+
+// newTestServer serves the api backed by the store
+func newTestServer(t *testing.T, st store.Store, cfg *config.Config, options ...app.Option) *httptest.Server {
+	t.Helper()
+
+	srv, err := app.NewServer(st)
+	if err != nil {
+		t.Fatalf("could not create the server: %v", err)
+	}
+	options = append([]app.Option{
+		app.SetConfig(cfg),
+		app.SetLogger(zlog.NewLogger(&zlog.LoggerConfig{})),
+		app.SetServer(srv),
+	}, options...)
+	a := app.New(options...)
+
+	r := chi.NewRouter()
+	Init(a, r)
+
+	ts := httptest.NewServer(r)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// testAccessToken signs the access token of the user the same way the login does
+func testAccessToken(t *testing.T, u *model.User, permissions ...string) string {
+	t.Helper()
+
+	claims := model.Claims{
+		Role:        u.Role,
+		Permissions: permissions,
+		SessionID:   "session-" + strconv.FormatInt(u.ID, 10),
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: &jwt.Time{Time: time.Now().Add(time.Hour)},
+			ID:        "access-" + strconv.FormatInt(u.ID, 10),
+			IssuedAt:  &jwt.Time{Time: time.Now()},
+			Subject:   strconv.FormatInt(u.ID, 10),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testAccessTokenSecret))
+	if err != nil {
+		t.Fatalf("could not sign the access token: %v", err)
+	}
+	return token
+}
+
+// doRequest sends the json body with the access token cookie when the token is set
+func doRequest(t *testing.T, ts *httptest.Server, method, path, token, body string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("could not create the request: %v", err)
+	}
+	if token != "" {
+		req.AddCookie(&http.Cookie{Name: model.AccessCookieName, Value: token})
+	}
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	resp.Body.Close()
+	return resp
+}
@@ -0,0 +1,122 @@
+package apiv1
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/url"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/go-chi/chi"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgOAuthStateMismatch = &i18n.Message{ID: "api.oauth.state_mismatch.app_error", Other: "login was started in another browser"}
+	msgOAuthDenied        = &i18n.Message{ID: "api.oauth.denied.app_error", Other: "login was cancelled at the provider"}
+)
+
+// InitOAuth inits the social login routes
+func InitOAuth(a *API) {
+	a.Routes.OAuth.Get("/providers", a.getOAuthProviders)
+	a.Routes.OAuth.Get("/{provider}/login", a.startOAuthLogin)
+	a.Routes.OAuth.Get("/{provider}/link", a.SessionRequired(a.startOAuthLink))
+	a.Routes.OAuth.Get("/{provider}/callback", a.oauthCallback)
+
+	a.Routes.Users.Get("/identities", a.SessionRequired(a.getUserIdentities))
+	a.Routes.Users.Delete("/identities/{provider}", a.SessionRequired(a.unlinkUserIdentity))
+}
+
+func (a *API) getOAuthProviders(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, a.app.GetOAuthProviders())
+}
+
+func (a *API) startOAuthLogin(w http.ResponseWriter, r *http.Request) {
+	a.redirectToProvider(w, r, 0)
+}
+
+func (a *API) startOAuthLink(w http.ResponseWriter, r *http.Request) {
+	a.redirectToProvider(w, r, a.app.GetUserIDFromContext(r.Context()))
+}
+
+func (a *API) redirectToProvider(w http.ResponseWriter, r *http.Request, userID int64) {
+	key, authURL, err := a.app.StartOAuthLogin(r.Context(), chi.URLParam(r, "provider"), userID)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	a.app.AttachOAuthStateCookie(w, key)
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// oauthCallback completes the login and sends the user back to the site, the errors are passed to the site in the query
+// string because the user arrives here from the provider's page
+func (a *API) oauthCallback(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	a.app.DeleteOAuthStateCookie(w)
+
+	if q.Get("error") != "" {
+		a.redirectOAuthError(w, r, model.NewAppErr("oauthCallback", model.ErrUnauthenticated, locale.GetUserLocalizer("en"), msgOAuthDenied, http.StatusUnauthorized, nil))
+		return
+	}
+
+	cookie, e := r.Cookie(model.OAuthStateCookieName)
+	if e != nil || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(q.Get("state"))) != 1 {
+		a.redirectOAuthError(w, r, model.NewAppErr("oauthCallback", model.ErrInvalid, locale.GetUserLocalizer("en"), msgOAuthStateMismatch, http.StatusBadRequest, nil))
+		return
+	}
+
+	res, err := a.audited(r).CompleteOAuthLogin(r.Context(), chi.URLParam(r, "provider"), cookie.Value, q.Get("code"), a.app.RequestIP(r))
+	if err != nil {
+		a.redirectOAuthError(w, r, err)
+		return
+	}
+	if res.Linked {
+		http.Redirect(w, r, a.app.SiteURL()+"/account/connections", http.StatusFound)
+		return
+	}
+	if res.Challenge != nil {
+		a.app.AttachTwoFactorChallengeCookie(w, res.Challenge)
+		http.Redirect(w, r, a.app.SiteURL()+"/login/2fa", http.StatusFound)
+		return
+	}
+
+	tokenMeta, err := a.app.IssueTokens(res.User)
+	if err != nil {
+		a.redirectOAuthError(w, r, err)
+		return
+	}
+	if err := a.app.SaveAuth(res.User.ID, tokenMeta, a.app.SessionFromRequest(r)); err != nil {
+		a.redirectOAuthError(w, r, err)
+		return
+	}
+	a.app.AttachSessionCookies(w, tokenMeta)
+	http.Redirect(w, r, a.app.SiteURL(), http.StatusFound)
+}
+
+func (a *API) redirectOAuthError(w http.ResponseWriter, r *http.Request, err *model.AppErr) {
+	err.Localize(locale.FromContext(r.Context()))
+	v := url.Values{}
+	v.Set("error", err.ID)
+	v.Set("message", err.Message)
+	http.Redirect(w, r, a.app.SiteURL()+"/login?"+v.Encode(), http.StatusFound)
+}
+
+func (a *API) getUserIdentities(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
+	identities, err := a.app.GetUserIdentities(uid)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, identities)
+}
+
+func (a *API) unlinkUserIdentity(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
+	if err := a.audited(r).UnlinkUserIdentity(uid, chi.URLParam(r, "provider")); err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondOK(w)
+}
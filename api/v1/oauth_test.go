@@ -0,0 +1,108 @@
+package apiv1
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/dankobgd/ecommerce-shop/app"
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/oauth"
+)
+
+// testOAuthProvider returns bob's profile for every code
+type testOAuthProvider struct{}
+
+func (testOAuthProvider) Name() string { return "test" }
+
+func (testOAuthProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	return "https://provider.example.com/authorize?state=" + state, nil
+}
+
+func (testOAuthProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*oauth.Profile, error) {
+	return &oauth.Profile{Subject: "sub-bob", Email: "bob@example.com", EmailVerified: true}, nil
+}
+
+// oauthFixture is the store where bob has linked the provider account and started the login
+func oauthFixture() *testStore {
+	st, _ := crossUserFixture()
+	st.users[2].Email = "bob@example.com"
+	st.identities["test:sub-bob"] = 2
+	st.states["state-1"] = &model.OAuthState{Provider: "test", Verifier: "verifier", Nonce: "nonce"}
+	return st
+}
+
+// oauthCallback completes the login from the browser that started it, the redirect is not followed
+func oauthCallback(t *testing.T, st *testStore) *http.Response {
+	t.Helper()
+
+	ts := newTestServer(t, st, newTestConfig(), app.SetOAuthProviders(map[string]oauth.Provider{"test": testOAuthProvider{}}))
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/v1/oauth/test/callback?state=state-1&code=code", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.AddCookie(&http.Cookie{Name: model.OAuthStateCookieName, Value: "state-1"})
+
+	client := ts.Client()
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("callback: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("callback = %d, want %d", resp.StatusCode, http.StatusFound)
+	}
+	return resp
+}
+
+func responseCookie(resp *http.Response, name string) *http.Cookie {
+	for _, c := range resp.Cookies() {
+		if c.Name == name && c.MaxAge >= 0 {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestOAuthCallbackLockedOut(t *testing.T) {
+	for _, key := range []string{"login:account:bob@example.com", "login:ip:127.0.0.1"} {
+		t.Run(key, func(t *testing.T) {
+			st := oauthFixture()
+			st.locked[key] = true
+
+			resp := oauthCallback(t, st)
+			if loc := resp.Header.Get("Location"); !strings.Contains(loc, "error=app.login_attempt.locked.app_error") {
+				t.Errorf("Location = %q, want the lockout error", loc)
+			}
+			if c := responseCookie(resp, model.AccessCookieName); c != nil {
+				t.Error("the locked out user was logged in")
+			}
+		})
+	}
+}
+
+func TestOAuthCallbackTwoFactorChallengeCookie(t *testing.T) {
+	st := oauthFixture()
+	st.twoFactor[2] = true
+
+	resp := oauthCallback(t, st)
+	if loc := resp.Header.Get("Location"); loc != "http://localhost:3000/login/2fa" {
+		t.Errorf("Location = %q, want the code form without the challenge", loc)
+	}
+	if c := responseCookie(resp, model.AccessCookieName); c != nil {
+		t.Error("the tokens were issued before the second factor")
+	}
+
+	c := responseCookie(resp, model.TwoFactorChallengeCookieName)
+	if c == nil {
+		t.Fatal("the challenge cookie is not set")
+	}
+	if !c.HttpOnly || c.MaxAge <= 0 || c.MaxAge > int(model.TwoFactorChallengeExpiry.Seconds()) {
+		t.Errorf("the challenge cookie is HttpOnly=%v MaxAge=%d, want the short lived HttpOnly cookie", c.HttpOnly, c.MaxAge)
+	}
+	if len(st.tokens) != 1 || c.Value != st.tokens[0].Token {
+		t.Error("the cookie does not carry the saved challenge")
+	}
+}
@@ -16,30 +16,35 @@ import (
 
 var (
 	msgOrderItemsDataFromJSON = &i18n.Message{ID: "api.order.create_order.json.app_error", Other: "could not parse order item json data"}
+	msgOrderShipmentFromJSON  = &i18n.Message{ID: "api.order.ship_order.json.app_error", Other: "could not parse order shipment json data"}
 )
 
 // InitOrder inits the order routes
 func InitOrder(a *API) {
 	a.Routes.Orders.Post("/", a.SessionRequired(a.createOrder))
 	a.Routes.Orders.Get("/count", a.getOrdersCount)
-	a.Routes.Orders.Get("/", a.SessionRequired(a.getOrders))
+	a.Routes.Orders.Get("/", a.RequirePermission(model.PermissionOrderRead, a.getOrders))
 
 	a.Routes.Order.Get("/", a.SessionRequired(a.getOrder))
 	a.Routes.Order.Get("/details", a.SessionRequired(a.getOrderDetails))
 	a.Routes.Order.Get("/details/pdf", a.SessionRequired(a.getOrderDetailsPDF))
+	a.Routes.Order.Post("/ship", a.RequirePermission(model.PermissionOrderWrite, a.shipOrder))
+	a.Routes.Order.Post("/deliver", a.RequirePermission(model.PermissionOrderWrite, a.deliverOrder))
+	a.Routes.Order.Post("/cancel", a.RequirePermission(model.PermissionOrderWrite, a.cancelOrder))
+	a.Routes.Order.Post("/refund", a.RequirePermission(model.PermissionOrderRefund, a.refundOrder))
 }
 
 func (a *API) createOrder(w http.ResponseWriter, r *http.Request) {
 	uid := a.app.GetUserIDFromContext(r.Context())
 	orderData, e := model.OrderRequestDataFromJSON(r.Body)
 	if e != nil {
-		respondError(w, model.NewAppErr("createOrder", model.ErrInternal, locale.GetUserLocalizer("en"), msgOrderItemsDataFromJSON, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("createOrder", model.ErrInternal, locale.GetUserLocalizer("en"), msgOrderItemsDataFromJSON, http.StatusInternalServerError, nil))
 		return
 	}
 
-	order, err := a.app.CreateOrder(uid, orderData)
+	order, err := a.audited(r).CreateOrder(uid, orderData)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 
@@ -55,7 +60,7 @@ func (a *API) getOrders(w http.ResponseWriter, r *http.Request) {
 	pages := pagination.NewFromRequest(r)
 	orders, err := a.app.GetOrders(pages.Limit(), pages.Offset())
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 
@@ -71,12 +76,14 @@ func (a *API) getOrders(w http.ResponseWriter, r *http.Request) {
 func (a *API) getOrder(w http.ResponseWriter, r *http.Request) {
 	oid, e := strconv.ParseInt(chi.URLParam(r, "order_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("getOrder", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("getOrder", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
-	order, err := a.app.GetOrder(oid)
+
+	ad := a.app.GetAccessDataFromContext(r.Context())
+	order, err := a.app.AuthorizeOrder(ad, oid, model.PermissionOrderRead)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 	respondJSON(w, http.StatusOK, order)
@@ -85,12 +92,19 @@ func (a *API) getOrder(w http.ResponseWriter, r *http.Request) {
 func (a *API) getOrderDetails(w http.ResponseWriter, r *http.Request) {
 	oid, e := strconv.ParseInt(chi.URLParam(r, "order_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("getOrderDetails", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("getOrderDetails", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	ad := a.app.GetAccessDataFromContext(r.Context())
+	if _, err := a.app.AuthorizeOrder(ad, oid, model.PermissionOrderRead); err != nil {
+		respondError(w, r, err)
 		return
 	}
+
 	details, err := a.app.GetOrderDetails(oid)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 	respondJSON(w, http.StatusOK, details)
@@ -99,31 +113,33 @@ func (a *API) getOrderDetails(w http.ResponseWriter, r *http.Request) {
 func (a *API) getOrderDetailsPDF(w http.ResponseWriter, r *http.Request) {
 	oid, e := strconv.ParseInt(chi.URLParam(r, "order_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("getOrderDetailsPDF", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("getOrderDetailsPDF", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 
-	order, err := a.app.GetOrder(oid)
+	ad := a.app.GetAccessDataFromContext(r.Context())
+	order, err := a.app.AuthorizeOrder(ad, oid, model.PermissionOrderRead)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 
 	details, err := a.app.GetOrderDetails(oid)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 
 	user, err := a.app.GetUserByID(order.UserID)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 
 	pdf, pdfErr := a.app.GenerateOrderDetailsPDF(order, details, user)
 	if pdfErr != nil {
-		respondError(w, pdfErr)
+		respondError(w, r, pdfErr)
+		return
 	}
 
 	t := order.CreatedAt
@@ -133,3 +149,68 @@ func (a *API) getOrderDetailsPDF(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
 	io.Copy(w, bytes.NewReader(pdf.Bytes()))
 }
+
+func (a *API) shipOrder(w http.ResponseWriter, r *http.Request) {
+	oid, e := strconv.ParseInt(chi.URLParam(r, "order_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("shipOrder", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+	shipment, e := model.OrderShipmentFromJSON(r.Body)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("shipOrder", model.ErrInternal, locale.GetUserLocalizer("en"), msgOrderShipmentFromJSON, http.StatusInternalServerError, nil))
+		return
+	}
+
+	order, err := a.audited(r).ShipOrder(oid, shipment)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, order)
+}
+
+func (a *API) deliverOrder(w http.ResponseWriter, r *http.Request) {
+	oid, e := strconv.ParseInt(chi.URLParam(r, "order_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("deliverOrder", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	order, err := a.audited(r).DeliverOrder(oid)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, order)
+}
+
+func (a *API) cancelOrder(w http.ResponseWriter, r *http.Request) {
+	oid, e := strconv.ParseInt(chi.URLParam(r, "order_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("cancelOrder", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	order, err := a.audited(r).CancelOrder(oid)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, order)
+}
+
+func (a *API) refundOrder(w http.ResponseWriter, r *http.Request) {
+	oid, e := strconv.ParseInt(chi.URLParam(r, "order_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("refundOrder", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	order, err := a.audited(r).RefundOrder(oid)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, order)
+}
@@ -4,7 +4,9 @@ import (
 	"mime/multipart"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/dankobgd/ecommerce-shop/app"
 	"github.com/dankobgd/ecommerce-shop/model"
 	"github.com/dankobgd/ecommerce-shop/utils/locale"
 	"github.com/dankobgd/ecommerce-shop/utils/pagination"
@@ -13,7 +15,7 @@ import (
 )
 
 var (
-	msgProductPatchFromJSON   = &i18n.Message{ID: "api.product.patch_product.app_error", Other: "could not decode product patch data"}
+	msgProductPatchFromJSON   = &i18n.Message{ID: "api.product.patch_product.json.app_error", Other: "could not decode product patch data"}
 	msgProductFileErr         = &i18n.Message{ID: "api.product.create_product.formfile.app_error", Other: "error parsing files"}
 	msgProductAvatarMultipart = &i18n.Message{ID: "api.product.create_product.multipart.app_error", Other: "could not decode product multipart data"}
 	msgProductPriceErr        = &i18n.Message{ID: "api.product.create_product.price.app_error", Other: "could not decode product price"}
@@ -21,57 +23,74 @@ var (
 	msgURLParamErr            = &i18n.Message{ID: "api.product.url.params.app_error", Other: "could not parse URL params"}
 	msgDiscountFromJSON       = &i18n.Message{ID: "api.product.create_product_discount.app_error", Other: "could not parse discount pricing from json"}
 	msgReviewFromJSON         = &i18n.Message{ID: "api.product.create_product_review.app_error", Other: "could not parse product review from json"}
-	msgReviewURLParamErr      = &i18n.Message{ID: "api.product.create_product_review.app_error", Other: "invalid product review url param"}
+	msgReviewURLParamErr      = &i18n.Message{ID: "api.product.review.url.params.app_error", Other: "invalid product review url param"}
 	msgReviewPatchFromJSONErr = &i18n.Message{ID: "api.product.patch_product_review.app_error", Other: "could not decode product review patch data"}
 )
 
+// searchRateLimit keeps the full text search from being hammered
+var searchRateLimit = &model.RateLimit{Name: "products_search", Requests: 60, Period: time.Minute, Burst: 20}
+
 // InitProducts inits the product routes
 func InitProducts(a *API) {
-	a.Routes.Products.Post("/", a.AdminSessionRequired(a.createProduct))
+	a.Routes.Products.Post("/", a.RequirePermission(model.PermissionProductWrite, a.createProduct))
 	a.Routes.Products.Get("/count", a.getProductsCount)
 	a.Routes.Products.Get("/", a.getProducts)
 	a.Routes.Products.Get("/featured", a.getFeaturedProducts)
 	a.Routes.Products.Get("/sold", a.getMostSoldProducts)
 	a.Routes.Products.Get("/deals", a.getBestDealsProducts)
-	a.Routes.Products.Get("/search", a.searchProducts)
-	a.Routes.Products.Delete("/bulk", a.deleteProducts)
+	a.Routes.Products.Get("/search", a.RateLimited(searchRateLimit, a.searchProducts))
+	a.Routes.Products.Get("/slug/{slug}", a.getProductBySlug)
+	a.Routes.Products.Delete("/bulk", a.RequirePermission(model.PermissionProductWrite, a.deleteProducts))
 
 	a.Routes.Product.Get("/", a.getProduct)
-	a.Routes.Product.Patch("/", a.AdminSessionRequired(a.patchProduct))
-	a.Routes.Product.Delete("/", a.AdminSessionRequired(a.deleteProduct))
+	a.Routes.Product.Patch("/", a.RequirePermission(model.PermissionProductWrite, a.patchProduct))
+	a.Routes.Product.Delete("/", a.RequirePermission(model.PermissionProductWrite, a.deleteProduct))
 
 	// discount
-	a.Routes.Product.Get("/pricing/latest", a.AdminSessionRequired(a.getProductLatestPricing))
-	a.Routes.Product.Post("/pricing", a.AdminSessionRequired(a.createProductPricing))
+	a.Routes.Product.Get("/pricing/latest", a.RequirePermission(model.PermissionProductWrite, a.getProductLatestPricing))
+	a.Routes.Product.Post("/pricing", a.RequirePermission(model.PermissionProductWrite, a.createProductPricing))
 
 	// product tags
-	a.Routes.Product.Post("/tags", a.AdminSessionRequired(a.createProductTag))
+	a.Routes.Product.Post("/tags", a.RequirePermission(model.PermissionProductWrite, a.createProductTag))
 	a.Routes.Product.Get("/tags", a.getProductTags)
-	a.Routes.Product.Put("/tags/replace", a.AdminSessionRequired(a.replaceProductTags))
-	a.Routes.Product.Patch("/tags/{tag_id:[A-Za-z0-9]+}", a.AdminSessionRequired(a.patchProductTag))
-	a.Routes.Product.Delete("/tags/{tag_id:[A-Za-z0-9]+}", a.AdminSessionRequired(a.deleteProductTag))
-	a.Routes.Product.Delete("/tags/bulk", a.AdminSessionRequired(a.deleteProductTags))
+	a.Routes.Product.Put("/tags/replace", a.RequirePermission(model.PermissionProductWrite, a.replaceProductTags))
+	a.Routes.Product.Patch("/tags/{tag_id:[A-Za-z0-9]+}", a.RequirePermission(model.PermissionProductWrite, a.patchProductTag))
+	a.Routes.Product.Delete("/tags/{tag_id:[A-Za-z0-9]+}", a.RequirePermission(model.PermissionProductWrite, a.deleteProductTag))
+	a.Routes.Product.Delete("/tags/bulk", a.RequirePermission(model.PermissionProductWrite, a.deleteProductTags))
 
 	// product images
-	a.Routes.Product.Post("/images/bulk", a.AdminSessionRequired(a.createProductImages))
-	a.Routes.Product.Post("/images", a.AdminSessionRequired(a.createProductImage))
+	a.Routes.Product.Post("/images/bulk", a.RequirePermission(model.PermissionProductWrite, a.createProductImages))
+	a.Routes.Product.Post("/images", a.RequirePermission(model.PermissionProductWrite, a.createProductImage))
 	a.Routes.Product.Get("/images", a.getProductImages)
-	a.Routes.Product.Patch("/images/{image_id:[A-Za-z0-9]+}", a.AdminSessionRequired(a.patchProductImage))
-	a.Routes.Product.Delete("/images/{image_id:[A-Za-z0-9]+}", a.AdminSessionRequired(a.deleteProductImage))
-	a.Routes.Product.Delete("/images/bulk", a.AdminSessionRequired(a.deleteProductImages))
+
+	// product translations
+	a.Routes.Product.Get("/translations", a.RequirePermission(model.PermissionProductWrite, a.getTranslations(model.TranslationResourceProduct, "product_id")))
+	a.Routes.Product.Get("/translations/{translation_locale:[A-Za-z-]+}", a.RequirePermission(model.PermissionProductWrite, a.getTranslation(model.TranslationResourceProduct, "product_id")))
+	a.Routes.Product.Put("/translations/{translation_locale:[A-Za-z-]+}", a.RequirePermission(model.PermissionProductWrite, a.saveTranslation(model.TranslationResourceProduct, "product_id")))
+	a.Routes.Product.Delete("/translations/{translation_locale:[A-Za-z-]+}", a.RequirePermission(model.PermissionProductWrite, a.deleteTranslation(model.TranslationResourceProduct, "product_id")))
+	a.Routes.Product.Patch("/images/{image_id:[A-Za-z0-9]+}", a.RequirePermission(model.PermissionProductWrite, a.patchProductImage))
+	a.Routes.Product.Delete("/images/{image_id:[A-Za-z0-9]+}", a.RequirePermission(model.PermissionProductWrite, a.deleteProductImage))
+	a.Routes.Product.Delete("/images/bulk", a.RequirePermission(model.PermissionProductWrite, a.deleteProductImages))
 
 	// product reviews
 	a.Routes.Product.Post("/reviews", a.SessionRequired(a.createProductReview))
 	a.Routes.Product.Get("/reviews", a.getProductReviews)
-	a.Routes.Product.Get("/reviews/{review_id:[A-Za-z0-9]+}", a.getProductReview)
+	a.Routes.Product.Get("/reviews/{review_id:[A-Za-z0-9]+}", a.SessionOptional(a.getProductReview))
 	a.Routes.Product.Patch("/reviews/{review_id:[A-Za-z0-9]+}", a.SessionRequired(a.patchProductReview))
 	a.Routes.Product.Delete("/reviews/{review_id:[A-Za-z0-9]+}", a.SessionRequired(a.deleteProductReview))
-	a.Routes.Product.Delete("/reviews/bulk", a.AdminSessionRequired(a.deleteProductReviews))
+	a.Routes.Product.Delete("/reviews/bulk", a.RequirePermission(model.PermissionReviewModerate, a.deleteProductReviews))
+	a.Routes.Product.Put("/reviews/{review_id:[A-Za-z0-9]+}/status", a.RequirePermission(model.PermissionReviewModerate, a.moderateProductReview))
+	a.Routes.Product.Post("/reviews/{review_id:[A-Za-z0-9]+}/vote", a.SessionRequired(a.voteProductReview))
+	a.Routes.Product.Delete("/reviews/{review_id:[A-Za-z0-9]+}/vote", a.SessionRequired(a.deleteProductReviewVote))
+	a.Routes.Product.Post("/reviews/{review_id:[A-Za-z0-9]+}/reports", a.SessionRequired(a.reportProductReview))
+	a.Routes.Product.Get("/reviews/{review_id:[A-Za-z0-9]+}/reports", a.RequirePermission(model.PermissionReviewModerate, a.getProductReviewReports))
+	a.Routes.Product.Post("/reviews/{review_id:[A-Za-z0-9]+}/media", a.SessionRequired(a.createProductReviewMedia))
+	a.Routes.Product.Delete("/reviews/{review_id:[A-Za-z0-9]+}/media/{media_id:[A-Za-z0-9]+}", a.SessionRequired(a.deleteProductReviewMedia))
 }
 
 func (a *API) createProduct(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseMultipartForm(model.FileUploadSizeLimit); err != nil {
-		respondError(w, model.NewAppErr("createProduct", model.ErrInternal, locale.GetUserLocalizer("en"), msgProductAvatarMultipart, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("createProduct", model.ErrInternal, locale.GetUserLocalizer("en"), msgProductAvatarMultipart, http.StatusInternalServerError, nil))
 		return
 	}
 
@@ -80,7 +99,7 @@ func (a *API) createProduct(w http.ResponseWriter, r *http.Request) {
 
 	p := &model.Product{}
 	if err := model.SchemaDecoder.Decode(p, mpf.Value); err != nil {
-		respondError(w, model.NewAppErr("createProduct", model.ErrInternal, locale.GetUserLocalizer("en"), msgProductAvatarMultipart, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("createProduct", model.ErrInternal, locale.GetUserLocalizer("en"), msgProductAvatarMultipart, http.StatusInternalServerError, nil))
 		return
 	}
 
@@ -90,12 +109,12 @@ func (a *API) createProduct(w http.ResponseWriter, r *http.Request) {
 	images := mpf.File["images"]
 
 	if len(price) == 0 {
-		respondError(w, model.NewAppErr("createProduct", model.ErrInternal, locale.GetUserLocalizer("en"), msgProductPriceErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("createProduct", model.ErrInternal, locale.GetUserLocalizer("en"), msgProductPriceErr, http.StatusInternalServerError, nil))
 		return
 	}
 	priceValue, err := strconv.Atoi(price[0])
 	if err != nil {
-		respondError(w, model.NewAppErr("createProduct", model.ErrInternal, locale.GetUserLocalizer("en"), msgProductPriceErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("createProduct", model.ErrInternal, locale.GetUserLocalizer("en"), msgProductPriceErr, http.StatusInternalServerError, nil))
 		return
 	}
 	p.ProductPricing = &model.ProductPricing{
@@ -108,9 +127,9 @@ func (a *API) createProduct(w http.ResponseWriter, r *http.Request) {
 		thumbnail = mpf.File["image"][0]
 	}
 
-	product, pErr := a.app.CreateProduct(p, thumbnail, images, tagids)
+	product, pErr := a.audited(r).CreateProduct(p, thumbnail, images, tagids)
 	if pErr != nil {
-		respondError(w, pErr)
+		respondError(w, r, pErr)
 		return
 	}
 
@@ -119,7 +138,7 @@ func (a *API) createProduct(w http.ResponseWriter, r *http.Request) {
 
 func (a *API) patchProduct(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseMultipartForm(model.FileUploadSizeLimit); err != nil {
-		respondError(w, model.NewAppErr("patchProduct", model.ErrInternal, locale.GetUserLocalizer("en"), msgProductAvatarMultipart, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("patchProduct", model.ErrInternal, locale.GetUserLocalizer("en"), msgProductAvatarMultipart, http.StatusInternalServerError, nil))
 		return
 	}
 
@@ -128,7 +147,7 @@ func (a *API) patchProduct(w http.ResponseWriter, r *http.Request) {
 
 	patch := &model.ProductPatch{}
 	if err := model.SchemaDecoder.Decode(patch, mpf.Value); err != nil {
-		respondError(w, model.NewAppErr("patchProduct", model.ErrInternal, locale.GetUserLocalizer("en"), msgProductAvatarMultipart, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("patchProduct", model.ErrInternal, locale.GetUserLocalizer("en"), msgProductAvatarMultipart, http.StatusInternalServerError, nil))
 		return
 	}
 	patch.SetProperties(patch.PropertiesText)
@@ -140,13 +159,13 @@ func (a *API) patchProduct(w http.ResponseWriter, r *http.Request) {
 
 	pid, err := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
 	if err != nil {
-		respondError(w, model.NewAppErr("patchProduct", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("patchProduct", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 
-	uprod, pErr := a.app.PatchProduct(pid, patch, image)
+	uprod, pErr := a.audited(r).PatchProduct(pid, patch, image)
 	if err != nil {
-		respondError(w, pErr)
+		respondError(w, r, pErr)
 		return
 	}
 
@@ -156,11 +175,11 @@ func (a *API) patchProduct(w http.ResponseWriter, r *http.Request) {
 func (a *API) deleteProduct(w http.ResponseWriter, r *http.Request) {
 	pid, err := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
 	if err != nil {
-		respondError(w, model.NewAppErr("deleteProduct", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("deleteProduct", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
-	if err := a.app.DeleteProduct(pid); err != nil {
-		respondError(w, err)
+	if err := a.audited(r).DeleteProduct(pid); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
@@ -170,12 +189,30 @@ func (a *API) deleteProduct(w http.ResponseWriter, r *http.Request) {
 func (a *API) getProduct(w http.ResponseWriter, r *http.Request) {
 	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("getProduct", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("getProduct", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 	p, err := a.app.GetProduct(pid)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
+		return
+	}
+	if err := a.app.TranslateProduct(p, locale.LocaleFromContext(r.Context())); err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, p)
+}
+
+func (a *API) getProductBySlug(w http.ResponseWriter, r *http.Request) {
+	lang := locale.LocaleFromContext(r.Context())
+	p, err := a.app.GetProductBySlug(chi.URLParam(r, "slug"), lang)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	if err := a.app.TranslateProduct(p, lang); err != nil {
+		respondError(w, r, err)
 		return
 	}
 	respondJSON(w, http.StatusOK, p)
@@ -191,7 +228,11 @@ func (a *API) getProducts(w http.ResponseWriter, r *http.Request) {
 	pages := pagination.NewFromRequest(r)
 	products, err := a.app.GetProducts(filters, pages.Limit(), pages.Offset())
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
+		return
+	}
+	if err := a.app.TranslateProducts(products, locale.LocaleFromContext(r.Context())); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
@@ -207,13 +248,13 @@ func (a *API) getProducts(w http.ResponseWriter, r *http.Request) {
 func (a *API) getProductLatestPricing(w http.ResponseWriter, r *http.Request) {
 	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("getProductLatestPricing", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("getProductLatestPricing", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 
 	pp, err := a.app.GetProductLatestPricing(pid)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 	respondJSON(w, http.StatusOK, pp)
@@ -222,13 +263,13 @@ func (a *API) getProductLatestPricing(w http.ResponseWriter, r *http.Request) {
 func (a *API) createProductPricing(w http.ResponseWriter, r *http.Request) {
 	salePricing, e := model.ProductPricingFromJSON(r.Body)
 	if e != nil {
-		respondError(w, model.NewAppErr("createProductPricing", model.ErrInternal, locale.GetUserLocalizer("en"), msgDiscountFromJSON, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("createProductPricing", model.ErrInternal, locale.GetUserLocalizer("en"), msgDiscountFromJSON, http.StatusInternalServerError, nil))
 		return
 	}
 
-	discount, err := a.app.AddProductPricing(salePricing)
+	discount, err := a.audited(r).AddProductPricing(salePricing)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 
@@ -238,19 +279,19 @@ func (a *API) createProductPricing(w http.ResponseWriter, r *http.Request) {
 func (a *API) createProductTag(w http.ResponseWriter, r *http.Request) {
 	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("createProductTag", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("createProductTag", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 
 	pt, e := model.ProductTagFromJSON(r.Body)
 	if e != nil {
-		respondError(w, model.NewAppErr("createProductTag", model.ErrInternal, locale.GetUserLocalizer("en"), msgTagFromJSON, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("createProductTag", model.ErrInternal, locale.GetUserLocalizer("en"), msgTagFromJSON, http.StatusInternalServerError, nil))
 		return
 	}
 
-	productTag, err := a.app.CreateProductTag(pid, pt)
+	productTag, err := a.audited(r).CreateProductTag(pid, pt)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 	respondJSON(w, http.StatusCreated, productTag)
@@ -259,12 +300,12 @@ func (a *API) createProductTag(w http.ResponseWriter, r *http.Request) {
 func (a *API) getProductTags(w http.ResponseWriter, r *http.Request) {
 	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("getProductTags", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("getProductTags", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 	tags, err := a.app.GetProductTags(pid)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 	respondJSON(w, http.StatusOK, tags)
@@ -273,15 +314,15 @@ func (a *API) getProductTags(w http.ResponseWriter, r *http.Request) {
 func (a *API) replaceProductTags(w http.ResponseWriter, r *http.Request) {
 	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("replaceProductTags", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("replaceProductTags", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 
 	tagIDs := model.IntSliceFromJSON(r.Body)
 
-	newTags, pErr := a.app.ReplaceProductTags(pid, tagIDs)
+	newTags, pErr := a.audited(r).ReplaceProductTags(pid, tagIDs)
 	if pErr != nil {
-		respondError(w, pErr)
+		respondError(w, r, pErr)
 		return
 	}
 
@@ -291,24 +332,24 @@ func (a *API) replaceProductTags(w http.ResponseWriter, r *http.Request) {
 func (a *API) patchProductTag(w http.ResponseWriter, r *http.Request) {
 	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("patchProductTag", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("patchProductTag", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 	tid, err := strconv.ParseInt(chi.URLParam(r, "tag_id"), 10, 64)
 	if err != nil {
-		respondError(w, model.NewAppErr("patchProductTag", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("patchProductTag", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 
 	patch, err := model.ProductTagPatchFromJSON(r.Body)
 	if err != nil {
-		respondError(w, model.NewAppErr("patchProductTag", model.ErrInternal, locale.GetUserLocalizer("en"), msgProductPatchFromJSON, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("patchProductTag", model.ErrInternal, locale.GetUserLocalizer("en"), msgProductPatchFromJSON, http.StatusInternalServerError, nil))
 		return
 	}
 
-	utag, pErr := a.app.PatchProductTag(pid, tid, patch)
+	utag, pErr := a.audited(r).PatchProductTag(pid, tid, patch)
 	if pErr != nil {
-		respondError(w, pErr)
+		respondError(w, r, pErr)
 		return
 	}
 
@@ -318,17 +359,17 @@ func (a *API) patchProductTag(w http.ResponseWriter, r *http.Request) {
 func (a *API) deleteProductTag(w http.ResponseWriter, r *http.Request) {
 	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("deleteProductTag", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("deleteProductTag", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 	tid, e := strconv.ParseInt(chi.URLParam(r, "tag_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("deleteProductTag", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("deleteProductTag", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 
-	if err := a.app.DeleteProductTag(pid, tid); err != nil {
-		respondError(w, err)
+	if err := a.audited(r).DeleteProductTag(pid, tid); err != nil {
+		respondError(w, r, err)
 		return
 	}
 	respondOK(w)
@@ -337,14 +378,14 @@ func (a *API) deleteProductTag(w http.ResponseWriter, r *http.Request) {
 func (a *API) deleteProductTags(w http.ResponseWriter, r *http.Request) {
 	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("deleteProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("deleteProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 
 	ids := model.IntSliceFromJSON(r.Body)
 
-	if err := a.app.DeleteProductTags(pid, ids); err != nil {
-		respondError(w, err)
+	if err := a.audited(r).DeleteProductTags(pid, ids); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
@@ -354,12 +395,12 @@ func (a *API) deleteProductTags(w http.ResponseWriter, r *http.Request) {
 func (a *API) createProductImages(w http.ResponseWriter, r *http.Request) {
 	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("createProductImages", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("createProductImages", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 
 	if err := r.ParseMultipartForm(model.FileUploadSizeLimit); err != nil {
-		respondError(w, model.NewAppErr("createProductImages", model.ErrInternal, locale.GetUserLocalizer("en"), msgProductAvatarMultipart, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("createProductImages", model.ErrInternal, locale.GetUserLocalizer("en"), msgProductAvatarMultipart, http.StatusInternalServerError, nil))
 		return
 	}
 
@@ -368,8 +409,8 @@ func (a *API) createProductImages(w http.ResponseWriter, r *http.Request) {
 
 	images := mpf.File["images"]
 
-	if pErr := a.app.CreateProductImages(pid, images); pErr != nil {
-		respondError(w, pErr)
+	if pErr := a.audited(r).CreateProductImages(pid, images); pErr != nil {
+		respondError(w, r, pErr)
 		return
 	}
 
@@ -379,12 +420,12 @@ func (a *API) createProductImages(w http.ResponseWriter, r *http.Request) {
 func (a *API) createProductImage(w http.ResponseWriter, r *http.Request) {
 	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("createProductImage", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("createProductImage", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 
 	if err := r.ParseMultipartForm(model.FileUploadSizeLimit); err != nil {
-		respondError(w, model.NewAppErr("createProductImage", model.ErrInternal, locale.GetUserLocalizer("en"), msgProductAvatarMultipart, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("createProductImage", model.ErrInternal, locale.GetUserLocalizer("en"), msgProductAvatarMultipart, http.StatusInternalServerError, nil))
 		return
 	}
 
@@ -393,15 +434,15 @@ func (a *API) createProductImage(w http.ResponseWriter, r *http.Request) {
 
 	img := &model.ProductImage{}
 	if err := model.SchemaDecoder.Decode(img, mpf.Value); err != nil {
-		respondError(w, model.NewAppErr("createProductImage", model.ErrInternal, locale.GetUserLocalizer("en"), msgProductAvatarMultipart, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("createProductImage", model.ErrInternal, locale.GetUserLocalizer("en"), msgProductAvatarMultipart, http.StatusInternalServerError, nil))
 		return
 	}
 
 	image := mpf.File["image"][0]
 
-	productImage, pErr := a.app.CreateProductImage(pid, img, image)
+	productImage, pErr := a.audited(r).CreateProductImage(pid, img, image)
 	if pErr != nil {
-		respondError(w, pErr)
+		respondError(w, r, pErr)
 		return
 	}
 
@@ -411,12 +452,12 @@ func (a *API) createProductImage(w http.ResponseWriter, r *http.Request) {
 func (a *API) getProductImages(w http.ResponseWriter, r *http.Request) {
 	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("getProduct", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("getProduct", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 	imgs, err := a.app.GetProductImages(pid)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 	respondJSON(w, http.StatusOK, imgs)
@@ -426,22 +467,22 @@ func (a *API) createProductReview(w http.ResponseWriter, r *http.Request) {
 	uid := a.app.GetUserIDFromContext(r.Context())
 	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("createProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("createProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 
 	rev, e := model.ReviewFromJSON(r.Body)
 	if e != nil {
-		respondError(w, model.NewAppErr("createProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgReviewFromJSON, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("createProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgReviewFromJSON, http.StatusInternalServerError, nil))
 		return
 	}
 
 	rev.UserID = uid
 	rev.ProductID = pid
 
-	review, err := a.app.CreateProductReview(pid, rev)
+	review, err := a.audited(r).CreateProductReview(pid, rev)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 	respondJSON(w, http.StatusCreated, review)
@@ -450,13 +491,13 @@ func (a *API) createProductReview(w http.ResponseWriter, r *http.Request) {
 func (a *API) getProductReviews(w http.ResponseWriter, r *http.Request) {
 	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("getProductReviews", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("getProductReviews", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 
-	reviews, err := a.app.GetProductReviews(pid)
+	reviews, err := a.app.GetProductReviews(pid, r.URL.Query().Get("sort"))
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 
@@ -466,18 +507,19 @@ func (a *API) getProductReviews(w http.ResponseWriter, r *http.Request) {
 func (a *API) getProductReview(w http.ResponseWriter, r *http.Request) {
 	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("getProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("getProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 	rid, e := strconv.ParseInt(chi.URLParam(r, "review_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("getProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("getProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 
-	review, err := a.app.GetProductReview(pid, rid)
+	ad, _ := r.Context().Value(app.AccessDataCtxKey).(*model.AccessData)
+	review, err := a.app.GetVisibleProductReview(ad, pid, rid)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 
@@ -487,25 +529,26 @@ func (a *API) getProductReview(w http.ResponseWriter, r *http.Request) {
 func (a *API) patchProductReview(w http.ResponseWriter, r *http.Request) {
 	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("patchProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("patchProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 
 	rid, err := strconv.ParseInt(chi.URLParam(r, "review_id"), 10, 64)
 	if err != nil {
-		respondError(w, model.NewAppErr("patchProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgReviewURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("patchProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgReviewURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 
 	patch, err := model.ReviewPatchFromJSON(r.Body)
 	if err != nil {
-		respondError(w, model.NewAppErr("patchProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgReviewPatchFromJSONErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("patchProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgReviewPatchFromJSONErr, http.StatusInternalServerError, nil))
 		return
 	}
 
-	urev, rErr := a.app.PatchProductReview(pid, rid, patch)
-	if err != nil {
-		respondError(w, rErr)
+	ad := a.app.GetAccessDataFromContext(r.Context())
+	urev, rErr := a.audited(r).PatchProductReview(ad, pid, rid, patch)
+	if rErr != nil {
+		respondError(w, r, rErr)
 		return
 	}
 
@@ -515,17 +558,18 @@ func (a *API) patchProductReview(w http.ResponseWriter, r *http.Request) {
 func (a *API) deleteProductReview(w http.ResponseWriter, r *http.Request) {
 	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("deleteProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("deleteProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 
 	rid, err := strconv.ParseInt(chi.URLParam(r, "review_id"), 10, 64)
 	if err != nil {
-		respondError(w, model.NewAppErr("deleteProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgReviewURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("deleteProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgReviewURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
-	if err := a.app.DeleteProductReview(pid, rid); err != nil {
-		respondError(w, err)
+	ad := a.app.GetAccessDataFromContext(r.Context())
+	if err := a.audited(r).DeleteProductReview(ad, pid, rid); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
@@ -535,14 +579,14 @@ func (a *API) deleteProductReview(w http.ResponseWriter, r *http.Request) {
 func (a *API) deleteProductReviews(w http.ResponseWriter, r *http.Request) {
 	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("deleteProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("deleteProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 
 	ids := model.IntSliceFromJSON(r.Body)
 
-	if err := a.app.DeleteProductReviews(pid, ids); err != nil {
-		respondError(w, err)
+	if err := a.audited(r).DeleteProductReviews(pid, ids); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
@@ -552,17 +596,17 @@ func (a *API) deleteProductReviews(w http.ResponseWriter, r *http.Request) {
 func (a *API) patchProductImage(w http.ResponseWriter, r *http.Request) {
 	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("getProductReviews", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("getProductReviews", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 	imgID, err := strconv.ParseInt(chi.URLParam(r, "image_id"), 10, 64)
 	if err != nil {
-		respondError(w, model.NewAppErr("patchProductImage", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("patchProductImage", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 
 	if err := r.ParseMultipartForm(model.FileUploadSizeLimit); err != nil {
-		respondError(w, model.NewAppErr("patchProductImage", model.ErrInternal, locale.GetUserLocalizer("en"), msgProductAvatarMultipart, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("patchProductImage", model.ErrInternal, locale.GetUserLocalizer("en"), msgProductAvatarMultipart, http.StatusInternalServerError, nil))
 		return
 	}
 
@@ -572,13 +616,13 @@ func (a *API) patchProductImage(w http.ResponseWriter, r *http.Request) {
 
 	patch := &model.ProductImagePatch{}
 	if err := model.SchemaDecoder.Decode(patch, mpf.Value); err != nil {
-		respondError(w, model.NewAppErr("patchProductImage", model.ErrInternal, locale.GetUserLocalizer("en"), msgProductAvatarMultipart, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("patchProductImage", model.ErrInternal, locale.GetUserLocalizer("en"), msgProductAvatarMultipart, http.StatusInternalServerError, nil))
 		return
 	}
 
-	uimg, pErr := a.app.PatchProductImage(pid, imgID, patch, image)
+	uimg, pErr := a.audited(r).PatchProductImage(pid, imgID, patch, image)
 	if err != nil {
-		respondError(w, pErr)
+		respondError(w, r, pErr)
 		return
 	}
 
@@ -588,17 +632,17 @@ func (a *API) patchProductImage(w http.ResponseWriter, r *http.Request) {
 func (a *API) deleteProductImage(w http.ResponseWriter, r *http.Request) {
 	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("deleteProductTag", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("deleteProductTag", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 	imgID, err := strconv.ParseInt(chi.URLParam(r, "image_id"), 10, 64)
 	if err != nil {
-		respondError(w, model.NewAppErr("deleteProductImage", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("deleteProductImage", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 
-	if err := a.app.DeleteProductImage(pid, imgID); err != nil {
-		respondError(w, err)
+	if err := a.audited(r).DeleteProductImage(pid, imgID); err != nil {
+		respondError(w, r, err)
 		return
 	}
 	respondOK(w)
@@ -607,14 +651,14 @@ func (a *API) deleteProductImage(w http.ResponseWriter, r *http.Request) {
 func (a *API) deleteProductImages(w http.ResponseWriter, r *http.Request) {
 	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("deleteProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("deleteProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 
 	ids := model.IntSliceFromJSON(r.Body)
 
-	if err := a.app.DeleteProductImages(pid, ids); err != nil {
-		respondError(w, err)
+	if err := a.audited(r).DeleteProductImages(pid, ids); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
@@ -625,7 +669,11 @@ func (a *API) getFeaturedProducts(w http.ResponseWriter, r *http.Request) {
 	pages := pagination.NewFromRequest(r)
 	featured, err := a.app.GetFeaturedProducts(pages.Limit(), pages.Offset())
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
+		return
+	}
+	if err := a.app.TranslateProducts(featured, locale.LocaleFromContext(r.Context())); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
@@ -642,7 +690,11 @@ func (a *API) getMostSoldProducts(w http.ResponseWriter, r *http.Request) {
 	pages := pagination.NewFromRequest(r)
 	mostSold, err := a.app.GetMostSoldProducts(pages.Limit(), pages.Offset())
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
+		return
+	}
+	if err := a.app.TranslateProducts(mostSold, locale.LocaleFromContext(r.Context())); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
@@ -659,7 +711,11 @@ func (a *API) getBestDealsProducts(w http.ResponseWriter, r *http.Request) {
 	pages := pagination.NewFromRequest(r)
 	bestDeals, err := a.app.GetBestDealsProducts(pages.Limit(), pages.Offset())
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
+		return
+	}
+	if err := a.app.TranslateProducts(bestDeals, locale.LocaleFromContext(r.Context())); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
@@ -675,9 +731,9 @@ func (a *API) getBestDealsProducts(w http.ResponseWriter, r *http.Request) {
 func (a *API) searchProducts(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
 
-	searchResults, err := a.app.SearchProducts(query)
+	searchResults, err := a.app.SearchProducts(query, locale.LocaleFromContext(r.Context()))
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 
@@ -687,8 +743,8 @@ func (a *API) searchProducts(w http.ResponseWriter, r *http.Request) {
 func (a *API) deleteProducts(w http.ResponseWriter, r *http.Request) {
 	ids := model.IntSliceFromJSON(r.Body)
 
-	if err := a.app.DeleteProducts(ids); err != nil {
-		respondError(w, err)
+	if err := a.audited(r).DeleteProducts(ids); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
@@ -12,7 +12,7 @@ import (
 
 var (
 	msgPromotionCreateErr        = &i18n.Message{ID: "api.promotion.create_promotion.app_error", Other: "could not create promotion"}
-	msgPromotionsGetErr          = &i18n.Message{ID: "api.promotion.get_promotion.app_error", Other: "could not get promotions"}
+	msgPromotionsGetErr          = &i18n.Message{ID: "api.promotion.get_promotions.app_error", Other: "could not get promotions"}
 	msgPromotionGetErr           = &i18n.Message{ID: "api.promotion.get_promotion.app_error", Other: "could not get promotion"}
 	msgPromotionPatchErr         = &i18n.Message{ID: "api.promotion.patch_promotion.app_error", Other: "could not update promotion"}
 	msgPromotionDeleteerr        = &i18n.Message{ID: "api.promotion.delete_promotion.app_error", Other: "could not delete promotion"}
@@ -22,14 +22,14 @@ var (
 
 // InitPromotions inits the promotion routes
 func InitPromotions(a *API) {
-	a.Routes.Promotions.Post("/", a.AdminSessionRequired(a.createPromotion))
+	a.Routes.Promotions.Post("/", a.RequirePermission(model.PermissionPromotionManage, a.createPromotion))
 	a.Routes.Promotions.Get("/count", a.getPromotionsCount)
 	a.Routes.Promotions.Get("/", a.SessionRequired(a.getPromotions))
-	a.Routes.Promotions.Delete("/bulk", a.AdminSessionRequired(a.deletePromotions))
+	a.Routes.Promotions.Delete("/bulk", a.RequirePermission(model.PermissionPromotionManage, a.deletePromotions))
 
 	a.Routes.Promotion.Get("/", a.SessionRequired(a.getPromotion))
-	a.Routes.Promotion.Patch("/", a.AdminSessionRequired(a.patchPromotion))
-	a.Routes.Promotion.Delete("/", a.AdminSessionRequired(a.deletePromotion))
+	a.Routes.Promotion.Patch("/", a.RequirePermission(model.PermissionPromotionManage, a.patchPromotion))
+	a.Routes.Promotion.Delete("/", a.RequirePermission(model.PermissionPromotionManage, a.deletePromotion))
 	a.Routes.Promotion.Get("/valid", a.SessionRequired(a.getPromotionIsValid))
 	a.Routes.Promotion.Get("/used", a.SessionRequired(a.getPromotionIsUsed))
 	a.Routes.Promotion.Get("/status", a.SessionRequired(a.getPromotionStatus))
@@ -38,13 +38,13 @@ func InitPromotions(a *API) {
 func (a *API) createPromotion(w http.ResponseWriter, r *http.Request) {
 	p, e := model.PromotionFromJSON(r.Body)
 	if e != nil {
-		respondError(w, model.NewAppErr("createPromotion", model.ErrInternal, locale.GetUserLocalizer("en"), msgPromotionCreateErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("createPromotion", model.ErrInternal, locale.GetUserLocalizer("en"), msgPromotionCreateErr, http.StatusInternalServerError, nil))
 		return
 	}
 
-	promotion, err := a.app.CreatePromotion(p)
+	promotion, err := a.audited(r).CreatePromotion(p)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 	respondJSON(w, http.StatusCreated, promotion)
@@ -54,7 +54,7 @@ func (a *API) getPromotion(w http.ResponseWriter, r *http.Request) {
 	code := chi.URLParam(r, "promo_code")
 	promotion, err := a.app.GetPromotion(code)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 	respondJSON(w, http.StatusOK, promotion)
@@ -69,7 +69,7 @@ func (a *API) getPromotions(w http.ResponseWriter, r *http.Request) {
 	pages := pagination.NewFromRequest(r)
 	promotions, err := a.app.GetPromotions(pages.Limit(), pages.Offset())
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 
@@ -86,13 +86,13 @@ func (a *API) patchPromotion(w http.ResponseWriter, r *http.Request) {
 	code := chi.URLParam(r, "promo_code")
 	patch, err := model.PromotionPatchFromJSON(r.Body)
 	if err != nil {
-		respondError(w, model.NewAppErr("patchPromotion", model.ErrInternal, locale.GetUserLocalizer("en"), msgPromotionPatchErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("patchPromotion", model.ErrInternal, locale.GetUserLocalizer("en"), msgPromotionPatchErr, http.StatusInternalServerError, nil))
 		return
 	}
 
-	up, pErr := a.app.PatchPromotion(code, patch)
+	up, pErr := a.audited(r).PatchPromotion(code, patch)
 	if err != nil {
-		respondError(w, pErr)
+		respondError(w, r, pErr)
 		return
 	}
 
@@ -101,8 +101,8 @@ func (a *API) patchPromotion(w http.ResponseWriter, r *http.Request) {
 
 func (a *API) deletePromotion(w http.ResponseWriter, r *http.Request) {
 	code := chi.URLParam(r, "promo_code")
-	if err := a.app.DeletePromotion(code); err != nil {
-		respondError(w, err)
+	if err := a.audited(r).DeletePromotion(code); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
@@ -113,7 +113,7 @@ func (a *API) getPromotionIsValid(w http.ResponseWriter, r *http.Request) {
 	code := chi.URLParam(r, "promo_code")
 
 	if err := a.app.IsValidPromotion(code); err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 
@@ -124,7 +124,7 @@ func (a *API) getPromotionIsUsed(w http.ResponseWriter, r *http.Request) {
 	code := chi.URLParam(r, "promo_code")
 
 	if err := a.app.IsUsedPromotion(code, uid); err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 
@@ -135,7 +135,7 @@ func (a *API) getPromotionStatus(w http.ResponseWriter, r *http.Request) {
 	code := chi.URLParam(r, "promo_code")
 
 	if err := a.app.GetPromotionStatus(code, uid); err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 
@@ -145,8 +145,8 @@ func (a *API) getPromotionStatus(w http.ResponseWriter, r *http.Request) {
 func (a *API) deletePromotions(w http.ResponseWriter, r *http.Request) {
 	codes := model.StrSliceFromJSON(r.Body)
 
-	if err := a.app.DeletePromotions(codes); err != nil {
-		respondError(w, err)
+	if err := a.audited(r).DeletePromotions(codes); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
@@ -0,0 +1,321 @@
+package apiv1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dankobgd/ecommerce-shop/app"
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/dankobgd/ecommerce-shop/utils/pagination"
+	"github.com/go-chi/chi"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgQuestionFromJSON      = &i18n.Message{ID: "api.question.create_question.app_error", Other: "could not parse question from json"}
+	msgAnswerFromJSON        = &i18n.Message{ID: "api.question.create_answer.app_error", Other: "could not parse answer from json"}
+	msgQuestionURLParamErr   = &i18n.Message{ID: "api.question.url.params.app_error", Other: "invalid question url param"}
+	msgQuestionStatusQuery   = &i18n.Message{ID: "api.question.get_questions.status.app_error", Other: "invalid moderation status"}
+	msgAnswerURLParamErr     = &i18n.Message{ID: "api.question.answer.url.params.app_error", Other: "invalid answer url param"}
+	msgQuestionStatusFromErr = &i18n.Message{ID: "api.question.moderate.app_error", Other: "could not decode moderation status data"}
+)
+
+// InitQuestions inits the product Q&A routes
+func InitQuestions(a *API) {
+	a.Routes.Questions.Get("/", a.RequirePermission(model.PermissionQuestionModerate, a.getQuestionsByStatus))
+	a.Routes.Questions.Get("/answers", a.RequirePermission(model.PermissionQuestionModerate, a.getAnswersByStatus))
+
+	a.Routes.Product.Post("/questions", a.SessionRequired(a.createProductQuestion))
+	a.Routes.Product.Get("/questions", a.getProductQuestions)
+	a.Routes.Product.Get("/questions/{question_id:[A-Za-z0-9]+}", a.SessionOptional(a.getProductQuestion))
+	a.Routes.Product.Delete("/questions/{question_id:[A-Za-z0-9]+}", a.RequirePermission(model.PermissionQuestionModerate, a.deleteProductQuestion))
+	a.Routes.Product.Put("/questions/{question_id:[A-Za-z0-9]+}/status", a.RequirePermission(model.PermissionQuestionModerate, a.moderateProductQuestion))
+	a.Routes.Product.Post("/questions/{question_id:[A-Za-z0-9]+}/answers", a.SessionRequired(a.createProductAnswer))
+	a.Routes.Product.Delete("/questions/{question_id:[A-Za-z0-9]+}/answers/{answer_id:[A-Za-z0-9]+}", a.RequirePermission(model.PermissionQuestionModerate, a.deleteProductAnswer))
+	a.Routes.Product.Put("/questions/{question_id:[A-Za-z0-9]+}/answers/{answer_id:[A-Za-z0-9]+}/status", a.RequirePermission(model.PermissionQuestionModerate, a.moderateProductAnswer))
+	a.Routes.Product.Post("/questions/{question_id:[A-Za-z0-9]+}/answers/{answer_id:[A-Za-z0-9]+}/upvote", a.SessionRequired(a.upvoteProductAnswer))
+	a.Routes.Product.Delete("/questions/{question_id:[A-Za-z0-9]+}/answers/{answer_id:[A-Za-z0-9]+}/upvote", a.SessionRequired(a.deleteProductAnswerUpvote))
+}
+
+func (a *API) getQuestionsByStatus(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = model.ReviewStatusPending
+	}
+	if !model.IsValidReviewStatus(status) {
+		respondError(w, r, model.NewAppErr("getQuestionsByStatus", model.ErrInvalid, locale.GetUserLocalizer("en"), msgQuestionStatusQuery, http.StatusBadRequest, nil))
+		return
+	}
+
+	pages := pagination.NewFromRequest(r)
+	questions, err := a.app.GetQuestionsByStatus(status, pages.Limit(), pages.Offset())
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	totalCount := -1
+	if len(questions) > 0 {
+		totalCount = questions[0].TotalCount
+	}
+	pages.SetData(questions, totalCount)
+
+	respondJSON(w, http.StatusOK, pages)
+}
+
+func (a *API) getAnswersByStatus(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = model.ReviewStatusPending
+	}
+	if !model.IsValidReviewStatus(status) {
+		respondError(w, r, model.NewAppErr("getAnswersByStatus", model.ErrInvalid, locale.GetUserLocalizer("en"), msgQuestionStatusQuery, http.StatusBadRequest, nil))
+		return
+	}
+
+	pages := pagination.NewFromRequest(r)
+	answers, err := a.app.GetAnswersByStatus(status, pages.Limit(), pages.Offset())
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	totalCount := -1
+	if len(answers) > 0 {
+		totalCount = answers[0].TotalCount
+	}
+	pages.SetData(answers, totalCount)
+
+	respondJSON(w, http.StatusOK, pages)
+}
+
+func (a *API) createProductQuestion(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
+	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("createProductQuestion", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	q, e := model.QuestionFromJSON(r.Body)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("createProductQuestion", model.ErrInternal, locale.GetUserLocalizer("en"), msgQuestionFromJSON, http.StatusInternalServerError, nil))
+		return
+	}
+
+	q.UserID = uid
+	question, err := a.audited(r).CreateProductQuestion(pid, q)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, question)
+}
+
+func (a *API) getProductQuestions(w http.ResponseWriter, r *http.Request) {
+	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("getProductQuestions", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	pages := pagination.NewFromRequest(r)
+	questions, err := a.app.GetProductQuestions(pid, pages.Limit(), pages.Offset())
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	totalCount := -1
+	if len(questions) > 0 {
+		totalCount = questions[0].TotalCount
+	}
+	pages.SetData(questions, totalCount)
+
+	respondJSON(w, http.StatusOK, pages)
+}
+
+func (a *API) getProductQuestion(w http.ResponseWriter, r *http.Request) {
+	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("getProductQuestion", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+	qid, e := strconv.ParseInt(chi.URLParam(r, "question_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("getProductQuestion", model.ErrInternal, locale.GetUserLocalizer("en"), msgQuestionURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	ad, _ := r.Context().Value(app.AccessDataCtxKey).(*model.AccessData)
+	q, err := a.app.GetVisibleProductQuestion(ad, pid, qid)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, q)
+}
+
+func (a *API) deleteProductQuestion(w http.ResponseWriter, r *http.Request) {
+	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("deleteProductQuestion", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+	qid, e := strconv.ParseInt(chi.URLParam(r, "question_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("deleteProductQuestion", model.ErrInternal, locale.GetUserLocalizer("en"), msgQuestionURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	if err := a.audited(r).DeleteProductQuestion(pid, qid); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondOK(w)
+}
+
+func (a *API) moderateProductQuestion(w http.ResponseWriter, r *http.Request) {
+	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("moderateProductQuestion", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+	qid, e := strconv.ParseInt(chi.URLParam(r, "question_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("moderateProductQuestion", model.ErrInternal, locale.GetUserLocalizer("en"), msgQuestionURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	st, e := model.ReviewStatusFromJSON(r.Body)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("moderateProductQuestion", model.ErrInternal, locale.GetUserLocalizer("en"), msgQuestionStatusFromErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	q, err := a.audited(r).ModerateProductQuestion(pid, qid, st)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, q)
+}
+
+func (a *API) createProductAnswer(w http.ResponseWriter, r *http.Request) {
+	ad := a.app.GetAccessDataFromContext(r.Context())
+	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("createProductAnswer", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+	qid, e := strconv.ParseInt(chi.URLParam(r, "question_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("createProductAnswer", model.ErrInternal, locale.GetUserLocalizer("en"), msgQuestionURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	ans, e := model.AnswerFromJSON(r.Body)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("createProductAnswer", model.ErrInternal, locale.GetUserLocalizer("en"), msgAnswerFromJSON, http.StatusInternalServerError, nil))
+		return
+	}
+
+	ans.UserID = ad.UserID
+	answer, err := a.audited(r).CreateProductAnswer(pid, qid, ans, ad.HasPermission(model.PermissionQuestionModerate))
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, answer)
+}
+
+func (a *API) deleteProductAnswer(w http.ResponseWriter, r *http.Request) {
+	pid, qid, aid, err := parseAnswerURLParams(r, "deleteProductAnswer")
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	if err := a.audited(r).DeleteProductAnswer(pid, qid, aid); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondOK(w)
+}
+
+func (a *API) moderateProductAnswer(w http.ResponseWriter, r *http.Request) {
+	pid, qid, aid, err := parseAnswerURLParams(r, "moderateProductAnswer")
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	st, e := model.ReviewStatusFromJSON(r.Body)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("moderateProductAnswer", model.ErrInternal, locale.GetUserLocalizer("en"), msgQuestionStatusFromErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	ans, err := a.audited(r).ModerateProductAnswer(pid, qid, aid, st)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, ans)
+}
+
+func (a *API) upvoteProductAnswer(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
+	pid, qid, aid, err := parseAnswerURLParams(r, "upvoteProductAnswer")
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	if err := a.audited(r).UpvoteProductAnswer(pid, qid, aid, uid); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondOK(w)
+}
+
+func (a *API) deleteProductAnswerUpvote(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
+	pid, qid, aid, err := parseAnswerURLParams(r, "deleteProductAnswerUpvote")
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	if err := a.audited(r).DeleteProductAnswerUpvote(pid, qid, aid, uid); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondOK(w)
+}
+
+func parseAnswerURLParams(r *http.Request, where string) (int64, int64, int64, *model.AppErr) {
+	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
+	if e != nil {
+		return 0, 0, 0, model.NewAppErr(where, model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil)
+	}
+	qid, e := strconv.ParseInt(chi.URLParam(r, "question_id"), 10, 64)
+	if e != nil {
+		return 0, 0, 0, model.NewAppErr(where, model.ErrInternal, locale.GetUserLocalizer("en"), msgQuestionURLParamErr, http.StatusInternalServerError, nil)
+	}
+	aid, e := strconv.ParseInt(chi.URLParam(r, "answer_id"), 10, 64)
+	if e != nil {
+		return 0, 0, 0, model.NewAppErr(where, model.ErrInternal, locale.GetUserLocalizer("en"), msgAnswerURLParamErr, http.StatusInternalServerError, nil)
+	}
+	return pid, qid, aid, nil
+}
@@ -0,0 +1,44 @@
+package apiv1
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+)
+
+// RateLimit applies the global rate limit to every request
+func (a *API) RateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.takeRateLimit(w, r, a.app.DefaultRateLimit()) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RateLimited applies the route's own limit on top of the global one
+func (a *API) RateLimited(limit *model.RateLimit, next http.HandlerFunc) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.takeRateLimit(w, r, limit) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// takeRateLimit sets the rate limit headers and responds with the error when the limit is exceeded
+func (a *API) takeRateLimit(w http.ResponseWriter, r *http.Request, limit *model.RateLimit) bool {
+	res, err := a.app.CheckRateLimit(r, limit)
+	if res != nil {
+		w.Header().Set(model.HeaderRateLimitLimit, strconv.Itoa(res.Limit))
+		w.Header().Set(model.HeaderRateLimitRemaining, strconv.Itoa(res.Remaining))
+		w.Header().Set(model.HeaderRateLimitReset, strconv.Itoa(int(math.Ceil(res.ResetAfter.Seconds()))))
+	}
+	if err != nil {
+		respondError(w, r, err)
+		return false
+	}
+	return true
+}
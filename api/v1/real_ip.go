@@ -0,0 +1,19 @@
+package apiv1
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/dankobgd/ecommerce-shop/app"
+)
+
+// RealIP resolves the client ip and puts it in the request context, the remote address is replaced with it
+// so the request log shows the client and not the proxy
+func (a *API) RealIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := a.app.ClientIP(r)
+		r.RemoteAddr = ip
+		ctx := context.WithValue(r.Context(), app.ClientIPCtxKey, ip)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,222 @@
+package apiv1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/dankobgd/ecommerce-shop/utils/pagination"
+	"github.com/go-chi/chi"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgReviewStatusFromJSON = &i18n.Message{ID: "api.review.moderate_review.app_error", Other: "could not decode review status data"}
+	msgReviewReportFromJSON = &i18n.Message{ID: "api.review.report_review.app_error", Other: "could not decode review report data"}
+	msgReviewMediaMultipart = &i18n.Message{ID: "api.review.create_review_media.multipart.app_error", Other: "could not decode review media multipart data"}
+	msgReviewStatusQuery    = &i18n.Message{ID: "api.review.get_reviews.status.app_error", Other: "invalid review status"}
+)
+
+// InitReviews inits the review moderation routes
+func InitReviews(a *API) {
+	a.Routes.Reviews.Get("/", a.RequirePermission(model.PermissionReviewModerate, a.getReviewsByStatus))
+}
+
+func (a *API) getReviewsByStatus(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = model.ReviewStatusPending
+	}
+	if !model.IsValidReviewStatus(status) {
+		respondError(w, r, model.NewAppErr("getReviewsByStatus", model.ErrInvalid, locale.GetUserLocalizer("en"), msgReviewStatusQuery, http.StatusBadRequest, nil))
+		return
+	}
+
+	pages := pagination.NewFromRequest(r)
+	reviews, err := a.app.GetReviewsByStatus(status, pages.Limit(), pages.Offset())
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	totalCount := -1
+	if len(reviews) > 0 {
+		totalCount = reviews[0].TotalCount
+	}
+	pages.SetData(reviews, totalCount)
+
+	respondJSON(w, http.StatusOK, pages)
+}
+
+func (a *API) moderateProductReview(w http.ResponseWriter, r *http.Request) {
+	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("moderateProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+	rid, e := strconv.ParseInt(chi.URLParam(r, "review_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("moderateProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgReviewURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	st, e := model.ReviewStatusFromJSON(r.Body)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("moderateProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgReviewStatusFromJSON, http.StatusInternalServerError, nil))
+		return
+	}
+
+	rev, err := a.audited(r).ModerateProductReview(pid, rid, st)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, rev)
+}
+
+func (a *API) voteProductReview(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
+	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("voteProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+	rid, e := strconv.ParseInt(chi.URLParam(r, "review_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("voteProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgReviewURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	if err := a.audited(r).VoteProductReview(pid, rid, uid); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondOK(w)
+}
+
+func (a *API) deleteProductReviewVote(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
+	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("deleteProductReviewVote", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+	rid, e := strconv.ParseInt(chi.URLParam(r, "review_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("deleteProductReviewVote", model.ErrInternal, locale.GetUserLocalizer("en"), msgReviewURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	if err := a.audited(r).DeleteProductReviewVote(pid, rid, uid); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondOK(w)
+}
+
+func (a *API) reportProductReview(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
+	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("reportProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+	rid, e := strconv.ParseInt(chi.URLParam(r, "review_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("reportProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgReviewURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	report, e := model.ReviewReportFromJSON(r.Body)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("reportProductReview", model.ErrInternal, locale.GetUserLocalizer("en"), msgReviewReportFromJSON, http.StatusInternalServerError, nil))
+		return
+	}
+
+	report.UserID = uid
+	rr, err := a.audited(r).ReportProductReview(pid, rid, report)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, rr)
+}
+
+func (a *API) getProductReviewReports(w http.ResponseWriter, r *http.Request) {
+	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("getProductReviewReports", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+	rid, e := strconv.ParseInt(chi.URLParam(r, "review_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("getProductReviewReports", model.ErrInternal, locale.GetUserLocalizer("en"), msgReviewURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	reports, err := a.app.GetProductReviewReports(pid, rid)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, reports)
+}
+
+func (a *API) createProductReviewMedia(w http.ResponseWriter, r *http.Request) {
+	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("createProductReviewMedia", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+	rid, e := strconv.ParseInt(chi.URLParam(r, "review_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("createProductReviewMedia", model.ErrInternal, locale.GetUserLocalizer("en"), msgReviewURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	if err := r.ParseMultipartForm(model.FileUploadSizeLimit); err != nil {
+		respondError(w, r, model.NewAppErr("createProductReviewMedia", model.ErrInternal, locale.GetUserLocalizer("en"), msgReviewMediaMultipart, http.StatusInternalServerError, nil))
+		return
+	}
+
+	ad := a.app.GetAccessDataFromContext(r.Context())
+	media, err := a.audited(r).CreateProductReviewMedia(ad, pid, rid, r.MultipartForm.File["media"])
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, media)
+}
+
+func (a *API) deleteProductReviewMedia(w http.ResponseWriter, r *http.Request) {
+	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("deleteProductReviewMedia", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+	rid, e := strconv.ParseInt(chi.URLParam(r, "review_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("deleteProductReviewMedia", model.ErrInternal, locale.GetUserLocalizer("en"), msgReviewURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+	mid, e := strconv.ParseInt(chi.URLParam(r, "media_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("deleteProductReviewMedia", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	ad := a.app.GetAccessDataFromContext(r.Context())
+	if err := a.audited(r).DeleteProductReviewMedia(ad, pid, rid, mid); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondOK(w)
+}
@@ -0,0 +1,18 @@
+package apiv1
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+)
+
+func TestGetReviewsByInvalidStatus(t *testing.T) {
+	st, users := crossUserFixture()
+	ts := newTestServer(t, st, newTestConfig())
+	moderator := testAccessToken(t, users["moderator"], model.PermissionReviewModerate)
+
+	if resp := doRequest(t, ts, http.MethodGet, "/api/v1/reviews?status=deleted", moderator, ""); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("listing the reviews by the unknown status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,173 @@
+package apiv1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/dankobgd/ecommerce-shop/utils/pagination"
+	"github.com/go-chi/chi"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgRoleURLParamErr      = &i18n.Message{ID: "api.role.url.params.app_error", Other: "invalid role url param"}
+	msgRoleFromJSON         = &i18n.Message{ID: "api.role.create_role.json.app_error", Other: "could not decode role json data"}
+	msgRolePatchFromJSONErr = &i18n.Message{ID: "api.role.patch_role.json.app_error", Other: "could not decode role patch json data"}
+	msgUserRoleURLParamErr  = &i18n.Message{ID: "api.role.user_role.url.params.app_error", Other: "invalid user role url param"}
+)
+
+// InitRoles inits the role routes, only the admins can manage the roles so the staff can't grant themselves more permissions
+func InitRoles(a *API) {
+	a.Routes.Roles.Get("/", a.AdminSessionRequired(a.getRoles))
+	a.Routes.Roles.Post("/", a.AdminSessionRequired(a.createRole))
+	a.Routes.Roles.Get("/permissions", a.AdminSessionRequired(a.getPermissions))
+	a.Routes.Role.Get("/", a.AdminSessionRequired(a.getRole))
+	a.Routes.Role.Patch("/", a.AdminSessionRequired(a.patchRole))
+	a.Routes.Role.Delete("/", a.AdminSessionRequired(a.deleteRole))
+
+	// role assignments
+	a.Routes.User.Get("/roles", a.AdminSessionRequired(a.getUserRoles))
+	a.Routes.User.Put("/roles/{role_id:[A-Za-z0-9]+}", a.AdminSessionRequired(a.assignUserRole))
+	a.Routes.User.Delete("/roles/{role_id:[A-Za-z0-9]+}", a.AdminSessionRequired(a.unassignUserRole))
+}
+
+func (a *API) createRole(w http.ResponseWriter, r *http.Request) {
+	role, e := model.RoleFromJSON(r.Body)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("createRole", model.ErrInternal, locale.GetUserLocalizer("en"), msgRoleFromJSON, http.StatusInternalServerError, nil))
+		return
+	}
+
+	created, err := a.audited(r).CreateRole(role)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, created)
+}
+
+func (a *API) getRoles(w http.ResponseWriter, r *http.Request) {
+	pages := pagination.NewFromRequest(r)
+	roles, err := a.app.GetRoles(pages.Limit(), pages.Offset())
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	totalCount := -1
+	if len(roles) > 0 {
+		totalCount = roles[0].TotalCount
+	}
+	pages.SetData(roles, totalCount)
+
+	respondJSON(w, http.StatusOK, pages)
+}
+
+func (a *API) getPermissions(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, model.Permissions)
+}
+
+func (a *API) getRole(w http.ResponseWriter, r *http.Request) {
+	id, e := strconv.ParseInt(chi.URLParam(r, "role_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("getRole", model.ErrInternal, locale.GetUserLocalizer("en"), msgRoleURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	role, err := a.app.GetRole(id)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, role)
+}
+
+func (a *API) patchRole(w http.ResponseWriter, r *http.Request) {
+	id, e := strconv.ParseInt(chi.URLParam(r, "role_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("patchRole", model.ErrInternal, locale.GetUserLocalizer("en"), msgRoleURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	patch, e := model.RolePatchFromJSON(r.Body)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("patchRole", model.ErrInternal, locale.GetUserLocalizer("en"), msgRolePatchFromJSONErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	role, err := a.audited(r).PatchRole(id, patch)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, role)
+}
+
+func (a *API) deleteRole(w http.ResponseWriter, r *http.Request) {
+	id, e := strconv.ParseInt(chi.URLParam(r, "role_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("deleteRole", model.ErrInternal, locale.GetUserLocalizer("en"), msgRoleURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	if err := a.audited(r).DeleteRole(id); err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondOK(w)
+}
+
+func (a *API) getUserRoles(w http.ResponseWriter, r *http.Request) {
+	uid, e := strconv.ParseInt(chi.URLParam(r, "user_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("getUserRoles", model.ErrInternal, locale.GetUserLocalizer("en"), msgUserRoleURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	roles, err := a.app.GetUserRoles(uid)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, roles)
+}
+
+func (a *API) assignUserRole(w http.ResponseWriter, r *http.Request) {
+	uid, e := strconv.ParseInt(chi.URLParam(r, "user_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("assignUserRole", model.ErrInternal, locale.GetUserLocalizer("en"), msgUserRoleURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+	rid, e := strconv.ParseInt(chi.URLParam(r, "role_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("assignUserRole", model.ErrInternal, locale.GetUserLocalizer("en"), msgUserRoleURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	if err := a.audited(r).AssignUserRole(uid, rid); err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondOK(w)
+}
+
+func (a *API) unassignUserRole(w http.ResponseWriter, r *http.Request) {
+	uid, e := strconv.ParseInt(chi.URLParam(r, "user_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("unassignUserRole", model.ErrInternal, locale.GetUserLocalizer("en"), msgUserRoleURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+	rid, e := strconv.ParseInt(chi.URLParam(r, "role_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("unassignUserRole", model.ErrInternal, locale.GetUserLocalizer("en"), msgUserRoleURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	if err := a.audited(r).UnassignUserRole(uid, rid); err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondOK(w)
+}
@@ -20,30 +20,30 @@ var (
 	msgTagDeleteerr        = &i18n.Message{ID: "api.tag.delete_tag.app_error", Other: "could not delete tag"}
 	msgTagMultipartErr     = &i18n.Message{ID: "api.tag.create_tag.multipart.app_error", Other: "could not decode tag multipart data"}
 	msgTagURLParamErr      = &i18n.Message{ID: "api.tag.url.params.app_error", Other: "could not parse URL params"}
-	msgTagPatchFromJSONErr = &i18n.Message{ID: "api.tag.patch_tag.app_error", Other: "could not decode tag patch data"}
+	msgTagPatchFromJSONErr = &i18n.Message{ID: "api.tag.patch_tag.json.app_error", Other: "could not decode tag patch data"}
 )
 
 // InitTags inits the tag routes
 func InitTags(a *API) {
-	a.Routes.Tags.Post("/", a.AdminSessionRequired(a.createTag))
-	a.Routes.Tags.Delete("/bulk", a.AdminSessionRequired(a.deleteTags))
+	a.Routes.Tags.Post("/", a.RequirePermission(model.PermissionTagWrite, a.createTag))
+	a.Routes.Tags.Delete("/bulk", a.RequirePermission(model.PermissionTagWrite, a.deleteTags))
 	a.Routes.Tags.Get("/count", a.getTagsCount)
 	a.Routes.Tags.Get("/", a.getTags)
 	a.Routes.Tag.Get("/", a.getTag)
-	a.Routes.Tag.Patch("/", a.AdminSessionRequired(a.patchTag))
-	a.Routes.Tag.Delete("/", a.AdminSessionRequired(a.deleteTag))
+	a.Routes.Tag.Patch("/", a.RequirePermission(model.PermissionTagWrite, a.patchTag))
+	a.Routes.Tag.Delete("/", a.RequirePermission(model.PermissionTagWrite, a.deleteTag))
 }
 
 func (a *API) createTag(w http.ResponseWriter, r *http.Request) {
 	t, e := model.TagFromJSON(r.Body)
 	if e != nil {
-		respondError(w, model.NewAppErr("createTag", model.ErrInternal, locale.GetUserLocalizer("en"), msgTagFromJSON, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("createTag", model.ErrInternal, locale.GetUserLocalizer("en"), msgTagFromJSON, http.StatusInternalServerError, nil))
 		return
 	}
 
-	tag, err := a.app.CreateTag(t)
+	tag, err := a.audited(r).CreateTag(t)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 	respondJSON(w, http.StatusCreated, tag)
@@ -52,12 +52,12 @@ func (a *API) createTag(w http.ResponseWriter, r *http.Request) {
 func (a *API) getTag(w http.ResponseWriter, r *http.Request) {
 	tid, e := strconv.ParseInt(chi.URLParam(r, "tag_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("getTag", model.ErrInternal, locale.GetUserLocalizer("en"), msgTagURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("getTag", model.ErrInternal, locale.GetUserLocalizer("en"), msgTagURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 	t, err := a.app.GetTag(tid)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 	respondJSON(w, http.StatusOK, t)
@@ -72,7 +72,7 @@ func (a *API) getTags(w http.ResponseWriter, r *http.Request) {
 	pages := pagination.NewFromRequest(r)
 	tags, err := a.app.GetTags(pages.Limit(), pages.Offset())
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 
@@ -88,19 +88,19 @@ func (a *API) getTags(w http.ResponseWriter, r *http.Request) {
 func (a *API) patchTag(w http.ResponseWriter, r *http.Request) {
 	tid, err := strconv.ParseInt(chi.URLParam(r, "tag_id"), 10, 64)
 	if err != nil {
-		respondError(w, model.NewAppErr("patchTag", model.ErrInternal, locale.GetUserLocalizer("en"), msgTagURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("patchTag", model.ErrInternal, locale.GetUserLocalizer("en"), msgTagURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 
 	patch, err := model.TagPatchFromJSON(r.Body)
 	if err != nil {
-		respondError(w, model.NewAppErr("patchTag", model.ErrInternal, locale.GetUserLocalizer("en"), msgTagPatchFromJSONErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("patchTag", model.ErrInternal, locale.GetUserLocalizer("en"), msgTagPatchFromJSONErr, http.StatusInternalServerError, nil))
 		return
 	}
 
-	utag, tErr := a.app.PatchTag(tid, patch)
+	utag, tErr := a.audited(r).PatchTag(tid, patch)
 	if err != nil {
-		respondError(w, tErr)
+		respondError(w, r, tErr)
 		return
 	}
 
@@ -110,11 +110,11 @@ func (a *API) patchTag(w http.ResponseWriter, r *http.Request) {
 func (a *API) deleteTag(w http.ResponseWriter, r *http.Request) {
 	tid, err := strconv.ParseInt(chi.URLParam(r, "tag_id"), 10, 64)
 	if err != nil {
-		respondError(w, model.NewAppErr("deleteTag", model.ErrInternal, locale.GetUserLocalizer("en"), msgTagURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("deleteTag", model.ErrInternal, locale.GetUserLocalizer("en"), msgTagURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
-	if err := a.app.DeleteTag(tid); err != nil {
-		respondError(w, err)
+	if err := a.audited(r).DeleteTag(tid); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
@@ -124,8 +124,8 @@ func (a *API) deleteTag(w http.ResponseWriter, r *http.Request) {
 func (a *API) deleteTags(w http.ResponseWriter, r *http.Request) {
 	ids := model.IntSliceFromJSON(r.Body)
 
-	if err := a.app.DeleteTags(ids); err != nil {
-		respondError(w, err)
+	if err := a.audited(r).DeleteTags(ids); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
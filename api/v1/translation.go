@@ -0,0 +1,99 @@
+package apiv1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/go-chi/chi"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgTranslationFromJSON    = &i18n.Message{ID: "api.translation.save_translation.json.app_error", Other: "could not decode translation json data"}
+	msgTranslationURLParamErr = &i18n.Message{ID: "api.translation.url.params.app_error", Other: "invalid translation url param"}
+)
+
+// the translation handlers are shared by the translatable catalog resources,
+// idParam is the url param of the resource id in the resource routes
+
+func (a *API) getTranslations(resource, idParam string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, e := strconv.ParseInt(chi.URLParam(r, idParam), 10, 64)
+		if e != nil {
+			respondError(w, r, model.NewAppErr("getTranslations", model.ErrInternal, locale.GetUserLocalizer("en"), msgTranslationURLParamErr, http.StatusInternalServerError, nil))
+			return
+		}
+
+		translations, err := a.app.GetTranslations(resource, id)
+		if err != nil {
+			respondError(w, r, err)
+			return
+		}
+
+		respondJSON(w, http.StatusOK, translations)
+	}
+}
+
+func (a *API) getTranslation(resource, idParam string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, e := strconv.ParseInt(chi.URLParam(r, idParam), 10, 64)
+		if e != nil {
+			respondError(w, r, model.NewAppErr("getTranslation", model.ErrInternal, locale.GetUserLocalizer("en"), msgTranslationURLParamErr, http.StatusInternalServerError, nil))
+			return
+		}
+
+		t, err := a.app.GetTranslation(resource, id, chi.URLParam(r, "translation_locale"))
+		if err != nil {
+			respondError(w, r, err)
+			return
+		}
+
+		respondJSON(w, http.StatusOK, t)
+	}
+}
+
+func (a *API) saveTranslation(resource, idParam string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, e := strconv.ParseInt(chi.URLParam(r, idParam), 10, 64)
+		if e != nil {
+			respondError(w, r, model.NewAppErr("saveTranslation", model.ErrInternal, locale.GetUserLocalizer("en"), msgTranslationURLParamErr, http.StatusInternalServerError, nil))
+			return
+		}
+
+		t, e := model.TranslationFromJSON(r.Body)
+		if e != nil {
+			respondError(w, r, model.NewAppErr("saveTranslation", model.ErrInternal, locale.GetUserLocalizer("en"), msgTranslationFromJSON, http.StatusInternalServerError, nil))
+			return
+		}
+		t.Resource = resource
+		t.ResourceID = id
+		t.Locale = chi.URLParam(r, "translation_locale")
+
+		saved, err := a.audited(r).SaveTranslation(t)
+		if err != nil {
+			respondError(w, r, err)
+			return
+		}
+
+		respondJSON(w, http.StatusOK, saved)
+	}
+}
+
+func (a *API) deleteTranslation(resource, idParam string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, e := strconv.ParseInt(chi.URLParam(r, idParam), 10, 64)
+		if e != nil {
+			respondError(w, r, model.NewAppErr("deleteTranslation", model.ErrInternal, locale.GetUserLocalizer("en"), msgTranslationURLParamErr, http.StatusInternalServerError, nil))
+			return
+		}
+
+		if err := a.audited(r).DeleteTranslation(resource, id, chi.URLParam(r, "translation_locale")); err != nil {
+			respondError(w, r, err)
+			return
+		}
+
+		respondOK(w)
+	}
+}
@@ -0,0 +1,120 @@
+package apiv1
+
+import (
+	"net/http"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgTwoFactorCodeFromJSON = &i18n.Message{ID: "api.two_factor.code.json.app_error", Other: "could not decode two-factor code json data"}
+)
+
+// InitTwoFactor inits the two-factor authentication routes
+func InitTwoFactor(a *API) {
+	a.Routes.Users.Get("/2fa", a.SessionRequired(a.getTwoFactor))
+	a.Routes.Users.Post("/2fa/enroll", a.SessionRequired(a.enrollTwoFactor))
+	a.Routes.Users.Post("/2fa/enable", a.SessionRequired(a.enableTwoFactor))
+	a.Routes.Users.Post("/2fa/disable", a.SessionRequired(a.disableTwoFactor))
+	a.Routes.Users.Post("/2fa/recovery-codes", a.SessionRequired(a.regenerateRecoveryCodes))
+}
+
+func (a *API) loginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	code, e := model.TwoFactorCodeFromJSON(r.Body)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("loginTwoFactor", model.ErrInternal, locale.GetUserLocalizer("en"), msgTwoFactorCodeFromJSON, http.StatusInternalServerError, nil))
+		return
+	}
+	// the social login passes the challenge in the cookie
+	if cookie, e := r.Cookie(model.TwoFactorChallengeCookieName); e == nil && code.ChallengeToken == "" {
+		code.ChallengeToken = cookie.Value
+	}
+
+	user, err := a.app.VerifyLoginChallenge(code, a.app.RequestIP(r))
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	a.app.DeleteTwoFactorChallengeCookie(w)
+
+	tokenMeta, err := a.app.IssueTokens(user)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	if err := a.app.SaveAuth(user.ID, tokenMeta, a.app.SessionFromRequest(r)); err != nil {
+		respondError(w, r, err)
+		return
+	}
+	a.app.AttachSessionCookies(w, tokenMeta)
+	respondJSON(w, http.StatusOK, user)
+}
+
+func (a *API) getTwoFactor(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
+	tf, err := a.app.GetTwoFactor(uid)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, tf)
+}
+
+func (a *API) enrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
+	enrollment, err := a.app.EnrollTwoFactor(uid)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, enrollment)
+}
+
+func (a *API) enableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
+	code, e := model.TwoFactorCodeFromJSON(r.Body)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("enableTwoFactor", model.ErrInternal, locale.GetUserLocalizer("en"), msgTwoFactorCodeFromJSON, http.StatusInternalServerError, nil))
+		return
+	}
+
+	codes, err := a.audited(r).EnableTwoFactor(uid, code.Code)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, codes)
+}
+
+func (a *API) disableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
+	code, e := model.TwoFactorCodeFromJSON(r.Body)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("disableTwoFactor", model.ErrInternal, locale.GetUserLocalizer("en"), msgTwoFactorCodeFromJSON, http.StatusInternalServerError, nil))
+		return
+	}
+
+	if err := a.audited(r).DisableTwoFactor(uid, code.Code); err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondOK(w)
+}
+
+func (a *API) regenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
+	code, e := model.TwoFactorCodeFromJSON(r.Body)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("regenerateRecoveryCodes", model.ErrInternal, locale.GetUserLocalizer("en"), msgTwoFactorCodeFromJSON, http.StatusInternalServerError, nil))
+		return
+	}
+
+	codes, err := a.audited(r).RegenerateRecoveryCodes(uid, code.Code)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, codes)
+}
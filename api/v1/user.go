@@ -4,6 +4,7 @@ import (
 	"mime/multipart"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/dankobgd/ecommerce-shop/model"
 	"github.com/dankobgd/ecommerce-shop/utils/locale"
@@ -15,31 +16,40 @@ import (
 var (
 	msgInvalidToken         = &i18n.Message{ID: "model.access_token_verify.json.app_error", Other: "token is invalid or has already expired"}
 	msgUserFromJSON         = &i18n.Message{ID: "api.user.create_user.json.app_error", Other: "could not decode user json data"}
-	msgRefreshTokenFromJSON = &i18n.Message{ID: "api.user.create_user.json.app_error", Other: "could not decode token json data"}
+	msgRefreshTokenFromJSON = &i18n.Message{ID: "api.user.token.json.app_error", Other: "could not decode token json data"}
 	msgInvalidEmail         = &i18n.Message{ID: "api.user.sendUserVerificationEmail.email.app_error", Other: "invalid email provided"}
 	msgInvalidPassword      = &i18n.Message{ID: "api.user.reset_user_password.password.app_error", Other: "invalid password provided"}
 	msgUserURLParams        = &i18n.Message{ID: "api.user.delete_user.app_error", Other: "invalid user_id url param"}
 	msgUserMultiPartErr     = &i18n.Message{ID: "api.user.create_user.app_error", Other: "invalid user form data"}
-	msgAddressFromJSON      = &i18n.Message{ID: "api.user.delete_user.app_error", Other: "could not parse address json data"}
-	msgAddressPatchFromJSON = &i18n.Message{ID: "api.user.delete_user.app_error", Other: "could not parse address patch data"}
-	msgDeleteUserAddress    = &i18n.Message{ID: "api.user.delete_user.app_error", Other: "could not delete address"}
+	msgAddressFromJSON      = &i18n.Message{ID: "api.user.address.json.app_error", Other: "could not parse address json data"}
+	msgAddressPatchFromJSON = &i18n.Message{ID: "api.user.address_patch.json.app_error", Other: "could not parse address patch data"}
+	msgDeleteUserAddress    = &i18n.Message{ID: "api.user.delete_address.app_error", Other: "could not delete address"}
 	msgUserAvatarMultipart  = &i18n.Message{ID: "api.user.upload_user_avatar.app_error", Other: "could not parse avatar multipart file"}
 	msgUpdateProfile        = &i18n.Message{ID: "api.user.update_profile.app_error", Other: "could not update user profile"}
 	msgGetUserOrders        = &i18n.Message{ID: "api.user.get_user_orders.app_error", Other: "could not get user orders"}
 	msgWishlistParamErr     = &i18n.Message{ID: "api.user.wishlist.app_error", Other: "invalid wishlist product_id"}
+
+	msgWishlistAlertsFromJSON = &i18n.Message{ID: "api.user.wishlist.alerts.from_json.app_error", Other: "could not decode wishlist alerts data"}
 )
 
+// signupRateLimit limits how many accounts can be created by the same client
+var signupRateLimit = &model.RateLimit{Name: "signup", Requests: 5, Period: time.Hour}
+
 // InitUser inits the user routes
 func InitUser(a *API) {
 	a.Routes.Users.Get("/count", a.getUsersCount)
-	a.Routes.Users.Get("/", a.AdminSessionRequired(a.getUsers))
+	a.Routes.Users.Get("/", a.RequirePermission(model.PermissionUserRead, a.getUsers))
 	a.Routes.Users.Get("/me", a.SessionRequired(a.currentUser))
-	a.Routes.Users.Post("/new", a.createUser)
-	a.Routes.Users.Post("/", a.signup)
+	a.Routes.Users.Post("/new", a.RateLimited(signupRateLimit, a.createUser))
+	a.Routes.Users.Post("/", a.RateLimited(signupRateLimit, a.signup))
 	a.Routes.Users.Post("/login", a.login)
+	a.Routes.Users.Post("/login/2fa", a.loginTwoFactor)
 	a.Routes.Users.Post("/logout", a.SessionRequired(a.logout))
-	a.Routes.Users.Delete("/bulk", a.AdminSessionRequired(a.deleteUsers))
+	a.Routes.Users.Delete("/bulk", a.RequirePermission(model.PermissionUserWrite, a.deleteUsers))
 	a.Routes.Users.Post("/token/refresh", a.refresh)
+	a.Routes.Users.Get("/sessions", a.SessionRequired(a.getSessions))
+	a.Routes.Users.Delete("/sessions", a.SessionRequired(a.revokeSessions))
+	a.Routes.Users.Delete("/sessions/{session_id}", a.SessionRequired(a.revokeSession))
 	a.Routes.Users.Post("/email/verify", a.verifyUserEmail)
 	a.Routes.Users.Post("/email/verify/send", a.sendVerificationEmail)
 	a.Routes.Users.Post("/password/reset", a.resetUserPassword)
@@ -57,9 +67,10 @@ func InitUser(a *API) {
 	a.Routes.Users.Get("/wishlist", a.SessionRequired(a.getWishlist))
 	a.Routes.Users.Delete("/wishlist/{product_id:[A-Za-z0-9]+}", a.SessionRequired(a.deleteWishlist))
 	a.Routes.Users.Delete("/wishlist/clear", a.SessionRequired(a.clearWishlist))
+	a.Routes.Users.Put("/wishlist/{product_id:[A-Za-z0-9]+}/alerts", a.SessionRequired(a.updateWishlistAlerts))
 
 	a.Routes.User.Get("/", a.SessionRequired(a.getUser))
-	a.Routes.User.Patch("/", a.AdminSessionRequired(a.update))
+	a.Routes.User.Patch("/", a.RequirePermission(model.PermissionUserWrite, a.update))
 	a.Routes.User.Delete("/", a.SessionRequired(a.deleteUser))
 	a.Routes.User.Get("/orders", a.SessionRequired(a.getUserOrders))
 }
@@ -68,7 +79,7 @@ func (a *API) currentUser(w http.ResponseWriter, r *http.Request) {
 	uid := a.app.GetUserIDFromContext(r.Context())
 	user, err := a.app.GetUserByID(uid)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 	respondJSON(w, http.StatusOK, user)
@@ -76,7 +87,7 @@ func (a *API) currentUser(w http.ResponseWriter, r *http.Request) {
 
 func (a *API) createUser(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseMultipartForm(model.FileUploadSizeLimit); err != nil {
-		respondError(w, model.NewAppErr("createUser", model.ErrInternal, locale.GetUserLocalizer("en"), msgUserMultiPartErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("createUser", model.ErrInternal, locale.GetUserLocalizer("en"), msgUserMultiPartErr, http.StatusInternalServerError, nil))
 		return
 	}
 
@@ -85,7 +96,7 @@ func (a *API) createUser(w http.ResponseWriter, r *http.Request) {
 
 	u := &model.User{}
 	if err := model.SchemaDecoder.Decode(u, mpf.Value); err != nil {
-		respondError(w, model.NewAppErr("createUser", model.ErrInternal, locale.GetUserLocalizer("en"), msgUserMultiPartErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("createUser", model.ErrInternal, locale.GetUserLocalizer("en"), msgUserMultiPartErr, http.StatusInternalServerError, nil))
 		return
 	}
 
@@ -94,9 +105,9 @@ func (a *API) createUser(w http.ResponseWriter, r *http.Request) {
 		fh = mpf.File["avatar_url"][0]
 	}
 
-	user, uErr := a.app.CreateUser(u, fh)
+	user, uErr := a.audited(r).CreateUser(u, fh)
 	if uErr != nil {
-		respondError(w, uErr)
+		respondError(w, r, uErr)
 		return
 	}
 	respondJSON(w, http.StatusCreated, user)
@@ -105,22 +116,24 @@ func (a *API) createUser(w http.ResponseWriter, r *http.Request) {
 func (a *API) signup(w http.ResponseWriter, r *http.Request) {
 	u, e := model.UserFromJSON(r.Body)
 	if e != nil {
-		respondError(w, model.NewAppErr("signup", model.ErrInternal, locale.GetUserLocalizer("en"), msgUserFromJSON, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("signup", model.ErrInternal, locale.GetUserLocalizer("en"), msgUserFromJSON, http.StatusInternalServerError, nil))
 		return
 	}
 
-	user, err := a.app.Signup(u)
+	user, err := a.audited(r).Signup(u)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 
 	tokenMeta, err := a.app.IssueTokens(user)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
+		return
 	}
-	if err := a.app.SaveAuth(user.ID, tokenMeta); err != nil {
-		respondError(w, err)
+	if err := a.app.SaveAuth(user.ID, tokenMeta, a.app.SessionFromRequest(r)); err != nil {
+		respondError(w, r, err)
+		return
 	}
 	a.app.AttachSessionCookies(w, tokenMeta)
 	respondJSON(w, http.StatusCreated, user)
@@ -129,22 +142,28 @@ func (a *API) signup(w http.ResponseWriter, r *http.Request) {
 func (a *API) login(w http.ResponseWriter, r *http.Request) {
 	u, e := model.UserLoginFromJSON(r.Body)
 	if e != nil {
-		respondError(w, model.NewAppErr("login", model.ErrInternal, locale.GetUserLocalizer("en"), msgUserFromJSON, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("login", model.ErrInternal, locale.GetUserLocalizer("en"), msgUserFromJSON, http.StatusInternalServerError, nil))
 		return
 	}
 
-	user, err := a.app.Login(u)
+	user, challenge, err := a.app.Login(u, a.app.RequestIP(r))
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
+		return
+	}
+	if challenge != nil {
+		respondJSON(w, http.StatusOK, challenge)
 		return
 	}
 
 	tokenMeta, err := a.app.IssueTokens(user)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
+		return
 	}
-	if err := a.app.SaveAuth(user.ID, tokenMeta); err != nil {
-		respondError(w, err)
+	if err := a.app.SaveAuth(user.ID, tokenMeta, a.app.SessionFromRequest(r)); err != nil {
+		respondError(w, r, err)
+		return
 	}
 	a.app.AttachSessionCookies(w, tokenMeta)
 	respondJSON(w, http.StatusOK, user)
@@ -154,27 +173,69 @@ func (a *API) logout(w http.ResponseWriter, r *http.Request) {
 	a.app.DeleteSessionCookies(w)
 	ad, err := a.app.ExtractTokenMetadata(r)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
+		return
+	}
+	if ad.SessionID != "" {
+		if err := a.app.RevokeSession(ad.UserID, ad.SessionID); err != nil {
+			respondError(w, r, err)
+			return
+		}
+		respondOK(w)
 		return
 	}
 	deleted, err := a.app.DeleteAuth(ad.AccessUUID)
 	if err != nil || deleted == 0 {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 	respondOK(w)
 }
 
+func (a *API) getSessions(w http.ResponseWriter, r *http.Request) {
+	ad := a.app.GetAccessDataFromContext(r.Context())
+	sessions, err := a.app.GetSessions(ad.UserID, ad.SessionID)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, sessions)
+}
+
+func (a *API) revokeSession(w http.ResponseWriter, r *http.Request) {
+	ad := a.app.GetAccessDataFromContext(r.Context())
+	sid := chi.URLParam(r, "session_id")
+
+	if err := a.app.RevokeSession(ad.UserID, sid); err != nil {
+		respondError(w, r, err)
+		return
+	}
+	if sid == ad.SessionID {
+		a.app.DeleteSessionCookies(w)
+	}
+	respondOK(w)
+}
+
+func (a *API) revokeSessions(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
+	if err := a.app.RevokeSessions(uid); err != nil {
+		respondError(w, r, err)
+		return
+	}
+	a.app.DeleteSessionCookies(w)
+	respondOK(w)
+}
+
 func (a *API) refresh(w http.ResponseWriter, r *http.Request) {
 	rt, e := model.RefreshTokenFromJSON(r.Body)
 	if e != nil {
-		respondError(w, model.NewAppErr("refresh", model.ErrInternal, locale.GetUserLocalizer("en"), msgRefreshTokenFromJSON, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("refresh", model.ErrInternal, locale.GetUserLocalizer("en"), msgRefreshTokenFromJSON, http.StatusInternalServerError, nil))
 		return
 	}
 
 	meta, err := a.app.RefreshToken(rt)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 
@@ -188,7 +249,11 @@ func (a *API) sendVerificationEmail(w http.ResponseWriter, r *http.Request) {
 	email = model.NormalizeEmail(email)
 
 	if len(email) == 0 || !model.IsValidEmail(email) {
-		respondError(w, model.NewAppErr("api.sendVerificationEmail", model.ErrInvalid, locale.GetUserLocalizer("en"), msgInvalidEmail, http.StatusBadRequest, nil))
+		respondError(w, r, model.NewAppErr("api.sendVerificationEmail", model.ErrInvalid, locale.GetUserLocalizer("en"), msgInvalidEmail, http.StatusBadRequest, nil))
+		return
+	}
+	if err := a.app.CheckEmailRateLimit(model.TokenTypeEmailVerification.String(), email, a.app.RequestIP(r)); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
@@ -213,12 +278,12 @@ func (a *API) verifyUserEmail(w http.ResponseWriter, r *http.Request) {
 	token := props["token"]
 
 	if len(token) == 0 {
-		respondError(w, model.NewAppErr("api.sendVerificationEmail", model.ErrInvalid, locale.GetUserLocalizer("en"), msgInvalidToken, http.StatusBadRequest, nil))
+		respondError(w, r, model.NewAppErr("api.sendVerificationEmail", model.ErrInvalid, locale.GetUserLocalizer("en"), msgInvalidToken, http.StatusBadRequest, nil))
 		return
 	}
 
-	if err := a.app.VerifyUserEmail(token); err != nil {
-		respondError(w, err)
+	if err := a.audited(r).VerifyUserEmail(token); err != nil {
+		respondError(w, r, err)
 		return
 	}
 	respondOK(w)
@@ -230,12 +295,16 @@ func (a *API) sendPasswordResetEmail(w http.ResponseWriter, r *http.Request) {
 	email = model.NormalizeEmail(email)
 
 	if len(email) == 0 || !model.IsValidEmail(email) {
-		respondError(w, model.NewAppErr("api.sendPasswordResetEmail", model.ErrInvalid, locale.GetUserLocalizer("en"), msgInvalidEmail, http.StatusBadRequest, nil))
+		respondError(w, r, model.NewAppErr("api.sendPasswordResetEmail", model.ErrInvalid, locale.GetUserLocalizer("en"), msgInvalidEmail, http.StatusBadRequest, nil))
+		return
+	}
+	if err := a.app.CheckEmailRateLimit(model.TokenTypePasswordRecovery.String(), email, a.app.RequestIP(r)); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
 	if err := a.app.SendPasswordResetEmail(email); err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 
@@ -247,8 +316,8 @@ func (a *API) resetUserPassword(w http.ResponseWriter, r *http.Request) {
 	token := props["token"]
 	newPassword := props["password"]
 
-	if err := a.app.ResetUserPassword(token, newPassword); err != nil {
-		respondError(w, err)
+	if err := a.audited(r).ResetUserPassword(token, newPassword); err != nil {
+		respondError(w, r, err)
 		return
 	}
 	respondOK(w)
@@ -257,12 +326,12 @@ func (a *API) resetUserPassword(w http.ResponseWriter, r *http.Request) {
 func (a *API) update(w http.ResponseWriter, r *http.Request) {
 	uid, e := strconv.ParseInt(chi.URLParam(r, "user_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("update", model.ErrInternal, locale.GetUserLocalizer("en"), msgUserMultiPartErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("update", model.ErrInternal, locale.GetUserLocalizer("en"), msgUserMultiPartErr, http.StatusInternalServerError, nil))
 		return
 	}
 
 	if err := r.ParseMultipartForm(model.FileUploadSizeLimit); err != nil {
-		respondError(w, model.NewAppErr("update", model.ErrInternal, locale.GetUserLocalizer("en"), msgUserAvatarMultipart, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("update", model.ErrInternal, locale.GetUserLocalizer("en"), msgUserAvatarMultipart, http.StatusInternalServerError, nil))
 		return
 	}
 
@@ -271,7 +340,7 @@ func (a *API) update(w http.ResponseWriter, r *http.Request) {
 
 	patch := &model.UserPatch{}
 	if err := model.SchemaDecoder.Decode(patch, mpf.Value); err != nil {
-		respondError(w, model.NewAppErr("update", model.ErrInternal, locale.GetUserLocalizer("en"), msgUserMultiPartErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("update", model.ErrInternal, locale.GetUserLocalizer("en"), msgUserMultiPartErr, http.StatusInternalServerError, nil))
 		return
 	}
 
@@ -280,9 +349,10 @@ func (a *API) update(w http.ResponseWriter, r *http.Request) {
 		avatar = mpf.File["avatar_url"][0]
 	}
 
-	uuser, pErr := a.app.PatchUser(uid, patch, avatar)
-	if e != nil {
-		respondError(w, pErr)
+	ad := a.app.GetAccessDataFromContext(r.Context())
+	uuser, pErr := a.audited(r).PatchUser(ad, uid, patch, avatar)
+	if pErr != nil {
+		respondError(w, r, pErr)
 		return
 	}
 
@@ -293,13 +363,13 @@ func (a *API) updateProfile(w http.ResponseWriter, r *http.Request) {
 	uid := a.app.GetUserIDFromContext(r.Context())
 	patch, err := model.UserPatchFromJSON(r.Body)
 	if err != nil {
-		respondError(w, model.NewAppErr("updateProfile", model.ErrInternal, locale.GetUserLocalizer("en"), msgUpdateProfile, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("updateProfile", model.ErrInternal, locale.GetUserLocalizer("en"), msgUpdateProfile, http.StatusInternalServerError, nil))
 		return
 	}
 
-	user, pErr := a.app.PatchUserProfile(uid, patch)
+	user, pErr := a.audited(r).PatchUserProfile(uid, patch)
 	if pErr != nil {
-		respondError(w, pErr)
+		respondError(w, r, pErr)
 		return
 	}
 	respondJSON(w, http.StatusOK, user)
@@ -313,27 +383,34 @@ func (a *API) changeUserPassword(w http.ResponseWriter, r *http.Request) {
 	confirmPassword := props["confirm_password"]
 
 	if len(oldPassword) == 0 || len(newPassword) == 0 || len(confirmPassword) == 0 || newPassword != confirmPassword {
-		respondError(w, model.NewAppErr("api.changeUserPassword", model.ErrInvalid, locale.GetUserLocalizer("en"), msgInvalidPassword, http.StatusBadRequest, nil))
+		respondError(w, r, model.NewAppErr("api.changeUserPassword", model.ErrInvalid, locale.GetUserLocalizer("en"), msgInvalidPassword, http.StatusBadRequest, nil))
 		return
 	}
 
-	if err := a.app.ChangeUserPassword(uid, oldPassword, newPassword); err != nil {
-		respondError(w, err)
+	if err := a.audited(r).ChangeUserPassword(uid, oldPassword, newPassword); err != nil {
+		respondError(w, r, err)
 		return
 	}
+	a.app.DeleteSessionCookies(w)
 	respondOK(w)
 }
 
 func (a *API) getUser(w http.ResponseWriter, r *http.Request) {
 	uid, e := strconv.ParseInt(chi.URLParam(r, "user_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("getUser", model.ErrInternal, locale.GetUserLocalizer("en"), msgUserURLParams, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("getUser", model.ErrInternal, locale.GetUserLocalizer("en"), msgUserURLParams, http.StatusInternalServerError, nil))
+		return
+	}
+
+	ad := a.app.GetAccessDataFromContext(r.Context())
+	if err := a.app.AuthorizeUser(ad, uid, model.PermissionUserRead); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
 	user, err := a.app.GetUserByID(uid)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 	respondJSON(w, http.StatusOK, user)
@@ -348,7 +425,7 @@ func (a *API) getUsers(w http.ResponseWriter, r *http.Request) {
 	pages := pagination.NewFromRequest(r)
 	users, err := a.app.GetUsers(pages.Limit(), pages.Offset())
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 
@@ -364,12 +441,18 @@ func (a *API) getUsers(w http.ResponseWriter, r *http.Request) {
 func (a *API) deleteUser(w http.ResponseWriter, r *http.Request) {
 	uid, e := strconv.ParseInt(chi.URLParam(r, "user_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("deleteUser", model.ErrInternal, locale.GetUserLocalizer("en"), msgUserURLParams, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("deleteUser", model.ErrInternal, locale.GetUserLocalizer("en"), msgUserURLParams, http.StatusInternalServerError, nil))
+		return
+	}
+
+	ad := a.app.GetAccessDataFromContext(r.Context())
+	if err := a.app.AuthorizeUser(ad, uid, model.PermissionUserWrite); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
-	if err := a.app.DeleteUser(uid); err != nil {
-		respondError(w, err)
+	if err := a.audited(r).DeleteUser(ad, uid); err != nil {
+		respondError(w, r, err)
 		return
 	}
 	respondOK(w)
@@ -378,8 +461,9 @@ func (a *API) deleteUser(w http.ResponseWriter, r *http.Request) {
 func (a *API) deleteUsers(w http.ResponseWriter, r *http.Request) {
 	ids := model.IntSliceFromJSON(r.Body)
 
-	if err := a.app.DeleteUsers(ids); err != nil {
-		respondError(w, err)
+	ad := a.app.GetAccessDataFromContext(r.Context())
+	if err := a.audited(r).DeleteUsers(ad, ids); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
@@ -389,20 +473,20 @@ func (a *API) deleteUsers(w http.ResponseWriter, r *http.Request) {
 func (a *API) uploadUserAvatar(w http.ResponseWriter, r *http.Request) {
 	uid := a.app.GetUserIDFromContext(r.Context())
 	if e := r.ParseMultipartForm(model.FileUploadSizeLimit); e != nil {
-		respondError(w, model.NewAppErr("uploadUserAvatar", model.ErrInternal, locale.GetUserLocalizer("en"), msgUserAvatarMultipart, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("uploadUserAvatar", model.ErrInternal, locale.GetUserLocalizer("en"), msgUserAvatarMultipart, http.StatusInternalServerError, nil))
 		return
 	}
 
 	f, fh, err := r.FormFile("avatar")
 	if err != nil {
-		respondError(w, model.NewAppErr("uploadUserAvatar", model.ErrInternal, locale.GetUserLocalizer("en"), msgUserAvatarMultipart, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("uploadUserAvatar", model.ErrInternal, locale.GetUserLocalizer("en"), msgUserAvatarMultipart, http.StatusInternalServerError, nil))
 		return
 	}
 	defer f.Close()
 
-	url, publicID, uErr := a.app.UploadUserAvatar(uid, f, fh)
+	url, publicID, uErr := a.audited(r).UploadUserAvatar(uid, f, fh)
 	if uErr != nil {
-		respondError(w, uErr)
+		respondError(w, r, uErr)
 		return
 	}
 
@@ -413,11 +497,11 @@ func (a *API) deleteUserAvatar(w http.ResponseWriter, r *http.Request) {
 	uid := a.app.GetUserIDFromContext(r.Context())
 	user, err := a.app.GetUserByID(uid)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 	}
 
-	if err := a.app.DeleteUserAvatar(uid, *user.AvatarPublicID); err != nil {
-		respondError(w, err)
+	if err := a.audited(r).DeleteUserAvatar(uid, *user.AvatarPublicID); err != nil {
+		respondError(w, r, err)
 		return
 	}
 	respondOK(w)
@@ -427,13 +511,13 @@ func (a *API) createUserAddress(w http.ResponseWriter, r *http.Request) {
 	uid := a.app.GetUserIDFromContext(r.Context())
 	addr, e := model.AddressFromJSON(r.Body)
 	if e != nil {
-		respondError(w, model.NewAppErr("createUserAddress", model.ErrInternal, locale.GetUserLocalizer("en"), msgAddressFromJSON, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("createUserAddress", model.ErrInternal, locale.GetUserLocalizer("en"), msgAddressFromJSON, http.StatusInternalServerError, nil))
 		return
 	}
 
-	address, err := a.app.CreateUserAddress(addr, uid)
+	address, err := a.audited(r).CreateUserAddress(addr, uid)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 	respondJSON(w, http.StatusCreated, address)
@@ -443,13 +527,13 @@ func (a *API) getUserAddress(w http.ResponseWriter, r *http.Request) {
 	uid := a.app.GetUserIDFromContext(r.Context())
 	addrID, e := strconv.ParseInt(chi.URLParam(r, "address_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("getUserAddress", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("getUserAddress", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 
 	address, err := a.app.GetUserAddress(uid, addrID)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 	respondJSON(w, http.StatusOK, address)
@@ -459,7 +543,7 @@ func (a *API) getUserAddresses(w http.ResponseWriter, r *http.Request) {
 	uid := a.app.GetUserIDFromContext(r.Context())
 	address, err := a.app.GetUserAddresses(uid)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 	respondJSON(w, http.StatusOK, address)
@@ -469,55 +553,56 @@ func (a *API) updateUserAddress(w http.ResponseWriter, r *http.Request) {
 	uid := a.app.GetUserIDFromContext(r.Context())
 	addrID, e := strconv.ParseInt(chi.URLParam(r, "address_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("updateUserAddress", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("updateUserAddress", model.ErrInternal, locale.GetUserLocalizer("en"), msgURLParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 
 	patch, err := model.AddressPatchFromJSON(r.Body)
 	if err != nil {
-		respondError(w, model.NewAppErr("updateUserAddress", model.ErrInternal, locale.GetUserLocalizer("en"), msgAddressPatchFromJSON, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("updateUserAddress", model.ErrInternal, locale.GetUserLocalizer("en"), msgAddressPatchFromJSON, http.StatusInternalServerError, nil))
 		return
 	}
 
-	address, pErr := a.app.PatchUserAddress(uid, addrID, patch)
+	address, pErr := a.audited(r).PatchUserAddress(uid, addrID, patch)
 	if pErr != nil {
-		respondError(w, pErr)
+		respondError(w, r, pErr)
 		return
 	}
 	respondJSON(w, http.StatusOK, address)
 }
 
 func (a *API) deleteUserAddress(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
 	addrID, e := strconv.ParseInt(chi.URLParam(r, "address_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("deleteUserAddress", model.ErrInternal, locale.GetUserLocalizer("en"), msgDeleteUserAddress, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("deleteUserAddress", model.ErrInternal, locale.GetUserLocalizer("en"), msgDeleteUserAddress, http.StatusInternalServerError, nil))
 		return
 	}
 
-	if err := a.app.DeleteUserAddress(addrID); err != nil {
-		respondError(w, err)
+	if err := a.audited(r).DeleteUserAddress(uid, addrID); err != nil {
+		respondError(w, r, err)
 		return
 	}
 	respondOK(w)
 }
 
 func (a *API) getUserOrders(w http.ResponseWriter, r *http.Request) {
-	uid := a.app.GetUserIDFromContext(r.Context())
 	userID, e := strconv.ParseInt(chi.URLParam(r, "user_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("getUserOrders", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetUserOrders, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("getUserOrders", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetUserOrders, http.StatusInternalServerError, nil))
 		return
 	}
 
-	if uid != userID {
-		respondError(w, model.NewAppErr("getUserOrders", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetUserOrders, http.StatusInternalServerError, nil))
+	ad := a.app.GetAccessDataFromContext(r.Context())
+	if err := a.app.AuthorizeUser(ad, userID, model.PermissionOrderRead); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
 	pages := pagination.NewFromRequest(r)
 	orders, err := a.app.GetOrdersForUser(userID, pages.Limit(), pages.Offset())
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 
@@ -535,14 +620,14 @@ func (a *API) createWishlist(w http.ResponseWriter, r *http.Request) {
 	props := model.MapStrInterfaceFromJSON(r.Body)
 	productID, ok := props["product_id"].(float64)
 	if !ok {
-		respondError(w, model.NewAppErr("getUserOrders", model.ErrInternal, locale.GetUserLocalizer("en"), msgWishlistParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("getUserOrders", model.ErrInternal, locale.GetUserLocalizer("en"), msgWishlistParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 	pid := int64(productID)
 
-	err := a.app.CreateWishlistForUser(uid, pid)
+	err := a.audited(r).CreateWishlistForUser(uid, pid)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 
@@ -553,7 +638,7 @@ func (a *API) getWishlist(w http.ResponseWriter, r *http.Request) {
 	uid := a.app.GetUserIDFromContext(r.Context())
 	wishlist, err := a.app.GetWishlistForUser(uid)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 
@@ -564,13 +649,13 @@ func (a *API) deleteWishlist(w http.ResponseWriter, r *http.Request) {
 	uid := a.app.GetUserIDFromContext(r.Context())
 	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
 	if e != nil {
-		respondError(w, model.NewAppErr("deleteWishlist", model.ErrInternal, locale.GetUserLocalizer("en"), msgWishlistParamErr, http.StatusInternalServerError, nil))
+		respondError(w, r, model.NewAppErr("deleteWishlist", model.ErrInternal, locale.GetUserLocalizer("en"), msgWishlistParamErr, http.StatusInternalServerError, nil))
 		return
 	}
 
-	err := a.app.DeleteWishlistForUser(uid, pid)
+	err := a.audited(r).DeleteWishlistForUser(uid, pid)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
 		return
 	}
 
@@ -579,9 +664,31 @@ func (a *API) deleteWishlist(w http.ResponseWriter, r *http.Request) {
 
 func (a *API) clearWishlist(w http.ResponseWriter, r *http.Request) {
 	uid := a.app.GetUserIDFromContext(r.Context())
-	err := a.app.ClearWishlistForUser(uid)
+	err := a.audited(r).ClearWishlistForUser(uid)
 	if err != nil {
-		respondError(w, err)
+		respondError(w, r, err)
+		return
+	}
+
+	respondOK(w)
+}
+
+func (a *API) updateWishlistAlerts(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
+	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("updateWishlistAlerts", model.ErrInternal, locale.GetUserLocalizer("en"), msgWishlistParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	sub, e := model.WishlistAlertSubscriptionFromJSON(r.Body)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("updateWishlistAlerts", model.ErrInternal, locale.GetUserLocalizer("en"), msgWishlistAlertsFromJSON, http.StatusInternalServerError, nil))
+		return
+	}
+
+	if err := a.audited(r).UpdateWishlistAlertsForUser(uid, pid, sub); err != nil {
+		respondError(w, r, err)
 		return
 	}
 
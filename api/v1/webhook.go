@@ -0,0 +1,177 @@
+package apiv1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/dankobgd/ecommerce-shop/utils/pagination"
+	"github.com/go-chi/chi"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgWebhookURLParamErr      = &i18n.Message{ID: "api.webhook.url.params.app_error", Other: "invalid webhook url param"}
+	msgWebhookFromJSON         = &i18n.Message{ID: "api.webhook.create_webhook.json.app_error", Other: "could not decode webhook json data"}
+	msgWebhookPatchFromJSONErr = &i18n.Message{ID: "api.webhook.patch_webhook.json.app_error", Other: "could not decode webhook patch json data"}
+	msgDeliveryURLParamErr     = &i18n.Message{ID: "api.webhook.delivery.url.params.app_error", Other: "invalid webhook delivery url param"}
+)
+
+// InitWebhooks inits the webhook routes
+func InitWebhooks(a *API) {
+	a.Routes.Webhooks.Get("/", a.RequirePermission(model.PermissionWebhookManage, a.getWebhooks))
+	a.Routes.Webhooks.Post("/", a.RequirePermission(model.PermissionWebhookManage, a.createWebhook))
+	a.Routes.Webhook.Get("/", a.RequirePermission(model.PermissionWebhookManage, a.getWebhook))
+	a.Routes.Webhook.Patch("/", a.RequirePermission(model.PermissionWebhookManage, a.patchWebhook))
+	a.Routes.Webhook.Delete("/", a.RequirePermission(model.PermissionWebhookManage, a.deleteWebhook))
+	a.Routes.Webhook.Get("/deliveries", a.RequirePermission(model.PermissionWebhookManage, a.getWebhookDeliveries))
+	a.Routes.Webhook.Get("/deliveries/{delivery_id:[A-Za-z0-9]+}", a.RequirePermission(model.PermissionWebhookManage, a.getWebhookDelivery))
+	a.Routes.Webhook.Post("/deliveries/{delivery_id:[A-Za-z0-9]+}/replay", a.RequirePermission(model.PermissionWebhookManage, a.replayWebhookDelivery))
+}
+
+func (a *API) createWebhook(w http.ResponseWriter, r *http.Request) {
+	wh, e := model.WebhookFromJSON(r.Body)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("createWebhook", model.ErrInternal, locale.GetUserLocalizer("en"), msgWebhookFromJSON, http.StatusInternalServerError, nil))
+		return
+	}
+
+	webhook, err := a.audited(r).CreateWebhook(wh)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, &model.WebhookWithSecret{Webhook: webhook, Secret: webhook.Secret})
+}
+
+func (a *API) getWebhooks(w http.ResponseWriter, r *http.Request) {
+	pages := pagination.NewFromRequest(r)
+	webhooks, err := a.app.GetWebhooks(pages.Limit(), pages.Offset())
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	totalCount := -1
+	if len(webhooks) > 0 {
+		totalCount = webhooks[0].TotalCount
+	}
+	pages.SetData(webhooks, totalCount)
+
+	respondJSON(w, http.StatusOK, pages)
+}
+
+func (a *API) getWebhook(w http.ResponseWriter, r *http.Request) {
+	id, e := strconv.ParseInt(chi.URLParam(r, "webhook_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("getWebhook", model.ErrInternal, locale.GetUserLocalizer("en"), msgWebhookURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	webhook, err := a.app.GetWebhook(id)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, webhook)
+}
+
+func (a *API) patchWebhook(w http.ResponseWriter, r *http.Request) {
+	id, e := strconv.ParseInt(chi.URLParam(r, "webhook_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("patchWebhook", model.ErrInternal, locale.GetUserLocalizer("en"), msgWebhookURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	patch, e := model.WebhookPatchFromJSON(r.Body)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("patchWebhook", model.ErrInternal, locale.GetUserLocalizer("en"), msgWebhookPatchFromJSONErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	webhook, err := a.audited(r).PatchWebhook(id, patch)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, webhook)
+}
+
+func (a *API) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, e := strconv.ParseInt(chi.URLParam(r, "webhook_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("deleteWebhook", model.ErrInternal, locale.GetUserLocalizer("en"), msgWebhookURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	if err := a.audited(r).DeleteWebhook(id); err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondOK(w)
+}
+
+func (a *API) getWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, e := strconv.ParseInt(chi.URLParam(r, "webhook_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("getWebhookDeliveries", model.ErrInternal, locale.GetUserLocalizer("en"), msgWebhookURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+	status := r.URL.Query().Get("status")
+
+	pages := pagination.NewFromRequest(r)
+	deliveries, err := a.app.GetWebhookDeliveries(id, status, pages.Limit(), pages.Offset())
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	totalCount := -1
+	if len(deliveries) > 0 {
+		totalCount = deliveries[0].TotalCount
+	}
+	pages.SetData(deliveries, totalCount)
+
+	respondJSON(w, http.StatusOK, pages)
+}
+
+func (a *API) getWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	id, e := strconv.ParseInt(chi.URLParam(r, "webhook_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("getWebhookDelivery", model.ErrInternal, locale.GetUserLocalizer("en"), msgWebhookURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+	did, e := strconv.ParseInt(chi.URLParam(r, "delivery_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("getWebhookDelivery", model.ErrInternal, locale.GetUserLocalizer("en"), msgDeliveryURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	delivery, err := a.app.GetWebhookDelivery(id, did)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, delivery)
+}
+
+func (a *API) replayWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	id, e := strconv.ParseInt(chi.URLParam(r, "webhook_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("replayWebhookDelivery", model.ErrInternal, locale.GetUserLocalizer("en"), msgWebhookURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+	did, e := strconv.ParseInt(chi.URLParam(r, "delivery_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("replayWebhookDelivery", model.ErrInternal, locale.GetUserLocalizer("en"), msgDeliveryURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	delivery, err := a.audited(r).ReplayWebhookDelivery(id, did)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, delivery)
+}
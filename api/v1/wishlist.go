@@ -0,0 +1,281 @@
+package apiv1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/go-chi/chi"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgWishlistFromJSON       = &i18n.Message{ID: "api.wishlist.create_wishlist.app_error", Other: "could not parse wishlist from json"}
+	msgWishlistPatchFromJSON  = &i18n.Message{ID: "api.wishlist.patch_wishlist.app_error", Other: "could not parse wishlist patch data"}
+	msgWishlistItemFromJSON   = &i18n.Message{ID: "api.wishlist.add_item.app_error", Other: "could not parse wishlist item from json"}
+	msgWishlistItemPatchJSON  = &i18n.Message{ID: "api.wishlist.patch_item.app_error", Other: "could not parse wishlist item patch data"}
+	msgWishlistURLParamErr    = &i18n.Message{ID: "api.wishlist.url.params.app_error", Other: "invalid wishlist url param"}
+	msgWishlistProductIDParam = &i18n.Message{ID: "api.wishlist.product_id.url.params.app_error", Other: "invalid wishlist product_id url param"}
+)
+
+// InitWishlists inits the named wishlist routes
+func InitWishlists(a *API) {
+	a.Routes.Wishlists.Get("/", a.SessionRequired(a.getWishlists))
+	a.Routes.Wishlists.Post("/", a.SessionRequired(a.createNamedWishlist))
+	a.Routes.Wishlist.Get("/", a.SessionRequired(a.getNamedWishlist))
+	a.Routes.Wishlist.Patch("/", a.SessionRequired(a.patchNamedWishlist))
+	a.Routes.Wishlist.Delete("/", a.SessionRequired(a.deleteNamedWishlist))
+	a.Routes.Wishlist.Post("/share", a.SessionRequired(a.shareWishlist))
+	a.Routes.Wishlist.Delete("/share", a.SessionRequired(a.unshareWishlist))
+	a.Routes.Wishlist.Post("/items", a.SessionRequired(a.addWishlistItem))
+	a.Routes.Wishlist.Delete("/items", a.SessionRequired(a.clearWishlistItems))
+	a.Routes.Wishlist.Patch("/items/{product_id:[A-Za-z0-9]+}", a.SessionRequired(a.patchWishlistItem))
+	a.Routes.Wishlist.Delete("/items/{product_id:[A-Za-z0-9]+}", a.SessionRequired(a.deleteWishlistItem))
+	a.Routes.Wishlist.Put("/items/{product_id:[A-Za-z0-9]+}/alerts", a.SessionRequired(a.updateWishlistItemAlerts))
+
+	a.Routes.API.Get("/wishlists/shared/{share_token:[A-Za-z0-9_-]+}", a.getSharedWishlist)
+}
+
+func (a *API) getWishlists(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
+	wishlists, err := a.app.GetWishlists(uid)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, wishlists)
+}
+
+func (a *API) createNamedWishlist(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
+	wl, e := model.WishlistFromJSON(r.Body)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("createNamedWishlist", model.ErrInternal, locale.GetUserLocalizer("en"), msgWishlistFromJSON, http.StatusInternalServerError, nil))
+		return
+	}
+
+	wishlist, err := a.audited(r).CreateWishlist(uid, wl)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, wishlist)
+}
+
+func (a *API) getNamedWishlist(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
+	wid, e := strconv.ParseInt(chi.URLParam(r, "wishlist_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("getNamedWishlist", model.ErrInternal, locale.GetUserLocalizer("en"), msgWishlistURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	wishlist, err := a.app.GetWishlist(uid, wid)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, wishlist)
+}
+
+func (a *API) patchNamedWishlist(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
+	wid, e := strconv.ParseInt(chi.URLParam(r, "wishlist_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("patchNamedWishlist", model.ErrInternal, locale.GetUserLocalizer("en"), msgWishlistURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	patch, e := model.WishlistPatchFromJSON(r.Body)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("patchNamedWishlist", model.ErrInternal, locale.GetUserLocalizer("en"), msgWishlistPatchFromJSON, http.StatusInternalServerError, nil))
+		return
+	}
+
+	wishlist, err := a.audited(r).PatchWishlist(uid, wid, patch)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, wishlist)
+}
+
+func (a *API) deleteNamedWishlist(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
+	wid, e := strconv.ParseInt(chi.URLParam(r, "wishlist_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("deleteNamedWishlist", model.ErrInternal, locale.GetUserLocalizer("en"), msgWishlistURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	if err := a.audited(r).DeleteWishlist(uid, wid); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondOK(w)
+}
+
+func (a *API) shareWishlist(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
+	wid, e := strconv.ParseInt(chi.URLParam(r, "wishlist_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("shareWishlist", model.ErrInternal, locale.GetUserLocalizer("en"), msgWishlistURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	wishlist, err := a.audited(r).ShareWishlist(uid, wid)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"share_token": wishlist.ShareToken,
+		"share_url":   a.app.WishlistShareURL(*wishlist.ShareToken),
+	})
+}
+
+func (a *API) unshareWishlist(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
+	wid, e := strconv.ParseInt(chi.URLParam(r, "wishlist_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("unshareWishlist", model.ErrInternal, locale.GetUserLocalizer("en"), msgWishlistURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	if err := a.audited(r).UnshareWishlist(uid, wid); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondOK(w)
+}
+
+func (a *API) getSharedWishlist(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "share_token")
+	wishlist, err := a.app.GetSharedWishlist(token)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, wishlist)
+}
+
+func (a *API) addWishlistItem(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
+	wid, e := strconv.ParseInt(chi.URLParam(r, "wishlist_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("addWishlistItem", model.ErrInternal, locale.GetUserLocalizer("en"), msgWishlistURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	item, e := model.WishlistItemFromJSON(r.Body)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("addWishlistItem", model.ErrInternal, locale.GetUserLocalizer("en"), msgWishlistItemFromJSON, http.StatusInternalServerError, nil))
+		return
+	}
+
+	witem, err := a.audited(r).AddWishlistItem(uid, wid, item)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, witem)
+}
+
+func (a *API) patchWishlistItem(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
+	wid, pid, err := parseWishlistItemURLParams(r, "patchWishlistItem")
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	patch, e := model.WishlistItemPatchFromJSON(r.Body)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("patchWishlistItem", model.ErrInternal, locale.GetUserLocalizer("en"), msgWishlistItemPatchJSON, http.StatusInternalServerError, nil))
+		return
+	}
+
+	item, err := a.audited(r).PatchWishlistItem(uid, wid, pid, patch)
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, item)
+}
+
+func (a *API) deleteWishlistItem(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
+	wid, pid, err := parseWishlistItemURLParams(r, "deleteWishlistItem")
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	if err := a.audited(r).DeleteWishlistItem(uid, wid, pid); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondOK(w)
+}
+
+func (a *API) clearWishlistItems(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
+	wid, e := strconv.ParseInt(chi.URLParam(r, "wishlist_id"), 10, 64)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("clearWishlistItems", model.ErrInternal, locale.GetUserLocalizer("en"), msgWishlistURLParamErr, http.StatusInternalServerError, nil))
+		return
+	}
+
+	if err := a.audited(r).ClearWishlistItems(uid, wid); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondOK(w)
+}
+
+func (a *API) updateWishlistItemAlerts(w http.ResponseWriter, r *http.Request) {
+	uid := a.app.GetUserIDFromContext(r.Context())
+	wid, pid, err := parseWishlistItemURLParams(r, "updateWishlistItemAlerts")
+	if err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	sub, e := model.WishlistAlertSubscriptionFromJSON(r.Body)
+	if e != nil {
+		respondError(w, r, model.NewAppErr("updateWishlistItemAlerts", model.ErrInternal, locale.GetUserLocalizer("en"), msgWishlistAlertsFromJSON, http.StatusInternalServerError, nil))
+		return
+	}
+
+	if err := a.audited(r).UpdateWishlistItemAlerts(uid, wid, pid, sub); err != nil {
+		respondError(w, r, err)
+		return
+	}
+
+	respondOK(w)
+}
+
+func parseWishlistItemURLParams(r *http.Request, where string) (int64, int64, *model.AppErr) {
+	wid, e := strconv.ParseInt(chi.URLParam(r, "wishlist_id"), 10, 64)
+	if e != nil {
+		return 0, 0, model.NewAppErr(where, model.ErrInternal, locale.GetUserLocalizer("en"), msgWishlistURLParamErr, http.StatusInternalServerError, nil)
+	}
+	pid, e := strconv.ParseInt(chi.URLParam(r, "product_id"), 10, 64)
+	if e != nil {
+		return 0, 0, model.NewAppErr(where, model.ErrInternal, locale.GetUserLocalizer("en"), msgWishlistProductIDParam, http.StatusInternalServerError, nil)
+	}
+	return wid, pid, nil
+}
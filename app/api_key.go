@@ -0,0 +1,138 @@
+package app
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/go-chi/chi/middleware"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgAPIKeyNotFound = &i18n.Message{ID: "app.api_key.not_found.app_error", Other: "api key not found"}
+	msgAPIKeyInvalid  = &i18n.Message{ID: "app.api_key.invalid.app_error", Other: "invalid, expired or revoked api key"}
+)
+
+// CreateAPIKey creates the new api key, the plain key is returned only this once
+func (a *App) CreateAPIKey(createdBy int64, k *model.APIKey) (*model.APIKey, *model.AppErr) {
+	k.CreatedBy = createdBy
+	k.PreSave()
+	if err := k.Validate(); err != nil {
+		return nil, err
+	}
+	created, err := a.Srv().Store.APIKey().Save(k)
+	if err != nil {
+		return nil, err
+	}
+	a.audit(model.AuditActionCreate, model.AuditResourceAPIKey, created.ID, nil, created)
+	return created, nil
+}
+
+// GetAPIKeys gets all api keys
+func (a *App) GetAPIKeys(limit, offset int) ([]*model.APIKey, *model.AppErr) {
+	return a.Srv().Store.APIKey().GetAll(limit, offset)
+}
+
+// GetAPIKey gets the api key by id
+func (a *App) GetAPIKey(id int64) (*model.APIKey, *model.AppErr) {
+	k, err := a.Srv().Store.APIKey().Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if k == nil {
+		return nil, model.NewAppErr("GetAPIKey", model.ErrNotFound, locale.GetUserLocalizer("en"), msgAPIKeyNotFound, http.StatusNotFound, nil)
+	}
+	return k, nil
+}
+
+// RevokeAPIKey revokes the api key, the key is looked up on every request so it stops working immediately.
+// Revoking the missing key is not found and revoking the key again changes nothing, so neither is audited
+func (a *App) RevokeAPIKey(id int64) *model.AppErr {
+	old, err := a.GetAPIKey(id)
+	if err != nil {
+		return err
+	}
+	if old.RevokedAt != nil {
+		return nil
+	}
+	if err := a.Srv().Store.APIKey().Revoke(id); err != nil {
+		return err
+	}
+	revoked, err := a.GetAPIKey(id)
+	if err != nil {
+		return err
+	}
+	a.audit(model.AuditActionUpdate, model.AuditResourceAPIKey, id, old, revoked)
+	return nil
+}
+
+// GetAPIKeyUsage gets the requests made with the api key
+func (a *App) GetAPIKeyUsage(id int64, limit, offset int) ([]*model.APIKeyUsage, *model.AppErr) {
+	if _, err := a.GetAPIKey(id); err != nil {
+		return nil, err
+	}
+	return a.Srv().Store.APIKey().GetUsage(id, limit, offset)
+}
+
+// AuthenticateAPIKey checks the api key and returns the access it grants
+func (a *App) AuthenticateAPIKey(key string) (*model.AccessData, *model.AppErr) {
+	if !model.IsAPIKey(key) {
+		return nil, model.NewAppErr("AuthenticateAPIKey", model.ErrUnauthenticated, locale.GetUserLocalizer("en"), msgAPIKeyInvalid, http.StatusUnauthorized, nil)
+	}
+	k, err := a.Srv().Store.APIKey().GetByHash(model.HashAPIKey(key))
+	if err != nil {
+		return nil, err
+	}
+	if k == nil || !k.IsActive(time.Now()) {
+		return nil, model.NewAppErr("AuthenticateAPIKey", model.ErrUnauthenticated, locale.GetUserLocalizer("en"), msgAPIKeyInvalid, http.StatusUnauthorized, nil)
+	}
+
+	// the key never grants more than its creator has now, so it stops working when the creator is deleted or demoted
+	creator, err := a.Srv().Store.User().Get(k.CreatedBy)
+	if err != nil && err.StatusCode != http.StatusNotFound {
+		return nil, err
+	}
+	ad := k.AccessData()
+	if creator == nil {
+		ad.Permissions = nil
+	} else if creator.Role != model.AdminRole {
+		permissions, err := a.GetUserPermissions(creator)
+		if err != nil {
+			return nil, err
+		}
+		ad.Permissions = intersectPermissions(ad.Permissions, permissions)
+	}
+	if len(ad.Permissions) == 0 {
+		return nil, model.NewAppErr("AuthenticateAPIKey", model.ErrUnauthenticated, locale.GetUserLocalizer("en"), msgAPIKeyInvalid, http.StatusUnauthorized, nil)
+	}
+	return ad, nil
+}
+
+// intersectPermissions keeps the scopes that are still among the permissions
+func intersectPermissions(scopes, permissions []string) []string {
+	granted := make(map[string]bool, len(permissions))
+	for _, p := range permissions {
+		granted[p] = true
+	}
+	kept := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		if granted[s] {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
+
+// RecordAPIKeyUsage records the request made with the api key in the key's audit trail
+func (a *App) RecordAPIKeyUsage(ad *model.AccessData, r *http.Request) *model.AppErr {
+	return a.Srv().Store.APIKey().RecordUsage(&model.APIKeyUsage{
+		APIKeyID:  ad.APIKeyID,
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		IP:        a.RequestIP(r),
+		RequestID: middleware.GetReqID(r.Context()),
+		CreatedAt: time.Now(),
+	})
+}
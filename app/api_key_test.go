@@ -0,0 +1,189 @@
+package app
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/config"
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/store"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/dankobgd/ecommerce-shop/zlog"
+)
+
+const testAPIKey = "esk_0123456789abcdef"
+
+// apiKeyTestStore keeps the api keys, their creators and the audit log in memory
+type apiKeyTestStore struct {
+	store.Store
+	keys        map[int64]*model.APIKey
+	users       map[int64]*model.User
+	permissions map[int64][]string
+	audit       []*model.AuditEntry
+}
+
+func (s *apiKeyTestStore) APIKey() store.APIKeyStore { return apiKeyTestKeys{s: s} }
+func (s *apiKeyTestStore) User() store.UserStore     { return apiKeyTestUsers{s: s} }
+func (s *apiKeyTestStore) Role() store.RoleStore     { return apiKeyTestRoles{s: s} }
+func (s *apiKeyTestStore) Audit() store.AuditStore   { return apiKeyTestAudit{s: s} }
+
+type apiKeyTestKeys struct {
+	store.APIKeyStore
+	s *apiKeyTestStore
+}
+
+func (ks apiKeyTestKeys) Get(id int64) (*model.APIKey, *model.AppErr) {
+	if k, ok := ks.s.keys[id]; ok {
+		c := *k
+		return &c, nil
+	}
+	return nil, nil
+}
+
+func (ks apiKeyTestKeys) GetByHash(hash string) (*model.APIKey, *model.AppErr) {
+	for _, k := range ks.s.keys {
+		if k.KeyHash == hash {
+			c := *k
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (ks apiKeyTestKeys) Revoke(id int64) *model.AppErr {
+	now := time.Now()
+	ks.s.keys[id].RevokedAt = &now
+	return nil
+}
+
+type apiKeyTestUsers struct {
+	store.UserStore
+	s *apiKeyTestStore
+}
+
+func (us apiKeyTestUsers) Get(id int64) (*model.User, *model.AppErr) {
+	if u, ok := us.s.users[id]; ok {
+		return u, nil
+	}
+	return nil, model.NewAppErr("apiKeyTestUsers.Get", model.ErrNotFound, locale.GetUserLocalizer("en"), msgAPIKeyNotFound, http.StatusNotFound, nil)
+}
+
+type apiKeyTestRoles struct {
+	store.RoleStore
+	s *apiKeyTestStore
+}
+
+func (rs apiKeyTestRoles) GetUserPermissions(uid int64) ([]string, *model.AppErr) {
+	return rs.s.permissions[uid], nil
+}
+
+type apiKeyTestAudit struct {
+	store.AuditStore
+	s *apiKeyTestStore
+}
+
+func (as apiKeyTestAudit) Save(e *model.AuditEntry) (*model.AuditEntry, *model.AppErr) {
+	as.s.audit = append(as.s.audit, e)
+	return e, nil
+}
+
+func newAPIKeyTestApp(t *testing.T) (*App, *apiKeyTestStore) {
+	t.Helper()
+
+	st := &apiKeyTestStore{
+		keys: map[int64]*model.APIKey{
+			1: {ID: 1, KeyHash: model.HashAPIKey(testAPIKey), Scopes: []string{model.PermissionOrderRead, model.PermissionProductWrite}, CreatedBy: 10},
+		},
+		users:       map[int64]*model.User{10: {ID: 10, Role: model.AdminRole}},
+		permissions: make(map[int64][]string),
+	}
+	srv, err := NewServer(st)
+	if err != nil {
+		t.Fatalf("could not create the server: %v", err)
+	}
+	return New(SetConfig(&config.Config{}), SetLogger(zlog.NewLogger(&zlog.LoggerConfig{})), SetServer(srv)), st
+}
+
+func TestAuthenticateAPIKeyChecksTheCreator(t *testing.T) {
+	tests := []struct {
+		name    string
+		creator *model.User
+		perms   []string
+		want    []string
+	}{
+		{name: "admin creator", creator: &model.User{ID: 10, Role: model.AdminRole}, want: []string{model.PermissionOrderRead, model.PermissionProductWrite}},
+		{name: "creator demoted to staff", creator: &model.User{ID: 10, Role: model.UserRole}, perms: []string{model.PermissionOrderRead}, want: []string{model.PermissionOrderRead}},
+		{name: "creator demoted to user", creator: &model.User{ID: 10, Role: model.UserRole}},
+		{name: "creator deleted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, st := newAPIKeyTestApp(t)
+			delete(st.users, 10)
+			if tt.creator != nil {
+				st.users[10] = tt.creator
+			}
+			st.permissions[10] = tt.perms
+
+			ad, err := a.AuthenticateAPIKey(testAPIKey)
+			if tt.want == nil {
+				if err == nil || err.StatusCode != http.StatusUnauthorized {
+					t.Fatalf("AuthenticateAPIKey() = %v, %v, want the invalid key error", ad, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AuthenticateAPIKey: %v", err)
+			}
+			if len(ad.Permissions) != len(tt.want) {
+				t.Fatalf("the key grants %v, want %v", ad.Permissions, tt.want)
+			}
+			for i := range tt.want {
+				if ad.Permissions[i] != tt.want[i] {
+					t.Errorf("the key grants %v, want %v", ad.Permissions, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRevokeAPIKeyAudit(t *testing.T) {
+	a, st := newAPIKeyTestApp(t)
+	audited := a.WithActor(&model.AuditActor{UserID: 10})
+
+	if err := audited.RevokeAPIKey(99); err == nil || err.StatusCode != http.StatusNotFound {
+		t.Fatalf("RevokeAPIKey() of the missing key = %v, want not found", err)
+	}
+	if len(st.audit) != 0 {
+		t.Fatal("revoking the missing key was audited")
+	}
+
+	if err := audited.RevokeAPIKey(1); err != nil {
+		t.Fatalf("RevokeAPIKey: %v", err)
+	}
+	if err := audited.RevokeAPIKey(1); err != nil {
+		t.Fatalf("RevokeAPIKey() again: %v", err)
+	}
+	if len(st.audit) != 1 {
+		t.Errorf("%d audit entries, want 1 for the revocation", len(st.audit))
+	}
+	if _, err := a.AuthenticateAPIKey(testAPIKey); err == nil {
+		t.Error("the revoked key still works")
+	}
+}
+
+func TestChangeWithoutActorIsAuditedAsSystem(t *testing.T) {
+	a, st := newAPIKeyTestApp(t)
+
+	if err := a.RevokeAPIKey(1); err != nil {
+		t.Fatalf("RevokeAPIKey: %v", err)
+	}
+	if len(st.audit) != 1 {
+		t.Fatalf("%d audit entries, want 1 for the revocation", len(st.audit))
+	}
+	if e := st.audit[0]; e.ActorID != nil || e.RequestID != model.SystemAuditActor.RequestID {
+		t.Errorf("the entry actor is %v with the request %q, want the system", e.ActorID, e.RequestID)
+	}
+}
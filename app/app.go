@@ -2,6 +2,9 @@ package app
 
 import (
 	"github.com/dankobgd/ecommerce-shop/config"
+	"github.com/dankobgd/ecommerce-shop/mailer"
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/oauth"
 	"github.com/dankobgd/ecommerce-shop/payment"
 	"github.com/dankobgd/ecommerce-shop/zlog"
 )
@@ -12,6 +15,9 @@ type App struct {
 	cfg             *config.Config
 	log             *zlog.Logger
 	paymentProvider payment.Provider
+	mailer          mailer.Transport
+	oauthProviders  map[string]oauth.Provider
+	actor           *model.AuditActor
 }
 
 // Option for the app
@@ -98,6 +104,40 @@ func SetPaymentProvider(provider payment.Provider) Option {
 	}
 }
 
+// Mailer retrieves the app email transport
+func (a *App) Mailer() mailer.Transport {
+	return a.mailer
+}
+
+// SetMailer option for the app
+func SetMailer(transport mailer.Transport) Option {
+	return func(a *App) error {
+		a.mailer = transport
+		return nil
+	}
+}
+
+// OAuthProvider retrieves the configured oauth provider by its name
+func (a *App) OAuthProvider(name string) (oauth.Provider, bool) {
+	p, ok := a.oauthProviders[name]
+	return p, ok
+}
+
+// SetOAuthProviders option for the app
+func SetOAuthProviders(providers map[string]oauth.Provider) Option {
+	return func(a *App) error {
+		a.oauthProviders = providers
+		return nil
+	}
+}
+
+// WithActor returns the copy of the app whose changes are recorded in the audit log as made by the actor
+func (a *App) WithActor(actor *model.AuditActor) *App {
+	c := *a
+	c.actor = actor
+	return &c
+}
+
 // SetConfig option for the app
 func SetConfig(cfg *config.Config) Option {
 	return func(a *App) error {
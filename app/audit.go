@@ -0,0 +1,147 @@
+package app
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/dankobgd/ecommerce-shop/zlog"
+	"github.com/go-chi/chi/middleware"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgExportAuditLog = &i18n.Message{ID: "app.audit.export.app_error", Other: "could not export audit log"}
+)
+
+var auditCSVHeader = []string{"id", "created_at", "actor_id", "api_key_id", "action", "resource_type", "resource_id", "ip", "request_id", "diff"}
+
+// AuditActorFromRequest returns the actor making the request, the user is not set for the public routes
+func (a *App) AuditActorFromRequest(r *http.Request) *model.AuditActor {
+	actor := &model.AuditActor{
+		IP:        a.RequestIP(r),
+		RequestID: middleware.GetReqID(r.Context()),
+	}
+	if ad, ok := r.Context().Value(AccessDataCtxKey).(*model.AccessData); ok && ad != nil {
+		actor.UserID = ad.UserID
+		actor.APIKeyID = ad.APIKeyID
+	}
+	return actor
+}
+
+// audit records the change made by the app's actor, the changes made without the actor (jobs, system tasks)
+// are recorded as made by the system. The entry is saved after the change is committed, so the failed recording
+// is logged instead of failing the change that already went through
+func (a *App) audit(action, resourceType string, resourceID interface{}, before, after interface{}) {
+	actor := a.actor
+	if actor == nil {
+		actor = model.SystemAuditActor
+	}
+
+	diff, err := model.AuditDiff(before, after)
+	if err != nil {
+		a.Log().Error(err.Error(), zlog.Err(err), zlog.String("audit_action", action), zlog.String("audit_resource_type", resourceType))
+		return
+	}
+
+	e := model.NewAuditEntry(actor, action, resourceType, fmt.Sprint(resourceID), diff)
+	if _, err := a.Srv().Store.Audit().Save(e); err != nil {
+		a.Log().Error(err.Error(), zlog.Err(err), zlog.String("audit_action", action), zlog.String("audit_resource_type", resourceType))
+	}
+}
+
+// auditID joins the ids of the nested resource, e.g. the product id and the image id
+func auditID(ids ...int64) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.FormatInt(id, 10))
+	}
+	return strings.Join(parts, "/")
+}
+
+// auditSaveAction is the action of the upsert, depending on whether the resource existed before
+func auditSaveAction(existed bool) string {
+	if existed {
+		return model.AuditActionUpdate
+	}
+	return model.AuditActionCreate
+}
+
+// auditSnapshots gets the resources before they are bulk changed
+func (a *App) auditSnapshots(ids []int, get func(id int64) (interface{}, *model.AppErr)) map[int]interface{} {
+	snapshots := make(map[int]interface{})
+	for _, id := range ids {
+		if v, err := get(int64(id)); err == nil {
+			snapshots[id] = v
+		}
+	}
+	return snapshots
+}
+
+// auditSnapshot gets the resource before it's changed, the change is recorded without it if it can't be fetched
+func (a *App) auditSnapshot(get func() (interface{}, *model.AppErr)) interface{} {
+	v, err := get()
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+// GetAuditLog gets the filtered audit log
+func (a *App) GetAuditLog(f *model.AuditFilter, limit, offset int) ([]*model.AuditEntry, *model.AppErr) {
+	return a.Srv().Store.Audit().GetAll(f, limit, offset)
+}
+
+// ExportAuditLog exports the filtered audit log as csv, the newest entries first
+func (a *App) ExportAuditLog(f *model.AuditFilter) (bytes.Buffer, *model.AppErr) {
+	var buf bytes.Buffer
+
+	entries, err := a.Srv().Store.Audit().GetAll(f, model.AuditExportMaxRows, 0)
+	if err != nil {
+		return buf, err
+	}
+
+	w := csv.NewWriter(&buf)
+	w.Write(auditCSVHeader)
+	for _, e := range entries {
+		w.Write([]string{
+			strconv.FormatInt(e.ID, 10),
+			e.CreatedAt.UTC().Format(time.RFC3339),
+			auditCSVID(e.ActorID),
+			auditCSVID(e.APIKeyID),
+			auditCSVCell(e.Action),
+			auditCSVCell(e.ResourceType),
+			auditCSVCell(e.ResourceID),
+			auditCSVCell(e.IP),
+			auditCSVCell(e.RequestID),
+			auditCSVCell(string(e.Diff)),
+		})
+	}
+	w.Flush()
+
+	if err := w.Error(); err != nil {
+		return buf, model.NewAppErr("ExportAuditLog", model.ErrInternal, locale.GetUserLocalizer("en"), msgExportAuditLog, http.StatusInternalServerError, nil)
+	}
+	return buf, nil
+}
+
+func auditCSVID(id *int64) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatInt(*id, 10)
+}
+
+// auditCSVCell escapes the string values so the spreadsheets don't evaluate them as formulas
+func auditCSVCell(s string) string {
+	if s != "" && strings.ContainsAny(s[:1], "=+-@\t\r") {
+		return "'" + s
+	}
+	return s
+}
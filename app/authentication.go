@@ -10,6 +10,7 @@ import (
 	"github.com/dankobgd/ecommerce-shop/config"
 	"github.com/dankobgd/ecommerce-shop/model"
 	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/dankobgd/ecommerce-shop/zlog"
 	"github.com/dgrijalva/jwt-go/v4"
 	"github.com/google/uuid"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
@@ -20,16 +21,18 @@ type contextKey string
 // context keys
 const (
 	AccessDataCtxKey contextKey = "access_data"
+	ClientIPCtxKey   contextKey = "client_ip"
 )
 
 var (
 	msgGenerateTokens     = &i18n.Message{ID: "app.generate_tokens.app_error", Other: "could not generate token"}
 	msgVerifyToken        = &i18n.Message{ID: "app.verify_token.app_error", Other: "invalid token"}
-	msgVerifyTokenMethod  = &i18n.Message{ID: "app.verify_token.app_error", Other: "invalid token signin method"}
+	msgVerifyTokenMethod  = &i18n.Message{ID: "app.verify_token.signing_method.app_error", Other: "invalid token signin method"}
 	msgExtractTokenMeta   = &i18n.Message{ID: "app.extract_token_meta.app_error", Other: "could not extract token meta data"}
 	msgRefreshToken       = &i18n.Message{ID: "app.refresh_token.app_error", Other: "invalid refresh token"}
-	msgRefreshTokenMethod = &i18n.Message{ID: "app.refresh_token.app_error", Other: "invalid refresh token signing method"}
-	msgDeleteToken        = &i18n.Message{ID: "app.refresh_token.app_error", Other: "could not delete old token"}
+	msgRefreshTokenMethod = &i18n.Message{ID: "app.refresh_token.signing_method.app_error", Other: "invalid refresh token signing method"}
+	msgDeleteToken        = &i18n.Message{ID: "app.refresh_token.delete_old.app_error", Other: "could not delete old token"}
+	msgRefreshTokenReused = &i18n.Message{ID: "app.refresh_token.reused.app_error", Other: "refresh token has already been used, the session has been revoked"}
 	msgComparePwd         = &i18n.Message{ID: "model.compare_password.app_error", Other: "passwords don't match"}
 )
 
@@ -46,13 +49,25 @@ func (a *App) CheckUserPassword(user *model.User, password string) *model.AppErr
 	return nil
 }
 
-// IssueTokens returns the token pair
+// IssueTokens returns the token pair for the new session
 func (a *App) IssueTokens(user *model.User) (*model.TokenMetadata, *model.AppErr) {
+	return a.issueTokens(user, uuid.New().String())
+}
+
+func (a *App) issueTokens(user *model.User, sessionID string) (*model.TokenMetadata, *model.AppErr) {
 	settings := &a.Cfg().AuthSettings
+	permissions, pErr := a.GetUserPermissions(user)
+	if pErr != nil {
+		return nil, pErr
+	}
+
 	atID := uuid.New().String()
-	atExp := time.Now().Add(time.Minute * 100000) // TODO: change later to small amount
+	atExp := time.Now().Add(time.Minute * time.Duration(settings.AccessTokenExpiryMinutes))
 	atClaims := model.Claims{
-		Role: user.Role,
+		Role:        user.Role,
+		Locale:      user.Locale,
+		Permissions: permissions,
+		SessionID:   sessionID,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: &jwt.Time{Time: atExp},
 			ID:        atID,
@@ -67,9 +82,11 @@ func (a *App) IssueTokens(user *model.User) (*model.TokenMetadata, *model.AppErr
 	}
 
 	rtID := uuid.New().String()
-	rtExp := time.Now().Add(time.Hour * 24 * 7)
+	rtExp := time.Now().Add(time.Hour * time.Duration(settings.RefreshTokenExpiryHours))
 	rtClaims := model.Claims{
-		Role: user.Role,
+		Role:      user.Role,
+		Locale:    user.Locale,
+		SessionID: sessionID,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: &jwt.Time{Time: rtExp},
 			ID:        rtID,
@@ -91,6 +108,7 @@ func (a *App) IssueTokens(user *model.User) (*model.TokenMetadata, *model.AppErr
 		AccessExpires:  atExp,
 		RefreshExpires: rtExp,
 		TokenType:      model.AccessTokenType,
+		SessionID:      sessionID,
 	}
 
 	return meta, nil
@@ -150,6 +168,11 @@ func expireCookie(cookieName string) *http.Cookie {
 func ExtractAuthTokenFromRequest(r *http.Request) (string, model.AccessTokenLocation) {
 	authHeader := r.Header.Get(model.HeaderBearer)
 
+	// extract the integration api key
+	if key := r.Header.Get(model.HeaderAPIKey); key != "" {
+		return key, model.TokenLocationAPIKey
+	}
+
 	// extract from cookie
 	if cookie, err := r.Cookie(model.AccessCookieName); err == nil {
 		return cookie.Value, model.TokenLocationCookie
@@ -214,9 +237,11 @@ func (a *App) ExtractTokenMetadata(r *http.Request) (*model.AccessData, *model.A
 		}
 
 		ad := &model.AccessData{
-			AccessUUID: claims.ID,
-			UserID:     userID,
-			Role:       claims.Role,
+			AccessUUID:  claims.ID,
+			SessionID:   claims.SessionID,
+			UserID:      userID,
+			Role:        claims.Role,
+			Permissions: claims.Permissions,
 		}
 
 		return ad, nil
@@ -243,28 +268,88 @@ func (a *App) RefreshToken(rt *model.RefreshToken) (*model.TokenMetadata, *model
 	}
 
 	claims, ok := token.Claims.(*model.Claims)
-	if ok && token.Valid {
+	if !ok || !token.Valid {
+		return nil, model.NewAppErr("RefreshToken", model.ErrUnauthenticated, l, msgRefreshToken, http.StatusUnauthorized, nil)
+	}
+
+	userID, _ := strconv.ParseInt(claims.Subject, 10, 64)
+
+	// the new tokens carry the current role and locale, not the ones the old token was issued with
+	udata, uErr := a.Srv().Store.User().Get(userID)
+	if uErr != nil {
+		if uErr.StatusCode == http.StatusNotFound {
+			return nil, model.NewAppErr("RefreshToken", model.ErrUnauthenticated, l, msgRefreshToken, http.StatusUnauthorized, nil)
+		}
+		return nil, uErr
+	}
+
+	// the tokens issued before the sessions existed start a new session
+	if claims.SessionID == "" {
 		deleted, err := a.DeleteAuth(claims.ID)
 		if err != nil || deleted == 0 {
 			return nil, model.NewAppErr("RefreshToken", model.ErrUnauthenticated, l, msgDeleteToken, http.StatusUnauthorized, nil)
 		}
-
-		userID, _ := strconv.ParseInt(claims.Subject, 10, 64)
-		udata := &model.User{Role: claims.Role, ID: userID}
-
 		meta, err := a.IssueTokens(udata)
 		if err != nil {
 			return nil, model.NewAppErr("RefreshToken", model.ErrUnauthenticated, l, msgRefreshToken, http.StatusUnauthorized, nil)
 		}
-
-		if err := a.SaveAuth(userID, meta); err != nil {
+		if err := a.SaveAuth(userID, meta, &model.Session{}); err != nil {
 			return nil, model.NewAppErr("RefreshToken", model.ErrUnauthenticated, l, msgRefreshToken, http.StatusUnauthorized, nil)
 		}
-
 		return meta, nil
 	}
 
-	return nil, model.NewAppErr("RefreshToken", model.ErrUnauthenticated, l, msgRefreshToken, http.StatusUnauthorized, nil)
+	session, sErr := a.Srv().Store.Session().Get(userID, claims.SessionID)
+	if sErr != nil {
+		return nil, sErr
+	}
+	if session == nil {
+		return nil, model.NewAppErr("RefreshToken", model.ErrUnauthenticated, l, msgSessionRevoked, http.StatusUnauthorized, nil)
+	}
+	if session.RefreshUUID != claims.ID {
+		return nil, a.checkRefreshTokenReuse(userID, claims)
+	}
+
+	meta, iErr := a.issueTokens(udata, claims.SessionID)
+	if iErr != nil {
+		return nil, model.NewAppErr("RefreshToken", model.ErrUnauthenticated, l, msgRefreshToken, http.StatusUnauthorized, nil)
+	}
+
+	// the refresh token can be presented twice at the same time, only the first one rotates the session
+	rotated, rErr := a.Srv().Store.Session().Rotate(userID, claims.SessionID, claims.ID, meta)
+	if rErr != nil {
+		return nil, rErr
+	}
+	if !rotated {
+		return nil, a.checkRefreshTokenReuse(userID, claims)
+	}
+
+	a.DeleteAuth(claims.ID)
+	a.DeleteAuth(session.AccessUUID)
+	if err := a.SaveAuth(userID, meta, nil); err != nil {
+		return nil, model.NewAppErr("RefreshToken", model.ErrUnauthenticated, l, msgRefreshToken, http.StatusUnauthorized, nil)
+	}
+
+	return meta, nil
+}
+
+// checkRefreshTokenReuse revokes the whole refresh token family (the session) when the already rotated out refresh token is presented again,
+// which means that either the client or the attacker holds the stolen token
+func (a *App) checkRefreshTokenReuse(userID int64, claims *model.Claims) *model.AppErr {
+	l := locale.GetUserLocalizer("en")
+	reused, err := a.Srv().Store.Session().IsRotated(claims.SessionID, claims.ID)
+	if err != nil {
+		return err
+	}
+	if !reused {
+		return model.NewAppErr("RefreshToken", model.ErrUnauthenticated, l, msgRefreshToken, http.StatusUnauthorized, nil)
+	}
+
+	a.Log().Warn("refresh token reuse detected, revoking the session", zlog.Int64("user_id", userID), zlog.String("session_id", claims.SessionID), zlog.String("refresh_uuid", claims.ID))
+	if err := a.Srv().Store.Session().Delete(userID, claims.SessionID); err != nil {
+		a.Log().Error(err.Error(), zlog.Err(err))
+	}
+	return model.NewAppErr("RefreshToken", model.ErrUnauthenticated, l, msgRefreshTokenReused, http.StatusUnauthorized, nil)
 }
 
 // GetUserIDFromContext gets the user from ctx
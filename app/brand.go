@@ -50,6 +50,7 @@ func (a *App) CreateBrand(b *model.Brand, fh *multipart.FileHeader) (*model.Bran
 		return nil, bErr
 	}
 
+	a.audit(model.AuditActionCreate, model.AuditResourceBrand, brand.ID, nil, brand)
 	return brand, nil
 }
 
@@ -64,6 +65,7 @@ func (a *App) PatchBrand(bid int64, patch *model.BrandPatch, fh *multipart.FileH
 		return nil, err
 	}
 
+	before := *old
 	oldPublicID := old.LogoPublicID
 
 	if fh != nil {
@@ -90,10 +92,11 @@ func (a *App) PatchBrand(bid int64, patch *model.BrandPatch, fh *multipart.FileH
 	if err != nil {
 		return nil, err
 	}
+	a.audit(model.AuditActionUpdate, model.AuditResourceBrand, bid, &before, ubrand)
 
 	defer func() {
 		if oldPublicID != "" {
-			if err := a.DeleteImage(oldPublicID); err != nil {
+			if err := a.EnqueueDeleteImage(oldPublicID); err != nil {
 				a.Log().Error(err.Error(), zlog.Err(err))
 			}
 		}
@@ -124,10 +127,11 @@ func (a *App) DeleteBrand(bid int64) *model.AppErr {
 	if err != nil {
 		return err
 	}
+	a.audit(model.AuditActionDelete, model.AuditResourceBrand, bid, old, nil)
 
 	defer func() {
 		if old.LogoPublicID != "" {
-			if err := a.DeleteImage(old.LogoPublicID); err != nil {
+			if err := a.EnqueueDeleteImage(old.LogoPublicID); err != nil {
 				a.Log().Error(err.Error(), zlog.Err(err))
 			}
 		}
@@ -138,5 +142,12 @@ func (a *App) DeleteBrand(bid int64) *model.AppErr {
 
 // DeleteBrands bulk deletes tags
 func (a *App) DeleteBrands(ids []int) *model.AppErr {
-	return a.Srv().Store.Brand().BulkDelete(ids)
+	before := a.auditSnapshots(ids, func(id int64) (interface{}, *model.AppErr) { return a.Srv().Store.Brand().Get(id) })
+	if err := a.Srv().Store.Brand().BulkDelete(ids); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		a.audit(model.AuditActionDelete, model.AuditResourceBrand, id, before[id], nil)
+	}
+	return nil
 }
@@ -50,6 +50,7 @@ func (a *App) CreateCategory(c *model.Category, fh *multipart.FileHeader) (*mode
 		return nil, cErr
 	}
 
+	a.audit(model.AuditActionCreate, model.AuditResourceCategory, category.ID, nil, category)
 	return category, nil
 }
 
@@ -64,6 +65,7 @@ func (a *App) PatchCategory(cid int64, patch *model.CategoryPatch, fh *multipart
 		return nil, err
 	}
 
+	before := *old
 	oldPublicID := old.LogoPublicID
 
 	if fh != nil {
@@ -91,10 +93,11 @@ func (a *App) PatchCategory(cid int64, patch *model.CategoryPatch, fh *multipart
 	if err != nil {
 		return nil, err
 	}
+	a.audit(model.AuditActionUpdate, model.AuditResourceCategory, cid, &before, ucat)
 
 	defer func() {
 		if oldPublicID != "" {
-			if err := a.DeleteImage(oldPublicID); err != nil {
+			if err := a.EnqueueDeleteImage(oldPublicID); err != nil {
 				a.Log().Error(err.Error(), zlog.Err(err))
 			}
 		}
@@ -124,10 +127,11 @@ func (a *App) DeleteCategory(cid int64) *model.AppErr {
 	if err != nil {
 		return err
 	}
+	a.audit(model.AuditActionDelete, model.AuditResourceCategory, cid, old, nil)
 
 	defer func() {
 		if old.LogoPublicID != "" {
-			if err := a.DeleteImage(old.LogoPublicID); err != nil {
+			if err := a.EnqueueDeleteImage(old.LogoPublicID); err != nil {
 				a.Log().Error(err.Error(), zlog.Err(err))
 			}
 		}
@@ -143,5 +147,12 @@ func (a *App) GetFeaturedCategories(limit, offset int) ([]*model.Category, *mode
 
 // DeleteCategories bulk deletes tags
 func (a *App) DeleteCategories(ids []int) *model.AppErr {
-	return a.Srv().Store.Category().BulkDelete(ids)
+	before := a.auditSnapshots(ids, func(id int64) (interface{}, *model.AppErr) { return a.Srv().Store.Category().Get(id) })
+	if err := a.Srv().Store.Category().BulkDelete(ids); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		a.audit(model.AuditActionDelete, model.AuditResourceCategory, id, before[id], nil)
+	}
+	return nil
 }
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// RequestIP returns the client ip the RealIP middleware resolved for the request
+func (a *App) RequestIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(ClientIPCtxKey).(string); ok {
+		return ip
+	}
+	return a.ClientIP(r)
+}
+
+// ClientIP resolves the client ip of the request, the forwarded headers are used only when the request came from the trusted proxy.
+// X-Forwarded-For is read from the right and the first address that is not the trusted proxy is the client,
+// the addresses the client put in the header itself are on the left so they can't spoof the ip
+func (a *App) ClientIP(r *http.Request) string {
+	remote := remoteIP(r)
+	proxies := a.trustedProxies()
+	if !isTrustedProxy(remote, proxies) {
+		return remote
+	}
+
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+		client := remote
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				break
+			}
+			client = ip.String()
+			if !isTrustedProxy(client, proxies) {
+				break
+			}
+		}
+		return client
+	}
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+	return remote
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.String()
+	}
+	return host
+}
+
+// trustedProxies parses the configured proxies, the single address is trusted as is and the range in the CIDR notation,
+// the invalid entries are skipped
+func (a *App) trustedProxies() []*net.IPNet {
+	proxies := make([]*net.IPNet, 0, len(a.Cfg().TrustedProxies))
+	for _, p := range a.Cfg().TrustedProxies {
+		p = strings.TrimSpace(p)
+		if _, n, err := net.ParseCIDR(p); err == nil {
+			proxies = append(proxies, n)
+			continue
+		}
+		if ip := net.ParseIP(p); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		}
+	}
+	return proxies
+}
+
+func isTrustedProxy(addr string, proxies []*net.IPNet) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, p := range proxies {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
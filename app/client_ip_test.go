@@ -0,0 +1,48 @@
+package app
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dankobgd/ecommerce-shop/config"
+)
+
+func TestClientIP(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.TrustedProxies = []string{"10.0.0.1", "172.16.0.0/12"}
+	a := New(SetConfig(cfg))
+
+	tests := []struct {
+		name   string
+		remote string
+		xff    string
+		xrip   string
+		want   string
+	}{
+		{"direct client", "203.0.113.7:5000", "", "", "203.0.113.7"},
+		{"untrusted client spoofing the forwarded headers", "203.0.113.7:5000", "198.51.100.1", "198.51.100.2", "203.0.113.7"},
+		{"trusted proxy", "10.0.0.1:5000", "198.51.100.1", "", "198.51.100.1"},
+		{"client prepends the spoofed address", "10.0.0.1:5000", "1.2.3.4, 198.51.100.1", "", "198.51.100.1"},
+		{"chain of trusted proxies", "10.0.0.1:5000", "198.51.100.1, 172.20.0.5", "", "198.51.100.1"},
+		{"only trusted proxies", "10.0.0.1:5000", "172.20.0.5", "", "172.20.0.5"},
+		{"malformed hop", "10.0.0.1:5000", "garbage, 172.20.0.5", "", "172.20.0.5"},
+		{"real ip header from the trusted proxy", "172.16.3.4:5000", "", "198.51.100.9", "198.51.100.9"},
+		{"ipv6 client", "[2001:db8::1]:5000", "", "", "2001:db8::1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remote
+			if tt.xff != "" {
+				r.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			if tt.xrip != "" {
+				r.Header.Set("X-Real-IP", tt.xrip)
+			}
+			if got := a.ClientIP(r); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
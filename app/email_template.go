@@ -0,0 +1,293 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/dankobgd/ecommerce-shop/mailer"
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/dankobgd/ecommerce-shop/utils/template"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgReadEmailTemplate     = &i18n.Message{ID: "app.email_template.read.app_error", Other: "could not read email template file"}
+	msgParseEmailTemplate    = &i18n.Message{ID: "app.email_template.parse.app_error", Other: "could not parse email template"}
+	msgRenderEmailTemplate   = &i18n.Message{ID: "app.email_template.render.app_error", Other: "could not render email template"}
+	msgEmailTemplateNotFound = &i18n.Message{ID: "app.email_template.not_found.app_error", Other: "email template not found"}
+)
+
+// emailTemplateFiles are the default templates used when the email is not overridden for the locale
+var emailTemplateFiles = map[string]string{
+	model.EmailTemplateWelcome:          "templates/welcome.html",
+	model.EmailTemplateEmailVerify:      "templates/email_verify.html",
+	model.EmailTemplatePasswordRecovery: "templates/reset_password.html",
+	model.EmailTemplatePasswordUpdated:  "templates/reset_password_completed.html",
+	model.EmailTemplateQuestionAsked:    "templates/notification.html",
+	model.EmailTemplateQuestionAnswered: "templates/notification.html",
+	model.EmailTemplateWishlistAlert:    "templates/wishlist_alert.html",
+	model.EmailTemplateOrderConfirmed:   "templates/order_confirmation.html",
+	model.EmailTemplateOrderShipped:     "templates/order_status.html",
+	model.EmailTemplateOrderDelivered:   "templates/order_status.html",
+	model.EmailTemplateOrderCancelled:   "templates/order_status.html",
+	model.EmailTemplateOrderRefunded:    "templates/order_status.html",
+	model.EmailTemplateSuspiciousLogin:  "templates/notification.html",
+}
+
+// GetEmailTemplateOverrides gets all email templates that are overridden in the db
+func (a *App) GetEmailTemplateOverrides() ([]*model.EmailTemplate, *model.AppErr) {
+	return a.Srv().Store.EmailTemplate().GetAll()
+}
+
+// GetEmailTemplate gets the template used for the email in the locale,
+// the db override if there is one or the template file
+func (a *App) GetEmailTemplate(name, lang string) (*model.EmailTemplate, *model.AppErr) {
+	filename, ok := emailTemplateFiles[name]
+	if !ok {
+		return nil, model.NewAppErr("GetEmailTemplate", model.ErrNotFound, locale.GetUserLocalizer("en"), msgEmailTemplateNotFound, http.StatusNotFound, nil)
+	}
+
+	et, err := a.Srv().Store.EmailTemplate().Get(name, lang)
+	if err != nil {
+		return nil, err
+	}
+	if et != nil {
+		return et, nil
+	}
+
+	b, e := ioutil.ReadFile(filename)
+	if e != nil {
+		return nil, model.NewAppErr("GetEmailTemplate", model.ErrInternal, locale.GetUserLocalizer("en"), msgReadEmailTemplate, http.StatusInternalServerError, nil)
+	}
+	return &model.EmailTemplate{Name: name, Locale: lang, Body: string(b)}, nil
+}
+
+// SaveEmailTemplate overrides the email template for the locale, it has to render with the sample data to be saved
+func (a *App) SaveEmailTemplate(et *model.EmailTemplate) (*model.EmailTemplate, *model.AppErr) {
+	et.PreSave()
+	_, data := emailTemplateSampleData(et.Name, et.Locale)
+	if err := et.Validate(data); err != nil {
+		return nil, err
+	}
+
+	old, _ := a.Srv().Store.EmailTemplate().Get(et.Name, et.Locale)
+	saved, err := a.Srv().Store.EmailTemplate().Save(et)
+	if err != nil {
+		return nil, err
+	}
+	a.audit(auditSaveAction(old != nil), model.AuditResourceEmailTemplate, saved.Name+"/"+saved.Locale, old, saved)
+	return saved, nil
+}
+
+// DeleteEmailTemplate removes the override so the template file is used again
+func (a *App) DeleteEmailTemplate(name, lang string) *model.AppErr {
+	old, _ := a.Srv().Store.EmailTemplate().Get(name, lang)
+	if err := a.Srv().Store.EmailTemplate().Delete(name, lang); err != nil {
+		return err
+	}
+	a.audit(model.AuditActionDelete, model.AuditResourceEmailTemplate, name+"/"+lang, old, nil)
+	return nil
+}
+
+// PreviewEmailTemplate renders the email with the sample data, the draft is rendered instead of the current template if provided
+func (a *App) PreviewEmailTemplate(name, lang string, draft *model.EmailTemplate) (*model.EmailTemplatePreview, *model.AppErr) {
+	et := draft
+	if et == nil {
+		current, err := a.GetEmailTemplate(name, lang)
+		if err != nil {
+			return nil, err
+		}
+		et = current
+	} else {
+		et.Name = name
+		et.Locale = lang
+		et.PreSave()
+	}
+
+	subject, data := emailTemplateSampleData(name, lang)
+	if draft != nil {
+		if err := et.Validate(data); err != nil {
+			return nil, err
+		}
+	}
+
+	html, err := renderEmailBody(et, data)
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := renderEmailSubject(et, data); ok {
+		subject = s
+	}
+
+	return &model.EmailTemplatePreview{Name: name, Locale: lang, Subject: subject, HTML: html}, nil
+}
+
+// sendWithEmailTemplate renders the template and sends the email, the overridden subject replaces the default one
+func (a *App) sendWithEmailTemplate(et *model.EmailTemplate, data interface{}, md *mailer.Maildata) *model.AppErr {
+	tmpl, e := et.ParseBody()
+	if e != nil {
+		return model.NewAppErr("sendWithEmailTemplate", model.ErrInternal, locale.GetUserLocalizer("en"), msgParseEmailTemplate, http.StatusInternalServerError, nil)
+	}
+	if s, ok := renderEmailSubject(et, data); ok {
+		md.Subject = s
+	}
+	return mailer.SendEmail(a.Mailer(), tmpl, data, md)
+}
+
+func renderEmailBody(et *model.EmailTemplate, data interface{}) (string, *model.AppErr) {
+	tmpl, err := et.ParseBody()
+	if err != nil {
+		return "", model.NewAppErr("renderEmailBody", model.ErrInternal, locale.GetUserLocalizer("en"), msgParseEmailTemplate, http.StatusInternalServerError, nil)
+	}
+	html, err := template.Render(tmpl, data)
+	if err != nil {
+		return "", model.NewAppErr("renderEmailBody", model.ErrInternal, locale.GetUserLocalizer("en"), msgRenderEmailTemplate, http.StatusInternalServerError, nil)
+	}
+	return html, nil
+}
+
+// renderEmailSubject renders the overridden subject, false is returned if it's not overridden or can't be rendered
+func renderEmailSubject(et *model.EmailTemplate, data interface{}) (string, bool) {
+	tmpl, err := et.ParseSubject()
+	if err != nil || tmpl == nil {
+		return "", false
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", false
+	}
+	return buf.String(), true
+}
+
+// emailTemplateSampleData returns the default subject and the template data with the same shape
+// as the real email has, it's used for the previews and to validate the overrides before they are saved
+func emailTemplateSampleData(name, lang string) (string, map[string]interface{}) {
+	l := locale.GetUserLocalizer(lang)
+	siteURL := "http://localhost:3000"
+	orderData := map[string]interface{}{"OrderID": 1001, "Total": toUSD(4998)}
+
+	data := map[string]interface{}{
+		"Email":       "jane.doe@example.com",
+		"DisplayName": "janedoe",
+		"Hello":       locale.LocalizeDefaultMessage(l, msgTemplateHello),
+	}
+
+	switch name {
+	case model.EmailTemplateWelcome:
+		return "Welcome", data
+	case model.EmailTemplateEmailVerify:
+		data["Token"] = "sample-token"
+		data["Link"] = fmt.Sprintf("%s/email/verify?token=%s", siteURL, "sample-token")
+		data["Title"] = locale.LocalizeDefaultMessage(l, msgEmailVerifyTitle)
+		data["BodyText"] = locale.LocalizeDefaultMessage(l, msgEmailVerifyBodyText)
+		data["ButtonText"] = locale.LocalizeDefaultMessage(l, msgEmailVerifyButtonText)
+		return locale.LocalizeDefaultMessage(l, msgEmailVerifySubject), data
+	case model.EmailTemplatePasswordRecovery:
+		data["Token"] = "sample-token"
+		data["Link"] = fmt.Sprintf("%s/password/reset?token=%s", siteURL, "sample-token")
+		data["Title"] = locale.LocalizeDefaultMessage(l, msgPwdRecoveryTitle)
+		data["BodyText"] = locale.LocalizeDefaultMessage(l, msgPwdRecoveryBodyText)
+		data["ButtonText"] = locale.LocalizeDefaultMessage(l, msgPwdRecoveryButtonText)
+		data["ValidForText"] = locale.LocalizeWithConfig(l, &i18n.LocalizeConfig{
+			DefaultMessage: msgPwdRecoveryValidForText,
+			PluralCount:    "2",
+			TemplateData:   map[string]interface{}{"Expiry": "2"},
+		})
+		data["WarningText"] = locale.LocalizeDefaultMessage(l, msgPwdRecoveryWarningText)
+		return locale.LocalizeDefaultMessage(l, msgPwdRecoverySubject), data
+	case model.EmailTemplatePasswordUpdated:
+		data["Title"] = locale.LocalizeDefaultMessage(l, msgPwdUpdatedTitle)
+		data["CompletedText"] = locale.LocalizeDefaultMessage(l, msgPwdUpdatedCompletedText)
+		data["ChangedText"] = locale.LocalizeDefaultMessage(l, msgPwdUpdatedChangedText)
+		data["ForAccountText"] = locale.LocalizeDefaultMessage(l, msgPwdUpdatedForAccountText)
+		return locale.LocalizeDefaultMessage(l, msgPwdUpdatedSubject), data
+	case model.EmailTemplateQuestionAsked, model.EmailTemplateQuestionAnswered:
+		subject, title, body, button := msgQuestionAskedSubject, msgQuestionAskedTitle, msgQuestionAskedBodyText, msgQuestionAskedButtonText
+		if name == model.EmailTemplateQuestionAnswered {
+			subject, title, body, button = msgQuestionAnsweredSubject, msgQuestionAnsweredTitle, msgQuestionAnsweredBodyText, msgQuestionAnsweredButtonText
+		}
+		data["Title"] = locale.LocalizeDefaultMessage(l, title)
+		data["BodyText"] = locale.LocalizeWithConfig(l, &i18n.LocalizeConfig{
+			DefaultMessage: body,
+			TemplateData:   map[string]interface{}{"Product": "Sample Product"},
+		})
+		data["Quote"] = "Does it come with a warranty?"
+		data["Link"] = fmt.Sprintf("%s/products/1", siteURL)
+		data["ButtonText"] = locale.LocalizeDefaultMessage(l, button)
+		return locale.LocalizeDefaultMessage(l, subject), data
+	case model.EmailTemplateSuspiciousLogin:
+		data["Title"] = locale.LocalizeDefaultMessage(l, msgSuspiciousLoginTitle)
+		data["BodyText"] = locale.LocalizeWithConfig(l, &i18n.LocalizeConfig{
+			DefaultMessage: msgSuspiciousLoginBodyText,
+			TemplateData:   map[string]interface{}{"Attempts": 10, "Minutes": 15},
+		})
+		data["Quote"] = locale.LocalizeWithConfig(l, &i18n.LocalizeConfig{
+			DefaultMessage: msgSuspiciousLoginIPText,
+			TemplateData:   map[string]interface{}{"IP": "203.0.113.7"},
+		})
+		data["Link"] = fmt.Sprintf("%s/password/reset", siteURL)
+		data["ButtonText"] = locale.LocalizeDefaultMessage(l, msgPwdRecoveryButtonText)
+		return locale.LocalizeDefaultMessage(l, msgSuspiciousLoginSubject), data
+	case model.EmailTemplateWishlistAlert:
+		data["Title"] = locale.LocalizeDefaultMessage(l, msgWishlistAlertTitle)
+		data["BodyText"] = locale.LocalizeDefaultMessage(l, msgWishlistAlertBodyText)
+		data["Items"] = []map[string]string{
+			{
+				"Name": "Sample Product",
+				"Text": locale.LocalizeWithConfig(l, &i18n.LocalizeConfig{
+					DefaultMessage: msgWishlistAlertPriceDrop,
+					TemplateData:   map[string]interface{}{"OldPrice": toUSD(2999), "Price": toUSD(2499)},
+				}),
+				"Link": fmt.Sprintf("%s/products/1", siteURL),
+			},
+		}
+		data["Link"] = fmt.Sprintf("%s/wishlist", siteURL)
+		data["ButtonText"] = locale.LocalizeDefaultMessage(l, msgWishlistAlertButtonText)
+		return locale.LocalizeDefaultMessage(l, msgWishlistAlertSubject), data
+	case model.EmailTemplateOrderConfirmed:
+		data["OrderID"] = orderData["OrderID"]
+		data["Title"] = locale.LocalizeDefaultMessage(l, msgOrderConfirmationTitle)
+		data["BodyText"] = locale.LocalizeWithConfig(l, &i18n.LocalizeConfig{DefaultMessage: msgOrderConfirmationBodyText, TemplateData: orderData})
+		data["ProductText"] = locale.LocalizeDefaultMessage(l, msgOrderProductText)
+		data["QuantityText"] = locale.LocalizeDefaultMessage(l, msgOrderQuantityText)
+		data["PriceText"] = locale.LocalizeDefaultMessage(l, msgOrderPriceText)
+		data["SubtotalText"] = locale.LocalizeDefaultMessage(l, msgOrderSubtotalText)
+		data["TotalText"] = locale.LocalizeDefaultMessage(l, msgOrderTotalText)
+		data["AttachmentText"] = locale.LocalizeDefaultMessage(l, msgOrderConfirmationAttachmentText)
+		data["Items"] = []map[string]string{
+			{"Name": "Sample Product", "Quantity": "2", "Price": toUSD(4998)},
+		}
+		data["Subtotal"] = toUSD(4998)
+		data["Total"] = toUSD(4998)
+		data["Link"] = fmt.Sprintf("%s/orders/%d", siteURL, orderData["OrderID"])
+		data["ButtonText"] = locale.LocalizeDefaultMessage(l, msgOrderButtonText)
+		return locale.LocalizeWithConfig(l, &i18n.LocalizeConfig{DefaultMessage: msgOrderConfirmationSubject, TemplateData: orderData}), data
+	case model.EmailTemplateOrderShipped, model.EmailTemplateOrderDelivered, model.EmailTemplateOrderCancelled, model.EmailTemplateOrderRefunded:
+		subject, title, body := orderStatusEmailMessages(name)
+		data["OrderID"] = fmt.Sprintf("%d", orderData["OrderID"])
+		data["Title"] = locale.LocalizeDefaultMessage(l, title)
+		data["BodyText"] = locale.LocalizeWithConfig(l, &i18n.LocalizeConfig{DefaultMessage: body, TemplateData: orderData})
+		data["TrackingText"] = locale.LocalizeDefaultMessage(l, msgOrderShippedTracking)
+		data["TrackingNumber"] = "1Z999AA10123456784"
+		data["Link"] = fmt.Sprintf("%s/orders/%d", siteURL, orderData["OrderID"])
+		data["ButtonText"] = locale.LocalizeDefaultMessage(l, msgOrderButtonText)
+		return locale.LocalizeWithConfig(l, &i18n.LocalizeConfig{DefaultMessage: subject, TemplateData: orderData}), data
+	}
+	return "", data
+}
+
+func orderStatusEmailMessages(name string) (subject, title, body *i18n.Message) {
+	switch name {
+	case model.EmailTemplateOrderShipped:
+		return msgOrderShippedSubject, msgOrderShippedTitle, msgOrderShippedBodyText
+	case model.EmailTemplateOrderDelivered:
+		return msgOrderDeliveredSubject, msgOrderDeliveredTitle, msgOrderDeliveredBodyText
+	case model.EmailTemplateOrderCancelled:
+		return msgOrderCancelledSubject, msgOrderCancelledTitle, msgOrderCancelledBodyText
+	default:
+		return msgOrderRefundedSubject, msgOrderRefundedTitle, msgOrderRefundedBodyText
+	}
+}
@@ -18,22 +18,84 @@ var (
 	msgEmailVerifyBodyText   = &i18n.Message{ID: "app.templates.email.verify.body_text", Other: "Thank you for using our site, please verify your email by pressing the button bellow."}
 	msgEmailVerifyButtonText = &i18n.Message{ID: "app.templates.email.verify.button_text", Other: "Verify Email"}
 
-	msgPwdRecoveryTitle        = &i18n.Message{ID: "app.templates.email.verify.title", Other: "Reset Your Password"}
-	msgPwdRecoverySubject      = &i18n.Message{ID: "app.templates.email.verify.subject", Other: "Password Recovery"}
-	msgPwdRecoveryBodyText     = &i18n.Message{ID: "app.templates.email.verify.body_text", Other: "We got a request to reset your password, press the button bellow to reset it."}
-	msgPwdRecoveryValidForText = &i18n.Message{ID: "app.templates.email.verify.valid_for_text", One: "This password reset is only valid for the next {{ .Expiry }} hour", Other: "This password reset is only valid for the next {{ .Expiry }} hours."}
-	msgPwdRecoveryWarningText  = &i18n.Message{ID: "app.templates.email.verify.button_text", Other: "If you didn't request this, you can ignore this message and your password will remain unchanged."}
-	msgPwdRecoveryButtonText   = &i18n.Message{ID: "app.templates.email.verify.warning_text", Other: "Reset Password"}
-
-	msgPwdUpdatedSubject        = &i18n.Message{ID: "app.templates.password.updated.title", Other: "Password Update Completed"}
-	msgPwdUpdatedTitle          = &i18n.Message{ID: "app.templates.password.updated.subject", Other: "Password Updated"}
-	msgPwdUpdatedForAccountText = &i18n.Message{ID: "app.templates.password.updated.subject", Other: "Password for the account"}
-	msgPwdUpdatedChangedText    = &i18n.Message{ID: "app.templates.password.updated.body_text", Other: "has been changed successfully!"}
-	msgPwdUpdatedCompletedText  = &i18n.Message{ID: "app.templates.password.updated.button_text", Other: "Password Reset Completed"}
+	msgPwdRecoveryTitle        = &i18n.Message{ID: "app.templates.password.recovery.title", Other: "Reset Your Password"}
+	msgPwdRecoverySubject      = &i18n.Message{ID: "app.templates.password.recovery.subject", Other: "Password Recovery"}
+	msgPwdRecoveryBodyText     = &i18n.Message{ID: "app.templates.password.recovery.body_text", Other: "We got a request to reset your password, press the button bellow to reset it."}
+	msgPwdRecoveryValidForText = &i18n.Message{ID: "app.templates.password.recovery.valid_for_text", One: "This password reset is only valid for the next {{ .Expiry }} hour", Other: "This password reset is only valid for the next {{ .Expiry }} hours."}
+	msgPwdRecoveryWarningText  = &i18n.Message{ID: "app.templates.password.recovery.warning_text", Other: "If you didn't request this, you can ignore this message and your password will remain unchanged."}
+	msgPwdRecoveryButtonText   = &i18n.Message{ID: "app.templates.password.recovery.button_text", Other: "Reset Password"}
+
+	msgPwdUpdatedSubject        = &i18n.Message{ID: "app.templates.password.updated.subject", Other: "Password Update Completed"}
+	msgPwdUpdatedTitle          = &i18n.Message{ID: "app.templates.password.updated.title", Other: "Password Updated"}
+	msgPwdUpdatedForAccountText = &i18n.Message{ID: "app.templates.password.updated.for_account_text", Other: "Password for the account"}
+	msgPwdUpdatedChangedText    = &i18n.Message{ID: "app.templates.password.updated.changed_text", Other: "has been changed successfully!"}
+	msgPwdUpdatedCompletedText  = &i18n.Message{ID: "app.templates.password.updated.completed_text", Other: "Password Reset Completed"}
+
+	msgQuestionAskedSubject    = &i18n.Message{ID: "app.templates.question.asked.subject", Other: "New Product Question"}
+	msgQuestionAskedTitle      = &i18n.Message{ID: "app.templates.question.asked.title", Other: "A shopper asked a question"}
+	msgQuestionAskedBodyText   = &i18n.Message{ID: "app.templates.question.asked.body_text", Other: "A new question was asked about {{ .Product }}:"}
+	msgQuestionAskedButtonText = &i18n.Message{ID: "app.templates.question.asked.button_text", Other: "View Question"}
+
+	msgQuestionAnsweredSubject    = &i18n.Message{ID: "app.templates.question.answered.subject", Other: "Your Question Was Answered"}
+	msgQuestionAnsweredTitle      = &i18n.Message{ID: "app.templates.question.answered.title", Other: "Your question has an answer"}
+	msgQuestionAnsweredBodyText   = &i18n.Message{ID: "app.templates.question.answered.body_text", Other: "Someone answered your question about {{ .Product }}:"}
+	msgQuestionAnsweredButtonText = &i18n.Message{ID: "app.templates.question.answered.button_text", Other: "View Answer"}
+
+	msgWishlistAlertSubject     = &i18n.Message{ID: "app.templates.wishlist.alert.subject", Other: "Good News About Your Wishlist"}
+	msgWishlistAlertTitle       = &i18n.Message{ID: "app.templates.wishlist.alert.title", Other: "Your wishlist has updates"}
+	msgWishlistAlertBodyText    = &i18n.Message{ID: "app.templates.wishlist.alert.body_text", Other: "Some of the products you saved have changed:"}
+	msgWishlistAlertBackInStock = &i18n.Message{ID: "app.templates.wishlist.alert.back_in_stock", Other: "is back in stock for {{ .Price }}"}
+	msgWishlistAlertPriceDrop   = &i18n.Message{ID: "app.templates.wishlist.alert.price_drop", Other: "dropped in price from {{ .OldPrice }} to {{ .Price }}"}
+	msgWishlistAlertButtonText  = &i18n.Message{ID: "app.templates.wishlist.alert.button_text", Other: "View Wishlist"}
+
+	msgSuspiciousLoginSubject  = &i18n.Message{ID: "app.templates.suspicious_login.subject", Other: "Suspicious Login Attempts"}
+	msgSuspiciousLoginTitle    = &i18n.Message{ID: "app.templates.suspicious_login.title", Other: "We blocked logins to your account"}
+	msgSuspiciousLoginBodyText = &i18n.Message{ID: "app.templates.suspicious_login.body_text", Other: "Someone failed to log in to your account {{ .Attempts }} times, so logins are blocked for the next {{ .Minutes }} minutes. If this wasn't you, we recommend resetting your password."}
+	msgSuspiciousLoginIPText   = &i18n.Message{ID: "app.templates.suspicious_login.ip_text", Other: "The last attempt came from the IP address {{ .IP }}"}
 )
 
-func (a *App) sendEmailTemplate(filename string, data interface{}, maildata *mailer.Maildata) *model.AppErr {
-	return mailer.SendEmailTemplate(filename, data, maildata, a.Cfg())
+var (
+	msgOrderConfirmationSubject        = &i18n.Message{ID: "app.templates.order.confirmation.subject", Other: "Order Confirmation #{{ .OrderID }}"}
+	msgOrderConfirmationTitle          = &i18n.Message{ID: "app.templates.order.confirmation.title", Other: "Thank you for your order"}
+	msgOrderConfirmationBodyText       = &i18n.Message{ID: "app.templates.order.confirmation.body_text", Other: "We received your order #{{ .OrderID }} and your payment was successful. Here is what you bought:"}
+	msgOrderConfirmationAttachmentText = &i18n.Message{ID: "app.templates.order.confirmation.attachment_text", Other: "The invoice for this order is attached to this email."}
+	msgOrderProductText                = &i18n.Message{ID: "app.templates.order.product_text", Other: "Product"}
+	msgOrderQuantityText               = &i18n.Message{ID: "app.templates.order.quantity_text", Other: "Quantity"}
+	msgOrderPriceText                  = &i18n.Message{ID: "app.templates.order.price_text", Other: "Price"}
+	msgOrderSubtotalText               = &i18n.Message{ID: "app.templates.order.subtotal_text", Other: "Subtotal"}
+	msgOrderTotalText                  = &i18n.Message{ID: "app.templates.order.total_text", Other: "Total"}
+	msgOrderButtonText                 = &i18n.Message{ID: "app.templates.order.button_text", Other: "View Order"}
+
+	msgOrderShippedSubject    = &i18n.Message{ID: "app.templates.order.shipped.subject", Other: "Your Order #{{ .OrderID }} Has Shipped"}
+	msgOrderShippedTitle      = &i18n.Message{ID: "app.templates.order.shipped.title", Other: "Your order is on its way"}
+	msgOrderShippedBodyText   = &i18n.Message{ID: "app.templates.order.shipped.body_text", Other: "Good news, your order #{{ .OrderID }} has been shipped."}
+	msgOrderShippedTracking   = &i18n.Message{ID: "app.templates.order.shipped.tracking_text", Other: "Tracking number:"}
+	msgOrderShippedButtonText = &i18n.Message{ID: "app.templates.order.shipped.button_text", Other: "Track Package"}
+
+	msgOrderDeliveredSubject  = &i18n.Message{ID: "app.templates.order.delivered.subject", Other: "Your Order #{{ .OrderID }} Was Delivered"}
+	msgOrderDeliveredTitle    = &i18n.Message{ID: "app.templates.order.delivered.title", Other: "Your order has arrived"}
+	msgOrderDeliveredBodyText = &i18n.Message{ID: "app.templates.order.delivered.body_text", Other: "Your order #{{ .OrderID }} has been delivered, we hope you enjoy it."}
+
+	msgOrderCancelledSubject  = &i18n.Message{ID: "app.templates.order.cancelled.subject", Other: "Your Order #{{ .OrderID }} Was Cancelled"}
+	msgOrderCancelledTitle    = &i18n.Message{ID: "app.templates.order.cancelled.title", Other: "Your order was cancelled"}
+	msgOrderCancelledBodyText = &i18n.Message{ID: "app.templates.order.cancelled.body_text", Other: "Your order #{{ .OrderID }} has been cancelled. If you didn't request this, please contact us."}
+
+	msgOrderRefundedSubject  = &i18n.Message{ID: "app.templates.order.refunded.subject", Other: "Your Order #{{ .OrderID }} Was Refunded"}
+	msgOrderRefundedTitle    = &i18n.Message{ID: "app.templates.order.refunded.title", Other: "Your refund is on its way"}
+	msgOrderRefundedBodyText = &i18n.Message{ID: "app.templates.order.refunded.body_text", Other: "We refunded {{ .Total }} for your order #{{ .OrderID }}, it can take a few days to show up on your statement."}
+)
+
+// sendEmailTemplate queues the email, it's rendered and sent by the job workers
+// with the template override for the locale if there is one
+func (a *App) sendEmailTemplate(name string, userLocale string, data interface{}, maildata *mailer.Maildata) *model.AppErr {
+	return a.EnqueueJob(model.JobTypeSendEmail, &emailJobPayload{
+		Template:    name,
+		Locale:      userLocale,
+		To:          maildata.To,
+		Subject:     maildata.Subject,
+		Data:        data,
+		Attachments: maildata.Attachments,
+	})
 }
 
 // SendWelcomeEmail sends the email o the newly registered user
@@ -43,7 +105,7 @@ func (a *App) SendWelcomeEmail(to string) *model.AppErr {
 		Subject: "Welcome",
 	}
 	data := map[string]string{"Email": strings.Join(info.To, ",")}
-	return a.sendEmailTemplate("templates/welcome.html", data, info)
+	return a.sendEmailTemplate(model.EmailTemplateWelcome, "en", data, info)
 }
 
 // SendEmailVerificationEmail sends the verify email
@@ -65,7 +127,7 @@ func (a *App) SendEmailVerificationEmail(to string, token string, siteURL string
 		"ButtonText": locale.LocalizeDefaultMessage(l, msgEmailVerifyButtonText),
 	}
 
-	return a.sendEmailTemplate("templates/email_verify.html", data, info)
+	return a.sendEmailTemplate(model.EmailTemplateEmailVerify, userLocale, data, info)
 }
 
 // SendPasswordRecoveryEmail sends the pwd reset email
@@ -101,7 +163,7 @@ func (a *App) SendPasswordRecoveryEmail(to string, username string, token *model
 		"WarningText": locale.LocalizeDefaultMessage(l, msgPwdRecoveryWarningText),
 	}
 
-	return a.sendEmailTemplate("templates/reset_password.html", data, info)
+	return a.sendEmailTemplate(model.EmailTemplatePasswordRecovery, userLocale, data, info)
 }
 
 // SendPasswordUpdatedEmail sends the pwd reset completed email
@@ -128,5 +190,290 @@ func (a *App) SendPasswordUpdatedEmail(to string, username string, siteURL strin
 		"ForAccountText": locale.LocalizeDefaultMessage(l, msgPwdUpdatedForAccountText),
 	}
 
-	return a.sendEmailTemplate("templates/reset_password_completed.html", data, info)
+	return a.sendEmailTemplate(model.EmailTemplatePasswordUpdated, userLocale, data, info)
+}
+
+// SendSuspiciousLoginEmail tells the user that the account was locked out after too many failed logins
+func (a *App) SendSuspiciousLoginEmail(to string, username string, ip string, attempts int, minutes int, siteURL string, userLocale string) *model.AppErr {
+	l := locale.GetUserLocalizer(userLocale)
+
+	info := &mailer.Maildata{
+		To:      []string{to},
+		Subject: locale.LocalizeDefaultMessage(l, msgSuspiciousLoginSubject),
+	}
+
+	displayName := username
+	if username == "" {
+		displayName = strings.Join(info.To, ",")
+	}
+
+	data := map[string]string{
+		"Email":       strings.Join(info.To, ","),
+		"DisplayName": displayName,
+		"Hello":       locale.LocalizeDefaultMessage(l, msgTemplateHello),
+		"Title":       locale.LocalizeDefaultMessage(l, msgSuspiciousLoginTitle),
+		"BodyText": locale.LocalizeWithConfig(l, &i18n.LocalizeConfig{
+			DefaultMessage: msgSuspiciousLoginBodyText,
+			TemplateData:   map[string]interface{}{"Attempts": attempts, "Minutes": minutes},
+		}),
+		"Quote": locale.LocalizeWithConfig(l, &i18n.LocalizeConfig{
+			DefaultMessage: msgSuspiciousLoginIPText,
+			TemplateData:   map[string]interface{}{"IP": ip},
+		}),
+		"Link":       fmt.Sprintf("%s/password/reset", siteURL),
+		"ButtonText": locale.LocalizeDefaultMessage(l, msgPwdRecoveryButtonText),
+	}
+
+	return a.sendEmailTemplate(model.EmailTemplateSuspiciousLogin, userLocale, data, info)
+}
+
+// SendQuestionAskedEmail notifies the product owner about the new question
+func (a *App) SendQuestionAskedEmail(to string, ownerName string, productName string, question string, link string, userLocale string) *model.AppErr {
+	l := locale.GetUserLocalizer(userLocale)
+
+	info := &mailer.Maildata{
+		To:      []string{to},
+		Subject: locale.LocalizeDefaultMessage(l, msgQuestionAskedSubject),
+	}
+
+	displayName := ownerName
+	if ownerName == "" {
+		displayName = strings.Join(info.To, ",")
+	}
+
+	data := map[string]string{
+		"DisplayName": displayName,
+		"Hello":       locale.LocalizeDefaultMessage(l, msgTemplateHello),
+		"Title":       locale.LocalizeDefaultMessage(l, msgQuestionAskedTitle),
+		"BodyText": locale.LocalizeWithConfig(l, &i18n.LocalizeConfig{
+			DefaultMessage: msgQuestionAskedBodyText,
+			TemplateData:   map[string]interface{}{"Product": productName},
+		}),
+		"Quote":      question,
+		"Link":       link,
+		"ButtonText": locale.LocalizeDefaultMessage(l, msgQuestionAskedButtonText),
+	}
+
+	return a.sendEmailTemplate(model.EmailTemplateQuestionAsked, userLocale, data, info)
+}
+
+// SendQuestionAnsweredEmail notifies the user that their question got an answer
+func (a *App) SendQuestionAnsweredEmail(to string, username string, productName string, answer string, link string, userLocale string) *model.AppErr {
+	l := locale.GetUserLocalizer(userLocale)
+
+	info := &mailer.Maildata{
+		To:      []string{to},
+		Subject: locale.LocalizeDefaultMessage(l, msgQuestionAnsweredSubject),
+	}
+
+	displayName := username
+	if username == "" {
+		displayName = strings.Join(info.To, ",")
+	}
+
+	data := map[string]string{
+		"DisplayName": displayName,
+		"Hello":       locale.LocalizeDefaultMessage(l, msgTemplateHello),
+		"Title":       locale.LocalizeDefaultMessage(l, msgQuestionAnsweredTitle),
+		"BodyText": locale.LocalizeWithConfig(l, &i18n.LocalizeConfig{
+			DefaultMessage: msgQuestionAnsweredBodyText,
+			TemplateData:   map[string]interface{}{"Product": productName},
+		}),
+		"Quote":      answer,
+		"Link":       link,
+		"ButtonText": locale.LocalizeDefaultMessage(l, msgQuestionAnsweredButtonText),
+	}
+
+	return a.sendEmailTemplate(model.EmailTemplateQuestionAnswered, userLocale, data, info)
+}
+
+// SendWishlistAlertEmail notifies the user about the back in stock and price drop changes of the wishlisted products
+func (a *App) SendWishlistAlertEmail(to string, username string, alerts []*model.WishlistAlert, siteURL string, userLocale string) *model.AppErr {
+	l := locale.GetUserLocalizer(userLocale)
+
+	info := &mailer.Maildata{
+		To:      []string{to},
+		Subject: locale.LocalizeDefaultMessage(l, msgWishlistAlertSubject),
+	}
+
+	displayName := username
+	if username == "" {
+		displayName = strings.Join(info.To, ",")
+	}
+
+	items := make([]map[string]string, 0, len(alerts))
+	for _, x := range alerts {
+		text := locale.LocalizeWithConfig(l, &i18n.LocalizeConfig{
+			DefaultMessage: msgWishlistAlertBackInStock,
+			TemplateData:   map[string]interface{}{"Price": toUSD(x.Price)},
+		})
+		if x.Kind == model.WishlistAlertPriceDrop && x.LastSeenPrice != nil {
+			text = locale.LocalizeWithConfig(l, &i18n.LocalizeConfig{
+				DefaultMessage: msgWishlistAlertPriceDrop,
+				TemplateData:   map[string]interface{}{"OldPrice": toUSD(*x.LastSeenPrice), "Price": toUSD(x.Price)},
+			})
+		}
+		items = append(items, map[string]string{
+			"Name": x.ProductName,
+			"Text": text,
+			"Link": fmt.Sprintf("%s/products/%d", siteURL, x.ProductID),
+		})
+	}
+
+	data := map[string]interface{}{
+		"DisplayName": displayName,
+		"Hello":       locale.LocalizeDefaultMessage(l, msgTemplateHello),
+		"Title":       locale.LocalizeDefaultMessage(l, msgWishlistAlertTitle),
+		"BodyText":    locale.LocalizeDefaultMessage(l, msgWishlistAlertBodyText),
+		"Items":       items,
+		"Link":        fmt.Sprintf("%s/wishlist", siteURL),
+		"ButtonText":  locale.LocalizeDefaultMessage(l, msgWishlistAlertButtonText),
+	}
+
+	return a.sendEmailTemplate(model.EmailTemplateWishlistAlert, userLocale, data, info)
+}
+
+// SendOrderConfirmationEmail sends the order summary with the pdf invoice attached
+func (a *App) SendOrderConfirmationEmail(to string, username string, o *model.Order, details []*model.OrderInfo, invoice []byte, siteURL string, userLocale string) *model.AppErr {
+	l := locale.GetUserLocalizer(userLocale)
+	orderData := map[string]interface{}{"OrderID": o.ID}
+
+	info := &mailer.Maildata{
+		To: []string{to},
+		Subject: locale.LocalizeWithConfig(l, &i18n.LocalizeConfig{
+			DefaultMessage: msgOrderConfirmationSubject,
+			TemplateData:   orderData,
+		}),
+		Attachments: []*mailer.Attachment{
+			{
+				Filename:    fmt.Sprintf("order-%d-invoice.pdf", o.ID),
+				ContentType: "application/pdf",
+				Content:     invoice,
+			},
+		},
+	}
+
+	displayName := username
+	if username == "" {
+		displayName = strings.Join(info.To, ",")
+	}
+
+	items := make([]map[string]string, 0, len(details))
+	for _, x := range details {
+		items = append(items, map[string]string{
+			"Name":     x.Product.Name,
+			"Quantity": fmt.Sprintf("%d", x.Quantity),
+			"Price":    toUSD(x.HistoryPrice * x.Quantity),
+		})
+	}
+
+	data := map[string]interface{}{
+		"OrderID":     o.ID,
+		"DisplayName": displayName,
+		"Hello":       locale.LocalizeDefaultMessage(l, msgTemplateHello),
+		"Title":       locale.LocalizeDefaultMessage(l, msgOrderConfirmationTitle),
+		"BodyText": locale.LocalizeWithConfig(l, &i18n.LocalizeConfig{
+			DefaultMessage: msgOrderConfirmationBodyText,
+			TemplateData:   orderData,
+		}),
+		"ProductText":    locale.LocalizeDefaultMessage(l, msgOrderProductText),
+		"QuantityText":   locale.LocalizeDefaultMessage(l, msgOrderQuantityText),
+		"PriceText":      locale.LocalizeDefaultMessage(l, msgOrderPriceText),
+		"SubtotalText":   locale.LocalizeDefaultMessage(l, msgOrderSubtotalText),
+		"TotalText":      locale.LocalizeDefaultMessage(l, msgOrderTotalText),
+		"AttachmentText": locale.LocalizeDefaultMessage(l, msgOrderConfirmationAttachmentText),
+		"Items":          items,
+		"Subtotal":       toUSD(o.Subtotal),
+		"Total":          toUSD(o.Total),
+		"Link":           fmt.Sprintf("%s/orders/%d", siteURL, o.ID),
+		"ButtonText":     locale.LocalizeDefaultMessage(l, msgOrderButtonText),
+	}
+
+	return a.sendEmailTemplate(model.EmailTemplateOrderConfirmed, userLocale, data, info)
+}
+
+// SendOrderShippedEmail notifies the user that the order was shipped, with the tracking details when available
+func (a *App) SendOrderShippedEmail(to string, username string, o *model.Order, siteURL string, userLocale string) *model.AppErr {
+	l := locale.GetUserLocalizer(userLocale)
+
+	link := fmt.Sprintf("%s/orders/%d", siteURL, o.ID)
+	buttonText := locale.LocalizeDefaultMessage(l, msgOrderButtonText)
+	if o.TrackingURL != nil && *o.TrackingURL != "" {
+		link = *o.TrackingURL
+		buttonText = locale.LocalizeDefaultMessage(l, msgOrderShippedButtonText)
+	}
+
+	trackingNumber := ""
+	if o.TrackingNumber != nil {
+		trackingNumber = *o.TrackingNumber
+	}
+
+	return a.sendOrderStatusEmail(model.EmailTemplateOrderShipped, to, username, o, userLocale, msgOrderShippedSubject, msgOrderShippedTitle, msgOrderShippedBodyText, map[string]string{
+		"TrackingText":   locale.LocalizeDefaultMessage(l, msgOrderShippedTracking),
+		"TrackingNumber": trackingNumber,
+		"Link":           link,
+		"ButtonText":     buttonText,
+	})
+}
+
+// SendOrderDeliveredEmail notifies the user that the order was delivered
+func (a *App) SendOrderDeliveredEmail(to string, username string, o *model.Order, siteURL string, userLocale string) *model.AppErr {
+	l := locale.GetUserLocalizer(userLocale)
+	return a.sendOrderStatusEmail(model.EmailTemplateOrderDelivered, to, username, o, userLocale, msgOrderDeliveredSubject, msgOrderDeliveredTitle, msgOrderDeliveredBodyText, map[string]string{
+		"Link":       fmt.Sprintf("%s/orders/%d", siteURL, o.ID),
+		"ButtonText": locale.LocalizeDefaultMessage(l, msgOrderButtonText),
+	})
+}
+
+// SendOrderCancelledEmail notifies the user that the order was cancelled
+func (a *App) SendOrderCancelledEmail(to string, username string, o *model.Order, siteURL string, userLocale string) *model.AppErr {
+	l := locale.GetUserLocalizer(userLocale)
+	return a.sendOrderStatusEmail(model.EmailTemplateOrderCancelled, to, username, o, userLocale, msgOrderCancelledSubject, msgOrderCancelledTitle, msgOrderCancelledBodyText, map[string]string{
+		"Link":       fmt.Sprintf("%s/orders/%d", siteURL, o.ID),
+		"ButtonText": locale.LocalizeDefaultMessage(l, msgOrderButtonText),
+	})
+}
+
+// SendOrderRefundedEmail notifies the user that the order was refunded
+func (a *App) SendOrderRefundedEmail(to string, username string, o *model.Order, siteURL string, userLocale string) *model.AppErr {
+	l := locale.GetUserLocalizer(userLocale)
+	return a.sendOrderStatusEmail(model.EmailTemplateOrderRefunded, to, username, o, userLocale, msgOrderRefundedSubject, msgOrderRefundedTitle, msgOrderRefundedBodyText, map[string]string{
+		"Link":       fmt.Sprintf("%s/orders/%d", siteURL, o.ID),
+		"ButtonText": locale.LocalizeDefaultMessage(l, msgOrderButtonText),
+	})
+}
+
+// sendOrderStatusEmail sends the order status template, extra holds the status specific template fields
+func (a *App) sendOrderStatusEmail(name string, to string, username string, o *model.Order, userLocale string, subject, title, bodyText *i18n.Message, extra map[string]string) *model.AppErr {
+	l := locale.GetUserLocalizer(userLocale)
+	orderData := map[string]interface{}{"OrderID": o.ID, "Total": toUSD(o.Total)}
+
+	info := &mailer.Maildata{
+		To: []string{to},
+		Subject: locale.LocalizeWithConfig(l, &i18n.LocalizeConfig{
+			DefaultMessage: subject,
+			TemplateData:   orderData,
+		}),
+	}
+
+	displayName := username
+	if username == "" {
+		displayName = strings.Join(info.To, ",")
+	}
+
+	data := map[string]string{
+		"OrderID":     fmt.Sprintf("%d", o.ID),
+		"DisplayName": displayName,
+		"Hello":       locale.LocalizeDefaultMessage(l, msgTemplateHello),
+		"Title":       locale.LocalizeDefaultMessage(l, title),
+		"BodyText": locale.LocalizeWithConfig(l, &i18n.LocalizeConfig{
+			DefaultMessage: bodyText,
+			TemplateData:   orderData,
+		}),
+	}
+	for k, v := range extra {
+		data[k] = v
+	}
+
+	return a.sendEmailTemplate(name, userLocale, data, info)
 }
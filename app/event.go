@@ -0,0 +1,98 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var msgDecodeEventPayload = &i18n.Message{ID: "app.event.decode_payload.app_error", Other: "could not decode event payload"}
+
+// StartEvents registers the in-process event subscribers and starts dispatching the outbox events
+func (a *App) StartEvents() {
+	ev := a.Srv().Events
+
+	ev.Subscribe(model.EventPasswordChanged, "password_changed_email", a.onPasswordChanged)
+	ev.Subscribe(model.EventOrderPlaced, "order_confirmation_email", a.onOrderPlaced)
+	ev.Subscribe(model.EventOrderShipped, "order_status_email", a.onOrderStatusChanged)
+	ev.Subscribe(model.EventOrderDelivered, "order_status_email", a.onOrderStatusChanged)
+	ev.Subscribe(model.EventOrderCancelled, "order_status_email", a.onOrderStatusChanged)
+	ev.Subscribe(model.EventOrderRefunded, "order_status_email", a.onOrderStatusChanged)
+	for _, eventType := range model.WebhookEventTypes {
+		ev.Subscribe(eventType, "webhooks", a.onWebhookEvent)
+	}
+
+	ev.Start(a.Cfg().EventSettings, a.Cfg().JobSettings.MaxAttempts)
+}
+
+func (a *App) runHandleEventJob(payload []byte) *model.AppErr {
+	var p eventJobPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return model.NewAppErr("runHandleEventJob", model.ErrInternal, locale.GetUserLocalizer("en"), msgDecodeJobPayload, http.StatusInternalServerError, nil)
+	}
+	return a.Srv().Events.Handle(p.EventID, p.Subscriber)
+}
+
+func decodeUserEventData(e *model.Event) (*model.UserEventData, *model.AppErr) {
+	var data model.UserEventData
+	if err := json.Unmarshal(e.Payload, &data); err != nil {
+		return nil, model.NewAppErr("decodeUserEventData", model.ErrInternal, locale.GetUserLocalizer("en"), msgDecodeEventPayload, http.StatusInternalServerError, nil)
+	}
+	return &data, nil
+}
+
+func (a *App) onPasswordChanged(e *model.Event) *model.AppErr {
+	data, err := decodeUserEventData(e)
+	if err != nil {
+		return err
+	}
+	return a.SendPasswordUpdatedEmail(data.Email, data.Username, a.SiteURL(), data.Locale)
+}
+
+func (a *App) onOrderPlaced(e *model.Event) *model.AppErr {
+	o, err := a.GetOrder(e.AggregateID)
+	if err != nil {
+		return err
+	}
+	details, err := a.GetOrderDetails(o.ID)
+	if err != nil {
+		return err
+	}
+	user, err := a.GetUserByID(o.UserID)
+	if err != nil {
+		return err
+	}
+
+	invoice, err := a.GenerateOrderDetailsPDF(o, details, user)
+	if err != nil {
+		return err
+	}
+	return a.SendOrderConfirmationEmail(user.Email, user.Username, o, details, invoice.Bytes(), a.SiteURL(), user.Locale)
+}
+
+// onOrderStatusChanged sends the current state of the order, not the one from the event payload
+func (a *App) onOrderStatusChanged(e *model.Event) *model.AppErr {
+	o, err := a.GetOrder(e.AggregateID)
+	if err != nil {
+		return err
+	}
+	user, err := a.GetUserByID(o.UserID)
+	if err != nil {
+		return err
+	}
+
+	switch e.Type {
+	case model.EventOrderShipped:
+		return a.SendOrderShippedEmail(user.Email, user.Username, o, a.SiteURL(), user.Locale)
+	case model.EventOrderDelivered:
+		return a.SendOrderDeliveredEmail(user.Email, user.Username, o, a.SiteURL(), user.Locale)
+	case model.EventOrderCancelled:
+		return a.SendOrderCancelledEmail(user.Email, user.Username, o, a.SiteURL(), user.Locale)
+	case model.EventOrderRefunded:
+		return a.SendOrderRefundedEmail(user.Email, user.Username, o, a.SiteURL(), user.Locale)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/config"
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/store"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/dankobgd/ecommerce-shop/zlog"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var msgNoEventSubscriber = &i18n.Message{ID: "app.event.no_subscriber.app_error", Other: "no subscriber registered for the event type"}
+
+// EventTypeAll subscribes the handler to every event type
+const EventTypeAll = "*"
+
+// EventHandler handles the dispatched domain event
+type EventHandler func(e *model.Event) *model.AppErr
+
+type eventJobPayload struct {
+	EventID    int64  `json:"event_id"`
+	Subscriber string `json:"subscriber"`
+}
+
+// EventDispatcher reads the events from the outbox and fans them out to the subscribers,
+// every subscriber gets its own job so failed deliveries are retried independently
+type EventDispatcher struct {
+	store       store.Store
+	settings    config.EventSettings
+	maxAttempts int
+	subscribers map[string]map[string]EventHandler
+	mu          sync.RWMutex
+	stop        chan struct{}
+	wg          sync.WaitGroup
+}
+
+// NewEventDispatcher creates the new event dispatcher
+func NewEventDispatcher(st store.Store) *EventDispatcher {
+	return &EventDispatcher{
+		store:       st,
+		subscribers: make(map[string]map[string]EventHandler),
+	}
+}
+
+// Subscribe registers the named handler for the event type, use EventTypeAll to receive all events
+func (d *EventDispatcher) Subscribe(eventType, name string, h EventHandler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.subscribers[eventType] == nil {
+		d.subscribers[eventType] = make(map[string]EventHandler)
+	}
+	d.subscribers[eventType][name] = h
+}
+
+func (d *EventDispatcher) subscribersFor(eventType string) []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	names := make([]string, 0)
+	for name := range d.subscribers[eventType] {
+		names = append(names, name)
+	}
+	for name := range d.subscribers[EventTypeAll] {
+		names = append(names, name)
+	}
+	return names
+}
+
+func (d *EventDispatcher) handler(eventType, name string) (EventHandler, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if h, ok := d.subscribers[eventType][name]; ok {
+		return h, true
+	}
+	h, ok := d.subscribers[EventTypeAll][name]
+	return h, ok
+}
+
+// Handle runs the subscriber for the stored event
+func (d *EventDispatcher) Handle(eventID int64, subscriber string) *model.AppErr {
+	e, err := d.store.Event().Get(eventID)
+	if err != nil {
+		return err
+	}
+	h, ok := d.handler(e.Type, subscriber)
+	if !ok {
+		return model.NewAppErr("EventDispatcher.Handle", model.ErrInternal, locale.GetUserLocalizer("en"), msgNoEventSubscriber, http.StatusInternalServerError, map[string]interface{}{"Subscriber": subscriber, "Type": e.Type})
+	}
+	return h(e)
+}
+
+// Start polls the outbox for the new events
+func (d *EventDispatcher) Start(settings config.EventSettings, maxAttempts int) {
+	d.settings = settings
+	d.maxAttempts = maxAttempts
+	d.stop = make(chan struct{})
+
+	d.wg.Add(1)
+	go d.run()
+}
+
+// Stop waits for the current batch to be dispatched and stops polling
+func (d *EventDispatcher) Stop() {
+	if d.stop == nil {
+		return
+	}
+	close(d.stop)
+	d.wg.Wait()
+}
+
+func (d *EventDispatcher) run() {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(time.Duration(d.settings.PollIntervalSeconds) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			// keep going while the batches are full so a backlog drains without waiting for the ticker
+			for {
+				if n := d.dispatch(); n < d.settings.BatchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// dispatch enqueues a job per subscriber for the next batch of events and returns the batch size,
+// the jobs are deduplicated by event and subscriber so a batch that gets dispatched twice is harmless
+func (d *EventDispatcher) dispatch() int {
+	events, err := d.store.Event().GetUndispatched(d.settings.BatchSize)
+	if err != nil {
+		zlog.Error("could not get undispatched events", zlog.Err(err))
+		return 0
+	}
+	if len(events) == 0 {
+		return 0
+	}
+
+	ids := make([]int64, 0, len(events))
+	for _, e := range events {
+		if err := d.enqueue(e); err != nil {
+			zlog.Error("could not dispatch event", zlog.Int64("event_id", e.ID), zlog.String("type", e.Type), zlog.Err(err))
+			break
+		}
+		ids = append(ids, e.ID)
+	}
+	if len(ids) == 0 {
+		return 0
+	}
+
+	if err := d.store.Event().MarkDispatched(ids); err != nil {
+		zlog.Error("could not mark events as dispatched", zlog.Err(err))
+		return 0
+	}
+	return len(ids)
+}
+
+func (d *EventDispatcher) enqueue(e *model.Event) error {
+	for _, name := range d.subscribersFor(e.Type) {
+		b, err := json.Marshal(&eventJobPayload{EventID: e.ID, Subscriber: name})
+		if err != nil {
+			return err
+		}
+
+		key := fmt.Sprintf("event:%d:%s", e.ID, name)
+		job := &model.Job{
+			Type:        model.JobTypeHandleEvent,
+			Payload:     b,
+			MaxAttempts: d.maxAttempts,
+			UniqueKey:   &key,
+		}
+		job.PreSave()
+
+		if _, err := d.store.Job().Save(job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/mailer"
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/dankobgd/ecommerce-shop/zlog"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgEncodeJobPayload = &i18n.Message{ID: "app.job.encode_payload.app_error", Other: "could not encode job payload"}
+	msgDecodeJobPayload = &i18n.Message{ID: "app.job.decode_payload.app_error", Other: "could not decode job payload"}
+	msgInvalidJobStatus = &i18n.Message{ID: "app.job.invalid_status.app_error", Other: "invalid job status"}
+	msgRetryJobNotDead  = &i18n.Message{ID: "app.job.retry.not_dead.app_error", Other: "only dead jobs can be retried"}
+)
+
+type emailJobPayload struct {
+	Template    string               `json:"template"`
+	Locale      string               `json:"locale"`
+	Filename    string               `json:"filename,omitempty"`
+	To          []string             `json:"to"`
+	Subject     string               `json:"subject"`
+	Data        interface{}          `json:"data"`
+	Attachments []*mailer.Attachment `json:"attachments,omitempty"`
+}
+
+type imageJobPayload struct {
+	PublicID string `json:"public_id"`
+}
+
+type tokenJobPayload struct {
+	Token string `json:"token"`
+}
+
+// EnqueueJob puts the new job in the queue to be run by the workers
+func (a *App) EnqueueJob(jobType string, payload interface{}) *model.AppErr {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return model.NewAppErr("EnqueueJob", model.ErrInternal, locale.GetUserLocalizer("en"), msgEncodeJobPayload, http.StatusInternalServerError, nil)
+	}
+
+	job := &model.Job{
+		Type:        jobType,
+		Payload:     b,
+		MaxAttempts: a.Cfg().JobSettings.MaxAttempts,
+	}
+	job.PreSave()
+
+	_, e := a.Srv().Store.Job().Save(job)
+	return e
+}
+
+// EnqueueDeleteImage queues the cloudinary image removal
+func (a *App) EnqueueDeleteImage(publicID string) *model.AppErr {
+	return a.EnqueueJob(model.JobTypeDeleteImage, &imageJobPayload{PublicID: publicID})
+}
+
+// StartJobs registers the job handlers and schedules, and starts the job workers
+func (a *App) StartJobs() {
+	js := a.Srv().Jobs
+
+	js.RegisterHandler(model.JobTypeSendEmail, a.runSendEmailJob)
+	js.RegisterHandler(model.JobTypeDeleteImage, a.runDeleteImageJob)
+	js.RegisterHandler(model.JobTypeDeleteToken, a.runDeleteTokenJob)
+	js.RegisterHandler(model.JobTypeWishlistAlerts, func(payload []byte) *model.AppErr {
+		return a.ProcessWishlistAlerts()
+	})
+	js.RegisterHandler(model.JobTypeCleanupJobs, func(payload []byte) *model.AppErr {
+		before := time.Now().AddDate(0, 0, -a.Cfg().JobSettings.RetentionDays)
+		return a.Srv().Store.Job().DeleteCompleted(before)
+	})
+	js.RegisterHandler(model.JobTypeHandleEvent, a.runHandleEventJob)
+	js.RegisterHandler(model.JobTypeDeliverWebhook, a.runDeliverWebhookJob)
+	js.RegisterHandler(model.JobTypeCleanupEvents, func(payload []byte) *model.AppErr {
+		before := time.Now().AddDate(0, 0, -a.Cfg().EventSettings.RetentionDays)
+		return a.Srv().Store.Event().DeleteDispatched(before)
+	})
+	js.RegisterHandler(model.JobTypeMaintenance, func(payload []byte) *model.AppErr {
+		a.RunMaintenance(false)
+		return nil
+	})
+
+	alertsSpec := fmt.Sprintf("@every %dm", a.Cfg().WishlistAlertSettings.IntervalMinutes)
+	if err := js.Schedule("wishlist_alerts", alertsSpec, model.JobTypeWishlistAlerts, nil); err != nil {
+		a.Log().Error("could not schedule job", zlog.Err(err))
+	}
+	if err := js.Schedule("cleanup_jobs", "@daily", model.JobTypeCleanupJobs, nil); err != nil {
+		a.Log().Error("could not schedule job", zlog.Err(err))
+	}
+	if err := js.Schedule("cleanup_events", "@daily", model.JobTypeCleanupEvents, nil); err != nil {
+		a.Log().Error("could not schedule job", zlog.Err(err))
+	}
+	if err := js.Schedule("maintenance", a.Cfg().MaintenanceSettings.Schedule, model.JobTypeMaintenance, nil); err != nil {
+		a.Log().Error("could not schedule job", zlog.Err(err))
+	}
+
+	js.Start(a.Cfg().JobSettings)
+}
+
+func (a *App) runSendEmailJob(payload []byte) *model.AppErr {
+	var p emailJobPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return model.NewAppErr("runSendEmailJob", model.ErrInternal, locale.GetUserLocalizer("en"), msgDecodeJobPayload, http.StatusInternalServerError, nil)
+	}
+	md := &mailer.Maildata{To: p.To, Subject: p.Subject, Attachments: p.Attachments}
+
+	// jobs queued before the templates could be overridden only have the file name
+	if p.Template == "" {
+		return mailer.SendEmailTemplate(a.Mailer(), p.Filename, p.Data, md)
+	}
+
+	et, err := a.GetEmailTemplate(p.Template, p.Locale)
+	if err != nil {
+		return err
+	}
+	return a.sendWithEmailTemplate(et, p.Data, md)
+}
+
+func (a *App) runDeleteImageJob(payload []byte) *model.AppErr {
+	var p imageJobPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return model.NewAppErr("runDeleteImageJob", model.ErrInternal, locale.GetUserLocalizer("en"), msgDecodeJobPayload, http.StatusInternalServerError, nil)
+	}
+	return a.DeleteImage(p.PublicID)
+}
+
+func (a *App) runDeleteTokenJob(payload []byte) *model.AppErr {
+	var p tokenJobPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return model.NewAppErr("runDeleteTokenJob", model.ErrInternal, locale.GetUserLocalizer("en"), msgDecodeJobPayload, http.StatusInternalServerError, nil)
+	}
+	return a.Srv().Store.Token().Delete(p.Token)
+}
+
+// deleteToken removes the used token, if that fails the removal is retried in the background
+func (a *App) deleteToken(token *model.Token) {
+	if err := a.Srv().Store.Token().Delete(token.Token); err != nil {
+		a.Log().Warn("could not delete token, retrying in background", zlog.Int64("user_id", token.UserID), zlog.String("token_type", token.Type), zlog.Err(err))
+		if err := a.EnqueueJob(model.JobTypeDeleteToken, &tokenJobPayload{Token: token.Token}); err != nil {
+			a.Log().Error("could not enqueue token removal", zlog.Int64("user_id", token.UserID), zlog.Err(err))
+		}
+	}
+}
+
+// GetJobs gets the jobs filtered by status and type
+func (a *App) GetJobs(status, jobType string, limit, offset int) ([]*model.Job, *model.AppErr) {
+	if status != "" && !model.IsValidJobStatus(status) {
+		return nil, model.NewAppErr("GetJobs", model.ErrInvalid, locale.GetUserLocalizer("en"), msgInvalidJobStatus, http.StatusBadRequest, nil)
+	}
+	return a.Srv().Store.Job().GetAll(status, jobType, limit, offset)
+}
+
+// GetJob gets the job by id
+func (a *App) GetJob(id int64) (*model.Job, *model.AppErr) {
+	return a.Srv().Store.Job().Get(id)
+}
+
+// GetJobStats gets the jobs count per type and status
+func (a *App) GetJobStats() ([]*model.JobStats, *model.AppErr) {
+	return a.Srv().Store.Job().GetStats()
+}
+
+// RetryJob puts the dead job back in the queue
+func (a *App) RetryJob(id int64) (*model.Job, *model.AppErr) {
+	job, err := a.Srv().Store.Job().Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != model.JobStatusDead {
+		return nil, model.NewAppErr("RetryJob", model.ErrConflict, locale.GetUserLocalizer("en"), msgRetryJobNotDead, http.StatusBadRequest, nil)
+	}
+	if err := a.Srv().Store.Job().Retry(id); err != nil {
+		return nil, err
+	}
+
+	retried, err := a.Srv().Store.Job().Get(id)
+	if err != nil {
+		return nil, err
+	}
+	a.audit(model.AuditActionUpdate, model.AuditResourceJob, id, job, retried)
+	return retried, nil
+}
@@ -0,0 +1,239 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/config"
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/store"
+	"github.com/dankobgd/ecommerce-shop/utils/cron"
+	"github.com/dankobgd/ecommerce-shop/zlog"
+)
+
+// JobHandler runs the job with the given json payload
+type JobHandler func(payload []byte) *model.AppErr
+
+type scheduledJob struct {
+	name     string
+	jobType  string
+	payload  []byte
+	schedule cron.Schedule
+	next     time.Time
+}
+
+// JobServer runs the queued background jobs and enqueues the scheduled ones
+type JobServer struct {
+	store     store.Store
+	settings  config.JobSettings
+	handlers  map[string]JobHandler
+	schedules []*scheduledJob
+	stop      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewJobServer creates the new job server
+func NewJobServer(st store.Store) *JobServer {
+	return &JobServer{
+		store:    st,
+		handlers: make(map[string]JobHandler),
+	}
+}
+
+// RegisterHandler sets the handler for the job type
+func (js *JobServer) RegisterHandler(jobType string, h JobHandler) {
+	js.handlers[jobType] = h
+}
+
+// Schedule enqueues the job of given type by the cron spec,
+// the scheduled runs are deduplicated so multiple app instances can run the scheduler
+func (js *JobServer) Schedule(name, spec, jobType string, payload interface{}) error {
+	sched, err := cron.Parse(spec)
+	if err != nil {
+		return fmt.Errorf("could not parse schedule %q for %s: %v", spec, name, err)
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("could not encode payload for %s: %v", name, err)
+	}
+
+	js.schedules = append(js.schedules, &scheduledJob{
+		name:     name,
+		jobType:  jobType,
+		payload:  b,
+		schedule: sched,
+		next:     sched.Next(time.Now()),
+	})
+	return nil
+}
+
+// Start runs the workers and the scheduler
+func (js *JobServer) Start(settings config.JobSettings) {
+	js.settings = settings
+	js.stop = make(chan struct{})
+
+	for i := 0; i < settings.Workers; i++ {
+		js.wg.Add(1)
+		go js.work()
+	}
+
+	js.wg.Add(1)
+	go js.runScheduler()
+}
+
+// Stop waits for the running jobs to finish and stops the workers
+func (js *JobServer) Stop() {
+	if js.stop == nil {
+		return
+	}
+	close(js.stop)
+	js.wg.Wait()
+}
+
+func (js *JobServer) pollInterval() time.Duration {
+	return time.Duration(js.settings.PollIntervalSeconds) * time.Second
+}
+
+func (js *JobServer) work() {
+	defer js.wg.Done()
+
+	for {
+		select {
+		case <-js.stop:
+			return
+		default:
+		}
+
+		if js.runNext() {
+			continue
+		}
+
+		select {
+		case <-js.stop:
+			return
+		case <-time.After(js.pollInterval()):
+		}
+	}
+}
+
+// runNext runs the next due job and returns false if the queue is empty
+func (js *JobServer) runNext() bool {
+	job, err := js.store.Job().ClaimNext()
+	if err != nil {
+		zlog.Error("could not claim job", zlog.Err(err))
+		return false
+	}
+	if job == nil {
+		return false
+	}
+
+	h, ok := js.handlers[job.Type]
+	var jobErr error
+	if !ok {
+		jobErr = fmt.Errorf("no handler registered for job type %s", job.Type)
+	} else {
+		jobErr = execute(h, job)
+	}
+
+	if jobErr == nil {
+		if err := js.store.Job().Complete(job.ID); err != nil {
+			zlog.Error("could not complete job", zlog.Int64("job_id", job.ID), zlog.Err(err))
+		}
+		return true
+	}
+
+	errMsg := strings.TrimSpace(jobErr.Error())
+	if !ok || job.Attempts >= job.MaxAttempts {
+		zlog.Error("job moved to dead letter", zlog.Int64("job_id", job.ID), zlog.String("type", job.Type), zlog.Int("attempts", job.Attempts), zlog.String("error", errMsg))
+		if err := js.store.Job().Kill(job.ID, errMsg); err != nil {
+			zlog.Error("could not kill job", zlog.Int64("job_id", job.ID), zlog.Err(err))
+		}
+		return true
+	}
+
+	runAt := time.Now().Add(js.backoff(job.Attempts))
+	zlog.Warn("job failed, retrying", zlog.Int64("job_id", job.ID), zlog.String("type", job.Type), zlog.Int("attempts", job.Attempts), zlog.String("error", errMsg))
+	if err := js.store.Job().Fail(job.ID, errMsg, runAt); err != nil {
+		zlog.Error("could not reschedule job", zlog.Int64("job_id", job.ID), zlog.Err(err))
+	}
+	return true
+}
+
+func execute(h JobHandler, job *model.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	if appErr := h(job.Payload); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
+// backoff doubles the delay for every attempt with a bit of jitter, up to the configured max
+func (js *JobServer) backoff(attempts int) time.Duration {
+	base := time.Duration(js.settings.BackoffSeconds) * time.Second
+	max := time.Duration(js.settings.MaxBackoffMinutes) * time.Minute
+
+	d := base
+	for i := 1; i < attempts && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d + time.Duration(rand.Int63n(int64(d)/10+1))
+}
+
+func (js *JobServer) runScheduler() {
+	defer js.wg.Done()
+
+	ticker := time.NewTicker(js.pollInterval())
+	defer ticker.Stop()
+
+	var lastRequeue time.Time
+	for {
+		select {
+		case <-js.stop:
+			return
+		case now := <-ticker.C:
+			js.enqueueDue(now)
+
+			if now.Sub(lastRequeue) >= time.Minute {
+				lastRequeue = now
+				before := now.Add(-time.Duration(js.settings.StaleLockMinutes) * time.Minute)
+				if err := js.store.Job().RequeueStale(before); err != nil {
+					zlog.Error("could not requeue stale jobs", zlog.Err(err))
+				}
+			}
+		}
+	}
+}
+
+func (js *JobServer) enqueueDue(now time.Time) {
+	for _, sj := range js.schedules {
+		if now.Before(sj.next) {
+			continue
+		}
+
+		key := fmt.Sprintf("cron:%s:%d", sj.name, sj.next.Unix())
+		job := &model.Job{
+			Type:        sj.jobType,
+			Payload:     sj.payload,
+			MaxAttempts: js.settings.MaxAttempts,
+			UniqueKey:   &key,
+		}
+		job.PreSave()
+
+		if _, err := js.store.Job().Save(job); err != nil {
+			zlog.Error("could not enqueue scheduled job", zlog.String("name", sj.name), zlog.Err(err))
+		}
+		sj.next = sj.schedule.Next(now)
+	}
+}
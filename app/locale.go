@@ -0,0 +1,31 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+)
+
+// LangQueryParam is the query param that overrides the request locale
+const LangQueryParam = "lang"
+
+// ResolveLocale picks the request locale from the lang query param, the authenticated user's locale
+// or the Accept-Language header, in that order, and falls back to english
+func (a *App) ResolveLocale(r *http.Request) string {
+	if lang := r.URL.Query().Get(LangQueryParam); locale.IsSupported(lang) {
+		return lang
+	}
+
+	// the token is verified again by the session middlewares, here it's only used for the locale
+	if token, err := VerifyToken(r, &a.Cfg().AuthSettings); err == nil {
+		if claims, ok := token.Claims.(*model.Claims); ok && token.Valid && locale.IsSupported(claims.Locale) {
+			return claims.Locale
+		}
+	}
+
+	if lang, ok := locale.MatchAcceptLanguage(r.Header.Get("Accept-Language")); ok {
+		return lang
+	}
+	return locale.DefaultLocale
+}
@@ -0,0 +1,143 @@
+package app
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/dankobgd/ecommerce-shop/zlog"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgLoginLocked      = &i18n.Message{ID: "app.login_attempt.locked.app_error", Other: "too many failed login attempts, try again later"}
+	msgEmailRateLimited = &i18n.Message{ID: "app.login_attempt.email_rate_limited.app_error", Other: "too many emails were requested, try again later"}
+)
+
+// maxLoginDelayShift caps the exponent of the progressive delay so it can't overflow
+const maxLoginDelayShift = 16
+
+func loginAccountKey(email string) string {
+	return "login:account:" + model.NormalizeEmail(email)
+}
+
+func loginIPKey(ip string) string {
+	return "login:ip:" + ip
+}
+
+func emailAccountKey(kind, email string) string {
+	return "email:" + kind + ":" + model.NormalizeEmail(email)
+}
+
+func emailIPKey(kind, ip string) string {
+	return "email:" + kind + ":ip:" + ip
+}
+
+func tooManyRequestsErr(op string, msg *i18n.Message, retryAfter time.Duration) *model.AppErr {
+	details := &model.RetryAfter{Seconds: int(math.Ceil(retryAfter.Seconds()))}
+	return model.NewAppErr(op, model.ErrTooManyRequests, locale.GetUserLocalizer("en"), msg, http.StatusTooManyRequests, details)
+}
+
+// CheckLoginAllowed rejects the login while the account or the ip is locked out or has to wait after the failed attempts
+func (a *App) CheckLoginAllowed(email, ip string) *model.AppErr {
+	for _, key := range []string{loginAccountKey(email), loginIPKey(ip)} {
+		left, err := a.Srv().Store.Attempt().LockedFor(key)
+		if err != nil {
+			return err
+		}
+		if left > 0 {
+			return tooManyRequestsErr("CheckLoginAllowed", msgLoginLocked, left)
+		}
+	}
+	return nil
+}
+
+// RecordLoginFailure counts the failed login for the account and the ip, every failure after the first few makes the account
+// wait twice as long before the next attempt and the account is locked out when it reaches the limit, the user is notified about it
+func (a *App) RecordLoginFailure(email, ip string, user *model.User) *model.AppErr {
+	settings := &a.Cfg().AuthSettings
+	window := time.Minute * time.Duration(settings.LoginAttemptWindowMinutes)
+	lockout := time.Minute * time.Duration(settings.LoginLockoutMinutes)
+
+	accountKey := loginAccountKey(email)
+	failures, _, err := a.Srv().Store.Attempt().Incr(accountKey, window)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case failures >= int64(settings.LoginMaxAttempts):
+		if err := a.lockAttempts(accountKey, lockout); err != nil {
+			return err
+		}
+		a.Log().Warn("too many failed logins, locking the account", zlog.String("email", email), zlog.String("ip", ip), zlog.Int64("failures", failures))
+		if user != nil {
+			if err := a.SendSuspiciousLoginEmail(user.Email, user.Username, ip, int(failures), settings.LoginLockoutMinutes, a.SiteURL(), user.Locale); err != nil {
+				a.Log().Error(err.Error(), zlog.Err(err))
+			}
+		}
+	case failures >= int64(settings.LoginDelayAfterAttempts):
+		shift := failures - int64(settings.LoginDelayAfterAttempts)
+		if shift > maxLoginDelayShift {
+			shift = maxLoginDelayShift
+		}
+		delay := (time.Second * time.Duration(settings.LoginDelaySeconds)) << uint(shift)
+		if delay > lockout {
+			delay = lockout
+		}
+		if err := a.Srv().Store.Attempt().Lock(accountKey, delay); err != nil {
+			return err
+		}
+	}
+
+	ipKey := loginIPKey(ip)
+	ipFailures, _, err := a.Srv().Store.Attempt().Incr(ipKey, window)
+	if err != nil {
+		return err
+	}
+	if ipFailures >= int64(settings.LoginMaxAttemptsPerIP) {
+		if err := a.lockAttempts(ipKey, lockout); err != nil {
+			return err
+		}
+		a.Log().Warn("too many failed logins, locking the ip", zlog.String("ip", ip), zlog.Int64("failures", ipFailures))
+	}
+	return nil
+}
+
+// ResetLoginFailures forgets the failed logins of the account after the user logged in
+func (a *App) ResetLoginFailures(email string) *model.AppErr {
+	return a.Srv().Store.Attempt().Reset(loginAccountKey(email))
+}
+
+// lockAttempts locks the key and starts counting the attempts from zero once the lock expires
+func (a *App) lockAttempts(key string, d time.Duration) *model.AppErr {
+	if err := a.Srv().Store.Attempt().Lock(key, d); err != nil {
+		return err
+	}
+	return a.Srv().Store.Attempt().Reset(key)
+}
+
+// CheckEmailRateLimit limits how many emails of the kind can be requested in an hour for the address and from the ip
+func (a *App) CheckEmailRateLimit(kind, email, ip string) *model.AppErr {
+	settings := &a.Cfg().AuthSettings
+	limits := []struct {
+		key string
+		max int
+	}{
+		{emailAccountKey(kind, email), settings.EmailRateLimitPerHour},
+		{emailIPKey(kind, ip), settings.EmailRateLimitPerIPPerHour},
+	}
+
+	for _, limit := range limits {
+		count, left, err := a.Srv().Store.Attempt().Incr(limit.key, time.Hour)
+		if err != nil {
+			return err
+		}
+		if count > int64(limit.max) {
+			return tooManyRequestsErr("CheckEmailRateLimit", msgEmailRateLimited, left)
+		}
+	}
+	return nil
+}
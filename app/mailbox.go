@@ -0,0 +1,55 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/dankobgd/ecommerce-shop/mailer"
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgMailboxUnavailable = &i18n.Message{ID: "app.mailbox.unavailable.app_error", Other: "mailbox is only available with the capture email transport"}
+	msgMailNotFound       = &i18n.Message{ID: "app.mailbox.not_found.app_error", Other: "captured email not found"}
+)
+
+func (a *App) captureTransport() (*mailer.CaptureTransport, *model.AppErr) {
+	t, ok := a.Mailer().(*mailer.CaptureTransport)
+	if !ok {
+		return nil, model.NewAppErr("captureTransport", model.ErrBadRequest, locale.GetUserLocalizer("en"), msgMailboxUnavailable, http.StatusBadRequest, nil)
+	}
+	return t, nil
+}
+
+// GetCapturedMails gets the emails captured by the capture transport, newest first
+func (a *App) GetCapturedMails() ([]*mailer.Message, *model.AppErr) {
+	t, err := a.captureTransport()
+	if err != nil {
+		return nil, err
+	}
+	return t.Messages(), nil
+}
+
+// GetCapturedMail gets the captured email by id
+func (a *App) GetCapturedMail(id int64) (*mailer.Message, *model.AppErr) {
+	t, err := a.captureTransport()
+	if err != nil {
+		return nil, err
+	}
+	m, ok := t.Message(id)
+	if !ok {
+		return nil, model.NewAppErr("GetCapturedMail", model.ErrNotFound, locale.GetUserLocalizer("en"), msgMailNotFound, http.StatusNotFound, nil)
+	}
+	return m, nil
+}
+
+// ClearCapturedMails removes all captured emails
+func (a *App) ClearCapturedMails() *model.AppErr {
+	t, err := a.captureTransport()
+	if err != nil {
+		return err
+	}
+	t.Reset()
+	return nil
+}
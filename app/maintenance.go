@@ -0,0 +1,106 @@
+package app
+
+import (
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/zlog"
+)
+
+// RunMaintenance purges the expired tokens, expires the abandoned pending orders
+// and removes the cloudinary images that are no longer referenced by any row.
+// Carts are kept on the client so there is nothing to clean up for them server side.
+// With dryRun set nothing is changed and the report only shows what would be removed
+func (a *App) RunMaintenance(dryRun bool) *model.MaintenanceReport {
+	now := time.Now()
+	settings := a.Cfg().MaintenanceSettings
+	report := &model.MaintenanceReport{DryRun: dryRun, OrphanedAssets: make([]string, 0), StartedAt: now}
+
+	addErr := func(step string, err *model.AppErr) {
+		a.Log().Error("maintenance step failed", zlog.String("step", step), zlog.Err(err))
+		report.Errors = append(report.Errors, step+": "+err.Message)
+	}
+
+	if dryRun {
+		n, err := a.Srv().Store.Token().CountExpired(now)
+		if err != nil {
+			addErr("tokens", err)
+		}
+		report.ExpiredTokens = n
+	} else {
+		n, err := a.Srv().Store.Token().DeleteExpired(now)
+		if err != nil {
+			addErr("tokens", err)
+		}
+		report.ExpiredTokens = n
+	}
+
+	ordersBefore := now.Add(-time.Duration(settings.PendingOrderExpiryHours) * time.Hour)
+	if dryRun {
+		n, err := a.Srv().Store.Order().CountAbandoned(ordersBefore)
+		if err != nil {
+			addErr("orders", err)
+		}
+		report.ExpiredOrders = n
+	} else {
+		n, err := a.Srv().Store.Order().ExpireAbandoned(ordersBefore)
+		if err != nil {
+			addErr("orders", err)
+		}
+		report.ExpiredOrders = n
+	}
+
+	orphans, err := a.findOrphanedImages(now.Add(-time.Duration(settings.OrphanedAssetGraceHours) * time.Hour))
+	if err != nil {
+		addErr("assets", err)
+	}
+	for _, publicID := range orphans {
+		if !dryRun {
+			if err := a.EnqueueDeleteImage(publicID); err != nil {
+				addErr("assets", err)
+				continue
+			}
+		}
+		report.OrphanedAssets = append(report.OrphanedAssets, publicID)
+	}
+
+	report.FinishedAt = time.Now()
+	a.Log().Info("maintenance completed",
+		zlog.Bool("dry_run", dryRun),
+		zlog.Int64("expired_tokens", report.ExpiredTokens),
+		zlog.Int64("expired_orders", report.ExpiredOrders),
+		zlog.Int("orphaned_assets", len(report.OrphanedAssets)),
+	)
+	return report
+}
+
+// findOrphanedImages returns the uploaded images that no row references,
+// images uploaded after the given time are skipped since their row might not be saved yet
+func (a *App) findOrphanedImages(uploadedBefore time.Time) ([]string, *model.AppErr) {
+	images, err := a.listCloudinaryImages()
+	if err != nil {
+		return nil, err
+	}
+	ids, err := a.Srv().Store.Asset().GetReferencedPublicIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	referenced := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		referenced[id] = true
+	}
+
+	orphans := make([]string, 0)
+	for _, img := range images {
+		if referenced[img.PublicID] {
+			continue
+		}
+		createdAt, e := time.Parse(time.RFC3339, img.CreatedAt)
+		if e != nil || createdAt.After(uploadedBefore) {
+			continue
+		}
+		orphans = append(orphans, img.PublicID)
+	}
+	return orphans, nil
+}
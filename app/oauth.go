@@ -0,0 +1,299 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/oauth"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/dankobgd/ecommerce-shop/utils/random"
+	"github.com/dankobgd/ecommerce-shop/zlog"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgOAuthProviderNotFound = &i18n.Message{ID: "app.oauth.provider_not_found.app_error", Other: "login provider is not supported"}
+	msgOAuthProvider         = &i18n.Message{ID: "app.oauth.provider.app_error", Other: "could not reach the login provider"}
+	msgOAuthInvalidState     = &i18n.Message{ID: "app.oauth.invalid_state.app_error", Other: "login request is invalid or has expired"}
+	msgOAuthExchange         = &i18n.Message{ID: "app.oauth.exchange.app_error", Other: "could not verify the login with the provider"}
+	msgOAuthNoEmail          = &i18n.Message{ID: "app.oauth.no_email.app_error", Other: "the provider account has no verified email"}
+	msgOAuthAlreadyLinked    = &i18n.Message{ID: "app.oauth.already_linked.app_error", Other: "the provider account is linked to another user"}
+	msgOAuthEmailNotVerified = &i18n.Message{ID: "app.oauth.email_not_verified.app_error", Other: "an account with this email already exists, log in with the password and link the provider from the settings"}
+)
+
+// oauthPasswordLength is the length of the random password of the users created by the oauth login,
+// the user can set the real password through the password reset
+const oauthPasswordLength = 32
+
+// GetOAuthProviders returns the names of the configured providers
+func (a *App) GetOAuthProviders() []string {
+	names := make([]string, 0, len(a.oauthProviders))
+	for name := range a.oauthProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StartOAuthLogin saves the login state and returns the state key and the provider url the user is redirected to,
+// the provider is linked to the user instead of logging in when the user id is set
+func (a *App) StartOAuthLogin(ctx context.Context, name string, userID int64) (string, string, *model.AppErr) {
+	l := locale.GetUserLocalizer("en")
+	provider, ok := a.OAuthProvider(name)
+	if !ok {
+		return "", "", model.NewAppErr("StartOAuthLogin", model.ErrNotFound, l, msgOAuthProviderNotFound, http.StatusNotFound, nil)
+	}
+
+	state := &model.OAuthState{
+		Provider: name,
+		Verifier: oauth.NewCodeVerifier(),
+		Nonce:    random.SecureToken(16),
+		UserID:   userID,
+	}
+	key := random.SecureToken(32)
+
+	url, err := provider.AuthCodeURL(ctx, key, state.Nonce, oauth.CodeChallenge(state.Verifier))
+	if err != nil {
+		a.Log().Error(err.Error(), zlog.Err(err), zlog.String("provider", name))
+		return "", "", model.NewAppErr("StartOAuthLogin", model.ErrInternal, l, msgOAuthProvider, http.StatusBadGateway, nil)
+	}
+
+	if err := a.Srv().Store.OAuthState().Save(key, state, a.oauthStateExpiry()); err != nil {
+		return "", "", err
+	}
+	return key, url, nil
+}
+
+// CompleteOAuthLogin exchanges the code for the user's profile and finds the user to log in. The user is found by the linked identity,
+// otherwise the identity is linked to the user with the same verified email or the new user is created.
+// The locked out account or ip can't log in with the provider either
+func (a *App) CompleteOAuthLogin(ctx context.Context, name, key, code, ip string) (*model.OAuthLogin, *model.AppErr) {
+	l := locale.GetUserLocalizer("en")
+	provider, ok := a.OAuthProvider(name)
+	if !ok {
+		return nil, model.NewAppErr("CompleteOAuthLogin", model.ErrNotFound, l, msgOAuthProviderNotFound, http.StatusNotFound, nil)
+	}
+
+	state, err := a.Srv().Store.OAuthState().Take(key)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil || state.Provider != name || code == "" {
+		return nil, model.NewAppErr("CompleteOAuthLogin", model.ErrInvalid, l, msgOAuthInvalidState, http.StatusBadRequest, nil)
+	}
+
+	profile, e := provider.Exchange(ctx, code, state.Verifier, state.Nonce)
+	if e != nil {
+		a.Log().Error(e.Error(), zlog.Err(e), zlog.String("provider", name))
+		return nil, model.NewAppErr("CompleteOAuthLogin", model.ErrUnauthenticated, l, msgOAuthExchange, http.StatusUnauthorized, nil)
+	}
+	profile.Email = model.NormalizeEmail(profile.Email)
+
+	identity, err := a.Srv().Store.UserIdentity().GetByProvider(name, profile.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	if state.UserID != 0 {
+		return a.linkOAuthIdentity(state.UserID, identity, name, profile)
+	}
+
+	var user *model.User
+	if identity != nil {
+		if err := a.Srv().Store.UserIdentity().UpdateLastLogin(identity.ID, emailPtr(profile.Email)); err != nil {
+			return nil, err
+		}
+		if user, err = a.GetUserByID(identity.UserID); err != nil {
+			return nil, err
+		}
+	} else if user, err = a.oauthUserByEmail(name, profile); err != nil {
+		return nil, err
+	}
+
+	if err := a.CheckLoginAllowed(user.Email, ip); err != nil {
+		return nil, err
+	}
+
+	enabled, err := a.IsTwoFactorEnabled(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		challenge, err := a.CreateLoginChallenge(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &model.OAuthLogin{Challenge: challenge}, nil
+	}
+	return &model.OAuthLogin{User: user}, nil
+}
+
+// linkOAuthIdentity links the provider account to the signed in user
+func (a *App) linkOAuthIdentity(userID int64, identity *model.UserIdentity, name string, profile *oauth.Profile) (*model.OAuthLogin, *model.AppErr) {
+	if identity != nil {
+		if identity.UserID != userID {
+			return nil, model.NewAppErr("linkOAuthIdentity", model.ErrConflict, locale.GetUserLocalizer("en"), msgOAuthAlreadyLinked, http.StatusConflict, nil)
+		}
+		return &model.OAuthLogin{Linked: true}, nil
+	}
+	if _, err := a.saveOAuthIdentity(userID, name, profile); err != nil {
+		return nil, err
+	}
+	return &model.OAuthLogin{Linked: true}, nil
+}
+
+// oauthUserByEmail links the identity to the user with the same email or creates the new user, only the emails verified
+// on both sides are linked so nobody can take over the account by signing up with someone else's email first
+func (a *App) oauthUserByEmail(name string, profile *oauth.Profile) (*model.User, *model.AppErr) {
+	l := locale.GetUserLocalizer("en")
+	if profile.Email == "" || !profile.EmailVerified {
+		return nil, model.NewAppErr("oauthUserByEmail", model.ErrInvalid, l, msgOAuthNoEmail, http.StatusBadRequest, nil)
+	}
+
+	user, err := a.Srv().Store.User().GetByEmail(profile.Email)
+	if err != nil && err.StatusCode != http.StatusNotFound {
+		return nil, err
+	}
+
+	if user == nil {
+		if user, err = a.createOAuthUser(profile); err != nil {
+			return nil, err
+		}
+	} else if !user.EmailVerified {
+		return nil, model.NewAppErr("oauthUserByEmail", model.ErrConflict, l, msgOAuthEmailNotVerified, http.StatusConflict, nil)
+	}
+
+	if _, err := a.saveOAuthIdentity(user.ID, name, profile); err != nil {
+		return nil, err
+	}
+	user.Sanitize(map[string]bool{})
+	return user, nil
+}
+
+func (a *App) createOAuthUser(profile *oauth.Profile) (*model.User, *model.AppErr) {
+	u := &model.User{
+		FirstName:     model.TruncateRunes(profile.FirstName, 64),
+		LastName:      model.TruncateRunes(profile.LastName, 64),
+		Username:      oauthUsername(profile),
+		Email:         profile.Email,
+		Password:      random.SecureToken(oauthPasswordLength),
+		EmailVerified: true,
+	}
+	u.PreSave()
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
+
+	user, err := a.Srv().Store.User().Save(u, userSignedUpEvent(u))
+	if err != nil {
+		a.Log().Error(err.Error(), zlog.Err(err))
+		return nil, err
+	}
+	a.audit(model.AuditActionCreate, model.AuditResourceUser, user.ID, nil, user)
+	return user, nil
+}
+
+func (a *App) saveOAuthIdentity(userID int64, name string, profile *oauth.Profile) (*model.UserIdentity, *model.AppErr) {
+	ui := &model.UserIdentity{
+		UserID:   userID,
+		Provider: name,
+		Subject:  profile.Subject,
+		Email:    emailPtr(profile.Email),
+	}
+	ui.PreSave()
+	identity, err := a.Srv().Store.UserIdentity().Save(ui)
+	if err != nil {
+		return nil, err
+	}
+	a.audit(model.AuditActionCreate, model.AuditResourceUserIdentity, fmt.Sprintf("%d/%s", userID, name), nil, identity)
+	return identity, nil
+}
+
+// GetUserIdentities gets the provider accounts linked to the user
+func (a *App) GetUserIdentities(userID int64) ([]*model.UserIdentity, *model.AppErr) {
+	return a.Srv().Store.UserIdentity().GetAll(userID)
+}
+
+// UnlinkUserIdentity unlinks the provider from the user
+func (a *App) UnlinkUserIdentity(userID int64, provider string) *model.AppErr {
+	if err := a.Srv().Store.UserIdentity().Delete(userID, provider); err != nil {
+		return err
+	}
+	a.audit(model.AuditActionDelete, model.AuditResourceUserIdentity, fmt.Sprintf("%d/%s", userID, provider), map[string]string{"provider": provider}, nil)
+	return nil
+}
+
+// AttachOAuthStateCookie binds the started login to the browser so the callback can't be completed in another one
+func (a *App) AttachOAuthStateCookie(w http.ResponseWriter, key string) {
+	expiry := a.oauthStateExpiry()
+	http.SetCookie(w, &http.Cookie{
+		Name:     model.OAuthStateCookieName,
+		Value:    key,
+		Expires:  time.Now().Add(expiry),
+		MaxAge:   int(expiry.Seconds()),
+		HttpOnly: true,
+		Secure:   a.IsProd(),
+		Path:     "/",
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// AttachTwoFactorChallengeCookie passes the challenge of the social login to the code form, the cookie lives as long as the challenge
+func (a *App) AttachTwoFactorChallengeCookie(w http.ResponseWriter, challenge *model.LoginChallenge) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     model.TwoFactorChallengeCookieName,
+		Value:    challenge.ChallengeToken,
+		Expires:  challenge.ExpiresAt,
+		MaxAge:   int(time.Until(challenge.ExpiresAt).Seconds()),
+		HttpOnly: true,
+		Secure:   a.IsProd(),
+		Path:     "/",
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// DeleteTwoFactorChallengeCookie deletes the challenge cookie
+func (a *App) DeleteTwoFactorChallengeCookie(w http.ResponseWriter) {
+	http.SetCookie(w, expireCookie(model.TwoFactorChallengeCookieName))
+}
+
+// DeleteOAuthStateCookie deletes the state cookie
+func (a *App) DeleteOAuthStateCookie(w http.ResponseWriter) {
+	http.SetCookie(w, expireCookie(model.OAuthStateCookieName))
+}
+
+func (a *App) oauthStateExpiry() time.Duration {
+	return time.Minute * time.Duration(a.Cfg().OAuthSettings.StateExpiryMinutes)
+}
+
+// oauthUsername picks the username from the provider's username or the email
+func oauthUsername(profile *oauth.Profile) string {
+	name := profile.Username
+	if name == "" {
+		name = strings.SplitN(profile.Email, "@", 2)[0]
+	}
+
+	var b strings.Builder
+	for _, r := range name {
+		if r == '.' || r == '-' || r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			b.WriteRune(r)
+		}
+	}
+	username := model.TruncateRunes(b.String(), 64)
+	if !model.IsValidUsername(username) {
+		return "user" + random.Numeric(6)
+	}
+	return username
+}
+
+func emailPtr(email string) *string {
+	if email == "" {
+		return nil
+	}
+	return &email
+}
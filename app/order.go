@@ -21,6 +21,11 @@ import (
 var (
 	msgGetAddressGeocodeResult = &i18n.Message{ID: "app.order.get_address_geocode_result.app_error", Other: "could not get geocoding result on given address"}
 	msgCreatePDF               = &i18n.Message{ID: "app.order.details_pdf.app_error", Other: "could not create order details pdf"}
+	msgCancelOrderStatus       = &i18n.Message{ID: "app.order.cancel_order.status.app_error", Other: "only the pending or paid orders that were not shipped can be cancelled"}
+	msgRefundOrderStatus       = &i18n.Message{ID: "app.order.refund_order.status.app_error", Other: "only the paid or cancelled orders can be refunded"}
+	msgRefundOrder             = &i18n.Message{ID: "app.order.refund_order.app_error", Other: "could not refund the order payment"}
+	msgShipOrderStatus         = &i18n.Message{ID: "app.order.ship_order.status.app_error", Other: "only the paid orders that were not shipped yet can be shipped"}
+	msgDeliverOrderStatus      = &i18n.Message{ID: "app.order.deliver_order.status.app_error", Other: "only the shipped orders that were not delivered yet can be delivered"}
 )
 
 // GetOrdersCount gets all users count
@@ -205,16 +210,9 @@ func (a *App) CreateOrder(userID int64, data *model.OrderRequestData) (*model.Or
 	o.PaymentIntentID = pi.ID
 	o.ReceiptURL = pi.Charges.Data[0].ReceiptURL
 
-	// save actual order
-	order, err := a.Srv().Store.Order().Save(o)
-	if err != nil {
-		return nil, err
-	}
-
 	orderDetails := make([]*model.OrderDetail, 0)
 	for i, p := range products {
 		detail := &model.OrderDetail{
-			OrderID:      order.ID,
 			ProductID:    p.ID,
 			Quantity:     data.Items[i].Quantity,
 			HistoryPrice: p.Price,
@@ -223,17 +221,23 @@ func (a *App) CreateOrder(userID int64, data *model.OrderRequestData) (*model.Or
 		orderDetails = append(orderDetails, detail)
 	}
 
-	if err := a.InsertOrderDetails(orderDetails); err != nil {
-		return nil, err
+	// mark the promo_code as used by the specific user
+	var pd *model.PromotionDetail
+	if data.PromoCode != nil && *data.PromoCode != "" {
+		pd = &model.PromotionDetail{UserID: userID, PromoCode: *data.PromoCode}
 	}
 
-	if data.PromoCode != nil && *data.PromoCode != "" {
-		// insert promo detail to mark the promo_code as used by the specific user
-		pd := &model.PromotionDetail{UserID: userID, PromoCode: *data.PromoCode}
-		if _, err := a.CreatePromotionDetail(pd); err != nil {
-			return nil, err
-		}
+	// save actual order
+	// the card is charged before the order is saved so the order is placed and paid at once,
+	// the details and the promo detail are saved in the same transaction so the events always see them
+	placed := model.NewEvent(model.EventOrderPlaced, model.AggregateOrder, 0, &model.OrderPlacedEventData{Order: o, Details: orderDetails})
+	paid := model.NewEvent(model.EventOrderPaid, model.AggregateOrder, 0, o)
+
+	order, err := a.Srv().Store.Order().Save(o, orderDetails, pd, placed, paid)
+	if err != nil {
+		return nil, err
 	}
+	a.audit(model.AuditActionCreate, model.AuditResourceOrder, order.ID, nil, order)
 
 	defer func() {
 		if (data.UseExistingBillingAddress == nil || data.UseExistingBillingAddress != nil && *data.UseExistingBillingAddress == false) && (data.SaveAddress != nil && *data.SaveAddress == true) {
@@ -256,9 +260,109 @@ func (a *App) GetOrders(limit, offset int) ([]*model.Order, *model.AppErr) {
 	return a.Srv().Store.Order().GetAll(limit, offset)
 }
 
-// UpdateOrder updates the order
+// UpdateOrder updates the order, the status changes are published as the order events
 func (a *App) UpdateOrder(id int64, o *model.Order) (*model.Order, *model.AppErr) {
-	return a.Srv().Store.Order().Update(id, o)
+	old, err := a.Srv().Store.Order().Get(id)
+	if err != nil {
+		return nil, err
+	}
+	o.ID = id
+	updated, err := a.Srv().Store.Order().Update(id, o, orderChangeEvents(old, o)...)
+	if err != nil {
+		return nil, err
+	}
+	a.audit(model.AuditActionUpdate, model.AuditResourceOrder, id, old, updated)
+	return updated, nil
+}
+
+// CancelOrder cancels the order that was not shipped yet, the paid order is refunded separately
+func (a *App) CancelOrder(id int64) (*model.Order, *model.AppErr) {
+	o, err := a.GetOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	if (o.Status != model.OrderStatusPending.String() && o.Status != model.OrderStatusSuccess.String()) || o.ShippedAt != nil {
+		return nil, model.NewAppErr("CancelOrder", model.ErrConflict, locale.GetUserLocalizer("en"), msgCancelOrderStatus, http.StatusConflict, nil)
+	}
+
+	o.Status = model.OrderStatusCancelled.String()
+	return a.UpdateOrder(id, o)
+}
+
+// ShipOrder marks the paid order as shipped with the optional tracking info
+func (a *App) ShipOrder(id int64, s *model.OrderShipment) (*model.Order, *model.AppErr) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	o, err := a.GetOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	if o.Status != model.OrderStatusSuccess.String() || o.ShippedAt != nil {
+		return nil, model.NewAppErr("ShipOrder", model.ErrConflict, locale.GetUserLocalizer("en"), msgShipOrderStatus, http.StatusConflict, nil)
+	}
+
+	now := time.Now()
+	o.ShippedAt = &now
+	o.TrackingNumber = s.TrackingNumber
+	o.TrackingURL = s.TrackingURL
+	return a.UpdateOrder(id, o)
+}
+
+// DeliverOrder marks the shipped order as delivered
+func (a *App) DeliverOrder(id int64) (*model.Order, *model.AppErr) {
+	o, err := a.GetOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	if o.Status != model.OrderStatusSuccess.String() || o.ShippedAt == nil || o.DeliveredAt != nil {
+		return nil, model.NewAppErr("DeliverOrder", model.ErrConflict, locale.GetUserLocalizer("en"), msgDeliverOrderStatus, http.StatusConflict, nil)
+	}
+
+	now := time.Now()
+	o.DeliveredAt = &now
+	return a.UpdateOrder(id, o)
+}
+
+// RefundOrder refunds the whole order payment
+func (a *App) RefundOrder(id int64) (*model.Order, *model.AppErr) {
+	o, err := a.GetOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	if o.Status != model.OrderStatusSuccess.String() && o.Status != model.OrderStatusCancelled.String() {
+		return nil, model.NewAppErr("RefundOrder", model.ErrConflict, locale.GetUserLocalizer("en"), msgRefundOrderStatus, http.StatusConflict, nil)
+	}
+
+	if _, e := a.PaymentProvider().Refund(o.PaymentIntentID, uint64(o.Total), "usd"); e != nil {
+		a.Log().Error(e.Error(), zlog.Err(e))
+		return nil, model.NewAppErr("RefundOrder", model.ErrInternal, locale.GetUserLocalizer("en"), msgRefundOrder, http.StatusInternalServerError, nil)
+	}
+
+	o.Status = model.OrderStatusRefunded.String()
+	return a.UpdateOrder(id, o)
+}
+
+func orderChangeEvents(old, o *model.Order) []*model.Event {
+	events := make([]*model.Event, 0)
+	if old.Status != o.Status {
+		switch o.Status {
+		case model.OrderStatusSuccess.String():
+			events = append(events, model.NewEvent(model.EventOrderPaid, model.AggregateOrder, o.ID, o))
+		case model.OrderStatusCancelled.String():
+			events = append(events, model.NewEvent(model.EventOrderCancelled, model.AggregateOrder, o.ID, o))
+		case model.OrderStatusRefunded.String():
+			events = append(events, model.NewEvent(model.EventOrderRefunded, model.AggregateOrder, o.ID, o))
+		}
+	}
+	if old.ShippedAt == nil && o.ShippedAt != nil {
+		events = append(events, model.NewEvent(model.EventOrderShipped, model.AggregateOrder, o.ID, o))
+	}
+	if old.DeliveredAt == nil && o.DeliveredAt != nil {
+		events = append(events, model.NewEvent(model.EventOrderDelivered, model.AggregateOrder, o.ID, o))
+	}
+	return events
 }
 
 // InsertOrderDetails inserts new order details
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgResourceForbidden     = &i18n.Message{ID: "app.policy.forbidden.app_error", Other: "you don't have access to this resource"}
+	msgAdminAccountForbidden = &i18n.Message{ID: "app.policy.admin_account.app_error", Other: "only the admins can change the admin accounts"}
+)
+
+// Authorize checks that the user owns the resource, the staff granted the permission and the admins can access the resources of other users,
+// the admins reaching into the other accounts need the two-factor authentication when it's required for them
+func (a *App) Authorize(ad *model.AccessData, ownerID int64, permission string) *model.AppErr {
+	if ad != nil && ad.UserID == ownerID {
+		return nil
+	}
+	if ad != nil && ad.HasPermission(permission) {
+		return a.CheckAdminTwoFactor(ad)
+	}
+	return model.NewAppErr("Authorize", model.ErrUnauthorized, locale.GetUserLocalizer("en"), msgResourceForbidden, http.StatusForbidden, nil)
+}
+
+// AuthorizeUser checks that the user can access the user account
+func (a *App) AuthorizeUser(ad *model.AccessData, uid int64, permission string) *model.AppErr {
+	return a.Authorize(ad, uid, permission)
+}
+
+// authorizeAccountChange checks that the admin account is changed only by the admins, the staff
+// granted the user permission can change the other accounts
+func (a *App) authorizeAccountChange(ad *model.AccessData, u *model.User) *model.AppErr {
+	if u.Role == model.AdminRole && (ad == nil || ad.Role != model.AdminRole) {
+		return model.NewAppErr("authorizeAccountChange", model.ErrUnauthorized, locale.GetUserLocalizer("en"), msgAdminAccountForbidden, http.StatusForbidden, nil)
+	}
+	return nil
+}
+
+// AuthorizeOrder gets the order if the user placed it or is granted the permission
+func (a *App) AuthorizeOrder(ad *model.AccessData, oid int64, permission string) (*model.Order, *model.AppErr) {
+	order, err := a.GetOrder(oid)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.Authorize(ad, order.UserID, permission); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// AuthorizeProductReview gets the review if the user wrote it or is granted the permission
+func (a *App) AuthorizeProductReview(ad *model.AccessData, pid, rid int64, permission string) (*model.ProductReview, *model.AppErr) {
+	rev, err := a.Srv().Store.ProductReview().Get(pid, rid)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.Authorize(ad, rev.UserID, permission); err != nil {
+		return nil, err
+	}
+	return rev, nil
+}
@@ -20,6 +20,10 @@ var (
 	msgErrPropsJSONFile    = &i18n.Message{ID: "app.product.get_product_properties.app_error", Other: "error parsing properties json file"}
 	msgProductImageFileErr = &i18n.Message{ID: "app.product.create_product_image.formfile.app_error", Other: "error parsing product image"}
 	msgProductImagesErr    = &i18n.Message{ID: "app.product.create_product_images.formfile.app_error", Other: "No images provided"}
+	msgReviewPurchaseReq   = &i18n.Message{ID: "app.product.create_product_review.purchase_required.app_error", Other: "only customers who purchased the product can review it"}
+	msgReviewNotApproved   = &i18n.Message{ID: "app.product.review.not_approved.app_error", Other: "review is not approved"}
+	msgReviewOwnVote       = &i18n.Message{ID: "app.product.vote_product_review.own_review.app_error", Other: "you cannot vote for your own review"}
+	msgReviewMediaFileErr  = &i18n.Message{ID: "app.product.create_review_media.formfile.app_error", Other: "error parsing review photo"}
 )
 
 // GetProductsCount gets all products count
@@ -49,7 +53,7 @@ func (a *App) CreateProduct(p *model.Product, thumbnailFH *multipart.FileHeader,
 	}
 	p.SetImageDetails(details)
 
-	product, pErr := a.Srv().Store.Product().Save(p)
+	product, pErr := a.Srv().Store.Product().Save(p, model.NewEvent(model.EventProductCreated, model.AggregateProduct, 0, p))
 	if pErr != nil {
 		a.Log().Error(pErr.Error(), zlog.Err(pErr))
 		return nil, pErr
@@ -110,6 +114,7 @@ func (a *App) CreateProduct(p *model.Product, thumbnailFH *multipart.FileHeader,
 		}
 	}
 
+	a.audit(model.AuditActionCreate, model.AuditResourceProduct, product.ID, nil, product)
 	return product, nil
 }
 
@@ -124,6 +129,7 @@ func (a *App) PatchProduct(pid int64, patch *model.ProductPatch, fh *multipart.F
 		return nil, err
 	}
 
+	before := *old
 	oldPublicID := old.ImagePublicID
 
 	if fh != nil {
@@ -146,14 +152,15 @@ func (a *App) PatchProduct(pid int64, patch *model.ProductPatch, fh *multipart.F
 	old.Patch(patch)
 	old.PreUpdate()
 
-	uprod, err := a.Srv().Store.Product().Update(pid, old)
+	uprod, err := a.Srv().Store.Product().Update(pid, old, model.NewEvent(model.EventProductUpdated, model.AggregateProduct, pid, old))
 	if err != nil {
 		return nil, err
 	}
+	a.audit(model.AuditActionUpdate, model.AuditResourceProduct, pid, &before, uprod)
 
 	defer func() {
 		if oldPublicID != "" {
-			if err := a.DeleteImage(oldPublicID); err != nil {
+			if err := a.EnqueueDeleteImage(oldPublicID); err != nil {
 				a.Log().Error(err.Error(), zlog.Err(err))
 			}
 		}
@@ -169,14 +176,15 @@ func (a *App) DeleteProduct(pid int64) *model.AppErr {
 		return e
 	}
 
-	err := a.Srv().Store.Product().Delete(pid)
+	err := a.Srv().Store.Product().Delete(pid, model.NewEvent(model.EventProductDeleted, model.AggregateProduct, pid, old))
 	if err != nil {
 		return err
 	}
+	a.audit(model.AuditActionDelete, model.AuditResourceProduct, pid, old, nil)
 
 	defer func() {
 		if old.ImageURL != "" {
-			if err := a.DeleteImage(old.ImagePublicID); err != nil {
+			if err := a.EnqueueDeleteImage(old.ImagePublicID); err != nil {
 				a.Log().Error(err.Error(), zlog.Err(err))
 			}
 		}
@@ -217,7 +225,18 @@ func (a *App) GetProductLatestPricing(pid int64) (*model.ProductPricing, *model.
 
 // DeleteProducts creates the discount
 func (a *App) DeleteProducts(ids []int) *model.AppErr {
-	return a.Srv().Store.Product().BulkDelete(ids)
+	events := make([]*model.Event, 0, len(ids))
+	for _, id := range ids {
+		events = append(events, model.NewEvent(model.EventProductDeleted, model.AggregateProduct, int64(id), map[string]int{"id": id}))
+	}
+	before := a.auditSnapshots(ids, func(id int64) (interface{}, *model.AppErr) { return a.Srv().Store.Product().Get(id) })
+	if err := a.Srv().Store.Product().BulkDelete(ids, events...); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		a.audit(model.AuditActionDelete, model.AuditResourceProduct, id, before[id], nil)
+	}
+	return nil
 }
 
 // AddProductPricing adds the new pricing (updates the prev val and creates 2 new entries)
@@ -238,7 +257,15 @@ func (a *App) AddProductPricing(pricing *model.ProductPricing) (*model.ProductPr
 
 	pricing.OriginalPrice = old.Price
 
-	discount, err := a.InsertProductPricing(pricing)
+	e := model.NewEvent(model.EventProductPriceChanged, model.AggregateProduct, pricing.ProductID, &model.PriceChangedEventData{
+		ProductID:  pricing.ProductID,
+		OldPrice:   old.Price,
+		NewPrice:   pricing.Price,
+		SaleStarts: pricing.SaleStarts,
+		SaleEnds:   pricing.SaleEnds,
+	})
+
+	discount, err := a.InsertProductPricing(pricing, e)
 	if err != nil {
 		return nil, err
 	}
@@ -254,15 +281,16 @@ func (a *App) AddProductPricing(pricing *model.ProductPricing) (*model.ProductPr
 	if _, err := a.InsertProductPricing(pricingAfter); err != nil {
 		return nil, err
 	}
+	a.audit(model.AuditActionCreate, model.AuditResourceProductPricing, pricing.ProductID, nil, pricing)
 	return pricing, nil
 }
 
 // InsertProductPricing creates the discount
-func (a *App) InsertProductPricing(pricing *model.ProductPricing) (*model.ProductPricing, *model.AppErr) {
+func (a *App) InsertProductPricing(pricing *model.ProductPricing, events ...*model.Event) (*model.ProductPricing, *model.AppErr) {
 	if err := pricing.Validate(); err != nil {
 		return nil, err
 	}
-	return a.Srv().Store.Product().InsertPricing(pricing)
+	return a.Srv().Store.Product().InsertPricing(pricing, events...)
 }
 
 // UpdateProductPricing updates the discount
@@ -272,7 +300,12 @@ func (a *App) UpdateProductPricing(pricing *model.ProductPricing) (*model.Produc
 
 // CreateProductTag gets all tags for the product
 func (a *App) CreateProductTag(pid int64, pt *model.ProductTag) (*model.ProductTag, *model.AppErr) {
-	return a.Srv().Store.ProductTag().Save(pid, pt)
+	tag, err := a.Srv().Store.ProductTag().Save(pid, pt)
+	if err != nil {
+		return nil, err
+	}
+	a.audit(model.AuditActionCreate, model.AuditResourceProductTag, pid, nil, tag)
+	return tag, nil
 }
 
 // GetProductTags gets all tags for the product
@@ -282,7 +315,13 @@ func (a *App) GetProductTags(pid int64) ([]*model.ProductTag, *model.AppErr) {
 
 // ReplaceProductTags patches the product tag
 func (a *App) ReplaceProductTags(pid int64, tagIDs []int) ([]*model.ProductTag, *model.AppErr) {
-	return a.Srv().Store.ProductTag().Replace(pid, tagIDs)
+	old, _ := a.Srv().Store.ProductTag().GetAll(pid)
+	tags, err := a.Srv().Store.ProductTag().Replace(pid, tagIDs)
+	if err != nil {
+		return nil, err
+	}
+	a.audit(model.AuditActionUpdate, model.AuditResourceProductTag, pid, old, tags)
+	return tags, nil
 }
 
 // PatchProductTag patches the product tag
@@ -292,23 +331,37 @@ func (a *App) PatchProductTag(pid, tid int64, patch *model.ProductTagPatch) (*mo
 		return nil, err
 	}
 
+	before := *old
 	old.Patch(patch)
 	utag, err := a.Srv().Store.ProductTag().Update(pid, tid, old)
 	if err != nil {
 		return nil, err
 	}
+	a.audit(model.AuditActionUpdate, model.AuditResourceProductTag, auditID(pid, tid), &before, utag)
 
 	return utag, nil
 }
 
 // DeleteProductTag gets all tags for the product
 func (a *App) DeleteProductTag(pid, tid int64) *model.AppErr {
-	return a.Srv().Store.ProductTag().Delete(pid, tid)
+	old, _ := a.Srv().Store.ProductTag().Get(pid, tid)
+	if err := a.Srv().Store.ProductTag().Delete(pid, tid); err != nil {
+		return err
+	}
+	a.audit(model.AuditActionDelete, model.AuditResourceProductTag, auditID(pid, tid), old, nil)
+	return nil
 }
 
 // DeleteProductTags bulk deletes tags
 func (a *App) DeleteProductTags(pid int64, ids []int) *model.AppErr {
-	return a.Srv().Store.ProductTag().BulkDelete(pid, ids)
+	before := a.auditSnapshots(ids, func(id int64) (interface{}, *model.AppErr) { return a.Srv().Store.ProductTag().Get(pid, id) })
+	if err := a.Srv().Store.ProductTag().BulkDelete(pid, ids); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		a.audit(model.AuditActionDelete, model.AuditResourceProductTag, auditID(pid, int64(id)), before[id], nil)
+	}
+	return nil
 }
 
 // CreateProductImages bulk inserts product images
@@ -358,6 +411,7 @@ func (a *App) CreateProductImages(pid int64, imagesFHs []*multipart.FileHeader)
 		return err
 	}
 
+	a.audit(model.AuditActionCreate, model.AuditResourceProductImage, pid, nil, images)
 	return nil
 }
 
@@ -384,7 +438,12 @@ func (a *App) CreateProductImage(pid int64, img *model.ProductImage, fh *multipa
 	}
 	img.SetImageDetails(details)
 
-	return a.Srv().Store.ProductImage().Save(pid, img)
+	uimg, sErr := a.Srv().Store.ProductImage().Save(pid, img)
+	if sErr != nil {
+		return nil, sErr
+	}
+	a.audit(model.AuditActionCreate, model.AuditResourceProductImage, auditID(pid, *uimg.ID), nil, uimg)
+	return uimg, nil
 }
 
 // GetProductImages gets all images for the product
@@ -394,53 +453,368 @@ func (a *App) GetProductImages(pid int64) ([]*model.ProductImage, *model.AppErr)
 
 // CreateProductReview creates new review for the product
 func (a *App) CreateProductReview(pid int64, rev *model.ProductReview) (*model.ProductReview, *model.AppErr) {
+	verified, err := a.Srv().Store.ProductReview().IsVerifiedPurchase(rev.UserID, pid)
+	if err != nil {
+		return nil, err
+	}
+	if !verified && a.Cfg().ReviewSettings.PurchaseRequired {
+		return nil, model.NewAppErr("CreateProductReview", model.ErrUnauthorized, locale.GetUserLocalizer("en"), msgReviewPurchaseReq, http.StatusForbidden, nil)
+	}
+
+	rev.IsVerifiedPurchase = verified
+	rev.Status = a.initialReviewStatus()
 	rev.PreSave()
 	if err := rev.Validate(); err != nil {
 		return nil, err
 	}
 
-	return a.Srv().Store.ProductReview().Save(pid, rev)
+	review, err := a.Srv().Store.ProductReview().Save(pid, rev)
+	if err != nil {
+		return nil, err
+	}
+	a.audit(model.AuditActionCreate, model.AuditResourceProductReview, auditID(pid, review.ID), nil, review)
+
+	a.refreshProductRating(pid)
+	return review, nil
 }
 
-// GetProductReviews gets all reviews for the product
-func (a *App) GetProductReviews(pid int64) ([]*model.ProductReview, *model.AppErr) {
-	return a.Srv().Store.ProductReview().GetAll(pid)
+// GetProductReviews gets all approved reviews for the product
+func (a *App) GetProductReviews(pid int64, sort string) ([]*model.ProductReview, *model.AppErr) {
+	reviews, err := a.Srv().Store.ProductReview().GetAll(pid, sort)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.attachReviewMedia(reviews...); err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// GetReviewsByStatus gets the reviews of all products with the given moderation status
+func (a *App) GetReviewsByStatus(status string, limit, offset int) ([]*model.ProductReview, *model.AppErr) {
+	reviews, err := a.Srv().Store.ProductReview().GetAllByStatus(status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.attachReviewMedia(reviews...); err != nil {
+		return nil, err
+	}
+	return reviews, nil
 }
 
 // GetProductReview gets all reviews for the product
 func (a *App) GetProductReview(pid, rid int64) (*model.ProductReview, *model.AppErr) {
-	return a.Srv().Store.ProductReview().Get(pid, rid)
+	rev, err := a.Srv().Store.ProductReview().Get(pid, rid)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.attachReviewMedia(rev); err != nil {
+		return nil, err
+	}
+	return rev, nil
 }
 
-// PatchProductReview patches the product review
-func (a *App) PatchProductReview(pid, rid int64, patch *model.ProductReviewPatch) (*model.ProductReview, *model.AppErr) {
+// GetVisibleProductReview gets the review if it's approved, the author and the moderators can also see the unmoderated review
+func (a *App) GetVisibleProductReview(ad *model.AccessData, pid, rid int64) (*model.ProductReview, *model.AppErr) {
+	rev, err := a.GetProductReview(pid, rid)
+	if err != nil {
+		return nil, err
+	}
+	if rev.Status != model.ReviewStatusApproved {
+		if err := a.Authorize(ad, rev.UserID, model.PermissionReviewModerate); err != nil {
+			return nil, model.NewAppErr("GetVisibleProductReview", model.ErrNotFound, locale.GetUserLocalizer("en"), msgReviewNotApproved, http.StatusNotFound, nil)
+		}
+	}
+	return rev, nil
+}
+
+// PatchProductReview patches the product review, the author's edit is moderated again
+func (a *App) PatchProductReview(ad *model.AccessData, pid, rid int64, patch *model.ProductReviewPatch) (*model.ProductReview, *model.AppErr) {
 	if err := patch.Validate(); err != nil {
 		return nil, err
 	}
 
-	old, err := a.Srv().Store.ProductReview().Get(pid, rid)
+	if _, err := a.AuthorizeProductReview(ad, pid, rid, model.PermissionReviewModerate); err != nil {
+		return nil, err
+	}
+	old, err := a.GetProductReview(pid, rid)
 	if err != nil {
 		return nil, err
 	}
 
+	before := *old
 	old.Patch(patch)
+	if ad.UserID == old.UserID {
+		old.Status = a.initialReviewStatus()
+	}
 	old.PreUpdate()
 	urev, err := a.Srv().Store.ProductReview().Update(pid, rid, old)
 	if err != nil {
 		return nil, err
 	}
+	a.audit(model.AuditActionUpdate, model.AuditResourceProductReview, auditID(pid, rid), &before, urev)
 
+	a.refreshProductRating(pid)
 	return urev, nil
 }
 
 // DeleteProductReview deletes the product review
-func (a *App) DeleteProductReview(pid, rid int64) *model.AppErr {
-	return a.Srv().Store.ProductReview().Delete(pid, rid)
+func (a *App) DeleteProductReview(ad *model.AccessData, pid, rid int64) *model.AppErr {
+	old, err := a.AuthorizeProductReview(ad, pid, rid, model.PermissionReviewModerate)
+	if err != nil {
+		return err
+	}
+
+	media, e := a.Srv().Store.ProductReviewMedia().GetAll(rid)
+	if e != nil {
+		return e
+	}
+
+	if err := a.Srv().Store.ProductReview().Delete(pid, rid); err != nil {
+		return err
+	}
+	a.audit(model.AuditActionDelete, model.AuditResourceProductReview, auditID(pid, rid), old, nil)
+
+	defer a.deleteReviewMediaImages(media)
+
+	a.refreshProductRating(pid)
+	return nil
 }
 
 // DeleteProductReviews bulk deletes reviews
 func (a *App) DeleteProductReviews(pid int64, ids []int) *model.AppErr {
-	return a.Srv().Store.ProductReview().BulkDelete(pid, ids)
+	rids := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		rids = append(rids, int64(id))
+	}
+	media, e := a.Srv().Store.ProductReviewMedia().GetAllForProductReviews(pid, rids)
+	if e != nil {
+		return e
+	}
+
+	before := a.auditSnapshots(ids, func(id int64) (interface{}, *model.AppErr) { return a.Srv().Store.ProductReview().Get(pid, id) })
+	if err := a.Srv().Store.ProductReview().BulkDelete(pid, ids); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		a.audit(model.AuditActionDelete, model.AuditResourceProductReview, auditID(pid, int64(id)), before[id], nil)
+	}
+
+	defer a.deleteReviewMediaImages(media)
+
+	a.refreshProductRating(pid)
+	return nil
+}
+
+// CreateProductReviewMedia uploads the photos and attaches them to the review, only the author and the moderators can add them
+func (a *App) CreateProductReviewMedia(ad *model.AccessData, pid, rid int64, fhs []*multipart.FileHeader) ([]*model.ProductReviewMedia, *model.AppErr) {
+	rev, err := a.AuthorizeProductReview(ad, pid, rid, model.PermissionReviewModerate)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.attachReviewMedia(rev); err != nil {
+		return nil, err
+	}
+	if err := model.ValidateReviewMediaFiles(fhs, len(rev.Media)); err != nil {
+		return nil, err
+	}
+
+	media := make([]*model.ProductReviewMedia, 0)
+	for _, fh := range fhs {
+		b, err := readFormFile(fh)
+		if err != nil {
+			a.deleteReviewMediaImages(media)
+			return nil, model.NewAppErr("CreateProductReviewMedia", model.ErrInternal, locale.GetUserLocalizer("en"), msgReviewMediaFileErr, http.StatusInternalServerError, nil)
+		}
+
+		details, uErr := a.UploadImage(bytes.NewBuffer(b), fh.Filename)
+		if uErr != nil {
+			a.deleteReviewMediaImages(media)
+			return nil, uErr
+		}
+
+		m := &model.ProductReviewMedia{ReviewID: rid}
+		m.SetImageDetails(details)
+		m.PreSave()
+		media = append(media, m)
+	}
+
+	if err := a.Srv().Store.ProductReviewMedia().BulkInsert(media); err != nil {
+		a.Log().Error(err.Error(), zlog.Err(err))
+		a.deleteReviewMediaImages(media)
+		return nil, err
+	}
+	a.audit(model.AuditActionCreate, model.AuditResourceReviewMedia, auditID(pid, rid), nil, media)
+
+	return a.Srv().Store.ProductReviewMedia().GetAll(rid)
+}
+
+// DeleteProductReviewMedia deletes the photo from the review, only the author and the moderators can delete it
+func (a *App) DeleteProductReviewMedia(ad *model.AccessData, pid, rid, mid int64) *model.AppErr {
+	if _, err := a.AuthorizeProductReview(ad, pid, rid, model.PermissionReviewModerate); err != nil {
+		return err
+	}
+
+	old, err := a.Srv().Store.ProductReviewMedia().Get(rid, mid)
+	if err != nil {
+		return err
+	}
+	if err := a.Srv().Store.ProductReviewMedia().Delete(rid, mid); err != nil {
+		return err
+	}
+
+	defer a.deleteReviewMediaImages([]*model.ProductReviewMedia{old})
+
+	a.audit(model.AuditActionDelete, model.AuditResourceReviewMedia, auditID(pid, rid, mid), old, nil)
+	return nil
+}
+
+// readFormFile reads the whole uploaded file and closes it
+func readFormFile(fh *multipart.FileHeader) ([]byte, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
+
+func (a *App) attachReviewMedia(reviews ...*model.ProductReview) *model.AppErr {
+	rids := make([]int64, 0, len(reviews))
+	for _, rev := range reviews {
+		rev.Media = make([]*model.ProductReviewMedia, 0)
+		rids = append(rids, rev.ID)
+	}
+
+	media, err := a.Srv().Store.ProductReviewMedia().GetAllForReviews(rids)
+	if err != nil {
+		return err
+	}
+
+	byReview := make(map[int64][]*model.ProductReviewMedia)
+	for _, m := range media {
+		byReview[m.ReviewID] = append(byReview[m.ReviewID], m)
+	}
+	for _, rev := range reviews {
+		if m, ok := byReview[rev.ID]; ok {
+			rev.Media = m
+		}
+	}
+	return nil
+}
+
+func (a *App) deleteReviewMediaImages(media []*model.ProductReviewMedia) {
+	for _, m := range media {
+		if m.PublicID != "" {
+			if err := a.EnqueueDeleteImage(m.PublicID); err != nil {
+				a.Log().Error(err.Error(), zlog.Err(err))
+			}
+		}
+	}
+}
+
+// ModerateProductReview sets the review moderation status
+func (a *App) ModerateProductReview(pid, rid int64, st *model.ProductReviewStatus) (*model.ProductReview, *model.AppErr) {
+	if err := st.Validate(); err != nil {
+		return nil, err
+	}
+
+	rev, err := a.GetProductReview(pid, rid)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.Srv().Store.ProductReview().UpdateStatus(pid, rid, st.Status); err != nil {
+		return nil, err
+	}
+
+	before := *rev
+	rev.Status = st.Status
+	a.audit(model.AuditActionUpdate, model.AuditResourceProductReview, auditID(pid, rid), &before, rev)
+	a.refreshProductRating(pid)
+	return rev, nil
+}
+
+// VoteProductReview marks the approved review as helpful for the user
+func (a *App) VoteProductReview(pid, rid, uid int64) *model.AppErr {
+	rev, err := a.getApprovedReview(pid, rid)
+	if err != nil {
+		return err
+	}
+	if rev.UserID == uid {
+		return model.NewAppErr("VoteProductReview", model.ErrConflict, locale.GetUserLocalizer("en"), msgReviewOwnVote, http.StatusBadRequest, nil)
+	}
+
+	if err := a.Srv().Store.ProductReview().SaveVote(rid, uid); err != nil {
+		return err
+	}
+	a.audit(model.AuditActionCreate, model.AuditResourceReviewVote, auditID(pid, rid, uid), nil, nil)
+	return nil
+}
+
+// DeleteProductReviewVote removes the users helpful vote from the review
+func (a *App) DeleteProductReviewVote(pid, rid, uid int64) *model.AppErr {
+	if _, err := a.GetProductReview(pid, rid); err != nil {
+		return err
+	}
+	if err := a.Srv().Store.ProductReview().DeleteVote(rid, uid); err != nil {
+		return err
+	}
+	a.audit(model.AuditActionDelete, model.AuditResourceReviewVote, auditID(pid, rid, uid), nil, nil)
+	return nil
+}
+
+// ReportProductReview reports the approved review for abuse
+func (a *App) ReportProductReview(pid, rid int64, report *model.ProductReviewReport) (*model.ProductReviewReport, *model.AppErr) {
+	if _, err := a.getApprovedReview(pid, rid); err != nil {
+		return nil, err
+	}
+
+	report.ReviewID = rid
+	report.PreSave()
+	if err := report.Validate(); err != nil {
+		return nil, err
+	}
+
+	rr, err := a.Srv().Store.ProductReview().SaveReport(report)
+	if err != nil {
+		return nil, err
+	}
+	a.audit(model.AuditActionCreate, model.AuditResourceReviewReport, auditID(pid, rid, rr.ID), nil, rr)
+	return rr, nil
+}
+
+// GetProductReviewReports gets all abuse reports for the review
+func (a *App) GetProductReviewReports(pid, rid int64) ([]*model.ProductReviewReport, *model.AppErr) {
+	if _, err := a.GetProductReview(pid, rid); err != nil {
+		return nil, err
+	}
+	return a.Srv().Store.ProductReview().GetReports(rid)
+}
+
+func (a *App) getApprovedReview(pid, rid int64) (*model.ProductReview, *model.AppErr) {
+	rev, err := a.GetProductReview(pid, rid)
+	if err != nil {
+		return nil, err
+	}
+	if rev.Status != model.ReviewStatusApproved {
+		return nil, model.NewAppErr("getApprovedReview", model.ErrNotFound, locale.GetUserLocalizer("en"), msgReviewNotApproved, http.StatusNotFound, nil)
+	}
+	return rev, nil
+}
+
+// initialReviewStatus is the status of new and edited reviews, they wait for moderation unless auto approve is enabled
+func (a *App) initialReviewStatus() string {
+	if a.Cfg().ReviewSettings.AutoApprove {
+		return model.ReviewStatusApproved
+	}
+	return model.ReviewStatusPending
+}
+
+// refreshProductRating recalculates the product rating stats, failure is only logged since the review itself is already persisted
+func (a *App) refreshProductRating(pid int64) {
+	if err := a.Srv().Store.ProductReview().RefreshRating(pid); err != nil {
+		a.Log().Error(err.Error(), zlog.Err(err))
+	}
 }
 
 // PatchProductImage patches the product image
@@ -454,6 +828,7 @@ func (a *App) PatchProductImage(pid, imgID int64, patch *model.ProductImagePatch
 		return nil, err
 	}
 
+	before := *old
 	oldPublicID := old.PublicID
 
 	if fh != nil {
@@ -481,10 +856,11 @@ func (a *App) PatchProductImage(pid, imgID int64, patch *model.ProductImagePatch
 	if err != nil {
 		return nil, err
 	}
+	a.audit(model.AuditActionUpdate, model.AuditResourceProductImage, auditID(pid, imgID), &before, uimg)
 
 	defer func() {
 		if *oldPublicID != "" {
-			if err := a.DeleteImage(*oldPublicID); err != nil {
+			if err := a.EnqueueDeleteImage(*oldPublicID); err != nil {
 				a.Log().Error(err.Error(), zlog.Err(err))
 			}
 		}
@@ -509,10 +885,11 @@ func (a *App) DeleteProductImage(pid, imgID int64) *model.AppErr {
 	if err != nil {
 		return err
 	}
+	a.audit(model.AuditActionDelete, model.AuditResourceProductImage, auditID(pid, imgID), old, nil)
 
 	defer func() {
 		if *old.PublicID != "" {
-			if err := a.DeleteImage(*old.PublicID); err != nil {
+			if err := a.EnqueueDeleteImage(*old.PublicID); err != nil {
 				a.Log().Error(err.Error(), zlog.Err(err))
 			}
 		}
@@ -523,10 +900,24 @@ func (a *App) DeleteProductImage(pid, imgID int64) *model.AppErr {
 
 // DeleteProductImages bulk deletes images
 func (a *App) DeleteProductImages(pid int64, ids []int) *model.AppErr {
-	return a.Srv().Store.ProductImage().BulkDelete(pid, ids)
+	before := a.auditSnapshots(ids, func(id int64) (interface{}, *model.AppErr) { return a.Srv().Store.ProductImage().Get(pid, id) })
+	if err := a.Srv().Store.ProductImage().BulkDelete(pid, ids); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		a.audit(model.AuditActionDelete, model.AuditResourceProductImage, auditID(pid, int64(id)), before[id], nil)
+	}
+	return nil
 }
 
-// SearchProducts performs the full text search on products
-func (a *App) SearchProducts(query string) ([]*model.Product, *model.AppErr) {
-	return a.Srv().Store.Product().Search(query)
+// SearchProducts performs the full text search on products, the products are also matched by their translation to the locale
+func (a *App) SearchProducts(query, lang string) ([]*model.Product, *model.AppErr) {
+	products, err := a.Srv().Store.Product().Search(query, lang)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.TranslateProducts(products, lang); err != nil {
+		return nil, err
+	}
+	return products, nil
 }
@@ -0,0 +1,338 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/dankobgd/ecommerce-shop/zlog"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgAnswerNotAllowed     = &i18n.Message{ID: "app.question.create_answer.not_allowed.app_error", Other: "only admins and customers who purchased the product can answer questions"}
+	msgQuestionNotApproved  = &i18n.Message{ID: "app.question.not_approved.app_error", Other: "question is not approved"}
+	msgAnswerNotApproved    = &i18n.Message{ID: "app.question.answer.not_approved.app_error", Other: "answer is not approved"}
+	msgAnswerOwnUpvote      = &i18n.Message{ID: "app.question.upvote_answer.own_answer.app_error", Other: "you cannot upvote your own answer"}
+	msgQuestionOwnerMissing = &i18n.Message{ID: "app.question.notify.owner_missing.app_error", Other: "product has no contact email"}
+)
+
+// CreateProductQuestion creates the new question for the product, the product owner is notified once the question is approved
+func (a *App) CreateProductQuestion(pid int64, q *model.ProductQuestion) (*model.ProductQuestion, *model.AppErr) {
+	q.ProductID = pid
+	q.Status = a.initialReviewStatus()
+	q.PreSave()
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	question, err := a.Srv().Store.ProductQuestion().Save(q)
+	if err != nil {
+		a.Log().Error(err.Error(), zlog.Err(err))
+		return nil, err
+	}
+	a.audit(model.AuditActionCreate, model.AuditResourceProductQuestion, auditID(pid, question.ID), nil, question)
+
+	if question.Status == model.ReviewStatusApproved {
+		a.onQuestionApproved(question)
+	}
+
+	return question, nil
+}
+
+// onQuestionApproved notifies the product owner about the approved question
+func (a *App) onQuestionApproved(q *model.ProductQuestion) {
+	if err := a.notifyQuestionAsked(q); err != nil {
+		a.Log().Error("could not send product question email", zlog.Int64("product_id", q.ProductID), zlog.Int64("question_id", q.ID), zlog.Err(err))
+	}
+}
+
+// GetProductQuestions gets the approved questions of the product with their approved answers
+func (a *App) GetProductQuestions(pid int64, limit, offset int) ([]*model.ProductQuestion, *model.AppErr) {
+	questions, err := a.Srv().Store.ProductQuestion().GetAll(pid, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.attachQuestionAnswers(questions...); err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+// GetProductQuestion gets the question with its approved answers
+func (a *App) GetProductQuestion(pid, qid int64) (*model.ProductQuestion, *model.AppErr) {
+	q, err := a.Srv().Store.ProductQuestion().Get(pid, qid)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.attachQuestionAnswers(q); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// GetVisibleProductQuestion gets the question if it's approved, the author and the moderators can also see the unmoderated question
+func (a *App) GetVisibleProductQuestion(ad *model.AccessData, pid, qid int64) (*model.ProductQuestion, *model.AppErr) {
+	q, err := a.GetProductQuestion(pid, qid)
+	if err != nil {
+		return nil, err
+	}
+	if q.Status != model.ReviewStatusApproved {
+		if err := a.Authorize(ad, q.UserID, model.PermissionQuestionModerate); err != nil {
+			return nil, model.NewAppErr("GetVisibleProductQuestion", model.ErrNotFound, locale.GetUserLocalizer("en"), msgQuestionNotApproved, http.StatusNotFound, nil)
+		}
+	}
+	return q, nil
+}
+
+// GetQuestionsByStatus gets the questions of all products with the given moderation status
+func (a *App) GetQuestionsByStatus(status string, limit, offset int) ([]*model.ProductQuestion, *model.AppErr) {
+	return a.Srv().Store.ProductQuestion().GetAllByStatus(status, limit, offset)
+}
+
+// GetAnswersByStatus gets the answers of all questions with the given moderation status
+func (a *App) GetAnswersByStatus(status string, limit, offset int) ([]*model.ProductAnswer, *model.AppErr) {
+	return a.Srv().Store.ProductQuestion().GetAnswersByStatus(status, limit, offset)
+}
+
+// ModerateProductQuestion sets the question moderation status
+func (a *App) ModerateProductQuestion(pid, qid int64, st *model.ProductReviewStatus) (*model.ProductQuestion, *model.AppErr) {
+	if err := st.Validate(); err != nil {
+		return nil, err
+	}
+
+	q, err := a.GetProductQuestion(pid, qid)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.Srv().Store.ProductQuestion().UpdateStatus(pid, qid, st.Status); err != nil {
+		return nil, err
+	}
+
+	before := *q
+	q.Status = st.Status
+	a.audit(model.AuditActionUpdate, model.AuditResourceProductQuestion, auditID(pid, qid), &before, q)
+	if q.Status == model.ReviewStatusApproved && before.Status != model.ReviewStatusApproved {
+		a.onQuestionApproved(q)
+	}
+	return q, nil
+}
+
+// DeleteProductQuestion deletes the question with all its answers
+func (a *App) DeleteProductQuestion(pid, qid int64) *model.AppErr {
+	old, _ := a.Srv().Store.ProductQuestion().Get(pid, qid)
+	if err := a.Srv().Store.ProductQuestion().Delete(pid, qid); err != nil {
+		return err
+	}
+	a.audit(model.AuditActionDelete, model.AuditResourceProductQuestion, auditID(pid, qid), old, nil)
+	return nil
+}
+
+// CreateProductAnswer answers the approved question, only the staff that moderates the questions and verified buyers are allowed to answer
+func (a *App) CreateProductAnswer(pid, qid int64, ans *model.ProductAnswer, isStaff bool) (*model.ProductAnswer, *model.AppErr) {
+	q, err := a.getApprovedQuestion(pid, qid)
+	if err != nil {
+		return nil, err
+	}
+
+	verified, err := a.Srv().Store.ProductReview().IsVerifiedPurchase(ans.UserID, pid)
+	if err != nil {
+		return nil, err
+	}
+	if !isStaff && !verified {
+		return nil, model.NewAppErr("CreateProductAnswer", model.ErrUnauthorized, locale.GetUserLocalizer("en"), msgAnswerNotAllowed, http.StatusForbidden, nil)
+	}
+
+	ans.QuestionID = qid
+	ans.IsAdmin = isStaff
+	ans.IsVerifiedPurchase = verified
+	ans.Status = a.initialReviewStatus()
+	if isStaff {
+		ans.Status = model.ReviewStatusApproved
+	}
+	ans.PreSave()
+	if err := ans.Validate(); err != nil {
+		return nil, err
+	}
+
+	answer, err := a.Srv().Store.ProductQuestion().SaveAnswer(ans)
+	if err != nil {
+		a.Log().Error(err.Error(), zlog.Err(err))
+		return nil, err
+	}
+
+	if answer.Status == model.ReviewStatusApproved {
+		a.onAnswerApproved(q, answer)
+	}
+
+	a.audit(model.AuditActionCreate, model.AuditResourceProductAnswer, auditID(pid, qid, answer.ID), nil, answer)
+	return answer, nil
+}
+
+// ModerateProductAnswer sets the answer moderation status
+func (a *App) ModerateProductAnswer(pid, qid, aid int64, st *model.ProductReviewStatus) (*model.ProductAnswer, *model.AppErr) {
+	if err := st.Validate(); err != nil {
+		return nil, err
+	}
+
+	q, err := a.Srv().Store.ProductQuestion().Get(pid, qid)
+	if err != nil {
+		return nil, err
+	}
+	ans, err := a.Srv().Store.ProductQuestion().GetAnswer(qid, aid)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.Srv().Store.ProductQuestion().UpdateAnswerStatus(qid, aid, st.Status); err != nil {
+		return nil, err
+	}
+
+	before := *ans
+	wasApproved := ans.Status == model.ReviewStatusApproved
+	ans.Status = st.Status
+	a.audit(model.AuditActionUpdate, model.AuditResourceProductAnswer, auditID(pid, qid, aid), &before, ans)
+	if st.Status == model.ReviewStatusApproved && !wasApproved {
+		a.onAnswerApproved(q, ans)
+	} else {
+		a.refreshAnswerCount(qid)
+	}
+
+	return ans, nil
+}
+
+// DeleteProductAnswer deletes the answer
+func (a *App) DeleteProductAnswer(pid, qid, aid int64) *model.AppErr {
+	if _, err := a.Srv().Store.ProductQuestion().Get(pid, qid); err != nil {
+		return err
+	}
+	old, _ := a.Srv().Store.ProductQuestion().GetAnswer(qid, aid)
+	if err := a.Srv().Store.ProductQuestion().DeleteAnswer(qid, aid); err != nil {
+		return err
+	}
+	a.audit(model.AuditActionDelete, model.AuditResourceProductAnswer, auditID(pid, qid, aid), old, nil)
+
+	a.refreshAnswerCount(qid)
+	return nil
+}
+
+// UpvoteProductAnswer upvotes the approved answer for the user
+func (a *App) UpvoteProductAnswer(pid, qid, aid, uid int64) *model.AppErr {
+	if _, err := a.Srv().Store.ProductQuestion().Get(pid, qid); err != nil {
+		return err
+	}
+	ans, err := a.Srv().Store.ProductQuestion().GetAnswer(qid, aid)
+	if err != nil {
+		return err
+	}
+	if ans.Status != model.ReviewStatusApproved {
+		return model.NewAppErr("UpvoteProductAnswer", model.ErrNotFound, locale.GetUserLocalizer("en"), msgAnswerNotApproved, http.StatusNotFound, nil)
+	}
+	if ans.UserID == uid {
+		return model.NewAppErr("UpvoteProductAnswer", model.ErrConflict, locale.GetUserLocalizer("en"), msgAnswerOwnUpvote, http.StatusBadRequest, nil)
+	}
+
+	if err := a.Srv().Store.ProductQuestion().SaveAnswerUpvote(aid, uid); err != nil {
+		return err
+	}
+	a.audit(model.AuditActionCreate, model.AuditResourceAnswerUpvote, auditID(pid, qid, aid, uid), nil, nil)
+	return nil
+}
+
+// DeleteProductAnswerUpvote removes the users upvote from the answer
+func (a *App) DeleteProductAnswerUpvote(pid, qid, aid, uid int64) *model.AppErr {
+	if _, err := a.Srv().Store.ProductQuestion().Get(pid, qid); err != nil {
+		return err
+	}
+	if _, err := a.Srv().Store.ProductQuestion().GetAnswer(qid, aid); err != nil {
+		return err
+	}
+	if err := a.Srv().Store.ProductQuestion().DeleteAnswerUpvote(aid, uid); err != nil {
+		return err
+	}
+	a.audit(model.AuditActionDelete, model.AuditResourceAnswerUpvote, auditID(pid, qid, aid, uid), nil, nil)
+	return nil
+}
+
+func (a *App) getApprovedQuestion(pid, qid int64) (*model.ProductQuestion, *model.AppErr) {
+	q, err := a.Srv().Store.ProductQuestion().Get(pid, qid)
+	if err != nil {
+		return nil, err
+	}
+	if q.Status != model.ReviewStatusApproved {
+		return nil, model.NewAppErr("getApprovedQuestion", model.ErrNotFound, locale.GetUserLocalizer("en"), msgQuestionNotApproved, http.StatusNotFound, nil)
+	}
+	return q, nil
+}
+
+func (a *App) attachQuestionAnswers(questions ...*model.ProductQuestion) *model.AppErr {
+	qids := make([]int64, 0, len(questions))
+	for _, q := range questions {
+		q.Answers = make([]*model.ProductAnswer, 0)
+		qids = append(qids, q.ID)
+	}
+
+	answers, err := a.Srv().Store.ProductQuestion().GetAnswers(qids)
+	if err != nil {
+		return err
+	}
+
+	byQuestion := make(map[int64][]*model.ProductAnswer)
+	for _, ans := range answers {
+		byQuestion[ans.QuestionID] = append(byQuestion[ans.QuestionID], ans)
+	}
+	for _, q := range questions {
+		if ans, ok := byQuestion[q.ID]; ok {
+			q.Answers = ans
+		}
+	}
+	return nil
+}
+
+// onAnswerApproved updates the answer count and lets the asker know their question got answered
+func (a *App) onAnswerApproved(q *model.ProductQuestion, ans *model.ProductAnswer) {
+	a.refreshAnswerCount(q.ID)
+
+	if err := a.notifyQuestionAnswered(q, ans); err != nil {
+		a.Log().Error("could not send question answered email", zlog.Int64("question_id", q.ID), zlog.Int64("answer_id", ans.ID), zlog.Err(err))
+	}
+}
+
+func (a *App) refreshAnswerCount(qid int64) {
+	if err := a.Srv().Store.ProductQuestion().RefreshAnswerCount(qid); err != nil {
+		a.Log().Error(err.Error(), zlog.Err(err))
+	}
+}
+
+func (a *App) notifyQuestionAsked(q *model.ProductQuestion) *model.AppErr {
+	p, err := a.GetProduct(q.ProductID)
+	if err != nil {
+		return err
+	}
+	if p.Brand == nil || p.Brand.Email == "" {
+		return model.NewAppErr("notifyQuestionAsked", model.ErrInternal, locale.GetUserLocalizer("en"), msgQuestionOwnerMissing, http.StatusInternalServerError, nil)
+	}
+
+	return a.SendQuestionAskedEmail(p.Brand.Email, p.Brand.Name, p.Name, q.Body, a.productQuestionLink(q), "en")
+}
+
+func (a *App) notifyQuestionAnswered(q *model.ProductQuestion, ans *model.ProductAnswer) *model.AppErr {
+	if q.UserID == ans.UserID {
+		return nil
+	}
+
+	asker, err := a.Srv().Store.User().Get(q.UserID)
+	if err != nil {
+		return err
+	}
+	p, err := a.GetProduct(q.ProductID)
+	if err != nil {
+		return err
+	}
+
+	return a.SendQuestionAnsweredEmail(asker.Email, asker.Username, p.Name, ans.Body, a.productQuestionLink(q), asker.Locale)
+}
+
+func (a *App) productQuestionLink(q *model.ProductQuestion) string {
+	return fmt.Sprintf("%s/products/%d?question=%d", a.SiteURL(), q.ProductID, q.ID)
+}
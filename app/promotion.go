@@ -28,6 +28,7 @@ func (a *App) CreatePromotion(p *model.Promotion) (*model.Promotion, *model.AppE
 		return nil, pErr
 	}
 
+	a.audit(model.AuditActionCreate, model.AuditResourcePromotion, promotion.PromoCode, nil, promotion)
 	return promotion, nil
 }
 
@@ -38,12 +39,14 @@ func (a *App) PatchPromotion(code string, patch *model.PromotionPatch) (*model.P
 		return nil, err
 	}
 
+	before := *old
 	old.Patch(patch)
 	old.PreUpdate()
 	up, err := a.Srv().Store.Promotion().Update(code, old)
 	if err != nil {
 		return nil, err
 	}
+	a.audit(model.AuditActionUpdate, model.AuditResourcePromotion, code, &before, up)
 
 	return up, nil
 }
@@ -60,7 +63,12 @@ func (a *App) GetPromotions(limit, offset int) ([]*model.Promotion, *model.AppEr
 
 // DeletePromotion hard deletes the promotion from the db
 func (a *App) DeletePromotion(code string) *model.AppErr {
-	return a.Srv().Store.Promotion().Delete(code)
+	old, _ := a.Srv().Store.Promotion().Get(code)
+	if err := a.Srv().Store.Promotion().Delete(code); err != nil {
+		return err
+	}
+	a.audit(model.AuditActionDelete, model.AuditResourcePromotion, code, old, nil)
+	return nil
 }
 
 // IsValidPromotion checks if promo_code is valid
@@ -97,5 +105,17 @@ func (a *App) CreatePromotionDetail(pd *model.PromotionDetail) (*model.Promotion
 
 // DeletePromotions bulk deletes promotions
 func (a *App) DeletePromotions(codes []string) *model.AppErr {
-	return a.Srv().Store.Promotion().BulkDelete(codes)
+	before := make(map[string]*model.Promotion)
+	for _, code := range codes {
+		if p, err := a.Srv().Store.Promotion().Get(code); err == nil {
+			before[code] = p
+		}
+	}
+	if err := a.Srv().Store.Promotion().BulkDelete(codes); err != nil {
+		return err
+	}
+	for _, code := range codes {
+		a.audit(model.AuditActionDelete, model.AuditResourcePromotion, code, before[code], nil)
+	}
+	return nil
 }
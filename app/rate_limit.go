@@ -0,0 +1,66 @@
+package app
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/zlog"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgRateLimited = &i18n.Message{ID: "app.rate_limit.exceeded.app_error", Other: "too many requests, try again later"}
+)
+
+// DefaultRateLimit returns the global rate limit every client is subject to, nil is returned when rate limiting is disabled
+func (a *App) DefaultRateLimit() *model.RateLimit {
+	settings := &a.Cfg().RateLimitSettings
+	if settings.Disabled {
+		return nil
+	}
+	return &model.RateLimit{
+		Name:     "global",
+		Requests: settings.Requests,
+		Period:   time.Second * time.Duration(settings.PeriodSeconds),
+		Burst:    settings.Burst,
+	}
+}
+
+// RateLimitKey identifies the client of the request, the signed in users and the api keys are limited by their id
+// so they don't share the bucket with everyone behind the same ip. The api key gets its own bucket only when it exists
+// and is active, otherwise made up keys would each get a fresh bucket and get around the ip limits
+func (a *App) RateLimitKey(r *http.Request) string {
+	if key, loc := ExtractAuthTokenFromRequest(r); loc == model.TokenLocationAPIKey {
+		if model.IsAPIKey(key) {
+			hash := model.HashAPIKey(key)
+			if k, err := a.Srv().Store.APIKey().GetByHash(hash); err == nil && k != nil && k.IsActive(time.Now()) {
+				return "api_key:" + hash
+			}
+		}
+		return "ip:" + a.RequestIP(r)
+	}
+	if ad, err := a.ExtractTokenMetadata(r); err == nil && ad != nil {
+		return "user:" + strconv.FormatInt(ad.UserID, 10)
+	}
+	return "ip:" + a.RequestIP(r)
+}
+
+// CheckRateLimit takes the token from the client's bucket, the error is returned when the bucket is empty.
+// The request is let through if the limit can't be checked so the api doesn't go down together with redis
+func (a *App) CheckRateLimit(r *http.Request, limit *model.RateLimit) (*model.RateLimitResult, *model.AppErr) {
+	if a.Cfg().RateLimitSettings.Disabled || limit == nil {
+		return nil, nil
+	}
+
+	res, err := a.Srv().Store.RateLimit().Take(a.RateLimitKey(r), limit)
+	if err != nil {
+		a.Log().Error(err.Error(), zlog.Err(err), zlog.String("rate_limit", limit.Name))
+		return nil, nil
+	}
+	if !res.Allowed {
+		return res, tooManyRequestsErr("CheckRateLimit", msgRateLimited, res.RetryAfter)
+	}
+	return res, nil
+}
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+)
+
+func TestRateLimitKey(t *testing.T) {
+	a, st := newAPIKeyTestApp(t)
+	a.Cfg().TrustedProxies = []string{"10.0.0.1"}
+	revoked := time.Now().Add(-time.Minute)
+	st.keys[2] = &model.APIKey{ID: 2, KeyHash: model.HashAPIKey("esk_revoked"), CreatedBy: 10, RevokedAt: &revoked}
+
+	tests := []struct {
+		name   string
+		apiKey string
+		xff    string
+		want   string
+	}{
+		{"api key", testAPIKey, "", "api_key:" + model.HashAPIKey(testAPIKey)},
+		{"unknown api key", "esk_fedcba9876543210", "198.51.100.1", "ip:198.51.100.1"},
+		{"revoked api key", "esk_revoked", "198.51.100.1", "ip:198.51.100.1"},
+		{"malformed api key", "not-a-key", "198.51.100.1", "ip:198.51.100.1"},
+		{"anonymous behind the trusted proxy", "", "1.2.3.4, 198.51.100.1", "ip:198.51.100.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = "10.0.0.1:5000"
+			if tt.apiKey != "" {
+				r.Header.Set(model.HeaderAPIKey, tt.apiKey)
+			}
+			if tt.xff != "" {
+				r.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			if got := a.RateLimitKey(r); got != tt.want {
+				t.Errorf("RateLimitKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/dankobgd/ecommerce-shop/config"
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/store"
+	rdstore "github.com/dankobgd/ecommerce-shop/store/redis"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/dankobgd/ecommerce-shop/zlog"
+	"github.com/go-redis/redis/v8"
+)
+
+func TestMain(m *testing.M) {
+	// the translations are loaded relative to the repository root
+	if err := os.Chdir(".."); err != nil {
+		panic(err)
+	}
+	locale.InitTranslations()
+	os.Exit(m.Run())
+}
+
+// redisTestStore keeps the tokens and the sessions in the in memory redis server and the users in the map,
+// calling the store that is not implemented panics so the tests notice it
+type redisTestStore struct {
+	store.Store
+	rdst  *rdstore.RdStore
+	users map[int64]*model.User
+}
+
+func (s redisTestStore) AccessToken() store.AccessTokenStore {
+	return rdstore.NewRedisAccessTokenStore(s.rdst)
+}
+
+func (s redisTestStore) Session() store.SessionStore {
+	return rdstore.NewRedisSessionStore(s.rdst)
+}
+
+func (s redisTestStore) User() store.UserStore { return redisTestUsers{users: s.users} }
+func (s redisTestStore) Role() store.RoleStore { return redisTestRoles{} }
+
+type redisTestUsers struct {
+	store.UserStore
+	users map[int64]*model.User
+}
+
+func (us redisTestUsers) Get(id int64) (*model.User, *model.AppErr) {
+	if u, ok := us.users[id]; ok {
+		c := *u
+		return &c, nil
+	}
+	return nil, model.NewAppErr("redisTestUsers.Get", model.ErrNotFound, locale.GetUserLocalizer("en"), msgRefreshToken, http.StatusNotFound, nil)
+}
+
+// redisTestRoles grants no permissions to the users that are not admins
+type redisTestRoles struct{ store.RoleStore }
+
+func (redisTestRoles) GetUserPermissions(uid int64) ([]string, *model.AppErr) { return []string{}, nil }
+
+func newRefreshTestApp(t *testing.T) (*App, *miniredis.Miniredis, redisTestStore) {
+	t.Helper()
+
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("could not start the redis server: %v", err)
+	}
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		client.Close()
+		mr.Close()
+	})
+
+	st := redisTestStore{
+		rdst:  rdstore.NewStore(client),
+		users: map[int64]*model.User{7: {ID: 7, Role: model.AdminRole}},
+	}
+	srv, err := NewServer(st)
+	if err != nil {
+		t.Fatalf("could not create the server: %v", err)
+	}
+
+	cfg := &config.Config{}
+	cfg.AuthSettings.AccessTokenSecret = "test-access-token-secret"
+	cfg.AuthSettings.RefreshTokenSecret = "test-refresh-token-secret"
+	cfg.AuthSettings.AccessTokenExpiryMinutes = 15
+	cfg.AuthSettings.RefreshTokenExpiryHours = 24
+
+	a := New(SetConfig(cfg), SetLogger(zlog.NewLogger(&zlog.LoggerConfig{})), SetServer(srv))
+	return a, mr, st
+}
+
+// login issues the tokens of the admin's new session the same way the login does
+func login(t *testing.T, a *App) *model.TokenMetadata {
+	t.Helper()
+
+	user := &model.User{ID: 7, Role: model.AdminRole}
+	meta, err := a.IssueTokens(user)
+	if err != nil {
+		t.Fatalf("IssueTokens: %v", err)
+	}
+	if err := a.SaveAuth(user.ID, meta, &model.Session{}); err != nil {
+		t.Fatalf("SaveAuth: %v", err)
+	}
+	return meta
+}
+
+func assertExpiresAt(t *testing.T, mr *miniredis.Miniredis, key string, want time.Duration) {
+	t.Helper()
+
+	got := mr.TTL(key)
+	if d := got - want; d < -time.Second || d > time.Second {
+		t.Errorf("TTL(%s) = %v, want %v", key, got, want)
+	}
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	a, mr, _ := newRefreshTestApp(t)
+	first := login(t, a)
+
+	assertExpiresAt(t, mr, first.AccessUUID, 15*time.Minute)
+	assertExpiresAt(t, mr, first.RefreshUUID, 24*time.Hour)
+	assertExpiresAt(t, mr, "session:"+first.SessionID, 24*time.Hour)
+
+	second, err := a.RefreshToken(&model.RefreshToken{RefreshToken: first.RefreshToken})
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+	if second.SessionID != first.SessionID {
+		t.Errorf("the refresh started the new session %s, want %s", second.SessionID, first.SessionID)
+	}
+	if second.RefreshUUID == first.RefreshUUID || second.AccessUUID == first.AccessUUID {
+		t.Error("the refresh did not issue the new token pair")
+	}
+	if mr.Exists(first.AccessUUID) || mr.Exists(first.RefreshUUID) {
+		t.Error("the rotated out tokens are still valid")
+	}
+	assertExpiresAt(t, mr, second.AccessUUID, 15*time.Minute)
+	assertExpiresAt(t, mr, second.RefreshUUID, 24*time.Hour)
+
+	if _, err := a.RefreshToken(&model.RefreshToken{RefreshToken: second.RefreshToken}); err != nil {
+		t.Errorf("RefreshToken() with the rotated in token: %v", err)
+	}
+}
+
+func TestRefreshTokenReuseRevokesTheSession(t *testing.T) {
+	a, mr, _ := newRefreshTestApp(t)
+	first := login(t, a)
+
+	second, err := a.RefreshToken(&model.RefreshToken{RefreshToken: first.RefreshToken})
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+
+	_, err = a.RefreshToken(&model.RefreshToken{RefreshToken: first.RefreshToken})
+	if err == nil || err.ID != "app.refresh_token.reused.app_error" {
+		t.Fatalf("RefreshToken() with the reused token = %v, want the reuse error", err)
+	}
+
+	if mr.Exists("session:" + first.SessionID) {
+		t.Error("the session was not revoked")
+	}
+	if mr.Exists(second.AccessUUID) || mr.Exists(second.RefreshUUID) {
+		t.Error("the tokens of the revoked session are still valid")
+	}
+	if _, err := a.RefreshToken(&model.RefreshToken{RefreshToken: second.RefreshToken}); err == nil {
+		t.Error("the refresh token of the revoked session still works")
+	}
+	if _, err := a.GetAuth(&model.AccessData{UserID: 7, AccessUUID: second.AccessUUID, SessionID: second.SessionID}); err == nil {
+		t.Error("the access token of the revoked session still works")
+	}
+}
+
+func TestRefreshTokenExpiredSession(t *testing.T) {
+	a, mr, _ := newRefreshTestApp(t)
+	first := login(t, a)
+
+	mr.FastForward(24*time.Hour + time.Second)
+	if _, err := a.RefreshToken(&model.RefreshToken{RefreshToken: first.RefreshToken}); err == nil {
+		t.Error("the refresh token of the expired session still works")
+	}
+}
+
+func TestRefreshTokenReloadsTheUser(t *testing.T) {
+	a, _, st := newRefreshTestApp(t)
+	first := login(t, a)
+
+	st.users[7].Role = model.UserRole
+	second, err := a.RefreshToken(&model.RefreshToken{RefreshToken: first.RefreshToken})
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+	ad, err := a.ExtractTokenMetadata(httptest.NewRequest(http.MethodGet, "/?access_token="+second.AccessToken, nil))
+	if err != nil {
+		t.Fatalf("ExtractTokenMetadata: %v", err)
+	}
+	if ad.Role != model.UserRole {
+		t.Errorf("the refreshed token has the %s role, want the demoted user's role", ad.Role)
+	}
+
+	delete(st.users, 7)
+	if _, err := a.RefreshToken(&model.RefreshToken{RefreshToken: second.RefreshToken}); err == nil || err.StatusCode != http.StatusUnauthorized {
+		t.Errorf("RefreshToken() of the deleted user = %v, want unauthorized", err)
+	}
+}
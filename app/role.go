@@ -0,0 +1,105 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var msgRoleNotFound = &i18n.Message{ID: "app.role.not_found.app_error", Other: "role not found"}
+
+// CreateRole creates the new staff role
+func (a *App) CreateRole(r *model.Role) (*model.Role, *model.AppErr) {
+	r.PreSave()
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	role, err := a.Srv().Store.Role().Save(r)
+	if err != nil {
+		return nil, err
+	}
+	a.audit(model.AuditActionCreate, model.AuditResourceRole, role.ID, nil, role)
+	return role, nil
+}
+
+// GetRoles gets all roles
+func (a *App) GetRoles(limit, offset int) ([]*model.Role, *model.AppErr) {
+	return a.Srv().Store.Role().GetAll(limit, offset)
+}
+
+// GetRole gets the role by id
+func (a *App) GetRole(id int64) (*model.Role, *model.AppErr) {
+	r, err := a.Srv().Store.Role().Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, model.NewAppErr("GetRole", model.ErrNotFound, locale.GetUserLocalizer("en"), msgRoleNotFound, http.StatusNotFound, nil)
+	}
+	return r, nil
+}
+
+// PatchRole patches the role, the users get the new permissions when their tokens are issued again
+func (a *App) PatchRole(id int64, patch *model.RolePatch) (*model.Role, *model.AppErr) {
+	r, err := a.GetRole(id)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *r
+	r.Patch(patch)
+	r.PreUpdate()
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	role, err := a.Srv().Store.Role().Update(id, r)
+	if err != nil {
+		return nil, err
+	}
+	a.audit(model.AuditActionUpdate, model.AuditResourceRole, id, &before, role)
+	return role, nil
+}
+
+// DeleteRole deletes the role and removes it from the users
+func (a *App) DeleteRole(id int64) *model.AppErr {
+	old, _ := a.Srv().Store.Role().Get(id)
+	if err := a.Srv().Store.Role().Delete(id); err != nil {
+		return err
+	}
+	a.audit(model.AuditActionDelete, model.AuditResourceRole, id, old, nil)
+	return nil
+}
+
+// GetUserRoles gets the roles assigned to the user
+func (a *App) GetUserRoles(uid int64) ([]*model.Role, *model.AppErr) {
+	return a.Srv().Store.Role().GetByUserID(uid)
+}
+
+// AssignUserRole assigns the role to the user
+func (a *App) AssignUserRole(uid, rid int64) *model.AppErr {
+	if err := a.Srv().Store.Role().Assign(uid, rid); err != nil {
+		return err
+	}
+	a.audit(model.AuditActionCreate, model.AuditResourceUserRole, auditID(uid, rid), nil, map[string]int64{"user_id": uid, "role_id": rid})
+	return nil
+}
+
+// UnassignUserRole removes the role from the user
+func (a *App) UnassignUserRole(uid, rid int64) *model.AppErr {
+	if err := a.Srv().Store.Role().Unassign(uid, rid); err != nil {
+		return err
+	}
+	a.audit(model.AuditActionDelete, model.AuditResourceUserRole, auditID(uid, rid), map[string]int64{"user_id": uid, "role_id": rid}, nil)
+	return nil
+}
+
+// GetUserPermissions gets the permissions granted to the user by the roles,
+// nothing is listed for the admins since they are granted all permissions
+func (a *App) GetUserPermissions(user *model.User) ([]string, *model.AppErr) {
+	if user.Role == model.AdminRole {
+		return nil, nil
+	}
+	return a.Srv().Store.Role().GetUserPermissions(user.ID)
+}
@@ -19,9 +19,9 @@ type Server struct {
 	Store  store.Store
 	Server *http.Server
 	Router *chi.Mux
+	Jobs   *JobServer
+	Events *EventDispatcher
 	// Log *log.Logger
-	// RateLimiter *RateLimiter
-	// jobs
 	// other cfg
 }
 
@@ -32,6 +32,8 @@ func NewServer(st store.Store) (*Server, error) {
 	s := &Server{
 		Router: r,
 		Store:  st,
+		Jobs:   NewJobServer(st),
+		Events: NewEventDispatcher(st),
 	}
 
 	// s.Log = log.NewLogger()
@@ -46,7 +48,7 @@ func (s *Server) Start() (err error) {
 		AllowedOrigins:   []string{"http://localhost:3000"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
 		AllowedHeaders:   []string{"Content-Type", "Authorization", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Content-Length", "Cache-Control", "Content-Language", "Content-Type", "Expires", "Last-Modified", "Pragma", "Authorization"},
+		ExposedHeaders:   []string{"Content-Length", "Cache-Control", "Content-Language", "Content-Type", "Expires", "Last-Modified", "Pragma", "Authorization", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After"},
 		MaxAge:           86400,
 		AllowCredentials: true,
 		Debug:            false,
@@ -54,8 +56,6 @@ func (s *Server) Start() (err error) {
 
 	handler := corsWrapper.Handler(s.Router)
 
-	// if ratelimit set it here...
-
 	listenAddr := ":3001"
 
 	s.Server = &http.Server{
@@ -78,12 +78,12 @@ func (s *Server) Start() (err error) {
 	}()
 	log.Printf("server started")
 
-	gracefullShutdown(s.Server)
+	gracefullShutdown(s)
 
 	return
 }
 
-func gracefullShutdown(srv *http.Server) {
+func gracefullShutdown(s *Server) {
 	interrupt := make(chan os.Signal, 1)
 	signal.Notify(interrupt, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 
@@ -92,8 +92,10 @@ func gracefullShutdown(srv *http.Server) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
 
-	if err := srv.Shutdown(ctx); err != nil {
+	if err := s.Server.Shutdown(ctx); err != nil {
 		log.Fatalf("server shutdown failed: %+s", err)
 	}
+	s.Events.Stop()
+	s.Jobs.Stop()
 	log.Fatalf("server is shutting down")
 }
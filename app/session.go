@@ -0,0 +1,52 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgSessionRevoked  = &i18n.Message{ID: "app.session.revoked.app_error", Other: "session has been revoked or has expired"}
+	msgSessionNotFound = &i18n.Message{ID: "app.session.not_found.app_error", Other: "session not found"}
+)
+
+// SessionFromRequest creates the session with the device info of the request
+func (a *App) SessionFromRequest(r *http.Request) *model.Session {
+	return &model.Session{
+		DeviceID:  r.Header.Get(model.HeaderDeviceID),
+		UserAgent: r.UserAgent(),
+		IP:        a.RequestIP(r),
+	}
+}
+
+// GetSessions gets the active user sessions and marks the one the request is made from
+func (a *App) GetSessions(userID int64, currentSessionID string) ([]*model.Session, *model.AppErr) {
+	sessions, err := a.Srv().Store.Session().GetAll(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range sessions {
+		s.Current = s.ID == currentSessionID
+	}
+	return sessions, nil
+}
+
+// RevokeSession logs the user out of the session
+func (a *App) RevokeSession(userID int64, sessionID string) *model.AppErr {
+	session, err := a.Srv().Store.Session().Get(userID, sessionID)
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		return model.NewAppErr("RevokeSession", model.ErrNotFound, locale.GetUserLocalizer("en"), msgSessionNotFound, http.StatusNotFound, nil)
+	}
+	return a.Srv().Store.Session().Delete(userID, sessionID)
+}
+
+// RevokeSessions logs the user out everywhere
+func (a *App) RevokeSessions(userID int64) *model.AppErr {
+	return a.Srv().Store.Session().DeleteAll(userID)
+}
@@ -23,6 +23,7 @@ func (a *App) CreateTag(t *model.Tag) (*model.Tag, *model.AppErr) {
 		return nil, tErr
 	}
 
+	a.audit(model.AuditActionCreate, model.AuditResourceTag, tag.ID, nil, tag)
 	return tag, nil
 }
 
@@ -37,12 +38,14 @@ func (a *App) PatchTag(tid int64, patch *model.TagPatch) (*model.Tag, *model.App
 		return nil, err
 	}
 
+	before := *old
 	old.Patch(patch)
 	old.PreUpdate()
 	utag, err := a.Srv().Store.Tag().Update(tid, old)
 	if err != nil {
 		return nil, err
 	}
+	a.audit(model.AuditActionUpdate, model.AuditResourceTag, tid, &before, utag)
 
 	return utag, nil
 }
@@ -59,10 +62,22 @@ func (a *App) GetTags(limit, offset int) ([]*model.Tag, *model.AppErr) {
 
 // DeleteTag hard deletes the tag from the db
 func (a *App) DeleteTag(tid int64) *model.AppErr {
-	return a.Srv().Store.Tag().Delete(tid)
+	old, _ := a.Srv().Store.Tag().Get(tid)
+	if err := a.Srv().Store.Tag().Delete(tid); err != nil {
+		return err
+	}
+	a.audit(model.AuditActionDelete, model.AuditResourceTag, tid, old, nil)
+	return nil
 }
 
 // DeleteTags bulk deletes tags
 func (a *App) DeleteTags(ids []int) *model.AppErr {
-	return a.Srv().Store.Tag().BulkDelete(ids)
+	before := a.auditSnapshots(ids, func(id int64) (interface{}, *model.AppErr) { return a.Srv().Store.Tag().Get(id) })
+	if err := a.Srv().Store.Tag().BulkDelete(ids); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		a.audit(model.AuditActionDelete, model.AuditResourceTag, id, before[id], nil)
+	}
+	return nil
 }
@@ -0,0 +1,196 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgTranslationNotFound = &i18n.Message{ID: "app.translation.not_found.app_error", Other: "translation not found"}
+	msgSlugNotFound        = &i18n.Message{ID: "app.translation.slug_not_found.app_error", Other: "no product matches the slug"}
+)
+
+// SaveTranslation creates or replaces the translation of the catalog resource
+func (a *App) SaveTranslation(t *model.Translation) (*model.Translation, *model.AppErr) {
+	t.PreSave()
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	if err := a.translatedResourceExists(t.Resource, t.ResourceID); err != nil {
+		return nil, err
+	}
+
+	old, _ := a.Srv().Store.Translation().Get(t.Resource, t.ResourceID, t.Locale)
+	saved, err := a.Srv().Store.Translation().Save(t)
+	if err != nil {
+		return nil, err
+	}
+	a.audit(auditSaveAction(old != nil), model.AuditResourceTranslation, translationAuditID(t.Resource, t.ResourceID, t.Locale), old, saved)
+	return saved, nil
+}
+
+// GetTranslations gets all translations of the catalog resource
+func (a *App) GetTranslations(resource string, id int64) ([]*model.Translation, *model.AppErr) {
+	return a.Srv().Store.Translation().GetAll(resource, id)
+}
+
+// GetTranslation gets the translation of the catalog resource to the locale
+func (a *App) GetTranslation(resource string, id int64, lang string) (*model.Translation, *model.AppErr) {
+	t, err := a.Srv().Store.Translation().Get(resource, id, lang)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, model.NewAppErr("GetTranslation", model.ErrNotFound, locale.GetUserLocalizer("en"), msgTranslationNotFound, http.StatusNotFound, nil)
+	}
+	return t, nil
+}
+
+// DeleteTranslation deletes the translation, the resource falls back to the default locale text
+func (a *App) DeleteTranslation(resource string, id int64, lang string) *model.AppErr {
+	old, _ := a.Srv().Store.Translation().Get(resource, id, lang)
+	if err := a.Srv().Store.Translation().Delete(resource, id, lang); err != nil {
+		return err
+	}
+	a.audit(model.AuditActionDelete, model.AuditResourceTranslation, translationAuditID(resource, id, lang), old, nil)
+	return nil
+}
+
+func translationAuditID(resource string, id int64, lang string) string {
+	return resource + "/" + auditID(id) + "/" + lang
+}
+
+// GetProductBySlug gets the product by the default or by any of the localized slugs, the slug of the locale is preferred
+func (a *App) GetProductBySlug(slug, lang string) (*model.Product, *model.AppErr) {
+	id, err := a.Srv().Store.Translation().ResolveSlug(model.TranslationResourceProduct, slug, lang)
+	if err != nil {
+		return nil, err
+	}
+	if id == 0 {
+		return nil, model.NewAppErr("GetProductBySlug", model.ErrNotFound, locale.GetUserLocalizer("en"), msgSlugNotFound, http.StatusNotFound, nil)
+	}
+	return a.GetProduct(id)
+}
+
+// TranslateProduct translates the product with its brand and category to the locale
+func (a *App) TranslateProduct(p *model.Product, lang string) *model.AppErr {
+	return a.TranslateProducts([]*model.Product{p}, lang)
+}
+
+// TranslateProducts translates the products with their brands and categories to the locale,
+// the text that is not translated stays in the default locale
+func (a *App) TranslateProducts(products []*model.Product, lang string) *model.AppErr {
+	if lang == locale.DefaultLocale || len(products) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(products))
+	brands := make([]*model.Brand, 0)
+	categories := make([]*model.Category, 0)
+	for _, p := range products {
+		ids = append(ids, p.ID)
+		if p.Brand != nil {
+			brands = append(brands, p.Brand)
+		}
+		if p.Category != nil {
+			categories = append(categories, p.Category)
+		}
+	}
+
+	translations, err := a.getTranslationsByResourceID(model.TranslationResourceProduct, ids, lang)
+	if err != nil {
+		return err
+	}
+	for _, p := range products {
+		if t, ok := translations[p.ID]; ok {
+			p.Translate(t)
+		}
+	}
+
+	if err := a.TranslateBrands(brands, lang); err != nil {
+		return err
+	}
+	return a.TranslateCategories(categories, lang)
+}
+
+// TranslateCategory translates the category to the locale
+func (a *App) TranslateCategory(c *model.Category, lang string) *model.AppErr {
+	return a.TranslateCategories([]*model.Category{c}, lang)
+}
+
+// TranslateCategories translates the categories to the locale
+func (a *App) TranslateCategories(categories []*model.Category, lang string) *model.AppErr {
+	if lang == locale.DefaultLocale || len(categories) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(categories))
+	for _, c := range categories {
+		ids = append(ids, c.ID)
+	}
+	translations, err := a.getTranslationsByResourceID(model.TranslationResourceCategory, ids, lang)
+	if err != nil {
+		return err
+	}
+	for _, c := range categories {
+		if t, ok := translations[c.ID]; ok {
+			c.Translate(t)
+		}
+	}
+	return nil
+}
+
+// TranslateBrand translates the brand to the locale
+func (a *App) TranslateBrand(b *model.Brand, lang string) *model.AppErr {
+	return a.TranslateBrands([]*model.Brand{b}, lang)
+}
+
+// TranslateBrands translates the brands to the locale
+func (a *App) TranslateBrands(brands []*model.Brand, lang string) *model.AppErr {
+	if lang == locale.DefaultLocale || len(brands) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(brands))
+	for _, b := range brands {
+		ids = append(ids, b.ID)
+	}
+	translations, err := a.getTranslationsByResourceID(model.TranslationResourceBrand, ids, lang)
+	if err != nil {
+		return err
+	}
+	for _, b := range brands {
+		if t, ok := translations[b.ID]; ok {
+			b.Translate(t)
+		}
+	}
+	return nil
+}
+
+func (a *App) getTranslationsByResourceID(resource string, ids []int64, lang string) (map[int64]*model.Translation, *model.AppErr) {
+	translations, err := a.Srv().Store.Translation().GetForResources(resource, ids, lang)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[int64]*model.Translation, len(translations))
+	for _, t := range translations {
+		m[t.ResourceID] = t
+	}
+	return m, nil
+}
+
+func (a *App) translatedResourceExists(resource string, id int64) *model.AppErr {
+	var err *model.AppErr
+	switch resource {
+	case model.TranslationResourceProduct:
+		_, err = a.Srv().Store.Product().Get(id)
+	case model.TranslationResourceCategory:
+		_, err = a.Srv().Store.Category().Get(id)
+	case model.TranslationResourceBrand:
+		_, err = a.Srv().Store.Brand().Get(id)
+	}
+	return err
+}
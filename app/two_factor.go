@@ -0,0 +1,276 @@
+package app
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/dankobgd/ecommerce-shop/utils/totp"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgTwoFactorSecret           = &i18n.Message{ID: "app.two_factor.secret.app_error", Other: "could not generate two-factor secret"}
+	msgTwoFactorAlreadyEnabled   = &i18n.Message{ID: "app.two_factor.already_enabled.app_error", Other: "two-factor authentication is already enabled"}
+	msgTwoFactorNotEnrolled      = &i18n.Message{ID: "app.two_factor.not_enrolled.app_error", Other: "two-factor authentication enrollment was not started"}
+	msgTwoFactorNotEnabled       = &i18n.Message{ID: "app.two_factor.not_enabled.app_error", Other: "two-factor authentication is not enabled"}
+	msgTwoFactorInvalidCode      = &i18n.Message{ID: "app.two_factor.invalid_code.app_error", Other: "invalid two-factor authentication code"}
+	msgTwoFactorRecoveryCodes    = &i18n.Message{ID: "app.two_factor.recovery_codes.app_error", Other: "could not generate recovery codes"}
+	msgTwoFactorRequired         = &i18n.Message{ID: "app.two_factor.required.app_error", Other: "two-factor authentication is required for admin accounts"}
+	msgTwoFactorInvalidChallenge = &i18n.Message{ID: "app.two_factor.invalid_challenge.app_error", Other: "login challenge is invalid or has expired"}
+	msgTwoFactorDecryptSecret    = &i18n.Message{ID: "app.two_factor.decrypt_secret.app_error", Other: "could not read two-factor secret"}
+)
+
+// GetTwoFactor gets the user's two-factor status
+func (a *App) GetTwoFactor(userID int64) (*model.TwoFactor, *model.AppErr) {
+	tf, err := a.Srv().Store.TwoFactor().Get(userID)
+	if err != nil {
+		return nil, err
+	}
+	if tf == nil {
+		return &model.TwoFactor{UserID: userID}, nil
+	}
+	if tf.Enabled {
+		left, err := a.Srv().Store.TwoFactor().CountRecoveryCodes(userID)
+		if err != nil {
+			return nil, err
+		}
+		tf.CodesLeft = left
+	}
+	return tf, nil
+}
+
+// EnrollTwoFactor starts the enrollment, the two-factor authentication is enabled only after the first code is verified
+func (a *App) EnrollTwoFactor(userID int64) (*model.TwoFactorEnrollment, *model.AppErr) {
+	l := locale.GetUserLocalizer("en")
+	tf, err := a.Srv().Store.TwoFactor().Get(userID)
+	if err != nil {
+		return nil, err
+	}
+	if tf != nil && tf.Enabled {
+		return nil, model.NewAppErr("EnrollTwoFactor", model.ErrConflict, l, msgTwoFactorAlreadyEnabled, http.StatusConflict, nil)
+	}
+
+	user, err := a.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, e := totp.GenerateSecret()
+	if e != nil {
+		return nil, model.NewAppErr("EnrollTwoFactor", model.ErrInternal, l, msgTwoFactorSecret, http.StatusInternalServerError, nil)
+	}
+
+	encrypted, err := a.encryptTwoFactorSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	tf = &model.TwoFactor{UserID: userID, Secret: encrypted}
+	tf.PreSave()
+	if err := a.Srv().Store.TwoFactor().Save(tf); err != nil {
+		return nil, err
+	}
+
+	issuer := a.Cfg().AuthSettings.TwoFactorIssuer
+	return &model.TwoFactorEnrollment{Secret: secret, URI: totp.ProvisioningURI(issuer, user.Email, secret)}, nil
+}
+
+// EnableTwoFactor verifies the first code of the enrollment, enables the two-factor authentication and returns the recovery codes
+func (a *App) EnableTwoFactor(userID int64, code string) (*model.RecoveryCodes, *model.AppErr) {
+	l := locale.GetUserLocalizer("en")
+	tf, err := a.Srv().Store.TwoFactor().Get(userID)
+	if err != nil {
+		return nil, err
+	}
+	if tf == nil {
+		return nil, model.NewAppErr("EnableTwoFactor", model.ErrInvalid, l, msgTwoFactorNotEnrolled, http.StatusBadRequest, nil)
+	}
+	if tf.Enabled {
+		return nil, model.NewAppErr("EnableTwoFactor", model.ErrConflict, l, msgTwoFactorAlreadyEnabled, http.StatusConflict, nil)
+	}
+
+	secret, err := a.twoFactorSecret(tf)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return nil, model.NewAppErr("EnableTwoFactor", model.ErrInvalid, l, msgTwoFactorInvalidCode, http.StatusBadRequest, nil)
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := a.Srv().Store.TwoFactor().Enable(userID, step, hashes); err != nil {
+		return nil, err
+	}
+	a.audit(model.AuditActionUpdate, model.AuditResourceTwoFactor, userID, map[string]bool{"enabled": false}, map[string]bool{"enabled": true})
+	return &model.RecoveryCodes{Codes: codes}, nil
+}
+
+// DisableTwoFactor disables the two-factor authentication after the code is verified
+func (a *App) DisableTwoFactor(userID int64, code string) *model.AppErr {
+	user, err := a.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.Role == model.AdminRole && a.Cfg().AuthSettings.TwoFactorRequiredForAdmins {
+		return model.NewAppErr("DisableTwoFactor", model.ErrUnauthorized, locale.GetUserLocalizer("en"), msgTwoFactorRequired, http.StatusForbidden, nil)
+	}
+
+	if err := a.VerifyTwoFactorCode(userID, code); err != nil {
+		return err
+	}
+	if err := a.Srv().Store.TwoFactor().Delete(userID); err != nil {
+		return err
+	}
+	a.audit(model.AuditActionDelete, model.AuditResourceTwoFactor, userID, map[string]bool{"enabled": true}, nil)
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes after the code is verified
+func (a *App) RegenerateRecoveryCodes(userID int64, code string) (*model.RecoveryCodes, *model.AppErr) {
+	if err := a.VerifyTwoFactorCode(userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := a.Srv().Store.TwoFactor().ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+	a.audit(model.AuditActionUpdate, model.AuditResourceTwoFactor, userID, nil, nil)
+	return &model.RecoveryCodes{Codes: codes}, nil
+}
+
+// VerifyTwoFactorCode checks the TOTP code or uses up the recovery code, each TOTP code is accepted only once
+func (a *App) VerifyTwoFactorCode(userID int64, code string) *model.AppErr {
+	l := locale.GetUserLocalizer("en")
+	tf, err := a.Srv().Store.TwoFactor().Get(userID)
+	if err != nil {
+		return err
+	}
+	if tf == nil || !tf.Enabled {
+		return model.NewAppErr("VerifyTwoFactorCode", model.ErrInvalid, l, msgTwoFactorNotEnabled, http.StatusBadRequest, nil)
+	}
+
+	var ok bool
+	if model.IsRecoveryCode(code) {
+		ok, err = a.Srv().Store.TwoFactor().UseRecoveryCode(userID, model.HashRecoveryCode(code))
+	} else {
+		secret, sErr := a.twoFactorSecret(tf)
+		if sErr != nil {
+			return sErr
+		}
+		if step, valid := totp.Validate(secret, code, time.Now()); valid {
+			ok, err = a.Srv().Store.TwoFactor().UseStep(userID, step)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return model.NewAppErr("VerifyTwoFactorCode", model.ErrUnauthenticated, l, msgTwoFactorInvalidCode, http.StatusUnauthorized, nil)
+	}
+	return nil
+}
+
+// IsTwoFactorEnabled checks if the user has to enter the code on login
+func (a *App) IsTwoFactorEnabled(userID int64) (bool, *model.AppErr) {
+	tf, err := a.Srv().Store.TwoFactor().Get(userID)
+	if err != nil {
+		return false, err
+	}
+	return tf != nil && tf.Enabled, nil
+}
+
+// CreateLoginChallenge creates the short lived challenge the user exchanges for the tokens together with the code
+func (a *App) CreateLoginChallenge(userID int64) (*model.LoginChallenge, *model.AppErr) {
+	token := model.NewToken(model.TokenTypeTwoFactorChallenge, userID)
+	token.ExpiresAt = token.CreatedAt.Add(model.TwoFactorChallengeExpiry)
+	if err := a.Srv().Store.Token().Save(token); err != nil {
+		return nil, err
+	}
+	return &model.LoginChallenge{Step: model.LoginStepTOTP, ChallengeToken: token.Token, ExpiresAt: token.ExpiresAt}, nil
+}
+
+// VerifyLoginChallenge verifies the code of the login challenge and returns the user the tokens can be issued to,
+// the wrong codes count as the failed logins so the codes can't be guessed
+func (a *App) VerifyLoginChallenge(challenge *model.TwoFactorCode, ip string) (*model.User, *model.AppErr) {
+	token, err := a.Srv().Store.Token().GetByToken(challenge.ChallengeToken)
+	if err != nil || token.Type != model.TokenTypeTwoFactorChallenge.String() || time.Now().After(token.ExpiresAt) {
+		return nil, model.NewAppErr("VerifyLoginChallenge", model.ErrUnauthenticated, locale.GetUserLocalizer("en"), msgTwoFactorInvalidChallenge, http.StatusUnauthorized, nil)
+	}
+
+	user, err := a.GetUserByID(token.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.CheckLoginAllowed(user.Email, ip); err != nil {
+		return nil, err
+	}
+
+	if err := a.VerifyTwoFactorCode(user.ID, challenge.Code); err != nil {
+		if err.StatusCode == http.StatusUnauthorized {
+			if rErr := a.RecordLoginFailure(user.Email, ip, user); rErr != nil {
+				return nil, rErr
+			}
+		}
+		return nil, err
+	}
+
+	a.deleteToken(token)
+	if err := a.ResetLoginFailures(user.Email); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// CheckAdminTwoFactor rejects the admins without the two-factor authentication when it's required for them
+func (a *App) CheckAdminTwoFactor(ad *model.AccessData) *model.AppErr {
+	if ad.Role != model.AdminRole || !a.Cfg().AuthSettings.TwoFactorRequiredForAdmins {
+		return nil
+	}
+	enabled, err := a.IsTwoFactorEnabled(ad.UserID)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return model.NewAppErr("CheckAdminTwoFactor", model.ErrUnauthorized, locale.GetUserLocalizer("en"), msgTwoFactorRequired, http.StatusForbidden, nil)
+	}
+	return nil
+}
+
+func (a *App) encryptTwoFactorSecret(secret string) (string, *model.AppErr) {
+	encrypted, err := totp.EncryptSecret(secret, a.Cfg().AuthSettings.TwoFactorEncryptionKey)
+	if err != nil {
+		return "", model.NewAppErr("encryptTwoFactorSecret", model.ErrInternal, locale.GetUserLocalizer("en"), msgTwoFactorSecret, http.StatusInternalServerError, nil)
+	}
+	return encrypted, nil
+}
+
+// twoFactorSecret decrypts the stored secret
+func (a *App) twoFactorSecret(tf *model.TwoFactor) (string, *model.AppErr) {
+	secret, err := totp.DecryptSecret(tf.Secret, a.Cfg().AuthSettings.TwoFactorEncryptionKey)
+	if err != nil {
+		return "", model.NewAppErr("twoFactorSecret", model.ErrInternal, locale.GetUserLocalizer("en"), msgTwoFactorDecryptSecret, http.StatusInternalServerError, nil)
+	}
+	return secret, nil
+}
+
+func newRecoveryCodes() ([]string, []string, *model.AppErr) {
+	codes, err := model.NewRecoveryCodes()
+	if err != nil {
+		return nil, nil, model.NewAppErr("newRecoveryCodes", model.ErrInternal, locale.GetUserLocalizer("en"), msgTwoFactorRecoveryCodes, http.StatusInternalServerError, nil)
+	}
+	hashes := make([]string, 0, len(codes))
+	for _, c := range codes {
+		hashes = append(hashes, model.HashRecoveryCode(c))
+	}
+	return codes, hashes, nil
+}
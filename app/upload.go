@@ -16,6 +16,10 @@ func (a *App) deleteImageFromCloudinary(publicID string) *model.AppErr {
 	return fileupload.DeleteImageFromCloudinary(publicID, a.Cfg().CloudinarySettings.EnvURI)
 }
 
+func (a *App) listCloudinaryImages() ([]*gocloudinary.Resource, *model.AppErr) {
+	return fileupload.ListCloudinaryImages(a.Cfg().CloudinarySettings.EnvURI)
+}
+
 // UploadImage uploads the image and returns the preview url
 func (a *App) UploadImage(data io.Reader, filename string) (*gocloudinary.ResourceDetails, *model.AppErr) {
 	return a.uploadImageToCloudinary(data, filename)
@@ -16,8 +16,9 @@ import (
 
 var (
 	msgTokenExpired               = &i18n.Message{ID: "model.token.expired.app_error", Other: "token has expired"}
+	msgTokenType                  = &i18n.Message{ID: "app.token.type.app_error", Other: "invalid token"}
 	msgUploadUserAvatar           = &i18n.Message{ID: "app.upload_user_avatar.app_error", Other: "could not upload user avatar"}
-	msgUserAvatarFileSizeExceeded = &i18n.Message{ID: "app.upload_user_avatar.app_error", Other: "File size limit exceeded"}
+	msgUserAvatarFileSizeExceeded = &i18n.Message{ID: "app.upload_user_avatar.size_limit.app_error", Other: "File size limit exceeded"}
 )
 
 // CreateUser creates the new user and in the system
@@ -48,13 +49,14 @@ func (a *App) CreateUser(u *model.User, fh *multipart.FileHeader) (*model.User,
 		u.SetAvatarDetails(details)
 	}
 
-	user, sErr := a.Srv().Store.User().Save(u)
+	user, sErr := a.Srv().Store.User().Save(u, userSignedUpEvent(u))
 	if sErr != nil {
 		a.Log().Error(sErr.Error(), zlog.Err(sErr))
 		return nil, sErr
 	}
 
 	user.Sanitize(map[string]bool{})
+	a.audit(model.AuditActionCreate, model.AuditResourceUser, user.ID, nil, user)
 	return user, nil
 }
 
@@ -69,7 +71,7 @@ func (a *App) Signup(u *model.User) (*model.User, *model.AppErr) {
 		return nil, err
 	}
 
-	user, err := a.Srv().Store.User().Save(u)
+	user, err := a.Srv().Store.User().Save(u, userSignedUpEvent(u))
 
 	if err != nil {
 		a.Log().Error(err.Error(), zlog.Err(err))
@@ -77,35 +79,85 @@ func (a *App) Signup(u *model.User) (*model.User, *model.AppErr) {
 	}
 
 	user.Sanitize(map[string]bool{})
+	a.audit(model.AuditActionCreate, model.AuditResourceUser, user.ID, nil, user)
 	return user, nil
 }
 
-// Login handles the user login
-func (a *App) Login(u *model.UserLogin) (*model.User, *model.AppErr) {
+// Login handles the user login, the challenge is returned instead of the user when the two-factor authentication is enabled
+func (a *App) Login(u *model.UserLogin, ip string) (*model.User, *model.LoginChallenge, *model.AppErr) {
 	if err := u.Validate(); err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if err := a.CheckLoginAllowed(u.Email, ip); err != nil {
+		return nil, nil, err
 	}
 
 	user, err := a.Srv().Store.User().GetByEmail(u.Email)
 	if err != nil {
 		a.Log().Error(err.Error(), zlog.Err(err))
-		return nil, err
+		if rErr := a.RecordLoginFailure(u.Email, ip, nil); rErr != nil {
+			return nil, nil, rErr
+		}
+		return nil, nil, err
 	}
 	if err := a.CheckUserPassword(user, u.Password); err != nil {
-		return nil, err
+		if rErr := a.RecordLoginFailure(u.Email, ip, user); rErr != nil {
+			return nil, nil, rErr
+		}
+		return nil, nil, err
 	}
 
-	return user, nil
-}
+	enabled, err := a.IsTwoFactorEnabled(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if enabled {
+		challenge, err := a.CreateLoginChallenge(user.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, challenge, nil
+	}
 
-// SaveAuth saves the user auth information
-func (a *App) SaveAuth(userID int64, meta *model.TokenMetadata) *model.AppErr {
+	if err := a.ResetLoginFailures(u.Email); err != nil {
+		return nil, nil, err
+	}
+	user.Sanitize(map[string]bool{})
+	return user, nil, nil
+}
+
+// SaveAuth saves the user auth information, the new session is created when the session is provided
+func (a *App) SaveAuth(userID int64, meta *model.TokenMetadata, session *model.Session) *model.AppErr {
+	if session != nil {
+		session.ID = meta.SessionID
+		session.UserID = userID
+		session.AccessUUID = meta.AccessUUID
+		session.RefreshUUID = meta.RefreshUUID
+		session.ExpiresAt = meta.RefreshExpires
+		session.PreSave()
+		if err := a.Srv().Store.Session().Save(session); err != nil {
+			return err
+		}
+	}
 	return a.Srv().Store.AccessToken().SaveAuth(userID, meta)
 }
 
-// GetAuth gets the auth details information
+// GetAuth gets the auth details information and records the session activity
 func (a *App) GetAuth(ad *model.AccessData) (int64, *model.AppErr) {
-	return a.Srv().Store.AccessToken().GetAuth(ad)
+	userID, err := a.Srv().Store.AccessToken().GetAuth(ad)
+	if err != nil {
+		return 0, err
+	}
+	if ad.SessionID != "" {
+		ok, err := a.Srv().Store.Session().Touch(ad.SessionID)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			return 0, model.NewAppErr("GetAuth", model.ErrUnauthenticated, locale.GetUserLocalizer("en"), msgSessionRevoked, http.StatusUnauthorized, nil)
+		}
+	}
+	return userID, nil
 }
 
 // DeleteAuth deletes the user auth details
@@ -178,6 +230,9 @@ func (a *App) VerifyUserEmail(tokenString string) *model.AppErr {
 		return err
 	}
 
+	if token.Type != model.TokenTypeEmailVerification.String() {
+		return model.NewAppErr("app.VerifyUserEmail", model.ErrInvalid, locale.GetUserLocalizer("en"), msgTokenType, http.StatusBadRequest, nil)
+	}
 	if time.Now().After(token.ExpiresAt) {
 		return model.NewAppErr("app.VerifyUserEmail", model.ErrInternal, locale.GetUserLocalizer("en"), msgTokenExpired, http.StatusInternalServerError, nil)
 	}
@@ -185,10 +240,9 @@ func (a *App) VerifyUserEmail(tokenString string) *model.AppErr {
 	if err := a.Srv().Store.User().VerifyEmail(token.UserID); err != nil {
 		return err
 	}
+	a.audit(model.AuditActionUpdate, model.AuditResourceUser, token.UserID, map[string]bool{"email_verified": false}, map[string]bool{"email_verified": true})
 
-	if err := a.Srv().Store.Token().Delete(token.Token); err != nil {
-		zlog.Error("could not delete token", zlog.Int64("user_id", token.UserID), zlog.String("token_type", token.Type), zlog.Err(err))
-	}
+	a.deleteToken(token)
 
 	return nil
 }
@@ -209,13 +263,16 @@ func (a *App) SendPasswordResetEmail(email string) *model.AppErr {
 	return nil
 }
 
-// ResetUserPassword resets the user pwd
+// ResetUserPassword resets the user pwd and logs the user out everywhere
 func (a *App) ResetUserPassword(tokenString, newPassword string) *model.AppErr {
 	token, err := a.Srv().Store.Token().GetByToken(tokenString)
 	if err != nil {
 		return err
 	}
 
+	if token.Type != model.TokenTypePasswordRecovery.String() {
+		return model.NewAppErr("app.ResetUserPassword", model.ErrInvalid, locale.GetUserLocalizer("en"), msgTokenType, http.StatusBadRequest, nil)
+	}
 	if time.Now().After(token.ExpiresAt) {
 		return model.NewAppErr("app.ResetUserPassword", model.ErrInternal, locale.GetUserLocalizer("en"), msgTokenExpired, http.StatusInternalServerError, nil)
 	}
@@ -229,20 +286,11 @@ func (a *App) ResetUserPassword(tokenString, newPassword string) *model.AppErr {
 		return err
 	}
 
-	if err := a.Srv().Store.Token().Delete(token.Token); err != nil {
-		a.Log().Error("could not delete token", zlog.Int64("user_id", token.UserID), zlog.String("token_type", token.Type), zlog.Err(err))
-	}
-
-	go func() {
-		if err := a.SendPasswordUpdatedEmail(user.Email, user.Username, a.SiteURL(), user.Locale); err != nil {
-			zlog.Error("could not send password reset completed email", zlog.Int64("user_id", token.UserID), zlog.Err(err))
-		}
-	}()
-
-	return nil
+	a.deleteToken(token)
+	return a.RevokeSessions(user.ID)
 }
 
-// ChangeUserPassword updates the user pwd from the app
+// ChangeUserPassword updates the user pwd from the app and logs the user out everywhere
 func (a *App) ChangeUserPassword(uid int64, oldPassword, newPassword string) *model.AppErr {
 	user, err := a.GetUserByIDWithPassword(uid)
 	if err != nil {
@@ -256,14 +304,7 @@ func (a *App) ChangeUserPassword(uid int64, oldPassword, newPassword string) *mo
 	if err := a.UpdatePassword(user, newPassword); err != nil {
 		return err
 	}
-
-	go func() {
-		if err := a.SendPasswordUpdatedEmail(user.Email, user.Username, a.SiteURL(), user.Locale); err != nil {
-			a.Log().Error("could not send password reset completed email", zlog.Int64("user_id", uid), zlog.Err(err))
-		}
-	}()
-
-	return nil
+	return a.RevokeSessions(uid)
 }
 
 // UpdatePassword sets the new user password
@@ -272,12 +313,22 @@ func (a *App) UpdatePassword(user *model.User, newPassword string) *model.AppErr
 		return err
 	}
 	hashed := model.HashPassword(newPassword)
-	if err := a.Srv().Store.User().UpdatePassword(user.ID, hashed); err != nil {
+	e := model.NewEvent(model.EventPasswordChanged, model.AggregateUser, user.ID, userEventData(user))
+	if err := a.Srv().Store.User().UpdatePassword(user.ID, hashed, e); err != nil {
 		return err
 	}
+	a.audit(model.AuditActionUpdate, model.AuditResourceUser, user.ID, map[string]string{"password": user.Password}, map[string]string{"password": hashed})
 	return nil
 }
 
+func userEventData(u *model.User) *model.UserEventData {
+	return &model.UserEventData{Email: u.Email, Username: u.Username, Locale: u.Locale}
+}
+
+func userSignedUpEvent(u *model.User) *model.Event {
+	return model.NewEvent(model.EventUserSignedUp, model.AggregateUser, 0, userEventData(u))
+}
+
 func (a *App) createTokenAndPersist(userID int64, tokenType model.TokenType, expiryHours ...int) (*model.Token, *model.AppErr) {
 	token := model.NewToken(tokenType, userID, expiryHours...)
 	if err := a.Srv().Store.Token().Save(token); err != nil {
@@ -287,7 +338,7 @@ func (a *App) createTokenAndPersist(userID int64, tokenType model.TokenType, exp
 }
 
 // PatchUser patches the user
-func (a *App) PatchUser(uid int64, patch *model.UserPatch, fh *multipart.FileHeader) (*model.User, *model.AppErr) {
+func (a *App) PatchUser(ad *model.AccessData, uid int64, patch *model.UserPatch, fh *multipart.FileHeader) (*model.User, *model.AppErr) {
 	if err := patch.Validate(); err != nil {
 		return nil, err
 	}
@@ -296,7 +347,12 @@ func (a *App) PatchUser(uid int64, patch *model.UserPatch, fh *multipart.FileHea
 	if err != nil {
 		return nil, err
 	}
+	if err := a.authorizeAccountChange(ad, old); err != nil {
+		return nil, err
+	}
 
+	before := *old
+	before.Sanitize(map[string]bool{})
 	oldPublicID := old.AvatarPublicID
 
 	if fh != nil {
@@ -326,7 +382,7 @@ func (a *App) PatchUser(uid int64, patch *model.UserPatch, fh *multipart.FileHea
 
 	defer func() {
 		if oldPublicID != nil && *oldPublicID != "" {
-			if err := a.DeleteImage(*oldPublicID); err != nil {
+			if err := a.EnqueueDeleteImage(*oldPublicID); err != nil {
 				a.Log().Error(err.Error(), zlog.Err(err))
 			}
 		}
@@ -334,6 +390,7 @@ func (a *App) PatchUser(uid int64, patch *model.UserPatch, fh *multipart.FileHea
 	}()
 
 	uuser.Sanitize(map[string]bool{})
+	a.audit(model.AuditActionUpdate, model.AuditResourceUser, uid, &before, uuser)
 	return uuser, nil
 }
 
@@ -344,6 +401,8 @@ func (a *App) PatchUserProfile(id int64, patch *model.UserPatch) (*model.User, *
 		return nil, err
 	}
 
+	before := *old
+	before.Sanitize(map[string]bool{})
 	old.Patch(patch)
 	old.PreUpdate()
 	if err := patch.Validate(); err != nil {
@@ -355,24 +414,30 @@ func (a *App) PatchUserProfile(id int64, patch *model.UserPatch) (*model.User, *
 	}
 
 	user.Sanitize(map[string]bool{})
+	a.audit(model.AuditActionUpdate, model.AuditResourceUser, id, &before, user)
 	return user, nil
 }
 
 // DeleteUser soft deletes the user account
-func (a *App) DeleteUser(id int64) *model.AppErr {
+func (a *App) DeleteUser(ad *model.AccessData, id int64) *model.AppErr {
 	old, e := a.Srv().Store.User().Get(id)
 	if e != nil {
 		return e
 	}
+	if err := a.authorizeAccountChange(ad, old); err != nil {
+		return err
+	}
 
 	err := a.Srv().Store.User().Delete(id)
 	if err != nil {
 		return err
 	}
+	old.Sanitize(map[string]bool{})
+	a.audit(model.AuditActionDelete, model.AuditResourceUser, id, old, nil)
 
 	defer func() {
 		if old.AvatarPublicID != nil && *old.AvatarPublicID != "" {
-			if err := a.DeleteImage(*old.AvatarPublicID); err != nil {
+			if err := a.EnqueueDeleteImage(*old.AvatarPublicID); err != nil {
 				a.Log().Error(err.Error(), zlog.Err(err))
 			}
 		}
@@ -381,9 +446,33 @@ func (a *App) DeleteUser(id int64) *model.AppErr {
 	return nil
 }
 
-// DeleteUsers bulk deletes users
-func (a *App) DeleteUsers(ids []int) *model.AppErr {
-	return a.Srv().Store.User().BulkDelete(ids)
+// DeleteUsers bulk deletes users, nothing is deleted if any of the accounts can't be changed by the user
+func (a *App) DeleteUsers(ad *model.AccessData, ids []int) *model.AppErr {
+	for _, id := range ids {
+		u, err := a.Srv().Store.User().Get(int64(id))
+		if err != nil {
+			continue
+		}
+		if err := a.authorizeAccountChange(ad, u); err != nil {
+			return err
+		}
+	}
+
+	before := a.auditSnapshots(ids, func(id int64) (interface{}, *model.AppErr) {
+		u, err := a.Srv().Store.User().Get(id)
+		if err != nil {
+			return nil, err
+		}
+		u.Sanitize(map[string]bool{})
+		return u, nil
+	})
+	if err := a.Srv().Store.User().BulkDelete(ids); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		a.audit(model.AuditActionDelete, model.AuditResourceUser, id, before[id], nil)
+	}
+	return nil
 }
 
 // UploadUserAvatar uploads the user profile image and returns the avatar url
@@ -399,18 +488,29 @@ func (a *App) UploadUserAvatar(userID int64, f multipart.File, fh *multipart.Fil
 		return model.NewString(""), model.NewString(""), uErr
 	}
 
-	return a.Srv().Store.User().UpdateAvatar(userID, model.NewString(details.SecureURL), model.NewString(details.PublicID))
+	old := a.auditSnapshot(func() (interface{}, *model.AppErr) { return a.Srv().Store.User().Get(userID) })
+	url, publicID, sErr := a.Srv().Store.User().UpdateAvatar(userID, model.NewString(details.SecureURL), model.NewString(details.PublicID))
+	if sErr != nil {
+		return url, publicID, sErr
+	}
+	updated := a.auditSnapshot(func() (interface{}, *model.AppErr) { return a.Srv().Store.User().Get(userID) })
+	a.audit(model.AuditActionUpdate, model.AuditResourceUser, userID, old, updated)
+	return url, publicID, nil
 }
 
 // DeleteUserAvatar deletes the user profile image
 func (a *App) DeleteUserAvatar(userID int64, publicID string) *model.AppErr {
-	go func() {
-		if err := a.DeleteImage(publicID); err != nil {
-			a.Log().Error("could not delete user avatar from cloudinary", zlog.Int64("user_id", userID), zlog.String("public_id", publicID), zlog.Err(err))
-		}
-	}()
+	if err := a.EnqueueDeleteImage(publicID); err != nil {
+		a.Log().Error("could not enqueue user avatar removal from cloudinary", zlog.Int64("user_id", userID), zlog.String("public_id", publicID), zlog.Err(err))
+	}
 
-	return a.Srv().Store.User().DeleteAvatar(userID)
+	old := a.auditSnapshot(func() (interface{}, *model.AppErr) { return a.Srv().Store.User().Get(userID) })
+	if err := a.Srv().Store.User().DeleteAvatar(userID); err != nil {
+		return err
+	}
+	updated := a.auditSnapshot(func() (interface{}, *model.AppErr) { return a.Srv().Store.User().Get(userID) })
+	a.audit(model.AuditActionUpdate, model.AuditResourceUser, userID, old, updated)
+	return nil
 }
 
 // CreateUserAddress creates the user addresss
@@ -434,10 +534,15 @@ func (a *App) CreateUserAddress(addr *model.Address, userID int64) (*model.Addre
 	}
 
 	addr.PreSave()
-	return a.Srv().Store.Address().Save(addr, userID)
+	address, err := a.Srv().Store.Address().Save(addr, userID)
+	if err != nil {
+		return nil, err
+	}
+	a.audit(model.AuditActionCreate, model.AuditResourceAddress, address.ID, nil, address)
+	return address, nil
 }
 
-// GetUserAddress gets the user addresss
+// GetUserAddress gets the user addresss, the address that belongs to another user is not found
 func (a *App) GetUserAddress(userID, addressID int64) (*model.Address, *model.AppErr) {
 	return a.Srv().Store.Address().Get(userID, addressID)
 }
@@ -454,42 +559,31 @@ func (a *App) PatchUserAddress(userID, addressID int64, patch *model.AddressPatc
 		return nil, err
 	}
 
+	before := *old
 	old.Patch(patch)
 	old.PreUpdate()
 	uaddress, err := a.Srv().Store.Address().Update(addressID, old)
 	if err != nil {
 		return nil, err
 	}
-
+	a.audit(model.AuditActionUpdate, model.AuditResourceAddress, addressID, &before, uaddress)
 	return uaddress, nil
 }
 
 // DeleteUserAddress hard deletes the user address
-func (a *App) DeleteUserAddress(id int64) *model.AppErr {
-	return a.Srv().Store.Address().Delete(id)
+func (a *App) DeleteUserAddress(userID, addressID int64) *model.AppErr {
+	old, err := a.Srv().Store.Address().Get(userID, addressID)
+	if err != nil {
+		return err
+	}
+	if err := a.Srv().Store.Address().Delete(addressID); err != nil {
+		return err
+	}
+	a.audit(model.AuditActionDelete, model.AuditResourceAddress, addressID, old, nil)
+	return nil
 }
 
 // GetOrdersForUser gets all user orders
 func (a *App) GetOrdersForUser(uid int64, limit, offset int) ([]*model.Order, *model.AppErr) {
 	return a.Srv().Store.User().GetAllOrders(uid, limit, offset)
 }
-
-// CreateWishlistForUser adds new product t the wishlist
-func (a *App) CreateWishlistForUser(uid, pid int64) *model.AppErr {
-	return a.Srv().Store.User().CreateWishlist(uid, pid)
-}
-
-// GetWishlistForUser gets all wishlist products for user
-func (a *App) GetWishlistForUser(uid int64) ([]*model.Product, *model.AppErr) {
-	return a.Srv().Store.User().GetWishlist(uid)
-}
-
-// DeleteWishlistForUser gets all user orders
-func (a *App) DeleteWishlistForUser(uid, pid int64) *model.AppErr {
-	return a.Srv().Store.User().DeleteWishlist(uid, pid)
-}
-
-// ClearWishlistForUser gets all user orders
-func (a *App) ClearWishlistForUser(uid int64) *model.AppErr {
-	return a.Srv().Store.User().ClearWishlist(uid)
-}
@@ -0,0 +1,281 @@
+package app
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/dankobgd/ecommerce-shop/utils/random"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgEncodeWebhookPayload = &i18n.Message{ID: "app.webhook.encode_payload.app_error", Other: "could not encode webhook payload"}
+	msgWebhookRequest       = &i18n.Message{ID: "app.webhook.request.app_error", Other: "could not send webhook request"}
+	msgWebhookResponse      = &i18n.Message{ID: "app.webhook.response.app_error", Other: "webhook responded with an unsuccessful status code"}
+)
+
+// webhook request headers
+const (
+	headerWebhookDelivery  = "X-Webhook-Delivery"
+	headerWebhookEvent     = "X-Webhook-Event"
+	headerWebhookTimestamp = "X-Webhook-Timestamp"
+	headerWebhookSignature = "X-Webhook-Signature"
+)
+
+const webhookSecretLength = 32
+
+type webhookJobPayload struct {
+	WebhookID  int64 `json:"webhook_id"`
+	DeliveryID int64 `json:"delivery_id"`
+}
+
+// CreateWebhook creates the new webhook subscription, the secret is generated if not provided
+func (a *App) CreateWebhook(wh *model.Webhook) (*model.Webhook, *model.AppErr) {
+	if wh.Secret == "" {
+		wh.Secret = random.SecureToken(webhookSecretLength)
+	}
+	wh.PreSave()
+	if err := wh.Validate(); err != nil {
+		return nil, err
+	}
+	created, err := a.Srv().Store.Webhook().Save(wh)
+	if err != nil {
+		return nil, err
+	}
+	a.audit(model.AuditActionCreate, model.AuditResourceWebhook, created.ID, nil, created)
+	return created, nil
+}
+
+// GetWebhooks gets all webhooks
+func (a *App) GetWebhooks(limit, offset int) ([]*model.Webhook, *model.AppErr) {
+	return a.Srv().Store.Webhook().GetAll(limit, offset)
+}
+
+// GetWebhook gets the webhook by id
+func (a *App) GetWebhook(id int64) (*model.Webhook, *model.AppErr) {
+	return a.Srv().Store.Webhook().Get(id)
+}
+
+// PatchWebhook patches the webhook
+func (a *App) PatchWebhook(id int64, patch *model.WebhookPatch) (*model.Webhook, *model.AppErr) {
+	wh, err := a.Srv().Store.Webhook().Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *wh
+	wh.Patch(patch)
+	wh.PreUpdate()
+	if err := wh.Validate(); err != nil {
+		return nil, err
+	}
+	updated, err := a.Srv().Store.Webhook().Update(id, wh)
+	if err != nil {
+		return nil, err
+	}
+	a.audit(model.AuditActionUpdate, model.AuditResourceWebhook, id, &before, updated)
+	return updated, nil
+}
+
+// DeleteWebhook deletes the webhook
+func (a *App) DeleteWebhook(id int64) *model.AppErr {
+	old, _ := a.Srv().Store.Webhook().Get(id)
+	if err := a.Srv().Store.Webhook().Delete(id); err != nil {
+		return err
+	}
+	a.audit(model.AuditActionDelete, model.AuditResourceWebhook, id, old, nil)
+	return nil
+}
+
+// GetWebhookDeliveries gets the webhook delivery log
+func (a *App) GetWebhookDeliveries(webhookID int64, status string, limit, offset int) ([]*model.WebhookDelivery, *model.AppErr) {
+	return a.Srv().Store.Webhook().GetDeliveries(webhookID, status, limit, offset)
+}
+
+// GetWebhookDelivery gets the webhook delivery
+func (a *App) GetWebhookDelivery(webhookID, id int64) (*model.WebhookDelivery, *model.AppErr) {
+	return a.Srv().Store.Webhook().GetDelivery(webhookID, id)
+}
+
+// ReplayWebhookDelivery sends the delivered event to the webhook again as a new delivery
+func (a *App) ReplayWebhookDelivery(webhookID, id int64) (*model.WebhookDelivery, *model.AppErr) {
+	original, err := a.Srv().Store.Webhook().GetDelivery(webhookID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	replayOf := original.ID
+	if original.ReplayOf != nil {
+		replayOf = *original.ReplayOf
+	}
+
+	d := &model.WebhookDelivery{
+		WebhookID: original.WebhookID,
+		EventID:   original.EventID,
+		EventType: original.EventType,
+		Payload:   original.Payload,
+		Status:    model.WebhookDeliveryPending,
+		ReplayOf:  &replayOf,
+		CreatedAt: time.Now(),
+	}
+	replay, err := a.Srv().Store.Webhook().SaveDelivery(d)
+	if err != nil {
+		return nil, err
+	}
+	a.audit(model.AuditActionCreate, model.AuditResourceWebhookDelivery, auditID(webhookID, replay.ID), nil, replay)
+	if err := a.enqueueWebhookDelivery(replay); err != nil {
+		return nil, err
+	}
+	return replay, nil
+}
+
+// onWebhookEvent creates a delivery for every active webhook subscribed to the event
+func (a *App) onWebhookEvent(e *model.Event) *model.AppErr {
+	webhooks, err := a.Srv().Store.Webhook().GetActiveByEventType(e.Type)
+	if err != nil {
+		return err
+	}
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	b, mErr := json.Marshal(&model.WebhookPayload{
+		ID:            e.ID,
+		Type:          e.Type,
+		AggregateType: e.AggregateType,
+		AggregateID:   e.AggregateID,
+		CreatedAt:     e.CreatedAt,
+		Data:          e.Payload,
+	})
+	if mErr != nil {
+		return model.NewAppErr("onWebhookEvent", model.ErrInternal, locale.GetUserLocalizer("en"), msgEncodeWebhookPayload, http.StatusInternalServerError, nil)
+	}
+
+	for _, wh := range webhooks {
+		d := &model.WebhookDelivery{
+			WebhookID: wh.ID,
+			EventID:   e.ID,
+			EventType: e.Type,
+			Payload:   b,
+			Status:    model.WebhookDeliveryPending,
+			CreatedAt: time.Now(),
+		}
+		saved, err := a.Srv().Store.Webhook().SaveDelivery(d)
+		if err != nil {
+			return err
+		}
+		if err := a.enqueueWebhookDelivery(saved); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *App) enqueueWebhookDelivery(d *model.WebhookDelivery) *model.AppErr {
+	b, err := json.Marshal(&webhookJobPayload{WebhookID: d.WebhookID, DeliveryID: d.ID})
+	if err != nil {
+		return model.NewAppErr("enqueueWebhookDelivery", model.ErrInternal, locale.GetUserLocalizer("en"), msgEncodeJobPayload, http.StatusInternalServerError, nil)
+	}
+
+	key := fmt.Sprintf("webhook_delivery:%d", d.ID)
+	job := &model.Job{
+		Type:        model.JobTypeDeliverWebhook,
+		Payload:     b,
+		MaxAttempts: a.Cfg().WebhookSettings.MaxAttempts,
+		UniqueKey:   &key,
+	}
+	job.PreSave()
+
+	_, e := a.Srv().Store.Job().Save(job)
+	return e
+}
+
+func (a *App) runDeliverWebhookJob(payload []byte) *model.AppErr {
+	var p webhookJobPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return model.NewAppErr("runDeliverWebhookJob", model.ErrInternal, locale.GetUserLocalizer("en"), msgDecodeJobPayload, http.StatusInternalServerError, nil)
+	}
+
+	wh, err := a.Srv().Store.Webhook().Get(p.WebhookID)
+	if err != nil {
+		return err
+	}
+	d, err := a.Srv().Store.Webhook().GetDelivery(p.WebhookID, p.DeliveryID)
+	if err != nil {
+		return err
+	}
+
+	deliveryErr := a.deliverWebhook(wh, d)
+	if err := a.Srv().Store.Webhook().UpdateDelivery(d); err != nil {
+		return err
+	}
+	return deliveryErr
+}
+
+// deliverWebhook posts the signed payload to the webhook url and records the outcome on the delivery
+func (a *App) deliverWebhook(wh *model.Webhook, d *model.WebhookDelivery) *model.AppErr {
+	now := time.Now()
+	ts := strconv.FormatInt(now.Unix(), 10)
+
+	d.Attempts++
+	d.LastAttemptAt = &now
+	d.Status = model.WebhookDeliveryFailed
+	d.ResponseCode = nil
+	d.ResponseBody = nil
+	d.Error = nil
+
+	req, err := http.NewRequest(http.MethodPost, wh.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		msg := err.Error()
+		d.Error = &msg
+		return model.NewAppErr("deliverWebhook", model.ErrInternal, locale.GetUserLocalizer("en"), msgWebhookRequest, http.StatusInternalServerError, nil)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(headerWebhookDelivery, strconv.FormatInt(d.ID, 10))
+	req.Header.Set(headerWebhookEvent, d.EventType)
+	req.Header.Set(headerWebhookTimestamp, ts)
+	req.Header.Set(headerWebhookSignature, "sha256="+signWebhookPayload(wh.Secret, ts, d.Payload))
+
+	client := &http.Client{Timeout: time.Duration(a.Cfg().WebhookSettings.TimeoutSeconds) * time.Second}
+	resp, err := client.Do(req)
+	duration := int(time.Since(now) / time.Millisecond)
+	d.DurationMS = &duration
+	if err != nil {
+		msg := err.Error()
+		d.Error = &msg
+		return model.NewAppErr("deliverWebhook", model.ErrInternal, locale.GetUserLocalizer("en"), msgWebhookRequest, http.StatusInternalServerError, nil)
+	}
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, model.WebhookResponseBodyMaxBytes))
+	respBody := string(body)
+	d.ResponseCode = &resp.StatusCode
+	d.ResponseBody = &respBody
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return model.NewAppErr("deliverWebhook", model.ErrInternal, locale.GetUserLocalizer("en"), msgWebhookResponse, http.StatusInternalServerError, map[string]int{"status_code": resp.StatusCode})
+	}
+
+	d.Status = model.WebhookDeliverySuccess
+	return nil
+}
+
+// signWebhookPayload signs the timestamp and the body so the receiver can verify the sender and reject replays
+func signWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,284 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/random"
+)
+
+// CreateWishlist creates the new named wishlist for the user
+func (a *App) CreateWishlist(uid int64, w *model.Wishlist) (*model.Wishlist, *model.AppErr) {
+	w.UserID = uid
+	w.IsDefault = false
+	w.ShareToken = nil
+	w.PreSave()
+	if err := w.Validate(); err != nil {
+		return nil, err
+	}
+	wishlist, err := a.Srv().Store.Wishlist().Save(w)
+	if err != nil {
+		return nil, err
+	}
+	a.audit(model.AuditActionCreate, model.AuditResourceWishlist, wishlist.ID, nil, wishlist)
+	return wishlist, nil
+}
+
+// GetWishlists gets all of the user's wishlists
+func (a *App) GetWishlists(uid int64) ([]*model.Wishlist, *model.AppErr) {
+	if _, err := a.Srv().Store.Wishlist().GetDefault(uid); err != nil {
+		return nil, err
+	}
+	return a.Srv().Store.Wishlist().GetAll(uid)
+}
+
+// GetWishlist gets the user's wishlist with its items
+func (a *App) GetWishlist(uid, wid int64) (*model.Wishlist, *model.AppErr) {
+	w, err := a.Srv().Store.Wishlist().Get(uid, wid)
+	if err != nil {
+		return nil, err
+	}
+	items, err := a.Srv().Store.Wishlist().GetItems(w.ID)
+	if err != nil {
+		return nil, err
+	}
+	w.Items = items
+	return w, nil
+}
+
+// PatchWishlist patches the user's wishlist
+func (a *App) PatchWishlist(uid, wid int64, patch *model.WishlistPatch) (*model.Wishlist, *model.AppErr) {
+	old, err := a.Srv().Store.Wishlist().Get(uid, wid)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *old
+	old.Patch(patch)
+	old.PreUpdate()
+	if err := old.Validate(); err != nil {
+		return nil, err
+	}
+	uwishlist, err := a.Srv().Store.Wishlist().Update(wid, old)
+	if err != nil {
+		return nil, err
+	}
+	a.audit(model.AuditActionUpdate, model.AuditResourceWishlist, wid, &before, uwishlist)
+	return uwishlist, nil
+}
+
+// DeleteWishlist deletes the user's wishlist with all of its items
+func (a *App) DeleteWishlist(uid, wid int64) *model.AppErr {
+	old := a.auditSnapshot(func() (interface{}, *model.AppErr) { return a.Srv().Store.Wishlist().Get(uid, wid) })
+	if err := a.Srv().Store.Wishlist().Delete(uid, wid); err != nil {
+		return err
+	}
+	a.audit(model.AuditActionDelete, model.AuditResourceWishlist, wid, old, nil)
+	return nil
+}
+
+// ShareWishlist generates the unguessable public link token for the wishlist
+func (a *App) ShareWishlist(uid, wid int64) (*model.Wishlist, *model.AppErr) {
+	w, err := a.Srv().Store.Wishlist().Get(uid, wid)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *w
+	token := random.SecureToken(model.WishlistShareTokenLength)
+	w.ShareToken = &token
+	w.PreUpdate()
+	uwishlist, err := a.Srv().Store.Wishlist().Update(wid, w)
+	if err != nil {
+		return nil, err
+	}
+	a.audit(model.AuditActionUpdate, model.AuditResourceWishlist, wid, &before, uwishlist)
+	return uwishlist, nil
+}
+
+// UnshareWishlist revokes the public link of the wishlist
+func (a *App) UnshareWishlist(uid, wid int64) *model.AppErr {
+	w, err := a.Srv().Store.Wishlist().Get(uid, wid)
+	if err != nil {
+		return err
+	}
+
+	before := *w
+	w.ShareToken = nil
+	w.PreUpdate()
+	uwishlist, err := a.Srv().Store.Wishlist().Update(wid, w)
+	if err != nil {
+		return err
+	}
+	a.audit(model.AuditActionUpdate, model.AuditResourceWishlist, wid, &before, uwishlist)
+	return nil
+}
+
+// WishlistShareURL returns the public link of the shared wishlist
+func (a *App) WishlistShareURL(token string) string {
+	return fmt.Sprintf("%s/wishlists/shared/%s", a.SiteURL(), token)
+}
+
+// GetSharedWishlist gets the public view of the shared wishlist
+func (a *App) GetSharedWishlist(token string) (*model.SharedWishlist, *model.AppErr) {
+	w, err := a.Srv().Store.Wishlist().GetByShareToken(token)
+	if err != nil {
+		return nil, err
+	}
+	items, err := a.Srv().Store.Wishlist().GetItems(w.ID)
+	if err != nil {
+		return nil, err
+	}
+	w.Items = items
+	return w.ToShared(), nil
+}
+
+// AddWishlistItem adds the product to the user's wishlist
+func (a *App) AddWishlistItem(uid, wid int64, item *model.WishlistItem) (*model.WishlistItem, *model.AppErr) {
+	if _, err := a.Srv().Store.Wishlist().Get(uid, wid); err != nil {
+		return nil, err
+	}
+
+	item.WishlistID = wid
+	item.UserID = uid
+	item.PreSave()
+	if err := item.Validate(); err != nil {
+		return nil, err
+	}
+	witem, err := a.Srv().Store.Wishlist().SaveItem(item)
+	if err != nil {
+		return nil, err
+	}
+	a.audit(model.AuditActionCreate, model.AuditResourceWishlistItem, auditID(wid, witem.ProductID), nil, witem)
+	return witem, nil
+}
+
+// PatchWishlistItem patches the quantity and note of the wishlist item
+func (a *App) PatchWishlistItem(uid, wid, pid int64, patch *model.WishlistItemPatch) (*model.WishlistItem, *model.AppErr) {
+	if _, err := a.Srv().Store.Wishlist().Get(uid, wid); err != nil {
+		return nil, err
+	}
+	old, err := a.Srv().Store.Wishlist().GetItem(wid, pid)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *old
+	old.Patch(patch)
+	if err := old.Validate(); err != nil {
+		return nil, err
+	}
+	uitem, err := a.Srv().Store.Wishlist().UpdateItem(old)
+	if err != nil {
+		return nil, err
+	}
+	a.audit(model.AuditActionUpdate, model.AuditResourceWishlistItem, auditID(wid, pid), &before, uitem)
+	return uitem, nil
+}
+
+// DeleteWishlistItem deletes the product from the user's wishlist
+func (a *App) DeleteWishlistItem(uid, wid, pid int64) *model.AppErr {
+	if _, err := a.Srv().Store.Wishlist().Get(uid, wid); err != nil {
+		return err
+	}
+	return a.deleteWishlistItem(wid, pid)
+}
+
+// ClearWishlistItems deletes all products from the user's wishlist
+func (a *App) ClearWishlistItems(uid, wid int64) *model.AppErr {
+	if _, err := a.Srv().Store.Wishlist().Get(uid, wid); err != nil {
+		return err
+	}
+	return a.clearWishlistItems(wid)
+}
+
+// UpdateWishlistItemAlerts toggles the back in stock and price drop alerts for the wishlist item
+func (a *App) UpdateWishlistItemAlerts(uid, wid, pid int64, sub *model.WishlistAlertSubscription) *model.AppErr {
+	if _, err := a.Srv().Store.Wishlist().Get(uid, wid); err != nil {
+		return err
+	}
+	return a.updateWishlistItemAlerts(wid, pid, sub)
+}
+
+// CreateWishlistForUser adds new product to the user's default wishlist
+func (a *App) CreateWishlistForUser(uid, pid int64) *model.AppErr {
+	w, err := a.Srv().Store.Wishlist().GetDefault(uid)
+	if err != nil {
+		return err
+	}
+	_, err = a.AddWishlistItem(uid, w.ID, &model.WishlistItem{ProductID: pid})
+	return err
+}
+
+// GetWishlistForUser gets all products from the user's default wishlist
+func (a *App) GetWishlistForUser(uid int64) ([]*model.Product, *model.AppErr) {
+	w, err := a.Srv().Store.Wishlist().GetDefault(uid)
+	if err != nil {
+		return nil, err
+	}
+	items, err := a.Srv().Store.Wishlist().GetItems(w.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	products := make([]*model.Product, 0, len(items))
+	for _, x := range items {
+		products = append(products, x.Product)
+	}
+	return products, nil
+}
+
+// DeleteWishlistForUser deletes the product from the user's default wishlist
+func (a *App) DeleteWishlistForUser(uid, pid int64) *model.AppErr {
+	w, err := a.Srv().Store.Wishlist().GetDefault(uid)
+	if err != nil {
+		return err
+	}
+	return a.deleteWishlistItem(w.ID, pid)
+}
+
+// ClearWishlistForUser deletes all products from the user's default wishlist
+func (a *App) ClearWishlistForUser(uid int64) *model.AppErr {
+	w, err := a.Srv().Store.Wishlist().GetDefault(uid)
+	if err != nil {
+		return err
+	}
+	return a.clearWishlistItems(w.ID)
+}
+
+// UpdateWishlistAlertsForUser toggles the alerts for the product in the user's default wishlist
+func (a *App) UpdateWishlistAlertsForUser(uid, pid int64, sub *model.WishlistAlertSubscription) *model.AppErr {
+	w, err := a.Srv().Store.Wishlist().GetDefault(uid)
+	if err != nil {
+		return err
+	}
+	return a.updateWishlistItemAlerts(w.ID, pid, sub)
+}
+
+func (a *App) deleteWishlistItem(wid, pid int64) *model.AppErr {
+	old := a.auditSnapshot(func() (interface{}, *model.AppErr) { return a.Srv().Store.Wishlist().GetItem(wid, pid) })
+	if err := a.Srv().Store.Wishlist().DeleteItem(wid, pid); err != nil {
+		return err
+	}
+	a.audit(model.AuditActionDelete, model.AuditResourceWishlistItem, auditID(wid, pid), old, nil)
+	return nil
+}
+
+func (a *App) clearWishlistItems(wid int64) *model.AppErr {
+	old := a.auditSnapshot(func() (interface{}, *model.AppErr) { return a.Srv().Store.Wishlist().GetItems(wid) })
+	if err := a.Srv().Store.Wishlist().ClearItems(wid); err != nil {
+		return err
+	}
+	a.audit(model.AuditActionDelete, model.AuditResourceWishlistItem, wid, old, nil)
+	return nil
+}
+
+func (a *App) updateWishlistItemAlerts(wid, pid int64, sub *model.WishlistAlertSubscription) *model.AppErr {
+	old := a.auditSnapshot(func() (interface{}, *model.AppErr) { return a.Srv().Store.Wishlist().GetItem(wid, pid) })
+	if err := a.Srv().Store.Wishlist().UpdateAlerts(wid, pid, sub); err != nil {
+		return err
+	}
+	updated := a.auditSnapshot(func() (interface{}, *model.AppErr) { return a.Srv().Store.Wishlist().GetItem(wid, pid) })
+	a.audit(model.AuditActionUpdate, model.AuditResourceWishlistItem, auditID(wid, pid), old, updated)
+	return nil
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/zlog"
+)
+
+// ProcessWishlistAlerts sends one email per user with all of their pending wishlist alerts,
+// skipping the users that already reached the daily email limit
+func (a *App) ProcessWishlistAlerts() *model.AppErr {
+	if err := a.Srv().Store.Wishlist().SyncAlerts(); err != nil {
+		return err
+	}
+
+	alerts, err := a.Srv().Store.Wishlist().GetPendingAlerts()
+	if err != nil {
+		return err
+	}
+
+	byUser := make(map[int64][]*model.WishlistAlert)
+	order := make([]int64, 0)
+	for _, x := range alerts {
+		if _, ok := byUser[x.UserID]; !ok {
+			order = append(order, x.UserID)
+		}
+		byUser[x.UserID] = append(byUser[x.UserID], x)
+	}
+
+	since := time.Now().Add(-24 * time.Hour)
+	limit := a.Cfg().WishlistAlertSettings.MaxEmailsPerDay
+
+	for _, uid := range order {
+		userAlerts := byUser[uid]
+
+		sent, err := a.Srv().Store.Wishlist().CountAlertEmails(uid, since)
+		if err != nil {
+			a.Log().Error("could not count wishlist alert emails", zlog.Int64("user_id", uid), zlog.Err(err))
+			continue
+		}
+		// the alerts stay pending and are sent once the user is below the limit again
+		if sent >= limit {
+			continue
+		}
+
+		first := userAlerts[0]
+		if err := a.SendWishlistAlertEmail(first.Email, first.Username, userAlerts, a.SiteURL(), first.Locale); err != nil {
+			a.Log().Error("could not send wishlist alert email", zlog.Int64("user_id", uid), zlog.Err(err))
+			continue
+		}
+
+		if err := a.Srv().Store.Wishlist().MarkAlertsSent(userAlerts); err != nil {
+			a.Log().Error("could not mark wishlist alerts as sent", zlog.Int64("user_id", uid), zlog.Err(err))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+const localesDir = "locales"
+
+var i18nCmd = &cobra.Command{
+	Use:   "i18n",
+	Short: "Manage the translations",
+	Long: `Extracts the messages from the source and merges them into the translation files.
+Both commands use the goi18n tool: go get -u github.com/nicksnyder/go-i18n/v2/goi18n`,
+}
+
+var i18nExtractCmd = &cobra.Command{
+	Use:     "extract",
+	Short:   "Extract the messages from the source into locales/active.en.json",
+	Example: "  i18n extract",
+	RunE:    i18nExtractFn,
+}
+
+var i18nMergeCmd = &cobra.Command{
+	Use:   "merge",
+	Short: "Merge the messages into the translation files",
+	Long: `Merges the extracted messages with the active translations. The untranslated messages
+are written to locales/translate.*.json, once they are translated run merge again to add them to the active files.`,
+	Example: "  i18n merge",
+	RunE:    i18nMergeFn,
+}
+
+func init() {
+	i18nCmd.AddCommand(i18nExtractCmd, i18nMergeCmd)
+	rootCmd.AddCommand(i18nCmd)
+}
+
+func i18nExtractFn(command *cobra.Command, args []string) error {
+	return goi18n("extract", "-outdir", localesDir, "-format", "json", ".")
+}
+
+func i18nMergeFn(command *cobra.Command, args []string) error {
+	active, err := filepath.Glob(filepath.Join(localesDir, "active.*.json"))
+	if err != nil {
+		return err
+	}
+	translate, err := filepath.Glob(filepath.Join(localesDir, "translate.*.json"))
+	if err != nil {
+		return err
+	}
+
+	// goi18n rewrites the translate files with only the messages that are still untranslated
+	return goi18n(append([]string{"merge", "-outdir", localesDir, "-format", "json"}, append(active, translate...)...)...)
+}
+
+func goi18n(args ...string) error {
+	bin, err := exec.LookPath("goi18n")
+	if err != nil {
+		return errors.New("goi18n is not installed, run: go get -u github.com/nicksnyder/go-i18n/v2/goi18n")
+	}
+
+	c := exec.Command(bin, args...)
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	return c.Run()
+}
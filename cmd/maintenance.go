@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var maintenanceCmd = &cobra.Command{
+	Use:   "maintenance",
+	Short: "Run maintenance",
+	Long: `Purges the expired tokens, expires the abandoned pending orders and removes the orphaned cloudinary images.
+The image removals are queued and carried out by the server job workers.`,
+	Example: "  maintenance --dry-run",
+	RunE:    maintenanceFn,
+}
+
+func init() {
+	maintenanceCmd.Flags().Bool("dry-run", false, "Only report what would be cleaned up without changing anything.")
+	rootCmd.AddCommand(maintenanceCmd)
+}
+
+func maintenanceFn(command *cobra.Command, args []string) error {
+	dryRun, err := command.Flags().GetBool("dry-run")
+	if err != nil {
+		return err
+	}
+
+	a, err := setupApp()
+	if err != nil {
+		return err
+	}
+
+	report := a.RunMaintenance(dryRun)
+
+	verb, orderVerb := "removed", "expired"
+	if dryRun {
+		verb, orderVerb = "would be removed", "would be expired"
+	}
+	fmt.Printf("expired tokens %s: %d\n", verb, report.ExpiredTokens)
+	fmt.Printf("abandoned pending orders %s: %d\n", orderVerb, report.ExpiredOrders)
+	fmt.Printf("orphaned images %s: %d\n", verb, len(report.OrphanedAssets))
+	for _, publicID := range report.OrphanedAssets {
+		fmt.Printf("  %s\n", publicID)
+	}
+	for _, e := range report.Errors {
+		fmt.Printf("error: %s\n", e)
+	}
+	fmt.Printf("finished in %s\n", report.FinishedAt.Sub(report.StartedAt))
+	return nil
+}
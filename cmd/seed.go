@@ -183,6 +183,7 @@ func seedReviews() error {
 	}
 
 	for _, t := range reviews {
+		t.Status = model.ReviewStatusApproved
 		t.PreSave()
 	}
 	if err := cmdApp.Srv().Store.ProductReview().BulkInsert(reviews); err != nil {
@@ -190,6 +191,18 @@ func seedReviews() error {
 		return err
 	}
 
+	rated := make(map[int64]bool)
+	for _, r := range reviews {
+		if rated[r.ProductID] {
+			continue
+		}
+		if err := cmdApp.Srv().Store.ProductReview().RefreshRating(r.ProductID); err != nil {
+			cmdApp.Log().Error("could not refresh product rating", zlog.String("err: ", err.Message))
+			return err
+		}
+		rated[r.ProductID] = true
+	}
+
 	cmdApp.Log().Info("reviews seeded")
 	return nil
 }
@@ -278,17 +291,9 @@ func seedOrders() error {
 		o.PaymentIntentID = pi.ID
 		o.ReceiptURL = pi.Charges.Data[0].ReceiptURL
 
-		o.PreSave()
-		order, err := cmdApp.Srv().Store.Order().Save(o)
-		if err != nil {
-			cmdApp.Log().Error("seed save order err", zlog.String("err: ", err.Error()))
-			return err
-		}
-
 		orderDetails := make([]*model.OrderDetail, 0)
 		for i, p := range products {
 			detail := &model.OrderDetail{
-				OrderID:      order.ID,
 				ProductID:    p.ID,
 				Quantity:     orderData.Items[i].Quantity,
 				HistoryPrice: p.Price,
@@ -297,8 +302,9 @@ func seedOrders() error {
 			orderDetails = append(orderDetails, detail)
 		}
 
-		if err := cmdApp.InsertOrderDetails(orderDetails); err != nil {
-			cmdApp.Log().Error("seed insert order details err", zlog.String("err: ", err.Error()))
+		o.PreSave()
+		if _, err := cmdApp.Srv().Store.Order().Save(o, orderDetails, nil); err != nil {
+			cmdApp.Log().Error("seed save order err", zlog.String("err: ", err.Error()))
 			return err
 		}
 	}
@@ -6,6 +6,8 @@ import (
 	api "github.com/dankobgd/ecommerce-shop/api/v1"
 	"github.com/dankobgd/ecommerce-shop/app"
 	"github.com/dankobgd/ecommerce-shop/config"
+	"github.com/dankobgd/ecommerce-shop/mailer"
+	"github.com/dankobgd/ecommerce-shop/oauth"
 	"github.com/dankobgd/ecommerce-shop/payment/stripe"
 	"github.com/dankobgd/ecommerce-shop/store/postgres"
 	"github.com/dankobgd/ecommerce-shop/store/redis"
@@ -32,6 +34,8 @@ func serverCmdFn(command *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	a.StartJobs()
+	a.StartEvents()
 	return runServer(a.Srv())
 }
 
@@ -59,6 +63,11 @@ func setupApp() (*app.App, error) {
 		return nil, pErr
 	}
 
+	mailTransport, mErr := mailer.NewTransport(cfg.EmailSettings)
+	if mErr != nil {
+		return nil, mErr
+	}
+
 	logger := zlog.NewLogger(&zlog.LoggerConfig{
 		EnableConsole: true,
 		ConsoleLevel:  "debug",
@@ -78,6 +87,8 @@ func setupApp() (*app.App, error) {
 		app.SetServer(server),
 		app.SetLogger(logger),
 		app.SetPaymentProvider(paymentProvider),
+		app.SetMailer(mailTransport),
+		app.SetOAuthProviders(oauth.NewProviders(cfg.OAuthSettings)),
 	}
 
 	a := app.New(appOpts...)
@@ -9,9 +9,10 @@ import (
 
 // AppSettings contains common app settings
 type AppSettings struct {
-	Host string `envconfig:"HOST"`
-	Port int    `envconfig:"PORT"`
-	ENV  string `envconfig:"ENV"`
+	Host           string   `envconfig:"HOST"`
+	Port           int      `envconfig:"PORT"`
+	ENV            string   `envconfig:"ENV"`
+	TrustedProxies []string `envconfig:"TRUSTED_PROXIES"`
 }
 
 // DatabaseSettings contains DB settings
@@ -29,6 +30,19 @@ type AuthSettings struct {
 	EmailVerificationExpiryHours int    `envconfig:"EMAIL_VERIFICATION_EXPIRY_HOURS"`
 	AccessTokenSecret            string `envconfig:"ACCESS_TOKEN_SECRET"`
 	RefreshTokenSecret           string `envconfig:"REFRESH_TOKEN_SECRET"`
+	AccessTokenExpiryMinutes     int    `envconfig:"ACCESS_TOKEN_EXPIRY_MINUTES"`
+	RefreshTokenExpiryHours      int    `envconfig:"REFRESH_TOKEN_EXPIRY_HOURS"`
+	TwoFactorIssuer              string `envconfig:"TWO_FACTOR_ISSUER"`
+	TwoFactorRequiredForAdmins   bool   `envconfig:"TWO_FACTOR_REQUIRED_FOR_ADMINS"`
+	TwoFactorEncryptionKey       string `envconfig:"TWO_FACTOR_ENCRYPTION_KEY"`
+	LoginMaxAttempts             int    `envconfig:"LOGIN_MAX_ATTEMPTS"`
+	LoginMaxAttemptsPerIP        int    `envconfig:"LOGIN_MAX_ATTEMPTS_PER_IP"`
+	LoginAttemptWindowMinutes    int    `envconfig:"LOGIN_ATTEMPT_WINDOW_MINUTES"`
+	LoginLockoutMinutes          int    `envconfig:"LOGIN_LOCKOUT_MINUTES"`
+	LoginDelayAfterAttempts      int    `envconfig:"LOGIN_DELAY_AFTER_ATTEMPTS"`
+	LoginDelaySeconds            int    `envconfig:"LOGIN_DELAY_SECONDS"`
+	EmailRateLimitPerHour        int    `envconfig:"EMAIL_RATE_LIMIT_PER_HOUR"`
+	EmailRateLimitPerIPPerHour   int    `envconfig:"EMAIL_RATE_LIMIT_PER_IP_PER_HOUR"`
 }
 
 // EmailSettings contains email settings
@@ -41,6 +55,7 @@ type EmailSettings struct {
 	SMTPPort      int    `envconfig:"SMTP_PORT"`
 	SMTPUsername  string `envconfig:"SMTP_USERNAME"`
 	SMTPPassword  string `envconfig:"SMTP_PASSWORD"`
+	FileDir       string `envconfig:"EMAIL_FILE_DIR"`
 	MailTrap      MailtrapSettings
 	Sendgrid      SendgridSettings
 }
@@ -104,18 +119,90 @@ type GeocodingSettings struct {
 	APIKey string `envconfig:"GEOCODING_API_KEY"`
 }
 
+// ReviewSettings contains the product review and Q&A settings
+type ReviewSettings struct {
+	PurchaseRequired bool `envconfig:"REVIEW_PURCHASE_REQUIRED"`
+	AutoApprove      bool `envconfig:"REVIEW_AUTO_APPROVE"`
+}
+
+// WishlistAlertSettings contains the back-in-stock and price-drop alert settings
+type WishlistAlertSettings struct {
+	IntervalMinutes int `envconfig:"WISHLIST_ALERT_INTERVAL_MINUTES"`
+	MaxEmailsPerDay int `envconfig:"WISHLIST_ALERT_MAX_EMAILS_PER_DAY"`
+}
+
+// JobSettings contains the background job queue settings
+type JobSettings struct {
+	Workers             int `envconfig:"JOB_WORKERS"`
+	PollIntervalSeconds int `envconfig:"JOB_POLL_INTERVAL_SECONDS"`
+	MaxAttempts         int `envconfig:"JOB_MAX_ATTEMPTS"`
+	BackoffSeconds      int `envconfig:"JOB_BACKOFF_SECONDS"`
+	MaxBackoffMinutes   int `envconfig:"JOB_MAX_BACKOFF_MINUTES"`
+	StaleLockMinutes    int `envconfig:"JOB_STALE_LOCK_MINUTES"`
+	RetentionDays       int `envconfig:"JOB_RETENTION_DAYS"`
+}
+
+// EventSettings contains the domain events outbox settings
+type EventSettings struct {
+	PollIntervalSeconds int `envconfig:"EVENT_POLL_INTERVAL_SECONDS"`
+	BatchSize           int `envconfig:"EVENT_BATCH_SIZE"`
+	RetentionDays       int `envconfig:"EVENT_RETENTION_DAYS"`
+}
+
+// WebhookSettings contains the outbound webhook delivery settings
+type WebhookSettings struct {
+	TimeoutSeconds int `envconfig:"WEBHOOK_TIMEOUT_SECONDS"`
+	MaxAttempts    int `envconfig:"WEBHOOK_MAX_ATTEMPTS"`
+}
+
+// MaintenanceSettings contains the scheduled cleanup settings
+type MaintenanceSettings struct {
+	Schedule                string `envconfig:"MAINTENANCE_SCHEDULE"`
+	PendingOrderExpiryHours int    `envconfig:"MAINTENANCE_PENDING_ORDER_EXPIRY_HOURS"`
+	OrphanedAssetGraceHours int    `envconfig:"MAINTENANCE_ORPHANED_ASSET_GRACE_HOURS"`
+}
+
+// RateLimitSettings contains the global http rate limit settings, the routes can set their own limits on top of it
+type RateLimitSettings struct {
+	Disabled      bool `envconfig:"RATE_LIMIT_DISABLED"`
+	Requests      int  `envconfig:"RATE_LIMIT_REQUESTS"`
+	PeriodSeconds int  `envconfig:"RATE_LIMIT_PERIOD_SECONDS"`
+	Burst         int  `envconfig:"RATE_LIMIT_BURST"`
+}
+
+// OAuthSettings contains the social login settings, the provider is enabled when its client id is set
+type OAuthSettings struct {
+	CallbackURL        string `envconfig:"OAUTH_CALLBACK_URL"`
+	StateExpiryMinutes int    `envconfig:"OAUTH_STATE_EXPIRY_MINUTES"`
+	GoogleClientID     string `envconfig:"OAUTH_GOOGLE_CLIENT_ID"`
+	GoogleClientSecret string `envconfig:"OAUTH_GOOGLE_CLIENT_SECRET"`
+	GitHubClientID     string `envconfig:"OAUTH_GITHUB_CLIENT_ID"`
+	GitHubClientSecret string `envconfig:"OAUTH_GITHUB_CLIENT_SECRET"`
+	OIDCIssuer         string `envconfig:"OAUTH_OIDC_ISSUER"`
+	OIDCClientID       string `envconfig:"OAUTH_OIDC_CLIENT_ID"`
+	OIDCClientSecret   string `envconfig:"OAUTH_OIDC_CLIENT_SECRET"`
+}
+
 // Config represents the app config
 type Config struct {
 	AppSettings
-	DatabaseSettings   DatabaseSettings
-	AuthSettings       AuthSettings
-	EmailSettings      EmailSettings
-	CookieSettings     CookieSettings
-	PasswordSettings   PasswordSettings
-	LoggerSettings     LoggerSettings
-	CloudinarySettings CloudinarySettings
-	GeocodingSettings  GeocodingSettings
-	StripeSettings     StripeSettings
+	DatabaseSettings      DatabaseSettings
+	AuthSettings          AuthSettings
+	EmailSettings         EmailSettings
+	CookieSettings        CookieSettings
+	PasswordSettings      PasswordSettings
+	LoggerSettings        LoggerSettings
+	CloudinarySettings    CloudinarySettings
+	GeocodingSettings     GeocodingSettings
+	StripeSettings        StripeSettings
+	ReviewSettings        ReviewSettings
+	WishlistAlertSettings WishlistAlertSettings
+	JobSettings           JobSettings
+	EventSettings         EventSettings
+	WebhookSettings       WebhookSettings
+	MaintenanceSettings   MaintenanceSettings
+	RateLimitSettings     RateLimitSettings
+	OAuthSettings         OAuthSettings
 }
 
 func loadEnvironment() {
@@ -134,6 +221,13 @@ func (c *Config) ApplyDefaults() {
 	c.CookieSettings.SetDefaults()
 	c.PasswordSettings.SetDefaults()
 	c.LoggerSettings.SetDefaults()
+	c.WishlistAlertSettings.SetDefaults()
+	c.JobSettings.SetDefaults()
+	c.EventSettings.SetDefaults()
+	c.WebhookSettings.SetDefaults()
+	c.MaintenanceSettings.SetDefaults()
+	c.RateLimitSettings.SetDefaults()
+	c.OAuthSettings.SetDefaults()
 }
 
 // New creates the new config
@@ -43,6 +43,42 @@ func (s *AuthSettings) SetDefaults() {
 	if s.EmailVerificationExpiryHours == 0 {
 		s.EmailVerificationExpiryHours = 12
 	}
+	if s.AccessTokenExpiryMinutes == 0 {
+		s.AccessTokenExpiryMinutes = 15
+	}
+	if s.RefreshTokenExpiryHours == 0 {
+		s.RefreshTokenExpiryHours = 24 * 7
+	}
+	if s.TwoFactorEncryptionKey == "" {
+		s.TwoFactorEncryptionKey = "secret3"
+	}
+	if s.TwoFactorIssuer == "" {
+		s.TwoFactorIssuer = "ecommerce-shop"
+	}
+	if s.LoginMaxAttempts == 0 {
+		s.LoginMaxAttempts = 10
+	}
+	if s.LoginMaxAttemptsPerIP == 0 {
+		s.LoginMaxAttemptsPerIP = 100
+	}
+	if s.LoginAttemptWindowMinutes == 0 {
+		s.LoginAttemptWindowMinutes = 15
+	}
+	if s.LoginLockoutMinutes == 0 {
+		s.LoginLockoutMinutes = 15
+	}
+	if s.LoginDelayAfterAttempts == 0 {
+		s.LoginDelayAfterAttempts = 3
+	}
+	if s.LoginDelaySeconds == 0 {
+		s.LoginDelaySeconds = 1
+	}
+	if s.EmailRateLimitPerHour == 0 {
+		s.EmailRateLimitPerHour = 5
+	}
+	if s.EmailRateLimitPerIPPerHour == 0 {
+		s.EmailRateLimitPerIPPerHour = 20
+	}
 	if s.VerificationRequired == false {
 		s.VerificationRequired = true
 	}
@@ -74,6 +110,9 @@ func (s *EmailSettings) SetDefaults() {
 	if s.Transport == "" {
 		s.Transport = "smtp"
 	}
+	if s.FileDir == "" {
+		s.FileDir = "./mails"
+	}
 }
 
 // SetDefaults sets default values for CookieSettings
@@ -144,3 +183,97 @@ func (s *LoggerSettings) SetDefaults() {
 		s.FileLocation = ""
 	}
 }
+
+// SetDefaults sets default values for WishlistAlertSettings
+func (s *WishlistAlertSettings) SetDefaults() {
+	if s.IntervalMinutes == 0 {
+		s.IntervalMinutes = 60
+	}
+	if s.MaxEmailsPerDay == 0 {
+		s.MaxEmailsPerDay = 2
+	}
+}
+
+// SetDefaults sets default values for JobSettings
+func (s *JobSettings) SetDefaults() {
+	if s.Workers == 0 {
+		s.Workers = 2
+	}
+	if s.PollIntervalSeconds == 0 {
+		s.PollIntervalSeconds = 2
+	}
+	if s.MaxAttempts == 0 {
+		s.MaxAttempts = 5
+	}
+	if s.BackoffSeconds == 0 {
+		s.BackoffSeconds = 10
+	}
+	if s.MaxBackoffMinutes == 0 {
+		s.MaxBackoffMinutes = 60
+	}
+	if s.StaleLockMinutes == 0 {
+		s.StaleLockMinutes = 15
+	}
+	if s.RetentionDays == 0 {
+		s.RetentionDays = 7
+	}
+}
+
+// SetDefaults sets default values for EventSettings
+func (s *EventSettings) SetDefaults() {
+	if s.PollIntervalSeconds == 0 {
+		s.PollIntervalSeconds = 2
+	}
+	if s.BatchSize == 0 {
+		s.BatchSize = 100
+	}
+	if s.RetentionDays == 0 {
+		s.RetentionDays = 30
+	}
+}
+
+// SetDefaults sets default values for WebhookSettings
+func (s *WebhookSettings) SetDefaults() {
+	if s.TimeoutSeconds == 0 {
+		s.TimeoutSeconds = 10
+	}
+	if s.MaxAttempts == 0 {
+		s.MaxAttempts = 8
+	}
+}
+
+// SetDefaults sets default values for MaintenanceSettings
+func (s *MaintenanceSettings) SetDefaults() {
+	if s.Schedule == "" {
+		s.Schedule = "@daily"
+	}
+	if s.PendingOrderExpiryHours == 0 {
+		s.PendingOrderExpiryHours = 24
+	}
+	if s.OrphanedAssetGraceHours == 0 {
+		s.OrphanedAssetGraceHours = 24
+	}
+}
+
+// SetDefaults sets default values for RateLimitSettings
+func (s *RateLimitSettings) SetDefaults() {
+	if s.Requests == 0 {
+		s.Requests = 300
+	}
+	if s.PeriodSeconds == 0 {
+		s.PeriodSeconds = 60
+	}
+	if s.Burst == 0 {
+		s.Burst = 60
+	}
+}
+
+// SetDefaults sets default values for OAuthSettings
+func (s *OAuthSettings) SetDefaults() {
+	if s.CallbackURL == "" {
+		s.CallbackURL = "http://localhost:3001/api/v1/oauth"
+	}
+	if s.StateExpiryMinutes == 0 {
+		s.StateExpiryMinutes = 10
+	}
+}
@@ -3,6 +3,7 @@ module github.com/dankobgd/ecommerce-shop
 go 1.14
 
 require (
+	github.com/alicebob/miniredis/v2 v2.14.3
 	github.com/cockroachdb/apd v1.1.0 // indirect
 	github.com/dgrijalva/jwt-go/v4 v4.0.0-preview1
 	github.com/go-chi/chi v4.0.3+incompatible
@@ -18,7 +19,7 @@ require (
 	github.com/jordan-wright/email v0.0.0-20200602115436-fd8a7622303e
 	github.com/jung-kurt/gofpdf v1.16.2
 	github.com/kelseyhightower/envconfig v1.4.0
-	github.com/lib/pq v1.6.0 // indirect
+	github.com/lib/pq v1.6.0
 	github.com/mattn/go-sqlite3 v1.11.0 // indirect
 	github.com/nicksnyder/go-i18n/v2 v2.0.3
 	github.com/olekukonko/tablewriter v0.0.4 // indirect
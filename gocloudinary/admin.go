@@ -21,7 +21,7 @@ const (
 )
 
 const (
-	maxResults = 2048
+	maxResults = 500
 )
 
 func (s *Service) dropAllResources(rtype ResourceType, w io.Writer) error {
@@ -108,8 +108,8 @@ func (s *Service) doGetResources(rtype ResourceType) ([]*Resource, error) {
 		for _, res := range rs.Resources {
 			allres = append(allres, res)
 		}
-		if rs.NextCursor > 0 {
-			qs.Set("next_cursor", strconv.FormatInt(rs.NextCursor, 10))
+		if rs.NextCursor != "" {
+			qs.Set("next_cursor", rs.NextCursor)
 		} else {
 			break
 		}
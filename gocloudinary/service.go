@@ -63,10 +63,11 @@ type Resource struct {
 	Size         int    `json:"bytes"`
 	URL          string `json:"url"`
 	SecureURL    string `json:"secure_url"`
+	CreatedAt    string `json:"created_at"`
 }
 
 type pagination struct {
-	NextCursor int64 `json:"next_cursor"`
+	NextCursor string `json:"next_cursor"`
 }
 
 type resourceList struct {
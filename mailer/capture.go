@@ -0,0 +1,123 @@
+package mailer
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+)
+
+// captureMaxMessages is the number of the most recent messages kept by the capture transport
+const captureMaxMessages = 500
+
+// Message is the email captured by the capture transport
+type Message struct {
+	ID          int64         `json:"id"`
+	From        string        `json:"from"`
+	To          []string      `json:"to"`
+	Subject     string        `json:"subject"`
+	Text        string        `json:"text"`
+	HTML        string        `json:"html"`
+	Attachments []*Attachment `json:"attachments"`
+	SentAt      time.Time     `json:"sent_at"`
+}
+
+// CaptureTransport keeps the emails in memory so they can be inspected by tests and the dev mailbox
+type CaptureTransport struct {
+	from     string
+	mu       sync.RWMutex
+	nextID   int64
+	messages []*Message
+}
+
+// NewCaptureTransport creates the new capture transport
+func NewCaptureTransport(from string) *CaptureTransport {
+	return &CaptureTransport{from: from, messages: make([]*Message, 0)}
+}
+
+// Send captures the email
+func (t *CaptureTransport) Send(md *Maildata) *model.AppErr {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.nextID++
+	t.messages = append(t.messages, &Message{
+		ID:          t.nextID,
+		From:        t.from,
+		To:          append([]string(nil), md.To...),
+		Subject:     md.Subject,
+		Text:        md.textBody,
+		HTML:        md.hTMLBody,
+		Attachments: md.Attachments,
+		SentAt:      time.Now(),
+	})
+	if len(t.messages) > captureMaxMessages {
+		t.messages = t.messages[len(t.messages)-captureMaxMessages:]
+	}
+	return nil
+}
+
+// Messages returns the captured emails, newest first
+func (t *CaptureTransport) Messages() []*Message {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	msgs := make([]*Message, 0, len(t.messages))
+	for i := len(t.messages) - 1; i >= 0; i-- {
+		msgs = append(msgs, t.messages[i])
+	}
+	return msgs
+}
+
+// Message returns the captured email by id
+func (t *CaptureTransport) Message(id int64) (*Message, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for _, m := range t.messages {
+		if m.ID == id {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+// Last returns the most recent email
+func (t *CaptureTransport) Last() (*Message, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if len(t.messages) == 0 {
+		return nil, false
+	}
+	return t.messages[len(t.messages)-1], true
+}
+
+// SentTo returns the emails sent to the address, newest first
+func (t *CaptureTransport) SentTo(address string) []*Message {
+	msgs := make([]*Message, 0)
+	for _, m := range t.Messages() {
+		for _, to := range m.To {
+			if strings.EqualFold(to, address) {
+				msgs = append(msgs, m)
+				break
+			}
+		}
+	}
+	return msgs
+}
+
+// Len returns the number of captured emails
+func (t *CaptureTransport) Len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.messages)
+}
+
+// Reset removes all captured emails
+func (t *CaptureTransport) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.messages = make([]*Message, 0)
+}
@@ -0,0 +1,94 @@
+package mailer
+
+import (
+	htmltemplate "html/template"
+	"strings"
+	"testing"
+)
+
+func TestCaptureTransport(t *testing.T) {
+	tr := NewCaptureTransport("shop@example.com")
+
+	if _, ok := tr.Last(); ok {
+		t.Fatal("Last() on the empty transport should report no message")
+	}
+
+	tr.Send(&Maildata{To: []string{"alice@example.com"}, Subject: "first"})
+	tr.Send(&Maildata{To: []string{"bob@example.com", "Alice@Example.com"}, Subject: "second"})
+	tr.Send(&Maildata{To: []string{"bob@example.com"}, Subject: "third"})
+
+	if got := tr.Len(); got != 3 {
+		t.Fatalf("Len() = %d, want 3", got)
+	}
+
+	last, ok := tr.Last()
+	if !ok || last.Subject != "third" {
+		t.Fatalf("Last() = %v, want the third message", last)
+	}
+	if last.From != "shop@example.com" {
+		t.Errorf("Last().From = %q, want the transport sender", last.From)
+	}
+
+	msgs := tr.Messages()
+	if len(msgs) != 3 || msgs[0].Subject != "third" || msgs[2].Subject != "first" {
+		t.Errorf("Messages() should return the newest message first")
+	}
+
+	alice := tr.SentTo("alice@example.com")
+	if len(alice) != 2 || alice[0].Subject != "second" || alice[1].Subject != "first" {
+		t.Errorf("SentTo(alice) = %d messages, want second and first, matched case insensitively", len(alice))
+	}
+	if got := tr.SentTo("nobody@example.com"); len(got) != 0 {
+		t.Errorf("SentTo(nobody) = %d messages, want 0", len(got))
+	}
+
+	m, ok := tr.Message(alice[1].ID)
+	if !ok || m.Subject != "first" {
+		t.Errorf("Message(%d) did not return the first message", alice[1].ID)
+	}
+
+	tr.Reset()
+	if got := tr.Len(); got != 0 {
+		t.Errorf("Len() after Reset() = %d, want 0", got)
+	}
+	if _, ok := tr.Last(); ok {
+		t.Error("Last() after Reset() should report no message")
+	}
+}
+
+func TestCaptureTransportKeepsRecentMessages(t *testing.T) {
+	tr := NewCaptureTransport("shop@example.com")
+	for i := 0; i < captureMaxMessages+10; i++ {
+		tr.Send(&Maildata{To: []string{"alice@example.com"}})
+	}
+
+	if got := tr.Len(); got != captureMaxMessages {
+		t.Fatalf("Len() = %d, want %d", got, captureMaxMessages)
+	}
+	if _, ok := tr.Message(1); ok {
+		t.Error("the oldest messages should be dropped")
+	}
+	if last, _ := tr.Last(); last.ID != captureMaxMessages+10 {
+		t.Errorf("Last().ID = %d, want %d", last.ID, captureMaxMessages+10)
+	}
+}
+
+func TestSendEmailCapturesRenderedBodies(t *testing.T) {
+	tr := NewCaptureTransport("shop@example.com")
+	tmpl := htmltemplate.Must(htmltemplate.New("welcome").Parse(`<p>Hello <b>{{.Name}}</b></p>`))
+
+	if err := SendEmail(tr, tmpl, map[string]string{"Name": "Alice"}, &Maildata{To: []string{"alice@example.com"}, Subject: "Hello"}); err != nil {
+		t.Fatalf("SendEmail: %v", err)
+	}
+
+	sent := tr.SentTo("alice@example.com")
+	if len(sent) != 1 {
+		t.Fatalf("SentTo(alice) = %d messages, want 1", len(sent))
+	}
+	if !strings.Contains(sent[0].HTML, "<b>Alice</b>") {
+		t.Errorf("HTML = %q, want the rendered template", sent[0].HTML)
+	}
+	if !strings.Contains(sent[0].Text, "Hello") || strings.Contains(sent[0].Text, "<b>") {
+		t.Errorf("Text = %q, want the plain text version", sent[0].Text)
+	}
+}
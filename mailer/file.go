@@ -0,0 +1,61 @@
+package mailer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/dankobgd/ecommerce-shop/zlog"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var msgWriteMailFile = &i18n.Message{ID: "mailer.write_file.app_error", Other: "could not write email file"}
+
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// FileTransport writes the emails as .eml files to the directory instead of sending them,
+// the files can be opened with any mail client
+type FileTransport struct {
+	from string
+	dir  string
+}
+
+// NewFileTransport creates the new file transport and the mail directory if it doesn't exist
+func NewFileTransport(from, dir string) (*FileTransport, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("could not create the mail directory: %w", err)
+	}
+	return &FileTransport{from: from, dir: dir}, nil
+}
+
+// Send writes the email to the file
+func (t *FileTransport) Send(md *Maildata) *model.AppErr {
+	e, err := toEmail(t.from, md)
+	if err != nil {
+		zlog.Info("could not attach file to the email", zlog.String("recipients:", strings.Join(md.To, ",")), zlog.Err(err))
+		return model.NewAppErr("mailer.Send", model.ErrInternal, locale.GetUserLocalizer("en"), msgAttachFile, http.StatusInternalServerError, nil)
+	}
+
+	b, err := e.Bytes()
+	if err != nil {
+		zlog.Info("could not encode email", zlog.String("recipients:", strings.Join(md.To, ",")), zlog.Err(err))
+		return model.NewAppErr("mailer.Send", model.ErrInternal, locale.GetUserLocalizer("en"), msgWriteMailFile, http.StatusInternalServerError, nil)
+	}
+
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), unsafeFilenameChars.ReplaceAllString(strings.Join(md.To, "_"), "_"))
+	path := filepath.Join(t.dir, name)
+	if err := ioutil.WriteFile(path, b, 0644); err != nil {
+		zlog.Info("could not write email file", zlog.String("path", path), zlog.Err(err))
+		return model.NewAppErr("mailer.Send", model.ErrInternal, locale.GetUserLocalizer("en"), msgWriteMailFile, http.StatusInternalServerError, nil)
+	}
+
+	zlog.Info("email has been written to file", zlog.String("recipients:", strings.Join(md.To, ",")), zlog.String("path", path))
+	return nil
+}
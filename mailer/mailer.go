@@ -1,11 +1,11 @@
 package mailer
 
 import (
+	"bytes"
 	"fmt"
+	htmltemplate "html/template"
 	"net/http"
-	"net/smtp"
 	"net/textproto"
-	"strings"
 
 	"github.com/dankobgd/ecommerce-shop/config"
 	"github.com/dankobgd/ecommerce-shop/model"
@@ -14,8 +14,6 @@ import (
 	"github.com/dankobgd/ecommerce-shop/zlog"
 	"github.com/jordan-wright/email"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
-	"github.com/sendgrid/sendgrid-go"
-	"github.com/sendgrid/sendgrid-go/helpers/mail"
 	"jaytaylor.com/html2text"
 )
 
@@ -26,18 +24,56 @@ var (
 	msgParseHTMLToText    = &i18n.Message{ID: "mailer.parse_html2text.app_error", Other: "could not parse email html to text"}
 )
 
+var msgAttachFile = &i18n.Message{ID: "mailer.attach_file.app_error", Other: "could not attach file to the email"}
+
+// Transport sends the rendered email
+type Transport interface {
+	Send(md *Maildata) *model.AppErr
+}
+
+// Attachment is the file attached to the email
+type Attachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Content     []byte `json:"content"`
+}
+
 // Maildata holds email details
 type Maildata struct {
-	To       []string
-	Subject  string
-	textBody string
-	hTMLBody string
-	Headers  textproto.MIMEHeader
+	To          []string
+	Subject     string
+	textBody    string
+	hTMLBody    string
+	Headers     textproto.MIMEHeader
+	Attachments []*Attachment
+}
+
+// NewTransport creates the transport configured in the email settings
+func NewTransport(settings config.EmailSettings) (Transport, error) {
+	switch settings.Transport {
+	case "smtp":
+		// smtp used to fall back to mailtrap while email was disabled, keep that for the existing setups
+		if !settings.Enabled {
+			return NewSMTPTransport(settings.FeedbackEmail, settings.MailTrap.Host, settings.MailTrap.Port, settings.MailTrap.Username, settings.MailTrap.Password), nil
+		}
+		return NewSMTPTransport(settings.FeedbackEmail, settings.SMTPHost, settings.SMTPPort, settings.SMTPUsername, settings.SMTPPassword), nil
+	case "mailtrap":
+		return NewSMTPTransport(settings.FeedbackEmail, settings.MailTrap.Host, settings.MailTrap.Port, settings.MailTrap.Username, settings.MailTrap.Password), nil
+	case "sendgrid":
+		return NewSendgridTransport(settings.FeedbackUser, settings.FeedbackEmail, settings.Sendgrid.APIKey), nil
+	case "file":
+		return NewFileTransport(settings.FeedbackEmail, settings.FileDir)
+	case "capture":
+		return NewCaptureTransport(settings.FeedbackEmail), nil
+	default:
+		return nil, fmt.Errorf("could not configure mailer: unknown email transport %q", settings.Transport)
+	}
 }
 
-func sendWithSMTP(settings config.EmailSettings, md *Maildata) *model.AppErr {
+// toEmail builds the mime email from the mail data
+func toEmail(from string, md *Maildata) (*email.Email, error) {
 	e := &email.Email{
-		From:    settings.FeedbackEmail,
+		From:    from,
 		To:      md.To,
 		Subject: md.Subject,
 		HTML:    []byte(md.hTMLBody),
@@ -45,56 +81,36 @@ func sendWithSMTP(settings config.EmailSettings, md *Maildata) *model.AppErr {
 		Headers: md.Headers,
 	}
 
-	var addr string
-	var auth smtp.Auth
-
-	if settings.Enabled {
-		addr = fmt.Sprintf("%s:%d", settings.SMTPHost, settings.SMTPPort)
-		auth = smtp.PlainAuth("", settings.SMTPUsername, settings.SMTPPassword, settings.SMTPHost)
-	} else {
-		addr = fmt.Sprintf("%s:%d", settings.MailTrap.Host, settings.MailTrap.Port)
-		auth = smtp.PlainAuth("", settings.MailTrap.Username, settings.MailTrap.Password, settings.MailTrap.Host)
-	}
-
-	if err := e.Send(addr, auth); err != nil {
-		zlog.Info("could not send email with smtp", zlog.String("recipients:", strings.Join(e.To, ",")), zlog.Err(err))
-		return model.NewAppErr("mailer.Send", model.ErrInternal, locale.GetUserLocalizer("en"), msgSendMailSMTP, http.StatusInternalServerError, nil)
-	}
-	zlog.Info("email has been sent successfully", zlog.String("recipients:", strings.Join(e.To, ",")))
-	return nil
-}
-
-func sendWithSendgrid(config *config.Config, md *Maildata) *model.AppErr {
-	mailSettings := config.EmailSettings
-
-	from := mail.NewEmail(mailSettings.FeedbackUser, mailSettings.FeedbackEmail)
-	to := mail.NewEmail("", strings.Join(md.To, ","))
-	subject := md.Subject
-	textContent := md.textBody
-	htmlContent := md.hTMLBody
-
-	message := mail.NewSingleEmail(from, subject, to, textContent, htmlContent)
-	client := sendgrid.NewSendClient(mailSettings.Sendgrid.APIKey)
-
-	resp, err := client.Send(message)
-	if err != nil {
-		zlog.Info("could not send email with sendgird", zlog.String("recipients:", strings.Join(md.To, ",")), zlog.Err(err))
-		return model.NewAppErr("mailer.Send", model.ErrInternal, locale.GetUserLocalizer("en"), msgSendMailSendgrid, http.StatusInternalServerError, nil)
+	for _, att := range md.Attachments {
+		if _, err := e.Attach(bytes.NewReader(att.Content), att.Filename, att.ContentType); err != nil {
+			return nil, err
+		}
 	}
-
-	zlog.Info("email successfully sent with sendgrid", zlog.String("recipients:", strings.Join(md.To, ",")), zlog.Int("statusCode", resp.StatusCode))
-	return nil
+	return e, nil
 }
 
 // SendEmailTemplate parses the email template to get the html and text contents
 // and sends the email with the information
-func SendEmailTemplate(filename string, data interface{}, md *Maildata, config *config.Config) *model.AppErr {
+func SendEmailTemplate(t Transport, filename string, data interface{}, md *Maildata) *model.AppErr {
 	htmlString, err := template.ParseTemplate(filename, data)
 	if err != nil {
 		zlog.Info("could not parse email template", zlog.Err(err))
 		return model.NewAppErr("mailer.parse_template", model.ErrInternal, locale.GetUserLocalizer("en"), msgParseEmailTemplate, http.StatusInternalServerError, nil)
 	}
+	return sendHTML(t, htmlString, md)
+}
+
+// SendEmail renders the already parsed template and sends the email with the information
+func SendEmail(t Transport, tmpl *htmltemplate.Template, data interface{}, md *Maildata) *model.AppErr {
+	htmlString, err := template.Render(tmpl, data)
+	if err != nil {
+		zlog.Info("could not render email template", zlog.String("template", tmpl.Name()), zlog.Err(err))
+		return model.NewAppErr("mailer.parse_template", model.ErrInternal, locale.GetUserLocalizer("en"), msgParseEmailTemplate, http.StatusInternalServerError, nil)
+	}
+	return sendHTML(t, htmlString, md)
+}
 
+func sendHTML(t Transport, htmlString string, md *Maildata) *model.AppErr {
 	textString, err := html2text.FromString(htmlString)
 	if err != nil {
 		zlog.Info("could not parse html to string", zlog.Err(err))
@@ -104,17 +120,5 @@ func SendEmailTemplate(filename string, data interface{}, md *Maildata, config *
 	md.hTMLBody = htmlString
 	md.textBody = textString
 
-	return send(config, md)
-}
-
-func send(config *config.Config, md *Maildata) *model.AppErr {
-	settings := config.EmailSettings
-	switch settings.Transport {
-	case "smtp":
-		return sendWithSMTP(settings, md)
-	case "sendgrid":
-		return sendWithSendgrid(config, md)
-	default:
-		panic(fmt.Errorf("could not configure mailer: unknown email transport"))
-	}
+	return t.Send(md)
 }
@@ -0,0 +1,51 @@
+package mailer
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/dankobgd/ecommerce-shop/zlog"
+	"github.com/sendgrid/sendgrid-go"
+	"github.com/sendgrid/sendgrid-go/helpers/mail"
+)
+
+// SendgridTransport sends the emails with the sendgrid api
+type SendgridTransport struct {
+	fromName  string
+	fromEmail string
+	apiKey    string
+}
+
+// NewSendgridTransport creates the new sendgrid transport
+func NewSendgridTransport(fromName, fromEmail, apiKey string) *SendgridTransport {
+	return &SendgridTransport{fromName: fromName, fromEmail: fromEmail, apiKey: apiKey}
+}
+
+// Send sends the email
+func (t *SendgridTransport) Send(md *Maildata) *model.AppErr {
+	from := mail.NewEmail(t.fromName, t.fromEmail)
+	to := mail.NewEmail("", strings.Join(md.To, ","))
+
+	message := mail.NewSingleEmail(from, md.Subject, to, md.textBody, md.hTMLBody)
+	for _, att := range md.Attachments {
+		a := mail.NewAttachment()
+		a.SetContent(base64.StdEncoding.EncodeToString(att.Content))
+		a.SetType(att.ContentType)
+		a.SetFilename(att.Filename)
+		a.SetDisposition("attachment")
+		message.AddAttachment(a)
+	}
+	client := sendgrid.NewSendClient(t.apiKey)
+
+	resp, err := client.Send(message)
+	if err != nil {
+		zlog.Info("could not send email with sendgird", zlog.String("recipients:", strings.Join(md.To, ",")), zlog.Err(err))
+		return model.NewAppErr("mailer.Send", model.ErrInternal, locale.GetUserLocalizer("en"), msgSendMailSendgrid, http.StatusInternalServerError, nil)
+	}
+
+	zlog.Info("email successfully sent with sendgrid", zlog.String("recipients:", strings.Join(md.To, ",")), zlog.Int("statusCode", resp.StatusCode))
+	return nil
+}
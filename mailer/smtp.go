@@ -0,0 +1,44 @@
+package mailer
+
+import (
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"strings"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/dankobgd/ecommerce-shop/zlog"
+)
+
+// SMTPTransport sends the emails through the smtp server
+type SMTPTransport struct {
+	from string
+	addr string
+	auth smtp.Auth
+}
+
+// NewSMTPTransport creates the new smtp transport
+func NewSMTPTransport(from, host string, port int, username, password string) *SMTPTransport {
+	return &SMTPTransport{
+		from: from,
+		addr: fmt.Sprintf("%s:%d", host, port),
+		auth: smtp.PlainAuth("", username, password, host),
+	}
+}
+
+// Send sends the email
+func (t *SMTPTransport) Send(md *Maildata) *model.AppErr {
+	e, err := toEmail(t.from, md)
+	if err != nil {
+		zlog.Info("could not attach file to the email", zlog.String("recipients:", strings.Join(md.To, ",")), zlog.Err(err))
+		return model.NewAppErr("mailer.Send", model.ErrInternal, locale.GetUserLocalizer("en"), msgAttachFile, http.StatusInternalServerError, nil)
+	}
+
+	if err := e.Send(t.addr, t.auth); err != nil {
+		zlog.Info("could not send email with smtp", zlog.String("recipients:", strings.Join(e.To, ",")), zlog.Err(err))
+		return model.NewAppErr("mailer.Send", model.ErrInternal, locale.GetUserLocalizer("en"), msgSendMailSMTP, http.StatusInternalServerError, nil)
+	}
+	zlog.Info("email has been sent successfully", zlog.String("recipients:", strings.Join(e.To, ",")))
+	return nil
+}
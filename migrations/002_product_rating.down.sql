@@ -0,0 +1,6 @@
+drop index product_rating_average_idx;
+
+alter table public.product
+  drop column rating_average,
+  drop column rating_count,
+  drop column rating_histogram;
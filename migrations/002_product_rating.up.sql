@@ -0,0 +1,27 @@
+alter table public.product
+  add column rating_average numeric(3, 2) default 0 not null,
+  add column rating_count int default 0 not null,
+  add column rating_histogram jsonb default '{"1": 0, "2": 0, "3": 0, "4": 0, "5": 0}' not null;
+
+update public.product p set
+  rating_average = s.rating_average,
+  rating_count = s.rating_count,
+  rating_histogram = s.rating_histogram
+from (
+  select
+    product_id,
+    round(avg(rating), 2) as rating_average,
+    count(*) as rating_count,
+    jsonb_build_object(
+      '1', count(*) filter (where rating = 1),
+      '2', count(*) filter (where rating = 2),
+      '3', count(*) filter (where rating = 3),
+      '4', count(*) filter (where rating = 4),
+      '5', count(*) filter (where rating = 5)
+    ) as rating_histogram
+  from public.product_review
+  group by product_id
+) s
+where p.id = s.product_id;
+
+create index product_rating_average_idx on public.product (rating_average);
@@ -0,0 +1,8 @@
+drop table public.product_review_report;
+drop table public.product_review_vote;
+
+alter table public.product_review
+  drop column is_verified_purchase,
+  drop column status,
+  drop column helpful_count,
+  drop column report_count;
@@ -0,0 +1,35 @@
+alter table public.product_review
+  add column is_verified_purchase bool default false not null,
+  add column status varchar(16) default 'approved' not null,
+  add column helpful_count int default 0 not null,
+  add column report_count int default 0 not null,
+  add check (status in ('pending', 'approved', 'rejected'));
+
+-- existing reviews stay approved, new ones go to the moderation queue
+alter table public.product_review alter column status set default 'pending';
+
+update public.product_review r set is_verified_purchase = exists (
+  select 1 from public.order_detail od
+  left join public.order o on od.order_id = o.id
+  where o.user_id = r.user_id and od.product_id = r.product_id and o.status = 'success'
+);
+
+create table public.product_review_vote (
+  review_id int not null,
+  user_id int not null,
+  created_at timestamptz not null,
+  foreign key (review_id) references public.product_review (id) on delete cascade,
+  foreign key (user_id) references public.user (id) on delete cascade,
+  primary key (review_id, user_id)
+);
+
+create table public.product_review_report (
+  id int generated always as identity primary key,
+  review_id int not null,
+  user_id int not null,
+  reason text not null,
+  created_at timestamptz not null,
+  foreign key (review_id) references public.product_review (id) on delete cascade,
+  foreign key (user_id) references public.user (id) on delete cascade,
+  unique (review_id, user_id)
+);
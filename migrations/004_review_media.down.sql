@@ -0,0 +1 @@
+drop table public.product_review_media;
@@ -0,0 +1,8 @@
+create table public.product_review_media (
+  id int generated always as identity primary key,
+  review_id int not null,
+  url text not null,
+  public_id text not null,
+  created_at timestamptz not null,
+  foreign key (review_id) references public.product_review (id) on delete cascade
+);
@@ -0,0 +1,3 @@
+drop table public.product_answer_vote;
+drop table public.product_answer;
+drop table public.product_question;
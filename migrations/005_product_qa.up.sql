@@ -0,0 +1,38 @@
+create table public.product_question (
+  id int generated always as identity primary key,
+  product_id int not null,
+  user_id int not null,
+  body text not null,
+  status varchar(16) default 'pending' not null,
+  answer_count int default 0 not null,
+  created_at timestamptz not null,
+  updated_at timestamptz not null,
+  foreign key (product_id) references public.product (id) on delete cascade,
+  foreign key (user_id) references public.user (id) on delete cascade,
+  check (status in ('pending', 'approved', 'rejected'))
+);
+
+create table public.product_answer (
+  id int generated always as identity primary key,
+  question_id int not null,
+  user_id int not null,
+  body text not null,
+  is_admin bool default false not null,
+  is_verified_purchase bool default false not null,
+  status varchar(16) default 'pending' not null,
+  upvote_count int default 0 not null,
+  created_at timestamptz not null,
+  updated_at timestamptz not null,
+  foreign key (question_id) references public.product_question (id) on delete cascade,
+  foreign key (user_id) references public.user (id) on delete cascade,
+  check (status in ('pending', 'approved', 'rejected'))
+);
+
+create table public.product_answer_vote (
+  answer_id int not null,
+  user_id int not null,
+  created_at timestamptz not null,
+  foreign key (answer_id) references public.product_answer (id) on delete cascade,
+  foreign key (user_id) references public.user (id) on delete cascade,
+  primary key (answer_id, user_id)
+);
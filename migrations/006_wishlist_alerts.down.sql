@@ -0,0 +1,8 @@
+drop table public.wishlist_alert_log;
+
+alter table public.product_wishlist
+  drop column notify_back_in_stock,
+  drop column notify_price_drop,
+  drop column last_seen_price,
+  drop column last_seen_in_stock,
+  drop column last_notified_at;
@@ -0,0 +1,19 @@
+alter table public.product_wishlist
+  add column notify_back_in_stock bool default false not null,
+  add column notify_price_drop bool default false not null,
+  add column last_seen_price int,
+  add column last_seen_in_stock bool,
+  add column last_notified_at timestamptz;
+
+create table public.wishlist_alert_log (
+  id int generated always as identity primary key,
+  user_id int not null,
+  product_id int not null,
+  kind varchar(20) not null check (kind in ('back_in_stock', 'price_drop')),
+  price int,
+  sent_at timestamptz not null,
+  foreign key (user_id) references public.user (id) on delete cascade,
+  foreign key (product_id) references public.product (id) on delete cascade
+);
+
+create index wishlist_alert_log_user_sent_idx on public.wishlist_alert_log (user_id, sent_at);
@@ -0,0 +1,13 @@
+delete from public.product_wishlist a
+using public.product_wishlist b
+where a.user_id = b.user_id and a.product_id = b.product_id and a.id > b.id;
+
+alter table public.product_wishlist
+  drop constraint product_wishlist_wishlist_id_product_id_key,
+  drop column wishlist_id,
+  drop column quantity,
+  drop column note,
+  drop column created_at,
+  add constraint product_wishlist_user_id_product_id_key unique (user_id, product_id);
+
+drop table public.wishlist;
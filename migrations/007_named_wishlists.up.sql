@@ -0,0 +1,31 @@
+create table public.wishlist (
+  id int generated always as identity primary key,
+  user_id int not null,
+  name varchar(100) not null,
+  is_default bool default false not null,
+  share_token varchar(64) unique,
+  created_at timestamptz not null,
+  updated_at timestamptz not null,
+  foreign key (user_id) references public.user (id) on delete cascade
+);
+
+create unique index wishlist_user_default_idx on public.wishlist (user_id) where is_default;
+
+insert into public.wishlist (user_id, name, is_default, created_at, updated_at)
+select distinct user_id, 'Wishlist', true, now(), now() from public.product_wishlist;
+
+alter table public.product_wishlist
+  add column wishlist_id int,
+  add column quantity int default 1 not null check (quantity > 0),
+  add column note text,
+  add column created_at timestamptz default now() not null;
+
+update public.product_wishlist pw set wishlist_id = w.id
+from public.wishlist w
+where w.user_id = pw.user_id and w.is_default;
+
+alter table public.product_wishlist
+  alter column wishlist_id set not null,
+  add foreign key (wishlist_id) references public.wishlist (id) on delete cascade,
+  drop constraint product_wishlist_user_id_product_id_key,
+  add constraint product_wishlist_wishlist_id_product_id_key unique (wishlist_id, product_id);
@@ -0,0 +1,18 @@
+create table public.job (
+  id bigint generated always as identity primary key,
+  type varchar(50) not null,
+  payload jsonb default '{}'::jsonb not null,
+  status varchar(20) default 'pending' not null check (status in ('pending', 'running', 'completed', 'dead')),
+  attempts int default 0 not null,
+  max_attempts int not null,
+  last_error text,
+  unique_key varchar(255) unique,
+  run_at timestamptz not null,
+  locked_at timestamptz,
+  completed_at timestamptz,
+  created_at timestamptz not null,
+  updated_at timestamptz not null
+);
+
+create index job_status_run_at_idx on public.job (status, run_at);
+create index job_type_idx on public.job (type);
@@ -0,0 +1,12 @@
+create table public.event_outbox (
+  id bigint generated always as identity primary key,
+  type varchar(50) not null,
+  aggregate_type varchar(50) not null,
+  aggregate_id bigint not null,
+  payload jsonb default '{}'::jsonb not null,
+  created_at timestamptz not null,
+  dispatched_at timestamptz
+);
+
+create index event_outbox_undispatched_idx on public.event_outbox (id) where dispatched_at is null;
+create index event_outbox_aggregate_idx on public.event_outbox (aggregate_type, aggregate_id);
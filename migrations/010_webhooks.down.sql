@@ -0,0 +1,2 @@
+drop table public.webhook_delivery;
+drop table public.webhook;
@@ -0,0 +1,30 @@
+create table public.webhook (
+  id int generated always as identity primary key,
+  url text not null,
+  secret varchar(100) not null,
+  event_types text[] not null,
+  description text,
+  active boolean default true not null,
+  created_at timestamptz not null,
+  updated_at timestamptz not null
+);
+
+create table public.webhook_delivery (
+  id bigint generated always as identity primary key,
+  webhook_id int not null references public.webhook (id) on delete cascade,
+  event_id bigint not null,
+  event_type varchar(50) not null,
+  payload jsonb not null,
+  status varchar(20) default 'pending' not null check (status in ('pending', 'success', 'failed')),
+  attempts int default 0 not null,
+  response_code int,
+  response_body text,
+  error text,
+  duration_ms int,
+  replay_of bigint references public.webhook_delivery (id) on delete set null,
+  last_attempt_at timestamptz,
+  created_at timestamptz not null
+);
+
+create unique index webhook_delivery_event_idx on public.webhook_delivery (webhook_id, event_id) where replay_of is null;
+create index webhook_delivery_webhook_created_idx on public.webhook_delivery (webhook_id, created_at desc);
@@ -0,0 +1,4 @@
+alter table public.order
+  drop column tracking_number,
+  drop column tracking_url,
+  drop column delivered_at;
@@ -0,0 +1,4 @@
+alter table public.order
+  add column tracking_number text,
+  add column tracking_url text,
+  add column delivered_at timestamptz;
@@ -0,0 +1 @@
+drop table public.email_template;
@@ -0,0 +1,10 @@
+create table public.email_template (
+  id int generated always as identity primary key,
+  name varchar(50) not null,
+  locale varchar(5) not null,
+  subject text,
+  body text not null,
+  created_at timestamptz not null,
+  updated_at timestamptz not null,
+  unique (name, locale)
+);
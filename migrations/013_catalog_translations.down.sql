@@ -0,0 +1,10 @@
+drop trigger brand_slug on public.brand;
+drop trigger category_slug on public.category;
+drop trigger product_slug on public.product;
+
+drop table public.brand_translation;
+drop table public.category_translation;
+drop table public.product_translation;
+
+drop function public.check_default_slug();
+drop function public.check_translation_slug();
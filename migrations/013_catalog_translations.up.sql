@@ -0,0 +1,92 @@
+create table public.product_translation (
+  product_id int not null references public.product(id) on delete cascade,
+  locale varchar(5) not null,
+  name varchar(64) not null,
+  slug varchar(64) not null,
+  description text,
+  created_at timestamptz not null,
+  updated_at timestamptz not null,
+  primary key (product_id, locale),
+  unique (locale, slug)
+);
+
+create table public.category_translation (
+  category_id int not null references public.category(id) on delete cascade,
+  locale varchar(5) not null,
+  name varchar(64) not null,
+  slug varchar(64) not null,
+  description text,
+  created_at timestamptz not null,
+  updated_at timestamptz not null,
+  primary key (category_id, locale),
+  unique (locale, slug)
+);
+
+create table public.brand_translation (
+  brand_id int not null references public.brand(id) on delete cascade,
+  locale varchar(5) not null,
+  name varchar(64) not null,
+  slug varchar(64) not null,
+  description text,
+  created_at timestamptz not null,
+  updated_at timestamptz not null,
+  primary key (brand_id, locale),
+  unique (locale, slug)
+);
+
+create index product_translation_slug_idx on public.product_translation (slug);
+
+-- the slug resolves to a single resource, so the translated slugs can't be used by the other
+-- resources, neither as their default slug nor as their slug in any other locale
+create function public.check_translation_slug() returns trigger as $$
+declare
+  resource text := TG_ARGV[0];
+  resource_id int;
+  taken bool;
+begin
+  execute format('select ($1).%I', resource || '_id') into resource_id using new;
+  perform pg_advisory_xact_lock(hashtext(resource || ':' || new.slug));
+
+  execute format('select exists (select 1 from public.%I where slug = $1 and id <> $2)
+    or exists (select 1 from public.%I where slug = $1 and %I <> $2)',
+    resource, resource || '_translation', resource || '_id')
+    into taken using new.slug, resource_id;
+
+  if taken then
+    raise exception 'slug "%" is already used by another %', new.slug, resource using errcode = 'unique_violation';
+  end if;
+  return new;
+end;
+$$ language plpgsql;
+
+create function public.check_default_slug() returns trigger as $$
+declare
+  resource text := TG_ARGV[0];
+  taken bool;
+begin
+  perform pg_advisory_xact_lock(hashtext(resource || ':' || new.slug));
+
+  execute format('select exists (select 1 from public.%I where slug = $1 and %I <> $2)',
+    resource || '_translation', resource || '_id')
+    into taken using new.slug, new.id;
+
+  if taken then
+    raise exception 'slug "%" is already used by another %', new.slug, resource using errcode = 'unique_violation';
+  end if;
+  return new;
+end;
+$$ language plpgsql;
+
+create trigger product_translation_slug before insert or update of slug on public.product_translation
+  for each row execute procedure public.check_translation_slug('product');
+create trigger category_translation_slug before insert or update of slug on public.category_translation
+  for each row execute procedure public.check_translation_slug('category');
+create trigger brand_translation_slug before insert or update of slug on public.brand_translation
+  for each row execute procedure public.check_translation_slug('brand');
+
+create trigger product_slug before insert or update of slug on public.product
+  for each row execute procedure public.check_default_slug('product');
+create trigger category_slug before insert or update of slug on public.category
+  for each row execute procedure public.check_default_slug('category');
+create trigger brand_slug before insert or update of slug on public.brand
+  for each row execute procedure public.check_default_slug('brand');
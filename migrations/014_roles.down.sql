@@ -0,0 +1,2 @@
+drop table public.user_role;
+drop table public.role;
@@ -0,0 +1,23 @@
+create table public.role (
+  id int generated always as identity primary key,
+  name varchar(50) not null unique,
+  description text,
+  permissions text[] not null default '{}',
+  created_at timestamptz not null,
+  updated_at timestamptz not null
+);
+
+create table public.user_role (
+  user_id int not null references public.user (id) on delete cascade,
+  role_id int not null references public.role (id) on delete cascade,
+  created_at timestamptz not null,
+  primary key (user_id, role_id)
+);
+
+create index user_role_role_id_idx on public.user_role (role_id);
+
+insert into public.role (name, description, permissions, created_at, updated_at) values
+('catalog_manager', 'Manages the products, categories, brands and tags', '{product:write,category:write,brand:write,tag:write}', now(), now()),
+('order_fulfillment', 'Processes and ships the orders', '{order:read,order:write}', now(), now()),
+('support', 'Helps the customers with their orders and moderates the reviews and questions', '{order:read,order:refund,user:read,review:moderate,question:moderate}', now(), now()),
+('marketing', 'Runs the promotions and edits the emails', '{promotion:manage,email_template:manage}', now(), now());
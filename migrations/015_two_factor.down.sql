@@ -0,0 +1,2 @@
+drop table public.user_recovery_code;
+drop table public.user_two_factor;
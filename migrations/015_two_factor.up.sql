@@ -0,0 +1,18 @@
+create table public.user_two_factor (
+  user_id int primary key references public.user (id) on delete cascade,
+  secret text not null,
+  enabled boolean not null default false,
+  last_used_step bigint not null default 0,
+  enabled_at timestamptz,
+  created_at timestamptz not null,
+  updated_at timestamptz not null
+);
+
+create table public.user_recovery_code (
+  id int generated always as identity primary key,
+  user_id int not null references public.user (id) on delete cascade,
+  code_hash varchar(64) not null,
+  used_at timestamptz,
+  created_at timestamptz not null,
+  unique (user_id, code_hash)
+);
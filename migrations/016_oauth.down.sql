@@ -0,0 +1 @@
+drop table public.user_identity;
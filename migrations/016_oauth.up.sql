@@ -0,0 +1,11 @@
+create table public.user_identity (
+  id int generated always as identity primary key,
+  user_id int not null references public.user (id) on delete cascade,
+  provider varchar(32) not null,
+  subject varchar(255) not null,
+  email varchar(128),
+  created_at timestamptz not null,
+  last_login_at timestamptz not null,
+  unique (provider, subject),
+  unique (user_id, provider)
+);
@@ -0,0 +1,2 @@
+drop table public.api_key_usage;
+drop table public.api_key;
@@ -0,0 +1,25 @@
+create table public.api_key (
+  id int generated always as identity primary key,
+  name varchar(100) not null,
+  prefix varchar(16) not null,
+  key_hash varchar(64) not null unique,
+  scopes text[] not null default '{}',
+  created_by int not null references public.user (id) on delete cascade,
+  expires_at timestamptz,
+  last_used_at timestamptz,
+  last_used_ip varchar(64),
+  revoked_at timestamptz,
+  created_at timestamptz not null
+);
+
+create table public.api_key_usage (
+  id bigint generated always as identity primary key,
+  api_key_id int not null references public.api_key (id) on delete cascade,
+  method varchar(10) not null,
+  path text not null,
+  ip varchar(64) not null,
+  request_id varchar(128) not null,
+  created_at timestamptz not null
+);
+
+create index api_key_usage_api_key_id_idx on public.api_key_usage (api_key_id, created_at desc);
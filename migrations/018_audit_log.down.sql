@@ -0,0 +1,2 @@
+drop table public.audit_log;
+drop function public.audit_log_append_only();
@@ -0,0 +1,31 @@
+create table public.audit_log (
+  id bigint generated always as identity primary key,
+  actor_id int,
+  api_key_id int,
+  action varchar(16) not null,
+  resource_type varchar(32) not null,
+  resource_id varchar(255) not null,
+  diff jsonb default '{}'::jsonb not null,
+  ip varchar(64) not null,
+  request_id varchar(128) not null,
+  created_at timestamptz not null
+);
+
+create index audit_log_created_at_idx on public.audit_log (created_at);
+create index audit_log_resource_idx on public.audit_log (resource_type, resource_id);
+create index audit_log_actor_id_idx on public.audit_log (actor_id);
+
+-- the log is append only, the entries outlive the users and keys so there are no foreign keys
+create function public.audit_log_append_only() returns trigger as $$
+begin
+  raise exception 'audit_log is append only, % is not allowed', TG_OP using errcode = 'insufficient_privilege';
+end;
+$$ language plpgsql;
+
+create trigger audit_log_no_update_delete before update or delete on public.audit_log
+  for each row execute procedure public.audit_log_append_only();
+create trigger audit_log_no_truncate before truncate on public.audit_log
+  for each statement execute procedure public.audit_log_append_only();
+
+-- the app role runs the migrations and owns the table, it only needs to insert and read the log
+revoke update, delete, truncate on public.audit_log from public, current_user;
@@ -36,6 +36,7 @@ const (
 	TokenLocationCookie
 	TokenLocationHeader
 	TokenLocationQueryString
+	TokenLocationAPIKey
 )
 
 func (loc AccessTokenLocation) String() string {
@@ -48,6 +49,8 @@ func (loc AccessTokenLocation) String() string {
 		return "cookie"
 	case TokenLocationQueryString:
 		return "query_string"
+	case TokenLocationAPIKey:
+		return "api_key"
 	default:
 		return "unknown"
 	}
@@ -60,14 +63,18 @@ type RefreshToken struct {
 
 // AccessData holds the auth access info
 type AccessData struct {
-	AccessUUID string
-	UserID     int64
-	Role       string
+	AccessUUID  string
+	SessionID   string
+	UserID      int64
+	Role        string
+	Permissions []string
+	APIKeyID    int64
 }
 
 // TokenMetadata holds the tokens details
 type TokenMetadata struct {
 	TokenType      string
+	SessionID      string
 	AccessToken    string
 	RefreshToken   string
 	AccessUUID     string
@@ -78,8 +85,11 @@ type TokenMetadata struct {
 
 // Claims is the custom claims for the jwt
 type Claims struct {
-	Username string `json:"username,omitempty"`
-	Role     string `json:"role,omitempty"`
+	Username    string   `json:"username,omitempty"`
+	Role        string   `json:"role,omitempty"`
+	Locale      string   `json:"locale,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	SessionID   string   `json:"sid,omitempty"`
 	jwt.StandardClaims
 }
 
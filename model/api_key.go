@@ -0,0 +1,143 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/dankobgd/ecommerce-shop/utils/random"
+	"github.com/lib/pq"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// error msgs
+var (
+	msgInvalidAPIKey           = &i18n.Message{ID: "model.api_key.validate.app_error", Other: "invalid api key data"}
+	msgValidateAPIKeyName      = &i18n.Message{ID: "model.api_key.validate.name.app_error", Other: "api key name must be 1 to 100 characters"}
+	msgValidateAPIKeyScopes    = &i18n.Message{ID: "model.api_key.validate.scopes.app_error", Other: "api key needs at least one valid scope"}
+	msgValidateAPIKeyExpiresAt = &i18n.Message{ID: "model.api_key.validate.expires_at.app_error", Other: "api key expiry must be in the future"}
+	msgValidateAPIKeyCreatedBy = &i18n.Message{ID: "model.api_key.validate.created_by.app_error", Other: "invalid api key creator"}
+	msgValidateAPIKeyCreatedAt = &i18n.Message{ID: "model.api_key.validate.created_at.app_error", Other: "invalid api key created_at timestamp"}
+)
+
+// api keys
+const (
+	HeaderAPIKey = "X-API-Key"
+
+	apiKeyPrefix        = "esk_"
+	apiKeySecretLength  = 32
+	apiKeyDisplayLength = 12
+	apiKeyNameMaxRunes  = 100
+)
+
+// APIKey is the scoped key the integrations authenticate with instead of the user session,
+// the key is granted only its scopes which are the same permissions the staff roles grant
+type APIKey struct {
+	TotalRecordsCount
+	ID         int64          `json:"id" db:"id"`
+	Name       string         `json:"name" db:"name"`
+	Key        string         `json:"key,omitempty" db:"-"`
+	Prefix     string         `json:"prefix" db:"prefix"`
+	KeyHash    string         `json:"-" db:"key_hash"`
+	Scopes     pq.StringArray `json:"scopes" db:"scopes"`
+	CreatedBy  int64          `json:"created_by" db:"created_by"`
+	ExpiresAt  *time.Time     `json:"expires_at" db:"expires_at"`
+	LastUsedAt *time.Time     `json:"last_used_at" db:"last_used_at"`
+	LastUsedIP *string        `json:"last_used_ip" db:"last_used_ip"`
+	RevokedAt  *time.Time     `json:"revoked_at" db:"revoked_at"`
+	CreatedAt  time.Time      `json:"created_at" db:"created_at"`
+}
+
+// APIKeyUsage is the request made with the api key
+type APIKeyUsage struct {
+	TotalRecordsCount
+	ID        int64     `json:"id" db:"id"`
+	APIKeyID  int64     `json:"api_key_id" db:"api_key_id"`
+	Method    string    `json:"method" db:"method"`
+	Path      string    `json:"path" db:"path"`
+	IP        string    `json:"ip" db:"ip"`
+	RequestID string    `json:"request_id" db:"request_id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// APIKeyFromJSON decodes the input and returns the APIKey
+func APIKeyFromJSON(data io.Reader) (*APIKey, error) {
+	var k *APIKey
+	err := json.NewDecoder(data).Decode(&k)
+	return k, err
+}
+
+// PreSave generates the key and fills the timestamps, the plain key is kept only until it's returned to the admin
+func (k *APIKey) PreSave() {
+	k.Key = apiKeyPrefix + random.SecureToken(apiKeySecretLength)
+	k.Prefix = k.Key[:apiKeyDisplayLength]
+	k.KeyHash = HashAPIKey(k.Key)
+	if k.Scopes == nil {
+		k.Scopes = pq.StringArray{}
+	}
+	k.CreatedAt = time.Now()
+}
+
+// Validate validates the api key and returns an error if it doesn't pass criteria
+func (k *APIKey) Validate() *AppErr {
+	var errs ValidationErrors
+	l := locale.GetUserLocalizer("en")
+
+	if n := utf8.RuneCountInString(strings.TrimSpace(k.Name)); n == 0 || n > apiKeyNameMaxRunes {
+		errs.Add(Invalid("name", l, msgValidateAPIKeyName))
+	}
+	if len(k.Scopes) == 0 {
+		errs.Add(Invalid("scopes", l, msgValidateAPIKeyScopes))
+	}
+	for _, s := range k.Scopes {
+		if !IsValidPermission(s) {
+			errs.Add(Invalid("scopes", l, msgValidateAPIKeyScopes))
+			break
+		}
+	}
+	if k.ExpiresAt != nil && !k.ExpiresAt.After(k.CreatedAt) {
+		errs.Add(Invalid("expires_at", l, msgValidateAPIKeyExpiresAt))
+	}
+	if k.CreatedBy == 0 {
+		errs.Add(Invalid("created_by", l, msgValidateAPIKeyCreatedBy))
+	}
+	if k.CreatedAt.IsZero() {
+		errs.Add(Invalid("created_at", l, msgValidateAPIKeyCreatedAt))
+	}
+
+	if !errs.IsZero() {
+		return NewValidationError("APIKey", msgInvalidAPIKey, "", errs)
+	}
+	return nil
+}
+
+// IsActive checks if the key is neither revoked nor expired
+func (k *APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// AccessData returns the access the key grants, the key acts on behalf of the admin who created it
+// but without the admin role so it's limited to its scopes
+func (k *APIKey) AccessData() *AccessData {
+	return &AccessData{
+		UserID:      k.CreatedBy,
+		Permissions: k.Scopes,
+		APIKeyID:    k.ID,
+	}
+}
+
+// HashAPIKey hashes the api key, the keys are random so the fast hash is enough
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIKey checks if the token looks like the api key
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, apiKeyPrefix)
+}
@@ -0,0 +1,217 @@
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jmoiron/sqlx/types"
+)
+
+// audit actions
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// audited resource types
+const (
+	AuditResourceUser            = "user"
+	AuditResourceUserRole        = "user_role"
+	AuditResourceUserIdentity    = "user_identity"
+	AuditResourceTwoFactor       = "two_factor"
+	AuditResourceProduct         = "product"
+	AuditResourceProductPricing  = "product_pricing"
+	AuditResourceProductTag      = "product_tag"
+	AuditResourceProductImage    = "product_image"
+	AuditResourceProductReview   = "product_review"
+	AuditResourceReviewMedia     = "product_review_media"
+	AuditResourceReviewVote      = "product_review_vote"
+	AuditResourceReviewReport    = "product_review_report"
+	AuditResourceProductQuestion = "product_question"
+	AuditResourceProductAnswer   = "product_answer"
+	AuditResourceAnswerUpvote    = "product_answer_upvote"
+	AuditResourceCategory        = "category"
+	AuditResourceBrand           = "brand"
+	AuditResourceTag             = "tag"
+	AuditResourcePromotion       = "promotion"
+	AuditResourceWebhook         = "webhook"
+	AuditResourceWebhookDelivery = "webhook_delivery"
+	AuditResourceEmailTemplate   = "email_template"
+	AuditResourceTranslation     = "translation"
+	AuditResourceRole            = "role"
+	AuditResourceAPIKey          = "api_key"
+	AuditResourceJob             = "job"
+	AuditResourceOrder           = "order"
+	AuditResourceAddress         = "address"
+	AuditResourceWishlist        = "wishlist"
+	AuditResourceWishlistItem    = "wishlist_item"
+)
+
+// AuditExportMaxRows limits how many entries the csv export contains
+const AuditExportMaxRows = 10000
+
+// the column sizes of the values that come from the request or the client,
+// the longer values are cut so the entry can always be saved
+const (
+	AuditResourceIDMaxLength = 255
+	AuditIPMaxLength         = 64
+	AuditRequestIDMaxLength  = 128
+)
+
+const auditRedacted = "[redacted]"
+
+// auditRedactedFields are the secrets whose changes are recorded without their values
+var auditRedactedFields = map[string]bool{
+	"password":         true,
+	"confirm_password": true,
+	"secret":           true,
+	"key":              true,
+	"token":            true,
+	"share_token":      true,
+}
+
+// auditIgnoredFields change on every update so they are left out of the diff
+var auditIgnoredFields = map[string]bool{
+	"updated_at": true,
+}
+
+// AuditActor is who made the change, the api key is set when the change was made with the key
+type AuditActor struct {
+	UserID    int64
+	APIKeyID  int64
+	IP        string
+	RequestID string
+}
+
+// SystemAuditActor makes the changes that are not made by a request, e.g. the jobs
+var SystemAuditActor = &AuditActor{RequestID: "system"}
+
+// AuditEntry is the recorded change of the resource, the entries are never updated or deleted
+type AuditEntry struct {
+	TotalRecordsCount
+	ID           int64          `json:"id" db:"id"`
+	ActorID      *int64         `json:"actor_id" db:"actor_id"`
+	APIKeyID     *int64         `json:"api_key_id" db:"api_key_id"`
+	Action       string         `json:"action" db:"action"`
+	ResourceType string         `json:"resource_type" db:"resource_type"`
+	ResourceID   string         `json:"resource_id" db:"resource_id"`
+	Diff         types.JSONText `json:"diff" db:"diff"`
+	IP           string         `json:"ip" db:"ip"`
+	RequestID    string         `json:"request_id" db:"request_id"`
+	CreatedAt    time.Time      `json:"created_at" db:"created_at"`
+}
+
+// AuditFilter filters the audit log, the zero values are not filtered on
+type AuditFilter struct {
+	ActorID      int64
+	APIKeyID     int64
+	Action       string
+	ResourceType string
+	ResourceID   string
+	RequestID    string
+	From         *time.Time
+	To           *time.Time
+}
+
+// AuditChange is the value of the field before and after the change
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// NewAuditEntry creates the entry of the actor's change
+func NewAuditEntry(actor *AuditActor, action, resourceType, resourceID string, diff types.JSONText) *AuditEntry {
+	e := &AuditEntry{
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   TruncateRunes(resourceID, AuditResourceIDMaxLength),
+		Diff:         diff,
+		IP:           TruncateRunes(actor.IP, AuditIPMaxLength),
+		RequestID:    TruncateRunes(actor.RequestID, AuditRequestIDMaxLength),
+		CreatedAt:    time.Now(),
+	}
+	if actor.UserID != 0 {
+		e.ActorID = &actor.UserID
+	}
+	if actor.APIKeyID != 0 {
+		e.APIKeyID = &actor.APIKeyID
+	}
+	return e
+}
+
+// AuditDiff returns the fields that differ between the json representations of the resource,
+// before is nil for the created resource and after is nil for the deleted one
+func AuditDiff(before, after interface{}) (types.JSONText, error) {
+	b, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := make(map[string]*AuditChange)
+	for k, v := range b {
+		if !reflect.DeepEqual(v, a[k]) {
+			diff[k] = &AuditChange{Before: v, After: a[k]}
+		}
+	}
+	for k, v := range a {
+		if _, ok := b[k]; !ok && v != nil {
+			diff[k] = &AuditChange{Before: nil, After: v}
+		}
+	}
+
+	for k, c := range diff {
+		if auditIgnoredFields[k] {
+			delete(diff, k)
+			continue
+		}
+		if auditRedactedFields[k] {
+			if c.Before != nil {
+				c.Before = auditRedacted
+			}
+			if c.After != nil {
+				c.After = auditRedacted
+			}
+		}
+	}
+
+	out, err := json.Marshal(diff)
+	if err != nil {
+		return nil, err
+	}
+	return types.JSONText(out), nil
+}
+
+func auditFields(v interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if v == nil || reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil() {
+		return fields, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		// the resource is not the json object, it's recorded as the whole value
+		var value interface{}
+		if err := json.Unmarshal(b, &value); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"value": value}, nil
+	}
+	return fields, nil
+}
+
+// TruncateRunes cuts the string to n characters, the varchar sizes count characters not bytes
+func TruncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
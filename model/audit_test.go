@@ -0,0 +1,28 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestAuditDiffRedactsTheShareToken(t *testing.T) {
+	before, after := "old-share-token", "new-share-token"
+
+	diff, err := AuditDiff(&Wishlist{ID: 1, ShareToken: &before}, &Wishlist{ID: 1, ShareToken: &after})
+	if err != nil {
+		t.Fatalf("AuditDiff: %v", err)
+	}
+	if strings.Contains(string(diff), before) || strings.Contains(string(diff), after) {
+		t.Fatalf("the diff %s has the share token", diff)
+	}
+
+	var changes map[string]*AuditChange
+	if err := json.Unmarshal(diff, &changes); err != nil {
+		t.Fatal(err)
+	}
+	c, ok := changes["share_token"]
+	if !ok || c.Before != auditRedacted || c.After != auditRedacted {
+		t.Errorf("the share token change is %+v, want it redacted", c)
+	}
+}
@@ -24,7 +24,7 @@ var (
 	msgValidateBrandCrAt       = &i18n.Message{ID: "model.brand.validate.created_at.app_error", Other: "invalid brand created_at timestamp"}
 	msgValidateBrandUpAt       = &i18n.Message{ID: "model.brand.validate.updated_at.app_error", Other: "invalid brand updated_at timestamp"}
 	msgValidateBrandLogo       = &i18n.Message{ID: "model.brand.validate.logo.app_error", Other: "invalid brand logo"}
-	msgValidateBrandLogoSize   = &i18n.Message{ID: "model.brand.validate.logo.app_error", Other: "File size exceeded, max 3MB allowed"}
+	msgValidateBrandLogoSize   = &i18n.Message{ID: "model.brand.validate.logo_size.app_error", Other: "File size exceeded, max 3MB allowed"}
 )
 
 // Brand is the brand of the product
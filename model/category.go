@@ -18,9 +18,9 @@ var (
 	msgInvalidCategory            = &i18n.Message{ID: "model.category.validate.app_error", Other: "invalid category data"}
 	msgValidateCategoryID         = &i18n.Message{ID: "model.category.validate.id.app_error", Other: "invalid category id"}
 	msgValidateCategoryName       = &i18n.Message{ID: "model.category.validate.name.app_error", Other: "invalid category name"}
-	msgValidateCategorySlug       = &i18n.Message{ID: "model.category.validate.created_at.app_error", Other: "invalid category slug"}
+	msgValidateCategorySlug       = &i18n.Message{ID: "model.category.validate.slug.app_error", Other: "invalid category slug"}
 	msgValidateCategoryLogo       = &i18n.Message{ID: "model.category.validate.logo.app_error", Other: "invalid logo"}
-	msgValidateCategoryLogoSize   = &i18n.Message{ID: "model.category.validate.logo.app_error", Other: "category file size exceeded, max 3MB"}
+	msgValidateCategoryLogoSize   = &i18n.Message{ID: "model.category.validate.logo_size.app_error", Other: "category file size exceeded, max 3MB"}
 	msgValidateCategoryCrAt       = &i18n.Message{ID: "model.category.validate.created_at.app_error", Other: "invalid category created_at timestamp"}
 	msgValidateCategoryUpAt       = &i18n.Message{ID: "model.category.validate.updated_at.app_error", Other: "invalid category updated_at timestamp"}
 	msgValidateCategoryProperties = &i18n.Message{ID: "model.category.validate.properties.app_error", Other: "invalid json provided as properties"}
@@ -0,0 +1,145 @@
+package model
+
+import (
+	"encoding/json"
+	htmltemplate "html/template"
+	"io"
+	"io/ioutil"
+	"text/template"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// error msgs
+var (
+	msgInvalidEmailTemplate        = &i18n.Message{ID: "model.email_template.validate.app_error", Other: "invalid email template data"}
+	msgValidateEmailTemplateName   = &i18n.Message{ID: "model.email_template.validate.name.app_error", Other: "unknown email template name"}
+	msgValidateEmailTemplateLocale = &i18n.Message{ID: "model.email_template.validate.locale.app_error", Other: "unsupported email template locale"}
+	msgValidateEmailTemplateBody   = &i18n.Message{ID: "model.email_template.validate.body.app_error", Other: "invalid email template body"}
+	msgValidateEmailTemplateSubj   = &i18n.Message{ID: "model.email_template.validate.subject.app_error", Other: "invalid email template subject"}
+	msgValidateEmailTemplateCrAt   = &i18n.Message{ID: "model.email_template.validate.created_at.app_error", Other: "invalid email template created_at timestamp"}
+	msgValidateEmailTemplateUpAt   = &i18n.Message{ID: "model.email_template.validate.updated_at.app_error", Other: "invalid email template updated_at timestamp"}
+)
+
+// email template names
+const (
+	EmailTemplateWelcome          = "welcome"
+	EmailTemplateEmailVerify      = "email_verify"
+	EmailTemplatePasswordRecovery = "password_recovery"
+	EmailTemplatePasswordUpdated  = "password_updated"
+	EmailTemplateQuestionAsked    = "question_asked"
+	EmailTemplateQuestionAnswered = "question_answered"
+	EmailTemplateWishlistAlert    = "wishlist_alert"
+	EmailTemplateOrderConfirmed   = "order_confirmation"
+	EmailTemplateOrderShipped     = "order_shipped"
+	EmailTemplateOrderDelivered   = "order_delivered"
+	EmailTemplateOrderCancelled   = "order_cancelled"
+	EmailTemplateOrderRefunded    = "order_refunded"
+	EmailTemplateSuspiciousLogin  = "suspicious_login"
+)
+
+// EmailTemplateNames are the emails whose templates can be overridden
+var EmailTemplateNames = []string{
+	EmailTemplateWelcome,
+	EmailTemplateEmailVerify,
+	EmailTemplatePasswordRecovery,
+	EmailTemplatePasswordUpdated,
+	EmailTemplateQuestionAsked,
+	EmailTemplateQuestionAnswered,
+	EmailTemplateWishlistAlert,
+	EmailTemplateOrderConfirmed,
+	EmailTemplateOrderShipped,
+	EmailTemplateOrderDelivered,
+	EmailTemplateOrderCancelled,
+	EmailTemplateOrderRefunded,
+	EmailTemplateSuspiciousLogin,
+}
+
+// EmailTemplate overrides the file template and the default subject of the email for the locale
+type EmailTemplate struct {
+	ID        int64     `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Locale    string    `json:"locale" db:"locale"`
+	Subject   *string   `json:"subject" db:"subject"`
+	Body      string    `json:"body" db:"body"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// EmailTemplatePreview is the rendered email
+type EmailTemplatePreview struct {
+	Name    string `json:"name"`
+	Locale  string `json:"locale"`
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+}
+
+// EmailTemplateFromJSON decodes the input and returns the EmailTemplate
+func EmailTemplateFromJSON(data io.Reader) (*EmailTemplate, error) {
+	var et *EmailTemplate
+	err := json.NewDecoder(data).Decode(&et)
+	return et, err
+}
+
+// PreSave will fill timestamps
+func (et *EmailTemplate) PreSave() {
+	et.CreatedAt = time.Now()
+	et.UpdatedAt = et.CreatedAt
+}
+
+// ParseBody parses the html template body
+func (et *EmailTemplate) ParseBody() (*htmltemplate.Template, error) {
+	return htmltemplate.New(et.Name).Parse(et.Body)
+}
+
+// ParseSubject parses the subject template, it's a text template so the subject is not html escaped
+func (et *EmailTemplate) ParseSubject() (*template.Template, error) {
+	if et.Subject == nil {
+		return nil, nil
+	}
+	return template.New(et.Name + "_subject").Parse(*et.Subject)
+}
+
+// Validate validates the email template and returns an error if it doesn't pass criteria,
+// the body and the subject have to be valid templates and render with the sample data
+func (et *EmailTemplate) Validate(sampleData interface{}) *AppErr {
+	var errs ValidationErrors
+	l := locale.GetUserLocalizer("en")
+
+	if !IsValidEmailTemplateName(et.Name) {
+		errs.Add(Invalid("name", l, msgValidateEmailTemplateName))
+	}
+	if _, ok := locale.GetSupportedLocales()[et.Locale]; !ok {
+		errs.Add(Invalid("locale", l, msgValidateEmailTemplateLocale))
+	}
+	// the sample data is the map so the misspelled fields would otherwise render as "<no value>"
+	if body, err := et.ParseBody(); et.Body == "" || err != nil || body.Option("missingkey=error").Execute(ioutil.Discard, sampleData) != nil {
+		errs.Add(Invalid("body", l, msgValidateEmailTemplateBody))
+	}
+	if subject, err := et.ParseSubject(); err != nil || (subject != nil && (*et.Subject == "" || subject.Option("missingkey=error").Execute(ioutil.Discard, sampleData) != nil)) {
+		errs.Add(Invalid("subject", l, msgValidateEmailTemplateSubj))
+	}
+	if et.CreatedAt.IsZero() {
+		errs.Add(Invalid("created_at", l, msgValidateEmailTemplateCrAt))
+	}
+	if et.UpdatedAt.IsZero() {
+		errs.Add(Invalid("updated_at", l, msgValidateEmailTemplateUpAt))
+	}
+
+	if !errs.IsZero() {
+		return NewValidationError("EmailTemplate", msgInvalidEmailTemplate, "", errs)
+	}
+	return nil
+}
+
+// IsValidEmailTemplateName checks if the email template can be overridden
+func IsValidEmailTemplateName(name string) bool {
+	for _, n := range EmailTemplateNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
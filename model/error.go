@@ -11,15 +11,21 @@ import (
 
 // Application error codes
 const (
-	ErrConflict        = "Conflict"        // action cannot be performed
-	ErrInternal        = "Internal"        // internal error
-	ErrBadRequest      = "Bad Request"     // bad request
-	ErrInvalid         = "Invalid"         // validation failed
-	ErrNotFound        = "Not Found"       // entity does not exist
-	ErrUnauthorized    = "Unauthorized"    // permission denied
-	ErrUnauthenticated = "Unauthenticated" // invalid token provided
+	ErrConflict        = "Conflict"          // action cannot be performed
+	ErrInternal        = "Internal"          // internal error
+	ErrBadRequest      = "Bad Request"       // bad request
+	ErrInvalid         = "Invalid"           // validation failed
+	ErrNotFound        = "Not Found"         // entity does not exist
+	ErrUnauthorized    = "Unauthorized"      // permission denied
+	ErrUnauthenticated = "Unauthenticated"   // invalid token provided
+	ErrTooManyRequests = "Too Many Requests" // rate limit exceeded
 )
 
+// RetryAfter is the detail of the throttled request error, it tells the client when to try again
+type RetryAfter struct {
+	Seconds int `json:"retry_after"`
+}
+
 // AppErr is the main app error
 type AppErr struct {
 	ID         string      `json:"id"`            // unique string which is the same as translation id
@@ -29,6 +35,9 @@ type AppErr struct {
 	Message    string      `json:"message"`       // meaningful end user message
 	Err        error       `json:"err,omitempty"` // embeded error
 	Details    interface{} `json:"details,omitempty"`
+
+	msg       *i18n.Message
+	fieldErrs ValidationErrors
 }
 
 // NewAppErr creates the new app error
@@ -39,11 +48,27 @@ func NewAppErr(op string, code string, l *i18n.Localizer, msg *i18n.Message, sta
 		Code:       code,
 		StatusCode: statusCode,
 		Details:    details,
+		msg:        msg,
 	}
 	e.Message = locale.LocalizeDefaultMessage(l, msg)
 	return e
 }
 
+// Localize translates the error and its validation messages with the localizer,
+// errors are created deep in the app without the request so they're translated again before they're sent
+func (e *AppErr) Localize(l *i18n.Localizer) *AppErr {
+	if l == nil {
+		return e
+	}
+	if e.msg != nil {
+		e.Message = locale.LocalizeDefaultMessage(l, e.msg)
+	}
+	for _, fe := range e.fieldErrs {
+		fe.Localize(l)
+	}
+	return e
+}
+
 func (e *AppErr) Error() string {
 	return fmt.Sprintf("%v, %v: %v\n", e.Code, e.Op, e.Message)
 }
@@ -58,6 +83,15 @@ func (e *AppErr) ToJSON() string {
 type FieldError struct {
 	Field   string `json:"field"`
 	Message string `json:"message"`
+
+	msg *i18n.Message
+}
+
+// Localize translates the field error message with the localizer
+func (fe *FieldError) Localize(l *i18n.Localizer) {
+	if fe.msg != nil {
+		fe.Message = locale.LocalizeDefaultMessage(l, fe.msg)
+	}
 }
 
 // ValidationErrors is a list of validation errors
@@ -75,7 +109,7 @@ func (list ValidationErrors) IsZero() bool {
 
 // Invalid creates the input validation error
 func Invalid(field string, l *i18n.Localizer, msg *i18n.Message) *FieldError {
-	e := &FieldError{Field: field}
+	e := &FieldError{Field: field, msg: msg}
 	e.Message = locale.LocalizeDefaultMessage(l, msg)
 	return e
 }
@@ -91,5 +125,7 @@ func NewValidationError(name string, msg *i18n.Message, userID string, errs Vali
 	}
 
 	errorName := name + ".Validate"
-	return NewAppErr(errorName, ErrInvalid, locale.GetUserLocalizer("en"), msg, http.StatusUnprocessableEntity, details)
+	e := NewAppErr(errorName, ErrInvalid, locale.GetUserLocalizer("en"), msg, http.StatusUnprocessableEntity, details)
+	e.fieldErrs = errs
+	return e
 }
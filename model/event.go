@@ -0,0 +1,70 @@
+package model
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx/types"
+)
+
+// domain event types
+const (
+	EventUserSignedUp        = "user.signed_up"
+	EventPasswordChanged     = "user.password_changed"
+	EventOrderPlaced         = "order.placed"
+	EventOrderPaid           = "order.paid"
+	EventOrderShipped        = "order.shipped"
+	EventOrderDelivered      = "order.delivered"
+	EventOrderCancelled      = "order.cancelled"
+	EventOrderRefunded       = "order.refunded"
+	EventProductCreated      = "product.created"
+	EventProductUpdated      = "product.updated"
+	EventProductDeleted      = "product.deleted"
+	EventProductPriceChanged = "product.price_changed"
+)
+
+// event aggregate types
+const (
+	AggregateUser    = "user"
+	AggregateOrder   = "order"
+	AggregateProduct = "product"
+)
+
+// Event is the domain event written to the outbox together with the state change
+type Event struct {
+	ID            int64          `json:"id" db:"id"`
+	Type          string         `json:"type" db:"type"`
+	AggregateType string         `json:"aggregate_type" db:"aggregate_type"`
+	AggregateID   int64          `json:"aggregate_id" db:"aggregate_id"`
+	Payload       types.JSONText `json:"payload" db:"payload"`
+	CreatedAt     time.Time      `json:"created_at" db:"created_at"`
+	DispatchedAt  *time.Time     `json:"dispatched_at" db:"dispatched_at"`
+	Data          interface{}    `json:"-" db:"-"`
+}
+
+// NewEvent creates the event, the data is encoded as the payload when the event is saved,
+// a zero aggregateID is filled with the id of the newly inserted row
+func NewEvent(eventType, aggregateType string, aggregateID int64, data interface{}) *Event {
+	return &Event{
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Data:          data,
+		CreatedAt:     time.Now(),
+	}
+}
+
+// UserEventData is the payload of the user events
+type UserEventData struct {
+	Email    string `json:"email"`
+	Username string `json:"username"`
+	Locale   string `json:"locale"`
+}
+
+// PriceChangedEventData is the payload of the product price change event
+type PriceChangedEventData struct {
+	ProductID  int64     `json:"product_id"`
+	OldPrice   int       `json:"old_price"`
+	NewPrice   int       `json:"new_price"`
+	SaleStarts time.Time `json:"sale_starts"`
+	SaleEnds   time.Time `json:"sale_ends"`
+}
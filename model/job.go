@@ -0,0 +1,77 @@
+package model
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx/types"
+)
+
+// job statuses
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusDead      = "dead"
+)
+
+// job types
+const (
+	JobTypeSendEmail      = "send_email"
+	JobTypeDeleteImage    = "delete_image"
+	JobTypeDeleteToken    = "delete_token"
+	JobTypeWishlistAlerts = "wishlist_alerts"
+	JobTypeCleanupJobs    = "cleanup_jobs"
+	JobTypeMaintenance    = "maintenance"
+	JobTypeHandleEvent    = "handle_event"
+	JobTypeCleanupEvents  = "cleanup_events"
+	JobTypeDeliverWebhook = "deliver_webhook"
+)
+
+// Job is the background job queued for the workers
+type Job struct {
+	TotalRecordsCount
+	ID          int64          `json:"id" db:"id"`
+	Type        string         `json:"type" db:"type"`
+	Payload     types.JSONText `json:"payload" db:"payload"`
+	Status      string         `json:"status" db:"status"`
+	Attempts    int            `json:"attempts" db:"attempts"`
+	MaxAttempts int            `json:"max_attempts" db:"max_attempts"`
+	LastError   *string        `json:"last_error" db:"last_error"`
+	UniqueKey   *string        `json:"unique_key" db:"unique_key"`
+	RunAt       time.Time      `json:"run_at" db:"run_at"`
+	LockedAt    *time.Time     `json:"locked_at" db:"locked_at"`
+	CompletedAt *time.Time     `json:"completed_at" db:"completed_at"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// JobStats is the jobs count per type and status
+type JobStats struct {
+	Type   string `json:"type" db:"type"`
+	Status string `json:"status" db:"status"`
+	Count  int    `json:"count" db:"count"`
+}
+
+// PreSave will set missing defaults and fill CreatedAt and UpdatedAt times
+func (j *Job) PreSave() {
+	if j.Status == "" {
+		j.Status = JobStatusPending
+	}
+	if len(j.Payload) == 0 {
+		j.Payload = types.JSONText("{}")
+	}
+	j.CreatedAt = time.Now()
+	j.UpdatedAt = j.CreatedAt
+	if j.RunAt.IsZero() {
+		j.RunAt = j.CreatedAt
+	}
+}
+
+// IsValidJobStatus checks if the job status is one of the known statuses
+func IsValidJobStatus(status string) bool {
+	switch status {
+	case JobStatusPending, JobStatusRunning, JobStatusCompleted, JobStatusDead:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// MaintenanceReport is the summary of a single maintenance run
+type MaintenanceReport struct {
+	DryRun         bool      `json:"dry_run"`
+	ExpiredTokens  int64     `json:"expired_tokens"`
+	ExpiredOrders  int64     `json:"expired_orders"`
+	OrphanedAssets []string  `json:"orphaned_assets"`
+	Errors         []string  `json:"errors,omitempty"`
+	StartedAt      time.Time `json:"started_at"`
+	FinishedAt     time.Time `json:"finished_at"`
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"time"
 
+	"github.com/dankobgd/ecommerce-shop/utils/is"
 	"github.com/dankobgd/ecommerce-shop/utils/locale"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 )
@@ -15,7 +16,13 @@ var msgValidateNoItems = &i18n.Message{ID: "model.order.validate.no_items.app_er
 var msgValidateBillingAddress = &i18n.Message{ID: "model.order.validate.billing_address.app_error", Other: "Invalid billing address"}
 var msgValidateBillingAddressID = &i18n.Message{ID: "model.order.validate.billing_address_id.app_error", Other: "Invalid billing address id"}
 var msgValidateShippingAddress = &i18n.Message{ID: "model.order.validate.shipping_address.app_error", Other: "Invalid shipping address"}
-var msgValidateShippingAddressNeedsBilling = &i18n.Message{ID: "model.order.validate.shipping_address.app_error", Other: "No billing address provided but same_shipping_as_billing is true"}
+var msgValidateShippingAddressNeedsBilling = &i18n.Message{ID: "model.order.validate.shipping_address_needs_billing.app_error", Other: "No billing address provided but same_shipping_as_billing is true"}
+var msgInvalidOrderShipment = &i18n.Message{ID: "model.order_shipment.validate.app_error", Other: "Invalid order shipment data"}
+var msgValidateTrackingNumber = &i18n.Message{ID: "model.order_shipment.validate.tracking_number.app_error", Other: "Tracking number must be between 1 and 100 characters"}
+var msgValidateTrackingURL = &i18n.Message{ID: "model.order_shipment.validate.tracking_url.app_error", Other: "Invalid tracking url"}
+
+// OrderTrackingNumberMaxLength is the max length of the shipment tracking number
+const OrderTrackingNumberMaxLength = 100
 
 type orderStatus int
 
@@ -24,6 +31,9 @@ const (
 	OrderStatusPending orderStatus = iota
 	OrderStatusSuccess
 	OrderStatusFailed
+	OrderStatusExpired
+	OrderStatusRefunded
+	OrderStatusCancelled
 )
 
 func (s orderStatus) String() string {
@@ -34,6 +44,12 @@ func (s orderStatus) String() string {
 		return "success"
 	case OrderStatusFailed:
 		return "fail"
+	case OrderStatusExpired:
+		return "expired"
+	case OrderStatusRefunded:
+		return "refunded"
+	case OrderStatusCancelled:
+		return "cancelled"
 	default:
 		return "unknown"
 	}
@@ -51,6 +67,9 @@ type Order struct {
 	Subtotal                 int        `json:"subtotal" db:"subtotal"`
 	Total                    int        `json:"total" db:"total"`
 	ShippedAt                *time.Time `json:"shipped_at" db:"shipped_at"`
+	TrackingNumber           *string    `json:"tracking_number" db:"tracking_number"`
+	TrackingURL              *string    `json:"tracking_url" db:"tracking_url"`
+	DeliveredAt              *time.Time `json:"delivered_at" db:"delivered_at"`
 	CreatedAt                time.Time  `json:"created_at" db:"created_at"`
 	PaymentMethodID          string     `json:"payment_method_id" db:"payment_method_id"`
 	PaymentIntentID          string     `json:"payment_intent_id" db:"payment_intent_id"`
@@ -81,6 +100,37 @@ func (o *Order) PreSave() {
 	}
 }
 
+// OrderShipment is the tracking info of the shipped order, both fields are optional
+type OrderShipment struct {
+	TrackingNumber *string `json:"tracking_number"`
+	TrackingURL    *string `json:"tracking_url"`
+}
+
+// OrderShipmentFromJSON decodes the input and returns the OrderShipment
+func OrderShipmentFromJSON(data io.Reader) (*OrderShipment, error) {
+	var s *OrderShipment
+	err := json.NewDecoder(data).Decode(&s)
+	return s, err
+}
+
+// Validate validates the shipment and returns an error if it doesn't pass criteria
+func (s *OrderShipment) Validate() *AppErr {
+	var errs ValidationErrors
+	l := locale.GetUserLocalizer("en")
+
+	if s.TrackingNumber != nil && (*s.TrackingNumber == "" || len(*s.TrackingNumber) > OrderTrackingNumberMaxLength) {
+		errs.Add(Invalid("tracking_number", l, msgValidateTrackingNumber))
+	}
+	if s.TrackingURL != nil && !is.ValidHTTPURL(*s.TrackingURL) {
+		errs.Add(Invalid("tracking_url", l, msgValidateTrackingURL))
+	}
+
+	if !errs.IsZero() {
+		return NewValidationError("OrderShipment", msgInvalidOrderShipment, "", errs)
+	}
+	return nil
+}
+
 // CartItem is the cart item info
 type CartItem struct {
 	ProductID int64 `json:"product_id"`
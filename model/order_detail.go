@@ -9,6 +9,12 @@ type OrderDetail struct {
 	HistorySKU   string `json:"history_sku" db:"history_sku"`
 }
 
+// OrderPlacedEventData is the payload of the placed order event, the order with its items
+type OrderPlacedEventData struct {
+	*Order
+	Details []*OrderDetail `json:"details"`
+}
+
 // OrderInfo returns the order details info with the product data
 type OrderInfo struct {
 	OrderDetail
@@ -62,6 +62,10 @@ type Product struct {
 	Properties     *types.JSONText `json:"properties" db:"properties" schema:"-"`
 	PropertiesText *string         `json:"-" schema:"properties"`
 
+	RatingAverage   float64         `json:"rating_average" db:"rating_average" schema:"-"`
+	RatingCount     int             `json:"rating_count" db:"rating_count" schema:"-"`
+	RatingHistogram *types.JSONText `json:"rating_histogram" db:"rating_histogram" schema:"-"`
+
 	*ProductPricing `schema:"-"`
 	Brand           *Brand    `json:"brand" schema:"-"`
 	Category        *Category `json:"category" schema:"-"`
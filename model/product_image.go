@@ -12,9 +12,9 @@ import (
 )
 
 var (
-	msgInvalidProductImage      = &i18n.Message{ID: "model.product.validate.app_error", Other: "invalid product image data"}
+	msgInvalidProductImage      = &i18n.Message{ID: "model.product_image.validate.app_error", Other: "invalid product image data"}
 	msgValidateProductImage     = &i18n.Message{ID: "model.product_image.validate.url.app_error", Other: "invalid product image"}
-	msgValidateProductImageSize = &i18n.Message{ID: "model.product_image.validate.url.app_error", Other: "File size exceeded, max 3MB allowed"}
+	msgValidateProductImageSize = &i18n.Message{ID: "model.product_image.validate.size.app_error", Other: "File size exceeded, max 3MB allowed"}
 )
 
 // ProductImage is the product image
@@ -0,0 +1,143 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// QuestionBodyMaxLength is the max allowed length of the question and answer text
+const QuestionBodyMaxLength = 2000
+
+// error msgs
+var (
+	msgInvalidQuestion        = &i18n.Message{ID: "model.question.validate.app_error", Other: "invalid question data"}
+	msgValidateQuestionID     = &i18n.Message{ID: "model.question.validate.id.app_error", Other: "invalid question id"}
+	msgValidateQuestionBody   = &i18n.Message{ID: "model.question.validate.body.app_error", Other: "invalid question text"}
+	msgValidateQuestionStatus = &i18n.Message{ID: "model.question.validate.status.app_error", Other: "invalid question status"}
+	msgValidateQuestionCrAt   = &i18n.Message{ID: "model.question.validate.created_at.app_error", Other: "invalid question created_at timestamp"}
+	msgValidateQuestionUpAt   = &i18n.Message{ID: "model.question.validate.updated_at.app_error", Other: "invalid question updated_at timestamp"}
+
+	msgInvalidAnswer        = &i18n.Message{ID: "model.answer.validate.app_error", Other: "invalid answer data"}
+	msgValidateAnswerID     = &i18n.Message{ID: "model.answer.validate.id.app_error", Other: "invalid answer id"}
+	msgValidateAnswerBody   = &i18n.Message{ID: "model.answer.validate.body.app_error", Other: "invalid answer text"}
+	msgValidateAnswerStatus = &i18n.Message{ID: "model.answer.validate.status.app_error", Other: "invalid answer status"}
+	msgValidateAnswerCrAt   = &i18n.Message{ID: "model.answer.validate.created_at.app_error", Other: "invalid answer created_at timestamp"}
+	msgValidateAnswerUpAt   = &i18n.Message{ID: "model.answer.validate.updated_at.app_error", Other: "invalid answer updated_at timestamp"}
+)
+
+// ProductQuestion is the shoppers question about the product
+// questions and answers are moderated with the same statuses as reviews
+type ProductQuestion struct {
+	TotalRecordsCount
+	ID          int64     `json:"id" db:"id"`
+	ProductID   int64     `json:"product_id" db:"product_id"`
+	UserID      int64     `json:"user_id" db:"user_id"`
+	Body        string    `json:"body" db:"body"`
+	Status      string    `json:"status" db:"status"`
+	AnswerCount int       `json:"answer_count" db:"answer_count"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	User        *User     `json:"user"`
+
+	Answers []*ProductAnswer `json:"answers" db:"-"`
+}
+
+// ProductAnswer is the answer to the product question given by admin or verified buyer
+type ProductAnswer struct {
+	TotalRecordsCount
+	ID                 int64     `json:"id" db:"id"`
+	QuestionID         int64     `json:"question_id" db:"question_id"`
+	UserID             int64     `json:"user_id" db:"user_id"`
+	Body               string    `json:"body" db:"body"`
+	IsAdmin            bool      `json:"is_admin" db:"is_admin"`
+	IsVerifiedPurchase bool      `json:"is_verified_purchase" db:"is_verified_purchase"`
+	Status             string    `json:"status" db:"status"`
+	UpvoteCount        int       `json:"upvote_count" db:"upvote_count"`
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+	User               *User     `json:"user"`
+}
+
+// QuestionFromJSON decodes the input and returns the ProductQuestion
+func QuestionFromJSON(data io.Reader) (*ProductQuestion, error) {
+	var q *ProductQuestion
+	err := json.NewDecoder(data).Decode(&q)
+	return q, err
+}
+
+// AnswerFromJSON decodes the input and returns the ProductAnswer
+func AnswerFromJSON(data io.Reader) (*ProductAnswer, error) {
+	var ans *ProductAnswer
+	err := json.NewDecoder(data).Decode(&ans)
+	return ans, err
+}
+
+// PreSave will fill timestamps
+func (q *ProductQuestion) PreSave() {
+	q.CreatedAt = time.Now()
+	q.UpdatedAt = q.CreatedAt
+}
+
+// PreSave will fill timestamps
+func (ans *ProductAnswer) PreSave() {
+	ans.CreatedAt = time.Now()
+	ans.UpdatedAt = ans.CreatedAt
+}
+
+// Validate validates the question and returns an error if it doesn't pass criteria
+func (q *ProductQuestion) Validate() *AppErr {
+	var errs ValidationErrors
+	l := locale.GetUserLocalizer("en")
+
+	if q.ID != 0 {
+		errs.Add(Invalid("id", l, msgValidateQuestionID))
+	}
+	if q.Body == "" || len(q.Body) > QuestionBodyMaxLength {
+		errs.Add(Invalid("body", l, msgValidateQuestionBody))
+	}
+	if !IsValidReviewStatus(q.Status) {
+		errs.Add(Invalid("status", l, msgValidateQuestionStatus))
+	}
+	if q.CreatedAt.IsZero() {
+		errs.Add(Invalid("created_at", l, msgValidateQuestionCrAt))
+	}
+	if q.UpdatedAt.IsZero() {
+		errs.Add(Invalid("updated_at", l, msgValidateQuestionUpAt))
+	}
+
+	if !errs.IsZero() {
+		return NewValidationError("Question", msgInvalidQuestion, "", errs)
+	}
+	return nil
+}
+
+// Validate validates the answer and returns an error if it doesn't pass criteria
+func (ans *ProductAnswer) Validate() *AppErr {
+	var errs ValidationErrors
+	l := locale.GetUserLocalizer("en")
+
+	if ans.ID != 0 {
+		errs.Add(Invalid("id", l, msgValidateAnswerID))
+	}
+	if ans.Body == "" || len(ans.Body) > QuestionBodyMaxLength {
+		errs.Add(Invalid("body", l, msgValidateAnswerBody))
+	}
+	if !IsValidReviewStatus(ans.Status) {
+		errs.Add(Invalid("status", l, msgValidateAnswerStatus))
+	}
+	if ans.CreatedAt.IsZero() {
+		errs.Add(Invalid("created_at", l, msgValidateAnswerCrAt))
+	}
+	if ans.UpdatedAt.IsZero() {
+		errs.Add(Invalid("updated_at", l, msgValidateAnswerUpAt))
+	}
+
+	if !errs.IsZero() {
+		return NewValidationError("Answer", msgInvalidAnswer, "", errs)
+	}
+	return nil
+}
@@ -21,19 +21,47 @@ var (
 	msgValidateReviewComment   = &i18n.Message{ID: "model.review.validate.comment.app_error", Other: "invalid review comment"}
 	msgValidateReviewCrAt      = &i18n.Message{ID: "model.review.validate.created_at.app_error", Other: "invalid review created_at timestamp"}
 	msgValidateReviewUpAt      = &i18n.Message{ID: "model.review.validate.updated_at.app_error", Other: "invalid review updated_at timestamp"}
+	msgValidateReviewStatus    = &i18n.Message{ID: "model.review.validate.status.app_error", Other: "invalid review status"}
+)
+
+// review moderation statuses
+const (
+	ReviewStatusPending  = "pending"
+	ReviewStatusApproved = "approved"
+	ReviewStatusRejected = "rejected"
+)
+
+// review list sort options
+const (
+	ReviewSortNewest     = "newest"
+	ReviewSortHelpful    = "helpful"
+	ReviewSortRatingHigh = "rating_high"
+	ReviewSortRatingLow  = "rating_low"
 )
 
 // ProductReview is the review model
 type ProductReview struct {
-	ID        int64     `json:"id" db:"id"`
-	UserID    int64     `json:"user_id" db:"user_id"`
-	ProductID int64     `json:"product_id" db:"product_id"`
-	Rating    int       `json:"rating" db:"rating"`
-	Title     string    `json:"title" db:"title"`
-	Comment   string    `json:"comment" db:"comment"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
-	User      *User     `json:"user"`
+	TotalRecordsCount
+	ID                 int64     `json:"id" db:"id"`
+	UserID             int64     `json:"user_id" db:"user_id"`
+	ProductID          int64     `json:"product_id" db:"product_id"`
+	Rating             int       `json:"rating" db:"rating"`
+	Title              string    `json:"title" db:"title"`
+	Comment            string    `json:"comment" db:"comment"`
+	IsVerifiedPurchase bool      `json:"is_verified_purchase" db:"is_verified_purchase"`
+	Status             string    `json:"status" db:"status"`
+	HelpfulCount       int       `json:"helpful_count" db:"helpful_count"`
+	ReportCount        int       `json:"report_count" db:"report_count"`
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+	User               *User     `json:"user"`
+
+	Media []*ProductReviewMedia `json:"media" db:"-"`
+}
+
+// ProductReviewStatus is the moderation status update payload
+type ProductReviewStatus struct {
+	Status string `json:"status"`
 }
 
 // ProductReviewPatch is the patch for review
@@ -63,6 +91,18 @@ func ReviewFromJSON(data io.Reader) (*ProductReview, error) {
 	return rev, err
 }
 
+// ReviewStatusFromJSON decodes the input and returns the ProductReviewStatus
+func ReviewStatusFromJSON(data io.Reader) (*ProductReviewStatus, error) {
+	var st *ProductReviewStatus
+	err := json.NewDecoder(data).Decode(&st)
+	return st, err
+}
+
+// IsValidReviewStatus checks if the status is one of the moderation statuses
+func IsValidReviewStatus(status string) bool {
+	return status == ReviewStatusPending || status == ReviewStatusApproved || status == ReviewStatusRejected
+}
+
 // ReviewPatchFromJSON decodes the input and returns the ReviewPatch
 func ReviewPatchFromJSON(data io.Reader) (*ProductReviewPatch, error) {
 	var p *ProductReviewPatch
@@ -98,6 +138,9 @@ func (rev *ProductReview) Validate() *AppErr {
 	if rev.Comment == "" {
 		errs.Add(Invalid("comment", l, msgValidateReviewComment))
 	}
+	if !IsValidReviewStatus(rev.Status) {
+		errs.Add(Invalid("status", l, msgValidateReviewStatus))
+	}
 	if rev.CreatedAt.IsZero() {
 		errs.Add(Invalid("created_at", l, msgValidateReviewCrAt))
 	}
@@ -116,7 +159,7 @@ func (patch *ProductReviewPatch) Validate() *AppErr {
 	var errs ValidationErrors
 	l := locale.GetUserLocalizer("en")
 
-	if patch.Rating != nil && *patch.Rating < 0 || *patch.Rating > 5 {
+	if patch.Rating != nil && (*patch.Rating < 0 || *patch.Rating > 5) {
 		errs.Add(Invalid("rating", l, msgValidateReviewRating))
 	}
 	if patch.Title != nil && *patch.Title == "" {
@@ -131,3 +174,18 @@ func (patch *ProductReviewPatch) Validate() *AppErr {
 	}
 	return nil
 }
+
+// Validate validates the review status update
+func (st *ProductReviewStatus) Validate() *AppErr {
+	var errs ValidationErrors
+	l := locale.GetUserLocalizer("en")
+
+	if !IsValidReviewStatus(st.Status) {
+		errs.Add(Invalid("status", l, msgValidateReviewStatus))
+	}
+
+	if !errs.IsZero() {
+		return NewValidationError("Review", msgInvalidReview, "", errs)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"io"
+	"mime/multipart"
+	"net/http"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/gocloudinary"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// ReviewMediaMaxCount is the max number of photos attached to a single review
+const ReviewMediaMaxCount = 5
+
+// ReviewMediaContentTypes are the allowed review photo mime types
+var ReviewMediaContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+	"image/gif":  true,
+}
+
+var (
+	msgInvalidReviewMedia      = &i18n.Message{ID: "model.review_media.validate.app_error", Other: "invalid review media data"}
+	msgValidateReviewMedia     = &i18n.Message{ID: "model.review_media.validate.file.app_error", Other: "invalid review photo"}
+	msgValidateReviewMediaSize = &i18n.Message{ID: "model.review_media.validate.size.app_error", Other: "File size exceeded, max 3MB allowed"}
+	msgValidateReviewMediaType = &i18n.Message{ID: "model.review_media.validate.type.app_error", Other: "Only jpeg, png, webp and gif photos are allowed"}
+	msgValidateReviewMediaMax  = &i18n.Message{ID: "model.review_media.validate.count.app_error", Other: "Too many photos, max 5 allowed per review"}
+)
+
+// ProductReviewMedia is the photo attached to the review
+type ProductReviewMedia struct {
+	ID        int64     `json:"id" db:"id"`
+	ReviewID  int64     `json:"review_id" db:"review_id"`
+	URL       string    `json:"url" db:"url"`
+	PublicID  string    `json:"public_id" db:"public_id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// PreSave will fill timestamps
+func (m *ProductReviewMedia) PreSave() {
+	m.CreatedAt = time.Now()
+}
+
+// SetImageDetails sets the media url and public_id
+func (m *ProductReviewMedia) SetImageDetails(details *gocloudinary.ResourceDetails) {
+	m.URL = details.SecureURL
+	m.PublicID = details.PublicID
+}
+
+// ValidateReviewMediaFiles validates the uploaded review photos, existing is the number of photos already attached
+func ValidateReviewMediaFiles(fhs []*multipart.FileHeader, existing int) *AppErr {
+	var errs ValidationErrors
+	l := locale.GetUserLocalizer("en")
+
+	if len(fhs) == 0 {
+		errs.Add(Invalid("media", l, msgValidateReviewMedia))
+	}
+	if existing+len(fhs) > ReviewMediaMaxCount {
+		errs.Add(Invalid("media", l, msgValidateReviewMediaMax))
+	}
+	for _, fh := range fhs {
+		if fh.Size > FileUploadSizeLimit {
+			errs.Add(Invalid("media", l, msgValidateReviewMediaSize))
+		}
+		if !ReviewMediaContentTypes[detectContentType(fh)] {
+			errs.Add(Invalid("media", l, msgValidateReviewMediaType))
+		}
+	}
+
+	if !errs.IsZero() {
+		return NewValidationError("ReviewMedia", msgInvalidReviewMedia, "", errs)
+	}
+	return nil
+}
+
+// detectContentType sniffs the file content, the content type sent by the client is not trusted
+func detectContentType(fh *multipart.FileHeader) string {
+	f, err := fh.Open()
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return ""
+	}
+	return http.DetectContentType(head[:n])
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// ReviewReportReasonMaxLength is the max allowed length of the abuse report reason
+const ReviewReportReasonMaxLength = 512
+
+// error msgs
+var (
+	msgInvalidReviewReport        = &i18n.Message{ID: "model.review_report.validate.app_error", Other: "invalid review report data"}
+	msgValidateReviewReportID     = &i18n.Message{ID: "model.review_report.validate.id.app_error", Other: "invalid review report id"}
+	msgValidateReviewReportReason = &i18n.Message{ID: "model.review_report.validate.reason.app_error", Other: "invalid review report reason"}
+	msgValidateReviewReportCrAt   = &i18n.Message{ID: "model.review_report.validate.created_at.app_error", Other: "invalid review report created_at timestamp"}
+)
+
+// ProductReviewReport is the abuse report for the review
+type ProductReviewReport struct {
+	ID        int64     `json:"id" db:"id"`
+	ReviewID  int64     `json:"review_id" db:"review_id"`
+	UserID    int64     `json:"user_id" db:"user_id"`
+	Reason    string    `json:"reason" db:"reason"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// ReviewReportFromJSON decodes the input and returns the ProductReviewReport
+func ReviewReportFromJSON(data io.Reader) (*ProductReviewReport, error) {
+	var rr *ProductReviewReport
+	err := json.NewDecoder(data).Decode(&rr)
+	return rr, err
+}
+
+// PreSave will fill timestamps
+func (rr *ProductReviewReport) PreSave() {
+	rr.CreatedAt = time.Now()
+}
+
+// Validate validates the review report and returns an error if it doesn't pass criteria
+func (rr *ProductReviewReport) Validate() *AppErr {
+	var errs ValidationErrors
+	l := locale.GetUserLocalizer("en")
+
+	if rr.ID != 0 {
+		errs.Add(Invalid("id", l, msgValidateReviewReportID))
+	}
+	if rr.Reason == "" || len(rr.Reason) > ReviewReportReasonMaxLength {
+		errs.Add(Invalid("reason", l, msgValidateReviewReportReason))
+	}
+	if rr.CreatedAt.IsZero() {
+		errs.Add(Invalid("created_at", l, msgValidateReviewReportCrAt))
+	}
+
+	if !errs.IsZero() {
+		return NewValidationError("ReviewReport", msgInvalidReviewReport, "", errs)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+)
+
+// rate limit response headers
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRetryAfter         = "Retry-After"
+)
+
+// RateLimit is the token bucket of the client, the bucket holds up to Burst tokens,
+// every request takes one token and Requests tokens are refilled every Period
+type RateLimit struct {
+	Name     string
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// Capacity returns how many requests can be made at once
+func (rl *RateLimit) Capacity() int {
+	if rl.Burst > 0 {
+		return rl.Burst
+	}
+	return rl.Requests
+}
+
+// Interval returns how long it takes to refill one token
+func (rl *RateLimit) Interval() time.Duration {
+	interval := rl.Period / time.Duration(rl.Requests)
+	if interval < time.Microsecond {
+		return time.Microsecond
+	}
+	return interval
+}
+
+// RateLimitResult is the state of the client's bucket after the request
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next token when the request was not allowed
+}
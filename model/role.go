@@ -0,0 +1,178 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/lib/pq"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// error msgs
+var (
+	msgInvalidRole           = &i18n.Message{ID: "model.role.validate.app_error", Other: "invalid role data"}
+	msgValidateRoleName      = &i18n.Message{ID: "model.role.validate.name.app_error", Other: "role name must be 2 to 50 lowercase letters, numbers or underscores and can't be user or admin"}
+	msgValidateRoleDesc      = &i18n.Message{ID: "model.role.validate.description.app_error", Other: "invalid role description"}
+	msgValidateRolePerms     = &i18n.Message{ID: "model.role.validate.permissions.app_error", Other: "invalid role permissions"}
+	msgValidateRoleCreatedAt = &i18n.Message{ID: "model.role.validate.created_at.app_error", Other: "invalid role created_at timestamp"}
+	msgValidateRoleUpdatedAt = &i18n.Message{ID: "model.role.validate.updated_at.app_error", Other: "invalid role updated_at timestamp"}
+)
+
+// permissions granted by the staff roles, the admins have all of them
+const (
+	PermissionProductWrite        = "product:write"
+	PermissionCategoryWrite       = "category:write"
+	PermissionBrandWrite          = "brand:write"
+	PermissionTagWrite            = "tag:write"
+	PermissionOrderRead           = "order:read"
+	PermissionOrderWrite          = "order:write"
+	PermissionOrderRefund         = "order:refund"
+	PermissionPromotionManage     = "promotion:manage"
+	PermissionReviewModerate      = "review:moderate"
+	PermissionQuestionModerate    = "question:moderate"
+	PermissionUserRead            = "user:read"
+	PermissionUserWrite           = "user:write"
+	PermissionJobManage           = "job:manage"
+	PermissionWebhookManage       = "webhook:manage"
+	PermissionEmailTemplateManage = "email_template:manage"
+)
+
+// Permissions are all the permissions that can be granted to the roles
+var Permissions = []string{
+	PermissionProductWrite,
+	PermissionCategoryWrite,
+	PermissionBrandWrite,
+	PermissionTagWrite,
+	PermissionOrderRead,
+	PermissionOrderWrite,
+	PermissionOrderRefund,
+	PermissionPromotionManage,
+	PermissionReviewModerate,
+	PermissionQuestionModerate,
+	PermissionUserRead,
+	PermissionUserWrite,
+	PermissionJobManage,
+	PermissionWebhookManage,
+	PermissionEmailTemplateManage,
+}
+
+// RoleDescriptionMaxLength is the max length of the role description
+const RoleDescriptionMaxLength = 500
+
+var roleNameRegex = regexp.MustCompile(`^[a-z0-9_]{2,50}$`)
+
+// Role is the staff role that grants the permissions to the users it's assigned to
+type Role struct {
+	TotalRecordsCount
+	ID          int64          `json:"id" db:"id"`
+	Name        string         `json:"name" db:"name"`
+	Description *string        `json:"description" db:"description"`
+	Permissions pq.StringArray `json:"permissions" db:"permissions"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// RolePatch is the role patch model
+type RolePatch struct {
+	Name        *string   `json:"name"`
+	Description *string   `json:"description"`
+	Permissions *[]string `json:"permissions"`
+}
+
+// RoleFromJSON decodes the input and returns the Role
+func RoleFromJSON(data io.Reader) (*Role, error) {
+	var r *Role
+	err := json.NewDecoder(data).Decode(&r)
+	return r, err
+}
+
+// RolePatchFromJSON decodes the input and returns the RolePatch
+func RolePatchFromJSON(data io.Reader) (*RolePatch, error) {
+	var patch *RolePatch
+	err := json.NewDecoder(data).Decode(&patch)
+	return patch, err
+}
+
+// PreSave will fill timestamps and other defaults
+func (r *Role) PreSave() {
+	if r.Permissions == nil {
+		r.Permissions = pq.StringArray{}
+	}
+	r.CreatedAt = time.Now()
+	r.UpdatedAt = r.CreatedAt
+}
+
+// PreUpdate sets the update timestamp
+func (r *Role) PreUpdate() {
+	r.UpdatedAt = time.Now()
+}
+
+// Patch patches the role fields that are provided
+func (r *Role) Patch(patch *RolePatch) {
+	if patch.Name != nil {
+		r.Name = *patch.Name
+	}
+	if patch.Description != nil {
+		r.Description = patch.Description
+	}
+	if patch.Permissions != nil {
+		r.Permissions = *patch.Permissions
+	}
+}
+
+// Validate validates the role and returns an error if it doesn't pass criteria
+func (r *Role) Validate() *AppErr {
+	var errs ValidationErrors
+	l := locale.GetUserLocalizer("en")
+
+	if !roleNameRegex.MatchString(r.Name) || r.Name == UserRole || r.Name == AdminRole {
+		errs.Add(Invalid("name", l, msgValidateRoleName))
+	}
+	if r.Description != nil && utf8.RuneCountInString(*r.Description) > RoleDescriptionMaxLength {
+		errs.Add(Invalid("description", l, msgValidateRoleDesc))
+	}
+	for _, p := range r.Permissions {
+		if !IsValidPermission(p) {
+			errs.Add(Invalid("permissions", l, msgValidateRolePerms))
+			break
+		}
+	}
+	if r.CreatedAt.IsZero() {
+		errs.Add(Invalid("created_at", l, msgValidateRoleCreatedAt))
+	}
+	if r.UpdatedAt.IsZero() {
+		errs.Add(Invalid("updated_at", l, msgValidateRoleUpdatedAt))
+	}
+
+	if !errs.IsZero() {
+		return NewValidationError("Role", msgInvalidRole, "", errs)
+	}
+	return nil
+}
+
+// IsValidPermission checks if the permission exists
+func IsValidPermission(permission string) bool {
+	for _, p := range Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermission checks if the user is granted the permission, the admins are granted all permissions
+func (ad *AccessData) HasPermission(permission string) bool {
+	if ad.Role == AdminRole {
+		return true
+	}
+	for _, p := range ad.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
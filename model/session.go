@@ -6,12 +6,19 @@ import (
 	"time"
 )
 
-// Session represents the user session data
+// HeaderDeviceID is the optional header the clients use to identify the device
+const HeaderDeviceID = "X-Device-ID"
+
+// Session represents the user session data, one session is created per login and lives across token refreshes
 type Session struct {
 	ID             string    `json:"id"`
-	Token          string    `json:"token"`
-	UserID         string    `json:"user_id"`
+	UserID         int64     `json:"user_id"`
 	DeviceID       string    `json:"device_id"`
+	UserAgent      string    `json:"user_agent"`
+	IP             string    `json:"ip"`
+	AccessUUID     string    `json:"-"`
+	RefreshUUID    string    `json:"-"`
+	Current        bool      `json:"current"`
 	CreatedAt      time.Time `json:"created_at"`
 	ExpiresAt      time.Time `json:"expires_at"`
 	LastActivityAt time.Time `json:"last_activity_at"`
@@ -29,3 +36,9 @@ func SessionFromJSON(data io.Reader) (*Session, error) {
 	err := json.NewDecoder(data).Decode(&me)
 	return me, err
 }
+
+// PreSave will fill timestamps
+func (me *Session) PreSave() {
+	me.CreatedAt = time.Now()
+	me.LastActivityAt = me.CreatedAt
+}
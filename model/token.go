@@ -14,6 +14,7 @@ type TokenType int
 const (
 	TokenTypePasswordRecovery TokenType = iota
 	TokenTypeEmailVerification
+	TokenTypeTwoFactorChallenge
 )
 
 func (tt TokenType) String() string {
@@ -22,6 +23,8 @@ func (tt TokenType) String() string {
 		return "password_recovery"
 	case TokenTypeEmailVerification:
 		return "email_verification"
+	case TokenTypeTwoFactorChallenge:
+		return "two_factor_challenge"
 	default:
 		return "unknown"
 	}
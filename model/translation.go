@@ -0,0 +1,124 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// error msgs
+var (
+	msgInvalidTranslation           = &i18n.Message{ID: "model.translation.validate.app_error", Other: "invalid translation data"}
+	msgValidateTranslationResource  = &i18n.Message{ID: "model.translation.validate.resource.app_error", Other: "invalid translation resource"}
+	msgValidateTranslationLocale    = &i18n.Message{ID: "model.translation.validate.locale.app_error", Other: "translation locale must be a supported locale other than the default one"}
+	msgValidateTranslationName      = &i18n.Message{ID: "model.translation.validate.name.app_error", Other: "invalid translation name"}
+	msgValidateTranslationSlug      = &i18n.Message{ID: "model.translation.validate.slug.app_error", Other: "invalid translation slug"}
+	msgValidateTranslationCreatedAt = &i18n.Message{ID: "model.translation.validate.created_at.app_error", Other: "invalid translation created_at timestamp"}
+	msgValidateTranslationUpdatedAt = &i18n.Message{ID: "model.translation.validate.updated_at.app_error", Other: "invalid translation updated_at timestamp"}
+)
+
+// translatable catalog resources
+const (
+	TranslationResourceProduct  = "product"
+	TranslationResourceCategory = "category"
+	TranslationResourceBrand    = "brand"
+)
+
+// TranslationNameMaxLength is the max length of the translated name and slug
+const TranslationNameMaxLength = 64
+
+// Translation is the localized text of the catalog resource,
+// the resource itself holds the text in the default locale which is used as the fallback
+type Translation struct {
+	Resource    string    `json:"resource" db:"-"`
+	ResourceID  int64     `json:"resource_id" db:"resource_id"`
+	Locale      string    `json:"locale" db:"locale"`
+	Name        string    `json:"name" db:"name"`
+	Slug        string    `json:"slug" db:"slug"`
+	Description string    `json:"description" db:"description"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// TranslationFromJSON decodes the input and returns the Translation
+func TranslationFromJSON(data io.Reader) (*Translation, error) {
+	var t *Translation
+	err := json.NewDecoder(data).Decode(&t)
+	return t, err
+}
+
+// PreSave will fill timestamps
+func (t *Translation) PreSave() {
+	t.CreatedAt = time.Now()
+	t.UpdatedAt = t.CreatedAt
+}
+
+// Validate validates the translation and returns an error if it doesn't pass criteria
+func (t *Translation) Validate() *AppErr {
+	var errs ValidationErrors
+	l := locale.GetUserLocalizer("en")
+
+	if !IsValidTranslationResource(t.Resource) || t.ResourceID == 0 {
+		errs.Add(Invalid("resource", l, msgValidateTranslationResource))
+	}
+	if t.Locale == locale.DefaultLocale || !locale.IsSupported(t.Locale) {
+		errs.Add(Invalid("locale", l, msgValidateTranslationLocale))
+	}
+	if t.Name == "" || utf8.RuneCountInString(t.Name) > TranslationNameMaxLength {
+		errs.Add(Invalid("name", l, msgValidateTranslationName))
+	}
+	if t.Slug == "" || utf8.RuneCountInString(t.Slug) > TranslationNameMaxLength {
+		errs.Add(Invalid("slug", l, msgValidateTranslationSlug))
+	}
+	if t.CreatedAt.IsZero() {
+		errs.Add(Invalid("created_at", l, msgValidateTranslationCreatedAt))
+	}
+	if t.UpdatedAt.IsZero() {
+		errs.Add(Invalid("updated_at", l, msgValidateTranslationUpdatedAt))
+	}
+
+	if !errs.IsZero() {
+		return NewValidationError("Translation", msgInvalidTranslation, "", errs)
+	}
+	return nil
+}
+
+// IsValidTranslationResource checks if the resource can be translated
+func IsValidTranslationResource(resource string) bool {
+	switch resource {
+	case TranslationResourceProduct, TranslationResourceCategory, TranslationResourceBrand:
+		return true
+	}
+	return false
+}
+
+// Translate replaces the product text with the translation, the description falls back to the default one if it's not translated
+func (p *Product) Translate(t *Translation) {
+	p.Name = t.Name
+	p.Slug = t.Slug
+	if t.Description != "" {
+		p.Description = t.Description
+	}
+}
+
+// Translate replaces the category text with the translation, the description falls back to the default one if it's not translated
+func (c *Category) Translate(t *Translation) {
+	c.Name = t.Name
+	c.Slug = t.Slug
+	if t.Description != "" {
+		c.Description = t.Description
+	}
+}
+
+// Translate replaces the brand text with the translation, the description falls back to the default one if it's not translated
+func (b *Brand) Translate(t *Translation) {
+	b.Name = t.Name
+	b.Slug = t.Slug
+	if t.Description != "" {
+		b.Description = t.Description
+	}
+}
@@ -0,0 +1,107 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+)
+
+// login steps returned when the password alone is not enough
+const (
+	LoginStepTOTP = "totp"
+)
+
+// recovery codes
+const (
+	RecoveryCodeCount  = 10
+	recoveryCodeLength = 10
+	recoveryCodeChars  = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+// TwoFactorChallengeExpiry is how long the user has to enter the code after the password was accepted
+const TwoFactorChallengeExpiry = time.Minute * 5
+
+// TwoFactorChallengeCookieName is the cookie that carries the challenge of the social login to the code form,
+// so the challenge is not put in the redirect url where it ends up in the browser history and the logs
+const TwoFactorChallengeCookieName = "two_factor_challenge"
+
+// TwoFactor is the user's TOTP enrollment
+type TwoFactor struct {
+	UserID       int64      `json:"user_id" db:"user_id"`
+	Secret       string     `json:"-" db:"secret"`
+	Enabled      bool       `json:"enabled" db:"enabled"`
+	LastUsedStep int64      `json:"-" db:"last_used_step"`
+	EnabledAt    *time.Time `json:"enabled_at" db:"enabled_at"`
+	CodesLeft    int        `json:"recovery_codes_left" db:"-"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// TwoFactorEnrollment is the secret and the provisioning uri shown to the user as the QR code
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// RecoveryCodes are the one time codes shown to the user only once
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
+
+// LoginChallenge is returned by the login when the second factor is required
+type LoginChallenge struct {
+	Step           string    `json:"step"`
+	ChallengeToken string    `json:"challenge_token"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// TwoFactorCode is the TOTP or recovery code sent by the user
+type TwoFactorCode struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+// TwoFactorCodeFromJSON decodes the input and returns the TwoFactorCode
+func TwoFactorCodeFromJSON(data io.Reader) (*TwoFactorCode, error) {
+	var c *TwoFactorCode
+	err := json.NewDecoder(data).Decode(&c)
+	return c, err
+}
+
+// PreSave will fill timestamps
+func (tf *TwoFactor) PreSave() {
+	tf.CreatedAt = time.Now()
+	tf.UpdatedAt = tf.CreatedAt
+}
+
+// NewRecoveryCodes generates the new set of recovery codes
+func NewRecoveryCodes() ([]string, error) {
+	codes := make([]string, 0, RecoveryCodeCount)
+	for i := 0; i < RecoveryCodeCount; i++ {
+		b := make([]byte, recoveryCodeLength)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		for j := range b {
+			b[j] = recoveryCodeChars[int(b[j])%len(recoveryCodeChars)]
+		}
+		codes = append(codes, string(b[:5])+"-"+string(b[5:]))
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode hashes the recovery code, the codes are random so the fast hash is enough
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsRecoveryCode checks if the code looks like the recovery code rather than the TOTP code
+func IsRecoveryCode(code string) bool {
+	return len(strings.ReplaceAll(strings.TrimSpace(code), "-", "")) == recoveryCodeLength
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+)
+
+// OAuthStateCookieName is the cookie that binds the started oauth login to the browser
+const OAuthStateCookieName = "oauth_state"
+
+// UserIdentity is the external provider account linked to the user
+type UserIdentity struct {
+	ID          int64     `json:"id" db:"id"`
+	UserID      int64     `json:"user_id" db:"user_id"`
+	Provider    string    `json:"provider" db:"provider"`
+	Subject     string    `json:"-" db:"subject"`
+	Email       *string   `json:"email" db:"email"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	LastLoginAt time.Time `json:"last_login_at" db:"last_login_at"`
+}
+
+// OAuthState is kept between the redirect to the provider and the callback,
+// the user id is set when the signed in user links the new provider
+type OAuthState struct {
+	Provider string `json:"provider"`
+	Verifier string `json:"verifier"`
+	Nonce    string `json:"nonce"`
+	UserID   int64  `json:"user_id,omitempty"`
+}
+
+// OAuthLogin is the result of the callback, the challenge is set instead of the user when the second factor is required
+type OAuthLogin struct {
+	User      *User
+	Challenge *LoginChallenge
+	Linked    bool
+}
+
+// PreSave will fill timestamps
+func (ui *UserIdentity) PreSave() {
+	ui.CreatedAt = time.Now()
+	ui.LastLoginAt = ui.CreatedAt
+}
@@ -0,0 +1,212 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dankobgd/ecommerce-shop/utils/is"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/jmoiron/sqlx/types"
+	"github.com/lib/pq"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// error msgs
+var (
+	msgInvalidWebhook           = &i18n.Message{ID: "model.webhook.validate.app_error", Other: "invalid webhook data"}
+	msgValidateWebhookURL       = &i18n.Message{ID: "model.webhook.validate.url.app_error", Other: "invalid webhook url"}
+	msgValidateWebhookSecret    = &i18n.Message{ID: "model.webhook.validate.secret.app_error", Other: "webhook secret must be between 16 and 100 characters"}
+	msgValidateWebhookEvents    = &i18n.Message{ID: "model.webhook.validate.event_types.app_error", Other: "invalid webhook event types"}
+	msgValidateWebhookDescLen   = &i18n.Message{ID: "model.webhook.validate.description.app_error", Other: "invalid webhook description"}
+	msgValidateWebhookCreatedAt = &i18n.Message{ID: "model.webhook.validate.created_at.app_error", Other: "invalid webhook created_at timestamp"}
+	msgValidateWebhookUpdatedAt = &i18n.Message{ID: "model.webhook.validate.updated_at.app_error", Other: "invalid webhook updated_at timestamp"}
+)
+
+// webhook limits
+const (
+	WebhookSecretMinLength      = 16
+	WebhookSecretMaxLength      = 100
+	WebhookDescriptionMaxLength = 500
+	WebhookResponseBodyMaxBytes = 1024
+)
+
+// webhook delivery statuses
+const (
+	WebhookDeliveryPending = "pending"
+	WebhookDeliverySuccess = "success"
+	WebhookDeliveryFailed  = "failed"
+)
+
+// WebhookEventTypes are the events that can be sent to the webhooks
+var WebhookEventTypes = []string{
+	EventOrderPlaced,
+	EventOrderPaid,
+	EventOrderShipped,
+	EventOrderDelivered,
+	EventOrderCancelled,
+	EventOrderRefunded,
+	EventProductCreated,
+	EventProductUpdated,
+	EventProductDeleted,
+	EventProductPriceChanged,
+}
+
+// Webhook is the admin managed subscription of an external system to the shop events
+type Webhook struct {
+	TotalRecordsCount
+	ID          int64          `json:"id" db:"id"`
+	URL         string         `json:"url" db:"url"`
+	Secret      string         `json:"-" db:"secret"`
+	EventTypes  pq.StringArray `json:"event_types" db:"event_types"`
+	Description *string        `json:"description" db:"description"`
+	Active      bool           `json:"active" db:"active"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// WebhookWithSecret is the created webhook, the secret is shown only once in the creation response
+type WebhookWithSecret struct {
+	*Webhook
+	Secret string `json:"secret"`
+}
+
+// WebhookPatch is the webhook patch model
+type WebhookPatch struct {
+	URL         *string   `json:"url"`
+	Secret      *string   `json:"secret"`
+	EventTypes  *[]string `json:"event_types"`
+	Description *string   `json:"description"`
+	Active      *bool     `json:"active"`
+}
+
+// WebhookDelivery is the log of the event sent to the webhook
+type WebhookDelivery struct {
+	TotalRecordsCount
+	ID            int64          `json:"id" db:"id"`
+	WebhookID     int64          `json:"webhook_id" db:"webhook_id"`
+	EventID       int64          `json:"event_id" db:"event_id"`
+	EventType     string         `json:"event_type" db:"event_type"`
+	Payload       types.JSONText `json:"payload" db:"payload"`
+	Status        string         `json:"status" db:"status"`
+	Attempts      int            `json:"attempts" db:"attempts"`
+	ResponseCode  *int           `json:"response_code" db:"response_code"`
+	ResponseBody  *string        `json:"response_body" db:"response_body"`
+	Error         *string        `json:"error" db:"error"`
+	DurationMS    *int           `json:"duration_ms" db:"duration_ms"`
+	ReplayOf      *int64         `json:"replay_of" db:"replay_of"`
+	LastAttemptAt *time.Time     `json:"last_attempt_at" db:"last_attempt_at"`
+	CreatedAt     time.Time      `json:"created_at" db:"created_at"`
+}
+
+// WebhookPayload is the body posted to the webhook url
+type WebhookPayload struct {
+	ID            int64          `json:"id"`
+	Type          string         `json:"type"`
+	AggregateType string         `json:"aggregate_type"`
+	AggregateID   int64          `json:"aggregate_id"`
+	CreatedAt     time.Time      `json:"created_at"`
+	Data          types.JSONText `json:"data"`
+}
+
+// WebhookFromJSON decodes the input and returns the Webhook
+func WebhookFromJSON(data io.Reader) (*Webhook, error) {
+	var wh *Webhook
+	err := json.NewDecoder(data).Decode(&wh)
+	return wh, err
+}
+
+// WebhookPatchFromJSON decodes the input and returns the WebhookPatch
+func WebhookPatchFromJSON(data io.Reader) (*WebhookPatch, error) {
+	var patch *WebhookPatch
+	err := json.NewDecoder(data).Decode(&patch)
+	return patch, err
+}
+
+// PreSave will fill timestamps and other defaults
+func (wh *Webhook) PreSave() {
+	wh.Active = true
+	wh.CreatedAt = time.Now()
+	wh.UpdatedAt = wh.CreatedAt
+}
+
+// PreUpdate sets the update timestamp
+func (wh *Webhook) PreUpdate() {
+	wh.UpdatedAt = time.Now()
+}
+
+// Patch patches the webhook fields that are provided
+func (wh *Webhook) Patch(patch *WebhookPatch) {
+	if patch.URL != nil {
+		wh.URL = *patch.URL
+	}
+	if patch.Secret != nil {
+		wh.Secret = *patch.Secret
+	}
+	if patch.EventTypes != nil {
+		wh.EventTypes = *patch.EventTypes
+	}
+	if patch.Description != nil {
+		wh.Description = patch.Description
+	}
+	if patch.Active != nil {
+		wh.Active = *patch.Active
+	}
+}
+
+// Subscribes checks if the webhook wants the event type
+func (wh *Webhook) Subscribes(eventType string) bool {
+	for _, t := range wh.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate validates the webhook and returns an error if it doesn't pass criteria
+func (wh *Webhook) Validate() *AppErr {
+	var errs ValidationErrors
+	l := locale.GetUserLocalizer("en")
+
+	if !is.ValidHTTPURL(wh.URL) {
+		errs.Add(Invalid("url", l, msgValidateWebhookURL))
+	}
+	if len(wh.Secret) < WebhookSecretMinLength || len(wh.Secret) > WebhookSecretMaxLength {
+		errs.Add(Invalid("secret", l, msgValidateWebhookSecret))
+	}
+	if len(wh.EventTypes) == 0 || !validWebhookEventTypes(wh.EventTypes) {
+		errs.Add(Invalid("event_types", l, msgValidateWebhookEvents))
+	}
+	if wh.Description != nil && utf8.RuneCountInString(*wh.Description) > WebhookDescriptionMaxLength {
+		errs.Add(Invalid("description", l, msgValidateWebhookDescLen))
+	}
+	if wh.CreatedAt.IsZero() {
+		errs.Add(Invalid("created_at", l, msgValidateWebhookCreatedAt))
+	}
+	if wh.UpdatedAt.IsZero() {
+		errs.Add(Invalid("updated_at", l, msgValidateWebhookUpdatedAt))
+	}
+
+	if !errs.IsZero() {
+		return NewValidationError("Webhook", msgInvalidWebhook, "", errs)
+	}
+	return nil
+}
+
+func validWebhookEventTypes(eventTypes []string) bool {
+	for _, t := range eventTypes {
+		found := false
+		for _, known := range WebhookEventTypes {
+			if t == known {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -3,6 +3,8 @@ package model
 import (
 	"encoding/json"
 	"io"
+	"time"
+	"unicode/utf8"
 
 	"github.com/dankobgd/ecommerce-shop/utils/locale"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
@@ -12,33 +14,123 @@ import (
 var (
 	msgInvalidWishlist           = &i18n.Message{ID: "model.wishlist.validate.app_error", Other: "invalid wishlist data"}
 	msgValidateWishlistUserID    = &i18n.Message{ID: "model.wishlist.validate.user_id.app_error", Other: "invalid wishlist user id"}
+	msgValidateWishlistName      = &i18n.Message{ID: "model.wishlist.validate.name.app_error", Other: "invalid wishlist name"}
+	msgInvalidWishlistItem       = &i18n.Message{ID: "model.wishlist_item.validate.app_error", Other: "invalid wishlist item data"}
 	msgValidateWishlistProductID = &i18n.Message{ID: "model.wishlist.validate.product_id.app_error", Other: "invalid wishlist product id"}
+	msgValidateWishlistQuantity  = &i18n.Message{ID: "model.wishlist_item.validate.quantity.app_error", Other: "invalid wishlist item quantity"}
+	msgValidateWishlistNote      = &i18n.Message{ID: "model.wishlist_item.validate.note.app_error", Other: "invalid wishlist item note"}
 )
 
-// Wishlist is the product wishlist
+// wishlist limits
+const (
+	WishlistNameMaxLength    = 100
+	WishlistNoteMaxLength    = 500
+	WishlistItemMaxQuantity  = 99
+	WishlistShareTokenLength = 24
+	WishlistDefaultName      = "Wishlist"
+)
+
+// Wishlist is the named list of the user's saved products
 type Wishlist struct {
-	ID        int64 `json:"id" db:"id"`
-	UserID    int64 `json:"user_id" db:"user_id"`
-	ProductID int64 `json:"product_id" db:"product_id"`
+	ID         int64           `json:"id" db:"id"`
+	UserID     int64           `json:"user_id" db:"user_id"`
+	Name       string          `json:"name" db:"name"`
+	IsDefault  bool            `json:"is_default" db:"is_default"`
+	ShareToken *string         `json:"share_token" db:"share_token"`
+	ItemsCount int             `json:"items_count" db:"items_count"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at" db:"updated_at"`
+	Items      []*WishlistItem `json:"items,omitempty" db:"-"`
+}
+
+// WishlistItem is the product saved in the wishlist
+type WishlistItem struct {
+	ID                int64     `json:"id" db:"id"`
+	WishlistID        int64     `json:"wishlist_id" db:"wishlist_id"`
+	UserID            int64     `json:"-" db:"user_id"`
+	ProductID         int64     `json:"product_id" db:"product_id"`
+	Quantity          int       `json:"quantity" db:"quantity"`
+	Note              *string   `json:"note" db:"note"`
+	NotifyBackInStock bool      `json:"notify_back_in_stock" db:"notify_back_in_stock"`
+	NotifyPriceDrop   bool      `json:"notify_price_drop" db:"notify_price_drop"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+	Product           *Product  `json:"product,omitempty" db:"-"`
+}
+
+// SharedWishlist is the public view of the shared wishlist
+type SharedWishlist struct {
+	Name      string          `json:"name"`
+	Items     []*WishlistItem `json:"items"`
+	CartItems []*CartItem     `json:"cart_items"`
+}
+
+// WishlistPatch is the wishlist patch model
+type WishlistPatch struct {
+	Name *string `json:"name"`
+}
+
+// WishlistItemPatch is the wishlist item patch model
+type WishlistItemPatch struct {
+	Quantity *int    `json:"quantity"`
+	Note     *string `json:"note"`
 }
 
 // WishlistFromJSON decodes the input and returns the Wishlist
 func WishlistFromJSON(data io.Reader) (*Wishlist, error) {
-	var p *Wishlist
-	err := json.NewDecoder(data).Decode(&p)
-	return p, err
+	var w *Wishlist
+	err := json.NewDecoder(data).Decode(&w)
+	return w, err
+}
+
+// WishlistPatchFromJSON decodes the input and returns the WishlistPatch
+func WishlistPatchFromJSON(data io.Reader) (*WishlistPatch, error) {
+	var patch *WishlistPatch
+	err := json.NewDecoder(data).Decode(&patch)
+	return patch, err
+}
+
+// WishlistItemFromJSON decodes the input and returns the WishlistItem
+func WishlistItemFromJSON(data io.Reader) (*WishlistItem, error) {
+	var item *WishlistItem
+	err := json.NewDecoder(data).Decode(&item)
+	return item, err
+}
+
+// WishlistItemPatchFromJSON decodes the input and returns the WishlistItemPatch
+func WishlistItemPatchFromJSON(data io.Reader) (*WishlistItemPatch, error) {
+	var patch *WishlistItemPatch
+	err := json.NewDecoder(data).Decode(&patch)
+	return patch, err
+}
+
+// PreSave will set missing defaults and fill CreatedAt and UpdatedAt times
+func (w *Wishlist) PreSave() {
+	w.CreatedAt = time.Now()
+	w.UpdatedAt = w.CreatedAt
+}
+
+// PreUpdate sets the update timestamp
+func (w *Wishlist) PreUpdate() {
+	w.UpdatedAt = time.Now()
+}
+
+// Patch patches the wishlist fields that are provided
+func (w *Wishlist) Patch(patch *WishlistPatch) {
+	if patch.Name != nil {
+		w.Name = *patch.Name
+	}
 }
 
 // Validate validates the wishlist and returns an error if it doesn't pass criteria
-func (pw *Wishlist) Validate() *AppErr {
+func (w *Wishlist) Validate() *AppErr {
 	var errs ValidationErrors
 	l := locale.GetUserLocalizer("en")
 
-	if pw.UserID == 0 {
+	if w.UserID == 0 {
 		errs.Add(Invalid("user_id", l, msgValidateWishlistUserID))
 	}
-	if pw.ProductID == 0 {
-		errs.Add(Invalid("product_id", l, msgValidateWishlistProductID))
+	if w.Name == "" || utf8.RuneCountInString(w.Name) > WishlistNameMaxLength {
+		errs.Add(Invalid("name", l, msgValidateWishlistName))
 	}
 
 	if !errs.IsZero() {
@@ -46,3 +138,57 @@ func (pw *Wishlist) Validate() *AppErr {
 	}
 	return nil
 }
+
+// PreSave will set missing defaults and fill CreatedAt time
+func (wi *WishlistItem) PreSave() {
+	if wi.Quantity == 0 {
+		wi.Quantity = 1
+	}
+	wi.CreatedAt = time.Now()
+}
+
+// Patch patches the wishlist item fields that are provided
+func (wi *WishlistItem) Patch(patch *WishlistItemPatch) {
+	if patch.Quantity != nil {
+		wi.Quantity = *patch.Quantity
+	}
+	if patch.Note != nil {
+		wi.Note = patch.Note
+	}
+}
+
+// Validate validates the wishlist item and returns an error if it doesn't pass criteria
+func (wi *WishlistItem) Validate() *AppErr {
+	var errs ValidationErrors
+	l := locale.GetUserLocalizer("en")
+
+	if wi.ProductID == 0 {
+		errs.Add(Invalid("product_id", l, msgValidateWishlistProductID))
+	}
+	if wi.Quantity < 1 || wi.Quantity > WishlistItemMaxQuantity {
+		errs.Add(Invalid("quantity", l, msgValidateWishlistQuantity))
+	}
+	if wi.Note != nil && utf8.RuneCountInString(*wi.Note) > WishlistNoteMaxLength {
+		errs.Add(Invalid("note", l, msgValidateWishlistNote))
+	}
+
+	if !errs.IsZero() {
+		return NewValidationError("WishlistItem", msgInvalidWishlistItem, "", errs)
+	}
+	return nil
+}
+
+// ToShared returns the public view of the wishlist with the items ready to be put in the cart
+func (w *Wishlist) ToShared() *SharedWishlist {
+	cartItems := make([]*CartItem, 0, len(w.Items))
+	for _, x := range w.Items {
+		cartItems = append(cartItems, &CartItem{ProductID: x.ProductID, Quantity: x.Quantity})
+	}
+
+	items := w.Items
+	if items == nil {
+		items = make([]*WishlistItem, 0)
+	}
+
+	return &SharedWishlist{Name: w.Name, Items: items, CartItems: cartItems}
+}
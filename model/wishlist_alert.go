@@ -0,0 +1,39 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// wishlist alert kinds
+const (
+	WishlistAlertBackInStock = "back_in_stock"
+	WishlistAlertPriceDrop   = "price_drop"
+)
+
+// WishlistAlertSubscription toggles the alerts for the wishlisted product
+type WishlistAlertSubscription struct {
+	BackInStock bool `json:"back_in_stock"`
+	PriceDrop   bool `json:"price_drop"`
+}
+
+// WishlistAlertSubscriptionFromJSON decodes the input and returns the WishlistAlertSubscription
+func WishlistAlertSubscriptionFromJSON(data io.Reader) (*WishlistAlertSubscription, error) {
+	var s *WishlistAlertSubscription
+	err := json.NewDecoder(data).Decode(&s)
+	return s, err
+}
+
+// WishlistAlert is the pending alert for the wishlisted product
+type WishlistAlert struct {
+	UserID        int64  `json:"user_id" db:"user_id"`
+	ProductID     int64  `json:"product_id" db:"product_id"`
+	Email         string `json:"email" db:"email"`
+	Username      string `json:"username" db:"username"`
+	Locale        string `json:"locale" db:"locale"`
+	ProductName   string `json:"product_name" db:"product_name"`
+	Slug          string `json:"slug" db:"slug"`
+	Kind          string `json:"kind" db:"kind"`
+	Price         int    `json:"price" db:"price"`
+	LastSeenPrice *int   `json:"last_seen_price" db:"last_seen_price"`
+}
@@ -0,0 +1,100 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	githubAuthURL   = "https://github.com/login/oauth/authorize"
+	githubTokenURL  = "https://github.com/login/oauth/access_token"
+	githubUserURL   = "https://api.github.com/user"
+	githubEmailsURL = "https://api.github.com/user/emails"
+)
+
+type githubUser struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+	Name  string `json:"name"`
+}
+
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// github is not the OpenID provider, the user is fetched from the api with the access token
+type githubProvider struct {
+	clientID     string
+	clientSecret string
+	redirectURL  string
+}
+
+// NewGitHubProvider creates the GitHub OAuth provider
+func NewGitHubProvider(clientID, clientSecret, redirectURL string) Provider {
+	return &githubProvider{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURL:  redirectURL,
+	}
+}
+
+func (p *githubProvider) Name() string {
+	return ProviderGitHub
+}
+
+// AuthCodeURL ignores the nonce, github doesn't issue the id tokens
+func (p *githubProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	v := url.Values{}
+	v.Set("client_id", p.clientID)
+	v.Set("redirect_uri", p.redirectURL)
+	v.Set("scope", "read:user user:email")
+	v.Set("state", state)
+	v.Set("code_challenge", codeChallenge)
+	v.Set("code_challenge_method", "S256")
+	return githubAuthURL + "?" + v.Encode(), nil
+}
+
+func (p *githubProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Profile, error) {
+	tr, err := exchangeCode(ctx, githubTokenURL, p.clientID, p.clientSecret, p.redirectURL, code, codeVerifier, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var u githubUser
+	if err := getJSON(ctx, githubUserURL, tr.AccessToken, &u); err != nil {
+		return nil, err
+	}
+	if u.ID == 0 {
+		return nil, errors.New("oauth: github user has no id")
+	}
+
+	// the public profile email may be hidden or unverified so the primary one is taken from the emails list
+	var emails []githubEmail
+	if err := getJSON(ctx, githubEmailsURL, tr.AccessToken, &emails); err != nil {
+		return nil, err
+	}
+
+	profile := &Profile{
+		Subject:  strconv.FormatInt(u.ID, 10),
+		Username: u.Login,
+	}
+	for _, e := range emails {
+		if e.Primary {
+			profile.Email = e.Email
+			profile.EmailVerified = e.Verified
+			break
+		}
+	}
+
+	names := strings.SplitN(strings.TrimSpace(u.Name), " ", 2)
+	profile.FirstName = names[0]
+	if len(names) == 2 {
+		profile.LastName = strings.TrimSpace(names[1])
+	}
+	return profile, nil
+}
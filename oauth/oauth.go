@@ -0,0 +1,148 @@
+package oauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/config"
+	"github.com/dankobgd/ecommerce-shop/utils/random"
+)
+
+// provider names used in the urls and stored with the linked identities
+const (
+	ProviderGoogle = "google"
+	ProviderGitHub = "github"
+	ProviderOIDC   = "oidc"
+)
+
+const googleIssuer = "https://accounts.google.com"
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// Profile is the user info returned by the provider
+type Profile struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	FirstName     string
+	LastName      string
+	Username      string
+}
+
+// Provider is the external identity provider the users can log in with
+type Provider interface {
+	Name() string
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Profile, error)
+}
+
+// NewProviders creates the configured providers by their names
+func NewProviders(settings config.OAuthSettings) map[string]Provider {
+	providers := make(map[string]Provider)
+	if settings.GoogleClientID != "" {
+		providers[ProviderGoogle] = NewOIDCProvider(ProviderGoogle, googleIssuer, settings.GoogleClientID, settings.GoogleClientSecret, callbackURL(settings, ProviderGoogle))
+	}
+	if settings.GitHubClientID != "" {
+		providers[ProviderGitHub] = NewGitHubProvider(settings.GitHubClientID, settings.GitHubClientSecret, callbackURL(settings, ProviderGitHub))
+	}
+	if settings.OIDCClientID != "" && settings.OIDCIssuer != "" {
+		providers[ProviderOIDC] = NewOIDCProvider(ProviderOIDC, settings.OIDCIssuer, settings.OIDCClientID, settings.OIDCClientSecret, callbackURL(settings, ProviderOIDC))
+	}
+	return providers
+}
+
+func callbackURL(settings config.OAuthSettings, name string) string {
+	return strings.TrimRight(settings.CallbackURL, "/") + "/" + name + "/callback"
+}
+
+// NewCodeVerifier returns the new PKCE code verifier
+func NewCodeVerifier() string {
+	return random.SecureToken(32)
+}
+
+// CodeChallenge returns the S256 PKCE challenge of the verifier
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// exchangeCode exchanges the authorization code for the tokens, the client secret is sent
+// in the request body unless the provider only accepts the basic auth
+func exchangeCode(ctx context.Context, tokenURL, clientID, clientSecret, redirectURL, code, codeVerifier string, basicAuth bool) (*tokenResponse, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURL)
+	form.Set("code_verifier", codeVerifier)
+	if !basicAuth {
+		form.Set("client_id", clientID)
+		form.Set("client_secret", clientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if basicAuth {
+		req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+	}
+
+	var tr tokenResponse
+	if err := doJSON(req, &tr); err != nil {
+		return nil, err
+	}
+	if tr.Error != "" {
+		return nil, fmt.Errorf("oauth: token exchange failed: %s %s", tr.Error, tr.ErrorDescription)
+	}
+	if tr.AccessToken == "" {
+		return nil, fmt.Errorf("oauth: token response has no access token")
+	}
+	return &tr, nil
+}
+
+// getJSON gets the resource, the access token is sent when it's provided
+func getJSON(ctx context.Context, resourceURL, accessToken string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, resourceURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "ecommerce-shop")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	return doJSON(req, v)
+}
+
+func doJSON(req *http.Request, v interface{}) error {
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("oauth: %s %s returned %d: %s", req.Method, req.URL.String(), res.StatusCode, body)
+	}
+	return json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(v)
+}
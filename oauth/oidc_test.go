@@ -0,0 +1,225 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go/v4"
+)
+
+const (
+	testClientID     = "shop"
+	testClientSecret = "shop-secret"
+	testRedirectURL  = "http://localhost:3001/api/v1/oauth/oidc/callback"
+	testCode         = "good-code"
+	testVerifier     = "the-code-verifier"
+)
+
+// mockOIDC is the OpenID Connect provider that issues the id token for the one known code
+type mockOIDC struct {
+	*httptest.Server
+	key    *rsa.PrivateKey
+	signer *rsa.PrivateKey
+	claims jwt.MapClaims
+	info   map[string]interface{}
+}
+
+func newMockOIDC(t *testing.T) *mockOIDC {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("could not generate the signing key: %v", err)
+	}
+	m := &mockOIDC{key: key, signer: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                 m.URL,
+			"authorization_endpoint": m.URL + "/authorize",
+			"token_endpoint":         m.URL + "/token",
+			"userinfo_endpoint":      m.URL + "/userinfo",
+			"jwks_uri":               m.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "k1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("code") != testCode || r.Form.Get("code_verifier") != testVerifier ||
+			r.Form.Get("client_id") != testClientID || r.Form.Get("client_secret") != testClientSecret ||
+			r.Form.Get("redirect_uri") != testRedirectURL {
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, m.claims)
+		token.Header["kid"] = "k1"
+		idToken, err := token.SignedString(m.signer)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "access", "token_type": "Bearer", "id_token": idToken})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(m.info)
+	})
+
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Close)
+
+	m.claims = jwt.MapClaims{
+		"iss":            m.URL,
+		"aud":            testClientID,
+		"sub":            "user-1",
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"iat":            time.Now().Unix(),
+		"nonce":          "the-nonce",
+		"email":          "alice@example.com",
+		"email_verified": true,
+		"given_name":     "Alice",
+		"family_name":    "Smith",
+	}
+	return m
+}
+
+func (m *mockOIDC) provider() Provider {
+	return NewOIDCProvider(ProviderOIDC, m.URL, testClientID, testClientSecret, testRedirectURL)
+}
+
+func TestOIDCAuthCodeURL(t *testing.T) {
+	m := newMockOIDC(t)
+
+	raw, err := m.provider().AuthCodeURL(context.Background(), "the-state", "the-nonce", CodeChallenge(testVerifier))
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("AuthCodeURL() = %q: %v", raw, err)
+	}
+
+	if !strings.HasPrefix(raw, m.URL+"/authorize?") {
+		t.Errorf("AuthCodeURL() = %q, want the discovered authorization endpoint", raw)
+	}
+	q := u.Query()
+	want := map[string]string{
+		"client_id":             testClientID,
+		"redirect_uri":          testRedirectURL,
+		"response_type":         "code",
+		"state":                 "the-state",
+		"nonce":                 "the-nonce",
+		"code_challenge":        CodeChallenge(testVerifier),
+		"code_challenge_method": "S256",
+	}
+	for k, v := range want {
+		if q.Get(k) != v {
+			t.Errorf("%s = %q, want %q", k, q.Get(k), v)
+		}
+	}
+}
+
+func TestOIDCExchange(t *testing.T) {
+	m := newMockOIDC(t)
+
+	profile, err := m.provider().Exchange(context.Background(), testCode, testVerifier, "the-nonce")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	want := Profile{Subject: "user-1", Email: "alice@example.com", EmailVerified: true, FirstName: "Alice", LastName: "Smith"}
+	if *profile != want {
+		t.Errorf("Exchange() = %+v, want %+v", *profile, want)
+	}
+}
+
+func TestOIDCExchangeUserInfo(t *testing.T) {
+	m := newMockOIDC(t)
+	delete(m.claims, "email")
+	delete(m.claims, "email_verified")
+	m.info = map[string]interface{}{"sub": "user-1", "email": "alice@example.com", "email_verified": "true"}
+
+	profile, err := m.provider().Exchange(context.Background(), testCode, testVerifier, "the-nonce")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if profile.Email != "alice@example.com" || !profile.EmailVerified {
+		t.Errorf("Exchange() = %+v, want the email from the userinfo", *profile)
+	}
+
+	m.info["sub"] = "user-2"
+	if _, err := m.provider().Exchange(context.Background(), testCode, testVerifier, "the-nonce"); err == nil {
+		t.Error("Exchange() accepted the userinfo of another subject")
+	}
+}
+
+func TestOIDCExchangeRejectsInvalidIDToken(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		change   func(m *mockOIDC)
+		code     string
+		verifier string
+		nonce    string
+	}{
+		{name: "wrong nonce", nonce: "other-nonce"},
+		{name: "wrong code", code: "bad-code"},
+		{name: "wrong code verifier", verifier: "other-verifier"},
+		{name: "wrong audience", change: func(m *mockOIDC) { m.claims["aud"] = "other-client" }},
+		{name: "wrong issuer", change: func(m *mockOIDC) { m.claims["iss"] = "https://evil.example.com" }},
+		{name: "expired", change: func(m *mockOIDC) { m.claims["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{name: "no subject", change: func(m *mockOIDC) { delete(m.claims, "sub") }},
+		{name: "signed by another key", change: func(m *mockOIDC) { m.signer = otherKey }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMockOIDC(t)
+			if tt.change != nil {
+				tt.change(m)
+			}
+			code, verifier, nonce := testCode, testVerifier, "the-nonce"
+			if tt.code != "" {
+				code = tt.code
+			}
+			if tt.verifier != "" {
+				verifier = tt.verifier
+			}
+			if tt.nonce != "" {
+				nonce = tt.nonce
+			}
+
+			if profile, err := m.provider().Exchange(context.Background(), code, verifier, nonce); err == nil {
+				t.Errorf("Exchange() = %+v, want the error", *profile)
+			}
+		})
+	}
+}
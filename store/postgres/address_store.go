@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"database/sql"
 	"net/http"
 
 	"github.com/dankobgd/ecommerce-shop/model"
@@ -20,11 +21,12 @@ func NewPgAddressStore(pgst *PgStore) store.AddressStore {
 }
 
 var (
-	msgSaveAddress   = &i18n.Message{ID: "store.postgres.address.save.app_error", Other: "could not save address"}
-	msgGetAddress    = &i18n.Message{ID: "store.postgres.address.get.app_error", Other: "could not get address"}
-	msgGetAddresses  = &i18n.Message{ID: "store.postgres.address.get_all.app_error", Other: "could not get addresses"}
-	msgUpdateAddress = &i18n.Message{ID: "store.postgres.address.update.app_error", Other: "could not update address"}
-	msgDeleteAddress = &i18n.Message{ID: "store.postgres.address.save.app_error", Other: "could not delete address"}
+	msgSaveAddress     = &i18n.Message{ID: "store.postgres.address.save.app_error", Other: "could not save address"}
+	msgGetAddress      = &i18n.Message{ID: "store.postgres.address.get.app_error", Other: "could not get address"}
+	msgAddressNotFound = &i18n.Message{ID: "store.postgres.address.get.not_found.app_error", Other: "address not found"}
+	msgGetAddresses    = &i18n.Message{ID: "store.postgres.address.get_all.app_error", Other: "could not get addresses"}
+	msgUpdateAddress   = &i18n.Message{ID: "store.postgres.address.update.app_error", Other: "could not update address"}
+	msgDeleteAddress   = &i18n.Message{ID: "store.postgres.address.delete.app_error", Other: "could not delete address"}
 )
 
 // Save creates the new address
@@ -79,6 +81,9 @@ func (s *PgAddressStore) Get(userID, addressID int64) (*model.Address, *model.Ap
 	q := `SELECT a.* FROM public.address a LEFT JOIN public.user_address ua ON a.id = ua.address_id WHERE ua.user_id = $1 AND a.id = $2`
 	var addr model.Address
 	if err := s.db.Get(&addr, q, userID, addressID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.NewAppErr("PgAddressStore.Get", model.ErrNotFound, locale.GetUserLocalizer("en"), msgAddressNotFound, http.StatusNotFound, nil)
+		}
 		return nil, model.NewAppErr("PgAddressStore.Get", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetAddress, http.StatusInternalServerError, nil)
 	}
 
@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/store"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// PgAPIKeyStore is the postgres implementation
+type PgAPIKeyStore struct {
+	PgStore
+}
+
+// NewPgAPIKeyStore creates the new api key store
+func NewPgAPIKeyStore(pgst *PgStore) store.APIKeyStore {
+	return &PgAPIKeyStore{*pgst}
+}
+
+var (
+	msgSaveAPIKey        = &i18n.Message{ID: "store.postgres.api_key.save.app_error", Other: "could not save api key"}
+	msgGetAPIKey         = &i18n.Message{ID: "store.postgres.api_key.get.app_error", Other: "could not get api key"}
+	msgGetAPIKeys        = &i18n.Message{ID: "store.postgres.api_key.get_all.app_error", Other: "could not get api keys"}
+	msgRevokeAPIKey      = &i18n.Message{ID: "store.postgres.api_key.revoke.app_error", Other: "could not revoke api key"}
+	msgAPIKeyNotFound    = &i18n.Message{ID: "store.postgres.api_key.not_found.app_error", Other: "api key not found"}
+	msgRecordAPIKeyUsage = &i18n.Message{ID: "store.postgres.api_key.record_usage.app_error", Other: "could not record api key usage"}
+	msgGetAPIKeyUsage    = &i18n.Message{ID: "store.postgres.api_key.get_usage.app_error", Other: "could not get api key usage"}
+)
+
+// Save creates the new api key
+func (s PgAPIKeyStore) Save(k *model.APIKey) (*model.APIKey, *model.AppErr) {
+	q := `INSERT INTO public.api_key (name, prefix, key_hash, scopes, created_by, expires_at, created_at) VALUES (:name, :prefix, :key_hash, :scopes, :created_by, :expires_at, :created_at) RETURNING id`
+
+	var id int64
+	rows, err := s.db.NamedQuery(q, k)
+	if err != nil {
+		return nil, model.NewAppErr("PgAPIKeyStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveAPIKey, http.StatusInternalServerError, nil)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		rows.Scan(&id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, model.NewAppErr("PgAPIKeyStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveAPIKey, http.StatusInternalServerError, nil)
+	}
+
+	k.ID = id
+	return k, nil
+}
+
+// Get gets one api key by id, nil is returned if the key doesn't exist
+func (s PgAPIKeyStore) Get(id int64) (*model.APIKey, *model.AppErr) {
+	var k model.APIKey
+	if err := s.db.Get(&k, `SELECT * FROM public.api_key WHERE id = $1`, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, model.NewAppErr("PgAPIKeyStore.Get", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetAPIKey, http.StatusInternalServerError, nil)
+	}
+	return &k, nil
+}
+
+// GetByHash gets the api key by its hash, nil is returned if there is no such key
+func (s PgAPIKeyStore) GetByHash(hash string) (*model.APIKey, *model.AppErr) {
+	var k model.APIKey
+	if err := s.db.Get(&k, `SELECT * FROM public.api_key WHERE key_hash = $1`, hash); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, model.NewAppErr("PgAPIKeyStore.GetByHash", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetAPIKey, http.StatusInternalServerError, nil)
+	}
+	return &k, nil
+}
+
+// GetAll gets all api keys, the newest first
+func (s PgAPIKeyStore) GetAll(limit, offset int) ([]*model.APIKey, *model.AppErr) {
+	var keys = make([]*model.APIKey, 0)
+	if err := s.db.Select(&keys, `SELECT COUNT(*) OVER() AS total_count, * FROM public.api_key ORDER BY id DESC LIMIT $1 OFFSET $2`, limit, offset); err != nil {
+		return nil, model.NewAppErr("PgAPIKeyStore.GetAll", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetAPIKeys, http.StatusInternalServerError, nil)
+	}
+	return keys, nil
+}
+
+// Revoke revokes the api key, the already revoked key keeps its original revocation time
+func (s PgAPIKeyStore) Revoke(id int64) *model.AppErr {
+	res, err := s.db.Exec(`UPDATE public.api_key SET revoked_at = COALESCE(revoked_at, $2) WHERE id = $1`, id, time.Now())
+	if err != nil {
+		return model.NewAppErr("PgAPIKeyStore.Revoke", model.ErrInternal, locale.GetUserLocalizer("en"), msgRevokeAPIKey, http.StatusInternalServerError, nil)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return model.NewAppErr("PgAPIKeyStore.Revoke", model.ErrNotFound, locale.GetUserLocalizer("en"), msgAPIKeyNotFound, http.StatusNotFound, nil)
+	}
+	return nil
+}
+
+// RecordUsage saves the request made with the key and updates when and from where the key was last used
+func (s PgAPIKeyStore) RecordUsage(u *model.APIKeyUsage) *model.AppErr {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return model.NewAppErr("PgAPIKeyStore.RecordUsage", model.ErrInternal, locale.GetUserLocalizer("en"), msgRecordAPIKeyUsage, http.StatusInternalServerError, nil)
+	}
+
+	q := `INSERT INTO public.api_key_usage (api_key_id, method, path, ip, request_id, created_at) VALUES (:api_key_id, :method, :path, :ip, :request_id, :created_at)`
+	if _, err := tx.NamedExec(q, u); err != nil {
+		tx.Rollback()
+		return model.NewAppErr("PgAPIKeyStore.RecordUsage", model.ErrInternal, locale.GetUserLocalizer("en"), msgRecordAPIKeyUsage, http.StatusInternalServerError, nil)
+	}
+	if _, err := tx.Exec(`UPDATE public.api_key SET last_used_at = $2, last_used_ip = $3 WHERE id = $1`, u.APIKeyID, u.CreatedAt, u.IP); err != nil {
+		tx.Rollback()
+		return model.NewAppErr("PgAPIKeyStore.RecordUsage", model.ErrInternal, locale.GetUserLocalizer("en"), msgRecordAPIKeyUsage, http.StatusInternalServerError, nil)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return model.NewAppErr("PgAPIKeyStore.RecordUsage", model.ErrInternal, locale.GetUserLocalizer("en"), msgRecordAPIKeyUsage, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// GetUsage gets the requests made with the key, the newest first
+func (s PgAPIKeyStore) GetUsage(id int64, limit, offset int) ([]*model.APIKeyUsage, *model.AppErr) {
+	var usage = make([]*model.APIKeyUsage, 0)
+	if err := s.db.Select(&usage, `SELECT COUNT(*) OVER() AS total_count, * FROM public.api_key_usage WHERE api_key_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3`, id, limit, offset); err != nil {
+		return nil, model.NewAppErr("PgAPIKeyStore.GetUsage", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetAPIKeyUsage, http.StatusInternalServerError, nil)
+	}
+	return usage, nil
+}
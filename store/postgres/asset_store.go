@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"net/http"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/store"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// PgAssetStore is the postgres implementation
+type PgAssetStore struct {
+	PgStore
+}
+
+// NewPgAssetStore creates the new asset store
+func NewPgAssetStore(pgst *PgStore) store.AssetStore {
+	return &PgAssetStore{*pgst}
+}
+
+var (
+	msgGetReferencedPublicIDs = &i18n.Message{ID: "store.postgres.asset.get_referenced_public_ids.app_error", Other: "could not get referenced asset ids"}
+)
+
+// GetReferencedPublicIDs gets the cloudinary public ids of all the stored images
+func (s PgAssetStore) GetReferencedPublicIDs() ([]string, *model.AppErr) {
+	q := `SELECT logo_public_id FROM public.category
+	UNION SELECT logo_public_id FROM public.brand
+	UNION SELECT avatar_public_id FROM public.user WHERE avatar_public_id IS NOT NULL
+	UNION SELECT image_public_id FROM public.product
+	UNION SELECT public_id FROM public.product_image
+	UNION SELECT public_id FROM public.product_review_media`
+
+	var ids []string
+	if err := s.db.Select(&ids, q); err != nil {
+		return nil, model.NewAppErr("PgAssetStore.GetReferencedPublicIDs", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetReferencedPublicIDs, http.StatusInternalServerError, nil)
+	}
+	return ids, nil
+}
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"net/http"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/store"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// PgAuditStore is the postgres implementation
+type PgAuditStore struct {
+	PgStore
+}
+
+// NewPgAuditStore creates the new audit store
+func NewPgAuditStore(pgst *PgStore) store.AuditStore {
+	return &PgAuditStore{*pgst}
+}
+
+var (
+	msgSaveAuditEntry = &i18n.Message{ID: "store.postgres.audit.save.app_error", Other: "could not save audit log entry"}
+	msgGetAuditLog    = &i18n.Message{ID: "store.postgres.audit.get_all.app_error", Other: "could not get audit log"}
+)
+
+// Save appends the entry to the audit log
+func (s PgAuditStore) Save(e *model.AuditEntry) (*model.AuditEntry, *model.AppErr) {
+	q := `INSERT INTO public.audit_log (actor_id, api_key_id, action, resource_type, resource_id, diff, ip, request_id, created_at) VALUES (:actor_id, :api_key_id, :action, :resource_type, :resource_id, :diff, :ip, :request_id, :created_at) RETURNING id`
+
+	var id int64
+	rows, err := s.db.NamedQuery(q, e)
+	if err != nil {
+		return nil, model.NewAppErr("PgAuditStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveAuditEntry, http.StatusInternalServerError, nil)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		rows.Scan(&id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, model.NewAppErr("PgAuditStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveAuditEntry, http.StatusInternalServerError, nil)
+	}
+
+	e.ID = id
+	return e, nil
+}
+
+// GetAll gets the filtered audit log entries, the newest first
+func (s PgAuditStore) GetAll(f *model.AuditFilter, limit, offset int) ([]*model.AuditEntry, *model.AppErr) {
+	q := `SELECT COUNT(*) OVER() AS total_count, * FROM public.audit_log
+	WHERE ($1::bigint = 0 OR actor_id = $1) AND ($2::bigint = 0 OR api_key_id = $2)
+	AND ($3 = '' OR action = $3) AND ($4 = '' OR resource_type = $4) AND ($5 = '' OR resource_id = $5) AND ($6 = '' OR request_id = $6)
+	AND ($7::timestamptz IS NULL OR created_at >= $7) AND ($8::timestamptz IS NULL OR created_at < $8)
+	ORDER BY id DESC
+	LIMIT $9 OFFSET $10`
+
+	var entries = make([]*model.AuditEntry, 0)
+	if err := s.db.Select(&entries, q, f.ActorID, f.APIKeyID, f.Action, f.ResourceType, f.ResourceID, f.RequestID, f.From, f.To, limit, offset); err != nil {
+		return nil, model.NewAppErr("PgAuditStore.GetAll", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetAuditLog, http.StatusInternalServerError, nil)
+	}
+	return entries, nil
+}
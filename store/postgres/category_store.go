@@ -26,9 +26,9 @@ var (
 	msgUpdateCategory           = &i18n.Message{ID: "store.postgres.category.update.app_error", Other: "could not update category"}
 	msgBulkInsertCategories     = &i18n.Message{ID: "store.postgres.category.bulk.insert.app_error", Other: "could not bulk insert categories"}
 	msgGetCategory              = &i18n.Message{ID: "store.postgres.category.get.app_error", Other: "could not get the category"}
-	msgGetCategories            = &i18n.Message{ID: "store.postgres.category.get.app_error", Other: "could not get categories"}
+	msgGetCategories            = &i18n.Message{ID: "store.postgres.category.get_all.app_error", Other: "could not get categories"}
 	msgDeleteCategory           = &i18n.Message{ID: "store.postgres.category.delete.app_error", Other: "could not delete category"}
-	msgBulkDeleteCategories     = &i18n.Message{ID: "store.postgres.category.delete.app_error", Other: "could not bulk delete categories"}
+	msgBulkDeleteCategories     = &i18n.Message{ID: "store.postgres.category.bulk_delete.app_error", Other: "could not bulk delete categories"}
 )
 
 // Count returns the total categories count
@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/store"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// PgEmailTemplateStore is the postgres implementation
+type PgEmailTemplateStore struct {
+	PgStore
+}
+
+// NewPgEmailTemplateStore creates the new email template store
+func NewPgEmailTemplateStore(pgst *PgStore) store.EmailTemplateStore {
+	return &PgEmailTemplateStore{*pgst}
+}
+
+var (
+	msgSaveEmailTemplate   = &i18n.Message{ID: "store.postgres.email_template.save.app_error", Other: "could not save email template"}
+	msgGetEmailTemplate    = &i18n.Message{ID: "store.postgres.email_template.get.app_error", Other: "could not get email template"}
+	msgGetEmailTemplates   = &i18n.Message{ID: "store.postgres.email_template.get_all.app_error", Other: "could not get email templates"}
+	msgDeleteEmailTemplate = &i18n.Message{ID: "store.postgres.email_template.delete.app_error", Other: "could not delete email template"}
+)
+
+// Save creates the template override or replaces the existing one for the same name and locale
+func (s PgEmailTemplateStore) Save(et *model.EmailTemplate) (*model.EmailTemplate, *model.AppErr) {
+	q := `INSERT INTO public.email_template (name, locale, subject, body, created_at, updated_at)
+	VALUES (:name, :locale, :subject, :body, :created_at, :updated_at)
+	ON CONFLICT (name, locale) DO UPDATE SET subject = EXCLUDED.subject, body = EXCLUDED.body, updated_at = EXCLUDED.updated_at
+	RETURNING id, created_at`
+
+	rows, err := s.db.NamedQuery(q, et)
+	if err != nil {
+		return nil, model.NewAppErr("PgEmailTemplateStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveEmailTemplate, http.StatusInternalServerError, nil)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		rows.Scan(&et.ID, &et.CreatedAt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, model.NewAppErr("PgEmailTemplateStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveEmailTemplate, http.StatusInternalServerError, nil)
+	}
+	return et, nil
+}
+
+// Get gets the template override, nil is returned if the template is not overridden
+func (s PgEmailTemplateStore) Get(name, lang string) (*model.EmailTemplate, *model.AppErr) {
+	var et model.EmailTemplate
+	if err := s.db.Get(&et, `SELECT * FROM public.email_template WHERE name = $1 AND locale = $2`, name, lang); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, model.NewAppErr("PgEmailTemplateStore.Get", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetEmailTemplate, http.StatusInternalServerError, nil)
+	}
+	return &et, nil
+}
+
+// GetAll gets all template overrides
+func (s PgEmailTemplateStore) GetAll() ([]*model.EmailTemplate, *model.AppErr) {
+	var templates = make([]*model.EmailTemplate, 0)
+	if err := s.db.Select(&templates, `SELECT * FROM public.email_template ORDER BY name, locale`); err != nil {
+		return nil, model.NewAppErr("PgEmailTemplateStore.GetAll", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetEmailTemplates, http.StatusInternalServerError, nil)
+	}
+	return templates, nil
+}
+
+// Delete deletes the template override
+func (s PgEmailTemplateStore) Delete(name, lang string) *model.AppErr {
+	if _, err := s.db.Exec(`DELETE FROM public.email_template WHERE name = $1 AND locale = $2`, name, lang); err != nil {
+		return model.NewAppErr("PgEmailTemplateStore.Delete", model.ErrInternal, locale.GetUserLocalizer("en"), msgDeleteEmailTemplate, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
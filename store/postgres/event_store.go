@@ -0,0 +1,150 @@
+package postgres
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/store"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/types"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// PgEventStore is the postgres implementation
+type PgEventStore struct {
+	PgStore
+}
+
+// NewPgEventStore creates the new event store
+func NewPgEventStore(pgst *PgStore) store.EventStore {
+	return &PgEventStore{*pgst}
+}
+
+var (
+	msgGetEvent             = &i18n.Message{ID: "store.postgres.event.get.app_error", Other: "could not get event"}
+	msgGetUndispatched      = &i18n.Message{ID: "store.postgres.event.get_undispatched.app_error", Other: "could not get undispatched events"}
+	msgMarkEventsDispatched = &i18n.Message{ID: "store.postgres.event.mark_dispatched.app_error", Other: "could not mark events as dispatched"}
+	msgDeleteEvents         = &i18n.Message{ID: "store.postgres.event.delete.app_error", Other: "could not delete events"}
+)
+
+// saveEvents writes the events to the outbox within the given transaction,
+// events without the aggregate id get the id of the row that was just inserted
+func saveEvents(tx *sqlx.Tx, aggregateID int64, events []*model.Event) error {
+	q := `INSERT INTO public.event_outbox (type, aggregate_type, aggregate_id, payload, created_at) VALUES (:type, :aggregate_type, :aggregate_id, :payload, :created_at)`
+	for _, e := range events {
+		if e.AggregateID == 0 {
+			e.AggregateID = aggregateID
+		}
+		if e.Data != nil {
+			b, err := json.Marshal(e.Data)
+			if err != nil {
+				return err
+			}
+			e.Payload = types.JSONText(b)
+		}
+		if len(e.Payload) == 0 {
+			e.Payload = types.JSONText("{}")
+		}
+		if _, err := tx.NamedExec(q, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertWithEvents runs the named insert returning the new row id and saves the events in the same transaction,
+// setID is called with the new id before the events are encoded so their payload contains it
+func (s PgStore) insertWithEvents(q string, arg interface{}, setID func(id int64), events []*model.Event) (int64, error) {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	rows, err := tx.NamedQuery(q, arg)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	for rows.Next() {
+		rows.Scan(&id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	setID(id)
+	if err := saveEvents(tx, id, events); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// execWithEvents runs the named statement and saves the events in the same transaction
+func (s PgStore) execWithEvents(q string, arg interface{}, aggregateID int64, events []*model.Event) error {
+	return s.inTxWithEvents(aggregateID, events, func(tx *sqlx.Tx) error {
+		_, err := tx.NamedExec(q, arg)
+		return err
+	})
+}
+
+// inTxWithEvents runs fn and saves the events in the same transaction
+func (s PgStore) inTxWithEvents(aggregateID int64, events []*model.Event, fn func(tx *sqlx.Tx) error) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := saveEvents(tx, aggregateID, events); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Get gets the event by id
+func (s PgEventStore) Get(id int64) (*model.Event, *model.AppErr) {
+	var e model.Event
+	if err := s.db.Get(&e, `SELECT * FROM public.event_outbox WHERE id = $1`, id); err != nil {
+		return nil, model.NewAppErr("PgEventStore.Get", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetEvent, http.StatusInternalServerError, nil)
+	}
+	return &e, nil
+}
+
+// GetUndispatched gets the oldest events that were not dispatched yet
+func (s PgEventStore) GetUndispatched(limit int) ([]*model.Event, *model.AppErr) {
+	var events = make([]*model.Event, 0)
+	if err := s.db.Select(&events, `SELECT * FROM public.event_outbox WHERE dispatched_at IS NULL ORDER BY id LIMIT $1`, limit); err != nil {
+		return nil, model.NewAppErr("PgEventStore.GetUndispatched", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetUndispatched, http.StatusInternalServerError, nil)
+	}
+	return events, nil
+}
+
+// MarkDispatched marks the events as dispatched
+func (s PgEventStore) MarkDispatched(ids []int64) *model.AppErr {
+	q, args, err := sqlx.In(`UPDATE public.event_outbox SET dispatched_at = NOW() WHERE id IN (?)`, ids)
+	if err != nil {
+		return model.NewAppErr("PgEventStore.MarkDispatched", model.ErrInternal, locale.GetUserLocalizer("en"), msgMarkEventsDispatched, http.StatusInternalServerError, nil)
+	}
+	if _, err := s.db.Exec(s.db.Rebind(q), args...); err != nil {
+		return model.NewAppErr("PgEventStore.MarkDispatched", model.ErrInternal, locale.GetUserLocalizer("en"), msgMarkEventsDispatched, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// DeleteDispatched deletes the events dispatched before the given time
+func (s PgEventStore) DeleteDispatched(before time.Time) *model.AppErr {
+	if _, err := s.db.Exec(`DELETE FROM public.event_outbox WHERE dispatched_at < $1`, before); err != nil {
+		return model.NewAppErr("PgEventStore.DeleteDispatched", model.ErrInternal, locale.GetUserLocalizer("en"), msgDeleteEvents, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package postgres
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/store"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// PgJobStore is the postgres implementation
+type PgJobStore struct {
+	PgStore
+}
+
+// NewPgJobStore creates the new job store
+func NewPgJobStore(pgst *PgStore) store.JobStore {
+	return &PgJobStore{*pgst}
+}
+
+var (
+	msgSaveJob         = &i18n.Message{ID: "store.postgres.job.save.app_error", Other: "could not save job"}
+	msgGetJob          = &i18n.Message{ID: "store.postgres.job.get.app_error", Other: "could not get job"}
+	msgGetJobs         = &i18n.Message{ID: "store.postgres.job.get_all.app_error", Other: "could not get jobs"}
+	msgGetJobStats     = &i18n.Message{ID: "store.postgres.job.get_stats.app_error", Other: "could not get job stats"}
+	msgClaimJob        = &i18n.Message{ID: "store.postgres.job.claim.app_error", Other: "could not claim job"}
+	msgUpdateJobStatus = &i18n.Message{ID: "store.postgres.job.update_status.app_error", Other: "could not update job status"}
+	msgRequeueJobs     = &i18n.Message{ID: "store.postgres.job.requeue.app_error", Other: "could not requeue stale jobs"}
+	msgDeleteJobs      = &i18n.Message{ID: "store.postgres.job.delete.app_error", Other: "could not delete jobs"}
+)
+
+// Save enqueues the new job, jobs with the already existing unique key are skipped
+func (s PgJobStore) Save(job *model.Job) (*model.Job, *model.AppErr) {
+	q := `INSERT INTO public.job (type, payload, status, attempts, max_attempts, unique_key, run_at, created_at, updated_at)
+	VALUES (:type, :payload, :status, :attempts, :max_attempts, :unique_key, :run_at, :created_at, :updated_at)
+	ON CONFLICT (unique_key) DO NOTHING
+	RETURNING id`
+
+	var id int64
+	rows, err := s.db.NamedQuery(q, job)
+	if err != nil {
+		return nil, model.NewAppErr("PgJobStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveJob, http.StatusInternalServerError, nil)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		rows.Scan(&id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, model.NewAppErr("PgJobStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveJob, http.StatusInternalServerError, nil)
+	}
+
+	job.ID = id
+	return job, nil
+}
+
+// Get gets the job by id
+func (s PgJobStore) Get(id int64) (*model.Job, *model.AppErr) {
+	var job model.Job
+	if err := s.db.Get(&job, `SELECT * FROM public.job WHERE id = $1`, id); err != nil {
+		return nil, model.NewAppErr("PgJobStore.Get", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetJob, http.StatusInternalServerError, nil)
+	}
+	return &job, nil
+}
+
+// GetAll gets the jobs optionally filtered by status and type
+func (s PgJobStore) GetAll(status, jobType string, limit, offset int) ([]*model.Job, *model.AppErr) {
+	q := `SELECT COUNT(*) OVER() AS total_count, * FROM public.job
+	WHERE ($1 = '' OR status = $1) AND ($2 = '' OR type = $2)
+	ORDER BY id DESC
+	LIMIT $3 OFFSET $4`
+
+	var jobs = make([]*model.Job, 0)
+	if err := s.db.Select(&jobs, q, status, jobType, limit, offset); err != nil {
+		return nil, model.NewAppErr("PgJobStore.GetAll", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetJobs, http.StatusInternalServerError, nil)
+	}
+	return jobs, nil
+}
+
+// GetStats counts the jobs per type and status
+func (s PgJobStore) GetStats() ([]*model.JobStats, *model.AppErr) {
+	var stats = make([]*model.JobStats, 0)
+	if err := s.db.Select(&stats, `SELECT type, status, COUNT(*) AS count FROM public.job GROUP BY type, status ORDER BY type, status`); err != nil {
+		return nil, model.NewAppErr("PgJobStore.GetStats", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetJobStats, http.StatusInternalServerError, nil)
+	}
+	return stats, nil
+}
+
+// ClaimNext locks the next due job for the worker, returns nil if there is nothing to run
+func (s PgJobStore) ClaimNext() (*model.Job, *model.AppErr) {
+	q := `UPDATE public.job SET status = 'running', attempts = attempts + 1, locked_at = NOW(), updated_at = NOW()
+	WHERE id = (
+		SELECT id FROM public.job
+		WHERE status = 'pending' AND run_at <= NOW()
+		ORDER BY run_at, id
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING *`
+
+	var job model.Job
+	if err := s.db.Get(&job, q); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, model.NewAppErr("PgJobStore.ClaimNext", model.ErrInternal, locale.GetUserLocalizer("en"), msgClaimJob, http.StatusInternalServerError, nil)
+	}
+	return &job, nil
+}
+
+// Complete marks the job as completed
+func (s PgJobStore) Complete(id int64) *model.AppErr {
+	q := `UPDATE public.job SET status = 'completed', locked_at = NULL, completed_at = NOW(), updated_at = NOW() WHERE id = $1`
+	if _, err := s.db.Exec(q, id); err != nil {
+		return model.NewAppErr("PgJobStore.Complete", model.ErrInternal, locale.GetUserLocalizer("en"), msgUpdateJobStatus, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// Fail puts the failed job back in the queue to be retried at the given time
+func (s PgJobStore) Fail(id int64, errMsg string, runAt time.Time) *model.AppErr {
+	q := `UPDATE public.job SET status = 'pending', last_error = $2, run_at = $3, locked_at = NULL, updated_at = NOW() WHERE id = $1`
+	if _, err := s.db.Exec(q, id, errMsg, runAt); err != nil {
+		return model.NewAppErr("PgJobStore.Fail", model.ErrInternal, locale.GetUserLocalizer("en"), msgUpdateJobStatus, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// Kill moves the job that ran out of attempts to the dead letter state
+func (s PgJobStore) Kill(id int64, errMsg string) *model.AppErr {
+	q := `UPDATE public.job SET status = 'dead', last_error = $2, locked_at = NULL, updated_at = NOW() WHERE id = $1`
+	if _, err := s.db.Exec(q, id, errMsg); err != nil {
+		return model.NewAppErr("PgJobStore.Kill", model.ErrInternal, locale.GetUserLocalizer("en"), msgUpdateJobStatus, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// Retry puts the dead job back in the queue with the fresh attempts
+func (s PgJobStore) Retry(id int64) *model.AppErr {
+	q := `UPDATE public.job SET status = 'pending', attempts = 0, run_at = NOW(), updated_at = NOW() WHERE id = $1 AND status = 'dead'`
+	if _, err := s.db.Exec(q, id); err != nil {
+		return model.NewAppErr("PgJobStore.Retry", model.ErrInternal, locale.GetUserLocalizer("en"), msgUpdateJobStatus, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// RequeueStale puts back the running jobs locked before the given time (e.g. the worker crashed),
+// the jobs that ran out of attempts are moved to the dead letter state so the job crashing the worker isn't retried forever
+func (s PgJobStore) RequeueStale(before time.Time) *model.AppErr {
+	q := `UPDATE public.job SET
+	status = CASE WHEN attempts >= max_attempts THEN 'dead' ELSE 'pending' END,
+	last_error = CASE WHEN attempts >= max_attempts THEN 'worker stopped while running the job' ELSE last_error END,
+	locked_at = NULL, updated_at = NOW()
+	WHERE status = 'running' AND locked_at < $1`
+	if _, err := s.db.Exec(q, before); err != nil {
+		return model.NewAppErr("PgJobStore.RequeueStale", model.ErrInternal, locale.GetUserLocalizer("en"), msgRequeueJobs, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// DeleteCompleted deletes the jobs completed before the given time
+func (s PgJobStore) DeleteCompleted(before time.Time) *model.AppErr {
+	if _, err := s.db.Exec(`DELETE FROM public.job WHERE status = 'completed' AND completed_at < $1`, before); err != nil {
+		return model.NewAppErr("PgJobStore.DeleteCompleted", model.ErrInternal, locale.GetUserLocalizer("en"), msgDeleteJobs, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
@@ -2,10 +2,12 @@ package postgres
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/dankobgd/ecommerce-shop/model"
 	"github.com/dankobgd/ecommerce-shop/store"
 	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/jmoiron/sqlx"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 )
 
@@ -26,6 +28,11 @@ var (
 	msgGetOrders   = &i18n.Message{ID: "store.postgres.orders.get.app_error", Other: "could not get orders"}
 )
 
+var (
+	msgCountAbandonedOrders  = &i18n.Message{ID: "store.postgres.order.count_abandoned.app_error", Other: "could not count abandoned orders"}
+	msgExpireAbandonedOrders = &i18n.Message{ID: "store.postgres.order.expire_abandoned.app_error", Other: "could not expire abandoned orders"}
+)
+
 // Count returns the total orders count
 func (s PgOrderStore) Count() int {
 	var n int
@@ -33,34 +40,68 @@ func (s PgOrderStore) Count() int {
 	return n
 }
 
-// Save creates the new order
-func (s PgOrderStore) Save(o *model.Order) (*model.Order, *model.AppErr) {
+// Save creates the new order with its details and the used promotion, the events are written to the outbox in the same transaction
+func (s PgOrderStore) Save(o *model.Order, details []*model.OrderDetail, promo *model.PromotionDetail, events ...*model.Event) (*model.Order, *model.AppErr) {
 	q := `INSERT INTO public.order (user_id, promo_code, promo_code_type, promo_code_amount, status, subtotal, total, shipped_at, created_at, payment_method_id, payment_intent_id, receipt_url, billing_address_line_1, billing_address_line_2, billing_address_city, billing_address_country, billing_address_state, billing_address_zip, billing_address_latitude, billing_address_longitude, shipping_address_line_1, shipping_address_line_2, shipping_address_city, shipping_address_country, shipping_address_state, shipping_address_zip, shipping_address_latitude, shipping_address_longitude) 
 	VALUES (:user_id, :promo_code, :promo_code_type, :promo_code_amount, :status, :subtotal, :total, :shipped_at, :created_at, :payment_method_id, :payment_intent_id, :receipt_url, :billing_address_line_1, :billing_address_line_2, :billing_address_city, :billing_address_country, :billing_address_state, :billing_address_zip, :billing_address_latitude, :billing_address_longitude, :shipping_address_line_1, :shipping_address_line_2, :shipping_address_city, :shipping_address_country, :shipping_address_state, :shipping_address_zip, :shipping_address_latitude, :shipping_address_longitude) RETURNING id`
 
-	var id int64
-	rows, err := s.db.NamedQuery(q, o)
+	tx, err := s.db.Beginx()
 	if err != nil {
 		return nil, model.NewAppErr("PgOrderStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveOrder, http.StatusInternalServerError, nil)
 	}
-	defer rows.Close()
+
+	if appErr := s.saveOrder(tx, q, o, details, promo, events); appErr != nil {
+		tx.Rollback()
+		return nil, appErr
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, model.NewAppErr("PgOrderStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveOrder, http.StatusInternalServerError, nil)
+	}
+	return o, nil
+}
+
+func (s PgOrderStore) saveOrder(tx *sqlx.Tx, q string, o *model.Order, details []*model.OrderDetail, promo *model.PromotionDetail, events []*model.Event) *model.AppErr {
+	l := locale.GetUserLocalizer("en")
+
+	rows, err := tx.NamedQuery(q, o)
+	if err != nil {
+		return model.NewAppErr("PgOrderStore.Save", model.ErrInternal, l, msgSaveOrder, http.StatusInternalServerError, nil)
+	}
 	for rows.Next() {
-		rows.Scan(&id)
+		rows.Scan(&o.ID)
 	}
+	rows.Close()
 	if err := rows.Err(); err != nil {
-		if IsUniqueConstraintViolationError(err) {
-			return nil, model.NewAppErr("PgOrderStore.Save", model.ErrConflict, locale.GetUserLocalizer("en"), msgUniqueConstraintUser, http.StatusInternalServerError, nil)
+		return model.NewAppErr("PgOrderStore.Save", model.ErrInternal, l, msgSaveOrder, http.StatusInternalServerError, nil)
+	}
+
+	for _, d := range details {
+		d.OrderID = o.ID
+	}
+	if len(details) > 0 {
+		if _, err := tx.NamedExec(`INSERT INTO public.order_detail (order_id, product_id, quantity, history_price, history_sku) VALUES (:order_id, :product_id, :quantity, :history_price, :history_sku)`, details); err != nil {
+			return model.NewAppErr("PgOrderStore.Save", model.ErrInternal, l, msgBulkInsertOrderDetails, http.StatusInternalServerError, nil)
 		}
-		return nil, model.NewAppErr("PgOrderStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveUser, http.StatusInternalServerError, nil)
 	}
 
-	o.ID = id
-	return o, nil
+	if promo != nil {
+		if _, err := tx.NamedExec(`INSERT INTO public.promotion_detail(user_id, promo_code) VALUES(:user_id, :promo_code)`, promo); err != nil {
+			if IsUniqueConstraintViolationError(err) {
+				return model.NewAppErr("PgOrderStore.Save", model.ErrConflict, l, msgUniqueConstraintPromotionDetail, http.StatusConflict, nil)
+			}
+			return model.NewAppErr("PgOrderStore.Save", model.ErrInternal, l, msgInsertPromotionDetail, http.StatusInternalServerError, nil)
+		}
+	}
+
+	if err := saveEvents(tx, o.ID, events); err != nil {
+		return model.NewAppErr("PgOrderStore.Save", model.ErrInternal, l, msgSaveOrder, http.StatusInternalServerError, nil)
+	}
+	return nil
 }
 
 // Update updates the product
-func (s PgOrderStore) Update(id int64, o *model.Order) (*model.Order, *model.AppErr) {
-	if _, err := s.db.NamedExec(`UPDATE public.order SET status=:status, subtotal=:subtotal, total=:total, shipped_at=:shipped_at WHERE id=:id`, o); err != nil {
+func (s PgOrderStore) Update(id int64, o *model.Order, events ...*model.Event) (*model.Order, *model.AppErr) {
+	if err := s.execWithEvents(`UPDATE public.order SET status=:status, subtotal=:subtotal, total=:total, shipped_at=:shipped_at, tracking_number=:tracking_number, tracking_url=:tracking_url, delivered_at=:delivered_at WHERE id=:id`, o, id, events); err != nil {
 		return nil, model.NewAppErr("PgOrderStore.Update", model.ErrInternal, locale.GetUserLocalizer("en"), msgUpdateOrder, http.StatusInternalServerError, nil)
 	}
 	return o, nil
@@ -89,3 +130,24 @@ func (s PgOrderStore) GetAll(limit, offset int) ([]*model.Order, *model.AppErr)
 func (s PgOrderStore) Delete(id int64) *model.AppErr {
 	return nil
 }
+
+// CountAbandoned counts the pending orders created before the given time
+func (s PgOrderStore) CountAbandoned(before time.Time) (int64, *model.AppErr) {
+	var count int64
+	q := `SELECT COUNT(*) FROM public.order WHERE status = $1 AND created_at < $2`
+	if err := s.db.Get(&count, q, model.OrderStatusPending.String(), before); err != nil {
+		return 0, model.NewAppErr("PgOrderStore.CountAbandoned", model.ErrInternal, locale.GetUserLocalizer("en"), msgCountAbandonedOrders, http.StatusInternalServerError, nil)
+	}
+	return count, nil
+}
+
+// ExpireAbandoned marks the pending orders created before the given time as expired
+func (s PgOrderStore) ExpireAbandoned(before time.Time) (int64, *model.AppErr) {
+	q := `UPDATE public.order SET status = $1 WHERE status = $2 AND created_at < $3`
+	res, err := s.db.Exec(q, model.OrderStatusExpired.String(), model.OrderStatusPending.String(), before)
+	if err != nil {
+		return 0, model.NewAppErr("PgOrderStore.ExpireAbandoned", model.ErrInternal, locale.GetUserLocalizer("en"), msgExpireAbandonedOrders, http.StatusInternalServerError, nil)
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
@@ -0,0 +1,284 @@
+package postgres
+
+import (
+	"net/http"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/store"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/jmoiron/sqlx"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// PgQuestionStore is the postgres implementation
+type PgQuestionStore struct {
+	PgStore
+}
+
+// NewPgQuestionStore creates the new question store
+func NewPgQuestionStore(pgst *PgStore) store.ProductQuestionStore {
+	return &PgQuestionStore{*pgst}
+}
+
+var (
+	msgSaveQuestion             = &i18n.Message{ID: "store.postgres.question.save.app_error", Other: "could not save question"}
+	msgGetQuestion              = &i18n.Message{ID: "store.postgres.question.get.app_error", Other: "could not get the question"}
+	msgGetQuestions             = &i18n.Message{ID: "store.postgres.question.get_all.app_error", Other: "could not get the questions"}
+	msgUpdateQuestionStatus     = &i18n.Message{ID: "store.postgres.question.update_status.app_error", Other: "could not update question status"}
+	msgDeleteQuestion           = &i18n.Message{ID: "store.postgres.question.delete.app_error", Other: "could not delete question"}
+	msgSaveAnswer               = &i18n.Message{ID: "store.postgres.question.save_answer.app_error", Other: "could not save answer"}
+	msgGetAnswer                = &i18n.Message{ID: "store.postgres.question.get_answer.app_error", Other: "could not get the answer"}
+	msgGetAnswers               = &i18n.Message{ID: "store.postgres.question.get_answers.app_error", Other: "could not get the answers"}
+	msgUpdateAnswerStatus       = &i18n.Message{ID: "store.postgres.question.update_answer_status.app_error", Other: "could not update answer status"}
+	msgDeleteAnswer             = &i18n.Message{ID: "store.postgres.question.delete_answer.app_error", Other: "could not delete answer"}
+	msgRefreshAnswerCount       = &i18n.Message{ID: "store.postgres.question.refresh_answer_count.app_error", Other: "could not refresh question answer count"}
+	msgUniqueConstraintUpvote   = &i18n.Message{ID: "store.postgres.question.save_upvote.unique_constraint.app_error", Other: "answer already upvoted"}
+	msgSaveAnswerUpvote         = &i18n.Message{ID: "store.postgres.question.save_upvote.app_error", Other: "could not save answer upvote"}
+	msgDeleteAnswerUpvote       = &i18n.Message{ID: "store.postgres.question.delete_upvote.app_error", Other: "could not delete answer upvote"}
+	msgInvalidQuestionProductFK = &i18n.Message{ID: "store.postgres.question.save.foreign_key.app_error", Other: "product does not exist"}
+)
+
+const questionUserColumns = `u.id AS user_id,
+	u.first_name AS user_first_name,
+	u.last_name AS user_last_name,
+	u.username AS user_username,
+	u.avatar_url AS user_avatar_url,
+	u.avatar_public_id AS user_avatar_public_id`
+
+// Save inserts the new question in the db
+func (s PgQuestionStore) Save(q *model.ProductQuestion) (*model.ProductQuestion, *model.AppErr) {
+	query := `INSERT INTO public.product_question(product_id, user_id, body, status, created_at, updated_at) VALUES(:product_id, :user_id, :body, :status, :created_at, :updated_at) RETURNING id`
+
+	var id int64
+	rows, err := s.db.NamedQuery(query, q)
+	if err != nil {
+		return nil, model.NewAppErr("PgQuestionStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveQuestion, http.StatusInternalServerError, nil)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		rows.Scan(&id)
+	}
+	if err := rows.Err(); err != nil {
+		if IsForeignKeyConstraintViolationError(err) {
+			return nil, model.NewAppErr("PgQuestionStore.Save", model.ErrConflict, locale.GetUserLocalizer("en"), msgInvalidQuestionProductFK, http.StatusBadRequest, nil)
+		}
+		return nil, model.NewAppErr("PgQuestionStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveQuestion, http.StatusInternalServerError, nil)
+	}
+
+	q.ID = id
+	return q, nil
+}
+
+// Get gets one question by id
+func (s PgQuestionStore) Get(pid, qid int64) (*model.ProductQuestion, *model.AppErr) {
+	q := `SELECT q.*, ` + questionUserColumns + `
+	FROM public.product_question q
+	LEFT JOIN public.user u ON q.user_id = u.id
+	WHERE q.product_id = $1 AND q.id = $2`
+
+	var qj questionJoin
+	if err := s.db.Get(&qj, q, pid, qid); err != nil {
+		return nil, model.NewAppErr("PgQuestionStore.Get", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetQuestion, http.StatusInternalServerError, nil)
+	}
+	return qj.ToQuestion(), nil
+}
+
+// GetAll returns the approved questions of the product, most recent first
+func (s PgQuestionStore) GetAll(pid int64, limit, offset int) ([]*model.ProductQuestion, *model.AppErr) {
+	q := `SELECT COUNT(*) OVER() AS total_count, q.*, ` + questionUserColumns + `
+	FROM public.product_question q
+	LEFT JOIN public.user u ON q.user_id = u.id
+	WHERE q.product_id = $1 AND q.status = $2
+	ORDER BY q.created_at DESC
+	LIMIT $3 OFFSET $4`
+
+	var qj []questionJoin
+	if err := s.db.Select(&qj, q, pid, model.ReviewStatusApproved, limit, offset); err != nil {
+		return nil, model.NewAppErr("PgQuestionStore.GetAll", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetQuestions, http.StatusInternalServerError, nil)
+	}
+
+	questions := make([]*model.ProductQuestion, 0)
+	for _, x := range qj {
+		questions = append(questions, x.ToQuestion())
+	}
+	return questions, nil
+}
+
+// GetAllByStatus returns the questions of all products with the given moderation status
+func (s PgQuestionStore) GetAllByStatus(status string, limit, offset int) ([]*model.ProductQuestion, *model.AppErr) {
+	q := `SELECT COUNT(*) OVER() AS total_count, q.*, ` + questionUserColumns + `
+	FROM public.product_question q
+	LEFT JOIN public.user u ON q.user_id = u.id
+	WHERE q.status = $1
+	ORDER BY q.created_at ASC
+	LIMIT $2 OFFSET $3`
+
+	var qj []questionJoin
+	if err := s.db.Select(&qj, q, status, limit, offset); err != nil {
+		return nil, model.NewAppErr("PgQuestionStore.GetAllByStatus", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetQuestions, http.StatusInternalServerError, nil)
+	}
+
+	questions := make([]*model.ProductQuestion, 0)
+	for _, x := range qj {
+		questions = append(questions, x.ToQuestion())
+	}
+	return questions, nil
+}
+
+// UpdateStatus sets the question moderation status
+func (s PgQuestionStore) UpdateStatus(pid, qid int64, status string) *model.AppErr {
+	m := map[string]interface{}{"product_id": pid, "question_id": qid, "status": status}
+	if _, err := s.db.NamedExec("UPDATE public.product_question SET status=:status, updated_at=CURRENT_TIMESTAMP WHERE product_id=:product_id AND id=:question_id", m); err != nil {
+		return model.NewAppErr("PgQuestionStore.UpdateStatus", model.ErrInternal, locale.GetUserLocalizer("en"), msgUpdateQuestionStatus, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// Delete hard deletes the question with its answers
+func (s PgQuestionStore) Delete(pid, qid int64) *model.AppErr {
+	if _, err := s.db.NamedExec("DELETE FROM public.product_question WHERE product_id=:product_id AND id=:question_id", map[string]interface{}{"product_id": pid, "question_id": qid}); err != nil {
+		return model.NewAppErr("PgQuestionStore.Delete", model.ErrInternal, locale.GetUserLocalizer("en"), msgDeleteQuestion, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// SaveAnswer inserts the new answer in the db
+func (s PgQuestionStore) SaveAnswer(ans *model.ProductAnswer) (*model.ProductAnswer, *model.AppErr) {
+	q := `INSERT INTO public.product_answer(question_id, user_id, body, is_admin, is_verified_purchase, status, created_at, updated_at) VALUES(:question_id, :user_id, :body, :is_admin, :is_verified_purchase, :status, :created_at, :updated_at) RETURNING id`
+
+	var id int64
+	rows, err := s.db.NamedQuery(q, ans)
+	if err != nil {
+		return nil, model.NewAppErr("PgQuestionStore.SaveAnswer", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveAnswer, http.StatusInternalServerError, nil)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		rows.Scan(&id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, model.NewAppErr("PgQuestionStore.SaveAnswer", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveAnswer, http.StatusInternalServerError, nil)
+	}
+
+	ans.ID = id
+	return ans, nil
+}
+
+// GetAnswer gets one answer of the question
+func (s PgQuestionStore) GetAnswer(qid, aid int64) (*model.ProductAnswer, *model.AppErr) {
+	q := `SELECT a.*, ` + questionUserColumns + `
+	FROM public.product_answer a
+	LEFT JOIN public.user u ON a.user_id = u.id
+	WHERE a.question_id = $1 AND a.id = $2`
+
+	var aj answerJoin
+	if err := s.db.Get(&aj, q, qid, aid); err != nil {
+		return nil, model.NewAppErr("PgQuestionStore.GetAnswer", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetAnswer, http.StatusInternalServerError, nil)
+	}
+	return aj.ToAnswer(), nil
+}
+
+// GetAnswers returns the approved answers of the given questions, most upvoted first
+func (s PgQuestionStore) GetAnswers(qids []int64) ([]*model.ProductAnswer, *model.AppErr) {
+	answers := make([]*model.ProductAnswer, 0)
+	if len(qids) == 0 {
+		return answers, nil
+	}
+
+	q, args, err := sqlx.In(`SELECT a.*, `+questionUserColumns+`
+	FROM public.product_answer a
+	LEFT JOIN public.user u ON a.user_id = u.id
+	WHERE a.question_id IN (?) AND a.status = ?
+	ORDER BY a.upvote_count DESC, a.created_at ASC`, qids, model.ReviewStatusApproved)
+	if err != nil {
+		return nil, model.NewAppErr("PgQuestionStore.GetAnswers", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetAnswers, http.StatusInternalServerError, nil)
+	}
+
+	var aj []answerJoin
+	if err := s.db.Select(&aj, s.db.Rebind(q), args...); err != nil {
+		return nil, model.NewAppErr("PgQuestionStore.GetAnswers", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetAnswers, http.StatusInternalServerError, nil)
+	}
+
+	for _, x := range aj {
+		answers = append(answers, x.ToAnswer())
+	}
+	return answers, nil
+}
+
+// GetAnswersByStatus returns the answers of all questions with the given moderation status
+func (s PgQuestionStore) GetAnswersByStatus(status string, limit, offset int) ([]*model.ProductAnswer, *model.AppErr) {
+	q := `SELECT COUNT(*) OVER() AS total_count, a.*, ` + questionUserColumns + `
+	FROM public.product_answer a
+	LEFT JOIN public.user u ON a.user_id = u.id
+	WHERE a.status = $1
+	ORDER BY a.created_at ASC
+	LIMIT $2 OFFSET $3`
+
+	var aj []answerJoin
+	if err := s.db.Select(&aj, q, status, limit, offset); err != nil {
+		return nil, model.NewAppErr("PgQuestionStore.GetAnswersByStatus", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetAnswers, http.StatusInternalServerError, nil)
+	}
+
+	answers := make([]*model.ProductAnswer, 0)
+	for _, x := range aj {
+		answers = append(answers, x.ToAnswer())
+	}
+	return answers, nil
+}
+
+// UpdateAnswerStatus sets the answer moderation status
+func (s PgQuestionStore) UpdateAnswerStatus(qid, aid int64, status string) *model.AppErr {
+	m := map[string]interface{}{"question_id": qid, "answer_id": aid, "status": status}
+	if _, err := s.db.NamedExec("UPDATE public.product_answer SET status=:status, updated_at=CURRENT_TIMESTAMP WHERE question_id=:question_id AND id=:answer_id", m); err != nil {
+		return model.NewAppErr("PgQuestionStore.UpdateAnswerStatus", model.ErrInternal, locale.GetUserLocalizer("en"), msgUpdateAnswerStatus, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// DeleteAnswer hard deletes the answer
+func (s PgQuestionStore) DeleteAnswer(qid, aid int64) *model.AppErr {
+	if _, err := s.db.NamedExec("DELETE FROM public.product_answer WHERE question_id=:question_id AND id=:answer_id", map[string]interface{}{"question_id": qid, "answer_id": aid}); err != nil {
+		return model.NewAppErr("PgQuestionStore.DeleteAnswer", model.ErrInternal, locale.GetUserLocalizer("en"), msgDeleteAnswer, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// RefreshAnswerCount recalculates the number of approved answers of the question
+func (s PgQuestionStore) RefreshAnswerCount(qid int64) *model.AppErr {
+	q := `UPDATE public.product_question SET answer_count = (
+		SELECT COUNT(*) FROM public.product_answer WHERE question_id = $1 AND status = $2
+	) WHERE id = $1`
+
+	if _, err := s.db.Exec(q, qid, model.ReviewStatusApproved); err != nil {
+		return model.NewAppErr("PgQuestionStore.RefreshAnswerCount", model.ErrInternal, locale.GetUserLocalizer("en"), msgRefreshAnswerCount, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// SaveAnswerUpvote upvotes the answer for the user and bumps the upvote count
+func (s PgQuestionStore) SaveAnswerUpvote(aid, uid int64) *model.AppErr {
+	q := `WITH v AS (
+		INSERT INTO public.product_answer_vote(answer_id, user_id, created_at) VALUES($1, $2, CURRENT_TIMESTAMP) RETURNING answer_id
+	)
+	UPDATE public.product_answer SET upvote_count = upvote_count + 1 WHERE id IN (SELECT answer_id FROM v)`
+
+	if _, err := s.db.Exec(q, aid, uid); err != nil {
+		if IsUniqueConstraintViolationError(err) {
+			return model.NewAppErr("PgQuestionStore.SaveAnswerUpvote", model.ErrConflict, locale.GetUserLocalizer("en"), msgUniqueConstraintUpvote, http.StatusConflict, nil)
+		}
+		return model.NewAppErr("PgQuestionStore.SaveAnswerUpvote", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveAnswerUpvote, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// DeleteAnswerUpvote removes the users upvote and decrements the upvote count
+func (s PgQuestionStore) DeleteAnswerUpvote(aid, uid int64) *model.AppErr {
+	q := `WITH v AS (
+		DELETE FROM public.product_answer_vote WHERE answer_id = $1 AND user_id = $2 RETURNING answer_id
+	)
+	UPDATE public.product_answer SET upvote_count = upvote_count - 1 WHERE id IN (SELECT answer_id FROM v)`
+
+	if _, err := s.db.Exec(q, aid, uid); err != nil {
+		return model.NewAppErr("PgQuestionStore.DeleteAnswerUpvote", model.ErrInternal, locale.GetUserLocalizer("en"), msgDeleteAnswerUpvote, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
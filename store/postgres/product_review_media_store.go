@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"net/http"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/store"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/jmoiron/sqlx"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// PgReviewMediaStore is the postgres implementation
+type PgReviewMediaStore struct {
+	PgStore
+}
+
+// NewPgReviewMediaStore creates the new review media store
+func NewPgReviewMediaStore(pgst *PgStore) store.ProductReviewMediaStore {
+	return &PgReviewMediaStore{*pgst}
+}
+
+var (
+	msgBulkInsertReviewMedia = &i18n.Message{ID: "store.postgres.review_media.bulk_insert.app_error", Other: "could not bulk insert review media"}
+	msgGetReviewMedia        = &i18n.Message{ID: "store.postgres.review_media.get.app_error", Other: "could not get review media"}
+	msgGetReviewMediaAll     = &i18n.Message{ID: "store.postgres.review_media.get_all.app_error", Other: "could not get review media"}
+	msgDeleteReviewMedia     = &i18n.Message{ID: "store.postgres.review_media.delete.app_error", Other: "could not delete review media"}
+)
+
+// BulkInsert inserts multiple review photos in the db
+func (s PgReviewMediaStore) BulkInsert(media []*model.ProductReviewMedia) *model.AppErr {
+	q := `INSERT INTO public.product_review_media (review_id, url, public_id, created_at) VALUES(:review_id, :url, :public_id, :created_at)`
+	if _, err := s.db.NamedExec(q, media); err != nil {
+		return model.NewAppErr("PgReviewMediaStore.BulkInsert", model.ErrInternal, locale.GetUserLocalizer("en"), msgBulkInsertReviewMedia, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// Get gets single review photo by id
+func (s PgReviewMediaStore) Get(rid, id int64) (*model.ProductReviewMedia, *model.AppErr) {
+	var m model.ProductReviewMedia
+	if err := s.db.Get(&m, "SELECT * FROM public.product_review_media WHERE review_id = $1 AND id = $2", rid, id); err != nil {
+		return nil, model.NewAppErr("PgReviewMediaStore.Get", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetReviewMedia, http.StatusInternalServerError, nil)
+	}
+	return &m, nil
+}
+
+// GetAll gets all photos of the review
+func (s PgReviewMediaStore) GetAll(rid int64) ([]*model.ProductReviewMedia, *model.AppErr) {
+	media := make([]*model.ProductReviewMedia, 0)
+	if err := s.db.Select(&media, "SELECT * FROM public.product_review_media WHERE review_id = $1 ORDER BY id", rid); err != nil {
+		return nil, model.NewAppErr("PgReviewMediaStore.GetAll", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetReviewMediaAll, http.StatusInternalServerError, nil)
+	}
+	return media, nil
+}
+
+// GetAllForReviews gets the photos of all given reviews
+func (s PgReviewMediaStore) GetAllForReviews(rids []int64) ([]*model.ProductReviewMedia, *model.AppErr) {
+	media := make([]*model.ProductReviewMedia, 0)
+	if len(rids) == 0 {
+		return media, nil
+	}
+
+	q, args, err := sqlx.In(`SELECT * FROM public.product_review_media WHERE review_id IN (?) ORDER BY id`, rids)
+	if err != nil {
+		return nil, model.NewAppErr("PgReviewMediaStore.GetAllForReviews", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetReviewMediaAll, http.StatusInternalServerError, nil)
+	}
+	if err := s.db.Select(&media, s.db.Rebind(q), args...); err != nil {
+		return nil, model.NewAppErr("PgReviewMediaStore.GetAllForReviews", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetReviewMediaAll, http.StatusInternalServerError, nil)
+	}
+	return media, nil
+}
+
+// GetAllForProductReviews gets the photos of the given reviews, only the reviews of the product are matched
+func (s PgReviewMediaStore) GetAllForProductReviews(pid int64, rids []int64) ([]*model.ProductReviewMedia, *model.AppErr) {
+	media := make([]*model.ProductReviewMedia, 0)
+	if len(rids) == 0 {
+		return media, nil
+	}
+
+	q, args, err := sqlx.In(`SELECT m.* FROM public.product_review_media m JOIN public.product_review r ON r.id = m.review_id WHERE r.product_id = ? AND m.review_id IN (?) ORDER BY m.id`, pid, rids)
+	if err != nil {
+		return nil, model.NewAppErr("PgReviewMediaStore.GetAllForProductReviews", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetReviewMediaAll, http.StatusInternalServerError, nil)
+	}
+	if err := s.db.Select(&media, s.db.Rebind(q), args...); err != nil {
+		return nil, model.NewAppErr("PgReviewMediaStore.GetAllForProductReviews", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetReviewMediaAll, http.StatusInternalServerError, nil)
+	}
+	return media, nil
+}
+
+// Delete hard deletes the review photo
+func (s PgReviewMediaStore) Delete(rid, id int64) *model.AppErr {
+	if _, err := s.db.NamedExec("DELETE FROM public.product_review_media WHERE review_id = :review_id AND id = :id", map[string]interface{}{"review_id": rid, "id": id}); err != nil {
+		return model.NewAppErr("PgReviewMediaStore.Delete", model.ErrInternal, locale.GetUserLocalizer("en"), msgDeleteReviewMedia, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
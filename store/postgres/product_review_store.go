@@ -26,14 +26,23 @@ var (
 	msgUpdateReview             = &i18n.Message{ID: "store.postgres.review.update.app_error", Other: "could not update review"}
 	msgBulkInsertReviews        = &i18n.Message{ID: "store.postgres.review.bulk.insert.app_error", Other: "could not bulk insert reviews"}
 	msgGetReview                = &i18n.Message{ID: "store.postgres.review.get.app_error", Other: "could not get the review"}
-	msgGetReviews               = &i18n.Message{ID: "store.postgres.review.get.app_error", Other: "could not get the reviews"}
+	msgGetReviews               = &i18n.Message{ID: "store.postgres.review.get_all.app_error", Other: "could not get the reviews"}
 	msgDeleteReview             = &i18n.Message{ID: "store.postgres.review.delete.app_error", Other: "could not delete review"}
 	msgBulkDeleteProductReviews = &i18n.Message{ID: "store.postgres.review.bulk_delete.app_error", Other: "could not bulk delete reviews"}
+	msgRefreshReviewRating      = &i18n.Message{ID: "store.postgres.review.refresh_rating.app_error", Other: "could not refresh product rating"}
+	msgUpdateReviewStatus       = &i18n.Message{ID: "store.postgres.review.update_status.app_error", Other: "could not update review status"}
+	msgVerifiedPurchase         = &i18n.Message{ID: "store.postgres.review.verified_purchase.app_error", Other: "could not check review purchase status"}
+	msgUniqueConstraintVote     = &i18n.Message{ID: "store.postgres.review.save_vote.unique_constraint.app_error", Other: "review already voted as helpful"}
+	msgSaveReviewVote           = &i18n.Message{ID: "store.postgres.review.save_vote.app_error", Other: "could not save review vote"}
+	msgDeleteReviewVote         = &i18n.Message{ID: "store.postgres.review.delete_vote.app_error", Other: "could not delete review vote"}
+	msgUniqueConstraintReport   = &i18n.Message{ID: "store.postgres.review.save_report.unique_constraint.app_error", Other: "review already reported"}
+	msgSaveReviewReport         = &i18n.Message{ID: "store.postgres.review.save_report.app_error", Other: "could not save review report"}
+	msgGetReviewReports         = &i18n.Message{ID: "store.postgres.review.get_reports.app_error", Other: "could not get the review reports"}
 )
 
 // BulkInsert inserts multiple reviews in the db
 func (s PgReviewStore) BulkInsert(reviews []*model.ProductReview) *model.AppErr {
-	q := `INSERT INTO product_review(user_id, product_id, rating, title, comment, created_at, updated_at) VALUES(:user_id, :product_id, :rating, :title, :comment, :created_at, :updated_at) RETURNING id`
+	q := `INSERT INTO product_review(user_id, product_id, rating, title, comment, is_verified_purchase, status, created_at, updated_at) VALUES(:user_id, :product_id, :rating, :title, :comment, :is_verified_purchase, :status, :created_at, :updated_at) RETURNING id`
 
 	if _, err := s.db.NamedExec(q, reviews); err != nil {
 		return model.NewAppErr("PgReviewStore.BulkInsert", model.ErrInternal, locale.GetUserLocalizer("en"), msgBulkInsertReviews, http.StatusInternalServerError, nil)
@@ -43,7 +52,7 @@ func (s PgReviewStore) BulkInsert(reviews []*model.ProductReview) *model.AppErr
 
 // Save inserts the new review in the db
 func (s PgReviewStore) Save(pid int64, review *model.ProductReview) (*model.ProductReview, *model.AppErr) {
-	q := `INSERT INTO product_review(user_id, product_id, rating, title, comment, created_at, updated_at) VALUES(:user_id, :product_id, :rating, :title, :comment, :created_at, :updated_at) RETURNING id`
+	q := `INSERT INTO product_review(user_id, product_id, rating, title, comment, is_verified_purchase, status, created_at, updated_at) VALUES(:user_id, :product_id, :rating, :title, :comment, :is_verified_purchase, :status, :created_at, :updated_at) RETURNING id`
 
 	var id int64
 	rows, err := s.db.NamedQuery(q, review)
@@ -85,8 +94,8 @@ func (s PgReviewStore) Get(pid, rid int64) (*model.ProductReview, *model.AppErr)
 	return rj.ToReview(), nil
 }
 
-// GetAll returns all reviews
-func (s PgReviewStore) GetAll(pid int64) ([]*model.ProductReview, *model.AppErr) {
+// GetAll returns all approved reviews for the product
+func (s PgReviewStore) GetAll(pid int64, sort string) ([]*model.ProductReview, *model.AppErr) {
 	q := `SELECT 
 	r.*,
 	u.id AS user_id,
@@ -97,10 +106,11 @@ func (s PgReviewStore) GetAll(pid int64) ([]*model.ProductReview, *model.AppErr)
 	u.avatar_public_id AS user_avatar_public_id
 	FROM product_review r 
 	LEFT JOIN public.user u ON r.user_id = u.id
-	WHERE r.product_id = $1`
+	WHERE r.product_id = $1 AND r.status = $2
+	ORDER BY ` + reviewsOrderBy(sort)
 
 	var rj []reviewJoin
-	if err := s.db.Select(&rj, q, pid); err != nil {
+	if err := s.db.Select(&rj, q, pid, model.ReviewStatusApproved); err != nil {
 		return nil, model.NewAppErr("PgReviewStore.GetAll", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetReviews, http.StatusInternalServerError, nil)
 	}
 
@@ -113,8 +123,8 @@ func (s PgReviewStore) GetAll(pid int64) ([]*model.ProductReview, *model.AppErr)
 
 // Update updates the review
 func (s PgReviewStore) Update(pid, rid int64, rev *model.ProductReview) (*model.ProductReview, *model.AppErr) {
-	q := `UPDATE product_review SET rating=:rating, title=:title, comment=:comment, updated_at=:updated_at WHERE product_id=:product_id AND id=:review_id`
-	m := map[string]interface{}{"product_id": pid, "review_id": rid, "rating": rev.Rating, "title": rev.Title, "comment": rev.Comment, "updated_at": rev.UpdatedAt}
+	q := `UPDATE product_review SET rating=:rating, title=:title, comment=:comment, status=:status, updated_at=:updated_at WHERE product_id=:product_id AND id=:review_id`
+	m := map[string]interface{}{"product_id": pid, "review_id": rid, "rating": rev.Rating, "title": rev.Title, "comment": rev.Comment, "status": rev.Status, "updated_at": rev.UpdatedAt}
 	if _, err := s.db.NamedExec(q, m); err != nil {
 		return nil, model.NewAppErr("PgReviewStore.Update", model.ErrInternal, locale.GetUserLocalizer("en"), msgUpdateReview, http.StatusInternalServerError, nil)
 	}
@@ -142,3 +152,156 @@ func (s PgReviewStore) BulkDelete(pid int64, ids []int) *model.AppErr {
 
 	return nil
 }
+
+// RefreshRating recalculates the product rating average, count and histogram from its reviews
+func (s PgReviewStore) RefreshRating(pid int64) *model.AppErr {
+	q := `UPDATE public.product p SET
+	rating_average = s.rating_average,
+	rating_count = s.rating_count,
+	rating_histogram = s.rating_histogram
+	FROM (
+		SELECT
+		COALESCE(ROUND(AVG(rating), 2), 0) AS rating_average,
+		COUNT(*) AS rating_count,
+		jsonb_build_object(
+			'1', COUNT(*) FILTER (WHERE rating = 1),
+			'2', COUNT(*) FILTER (WHERE rating = 2),
+			'3', COUNT(*) FILTER (WHERE rating = 3),
+			'4', COUNT(*) FILTER (WHERE rating = 4),
+			'5', COUNT(*) FILTER (WHERE rating = 5)
+		) AS rating_histogram
+		FROM public.product_review
+		WHERE product_id = $1 AND status = $2
+	) s
+	WHERE p.id = $1`
+
+	if _, err := s.db.Exec(q, pid, model.ReviewStatusApproved); err != nil {
+		return model.NewAppErr("PgReviewStore.RefreshRating", model.ErrInternal, locale.GetUserLocalizer("en"), msgRefreshReviewRating, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// GetAllByStatus returns the reviews of all products with the given moderation status
+func (s PgReviewStore) GetAllByStatus(status string, limit, offset int) ([]*model.ProductReview, *model.AppErr) {
+	q := `SELECT 
+	COUNT(*) OVER() AS total_count,
+	r.*,
+	u.id AS user_id,
+	u.first_name AS user_first_name,
+	u.last_name AS user_last_name,
+	u.username AS user_username,
+	u.avatar_url AS user_avatar_url,
+	u.avatar_public_id AS user_avatar_public_id
+	FROM product_review r 
+	LEFT JOIN public.user u ON r.user_id = u.id
+	WHERE r.status = $1
+	ORDER BY r.report_count DESC, r.created_at ASC
+	LIMIT $2 OFFSET $3`
+
+	var rj []reviewJoin
+	if err := s.db.Select(&rj, q, status, limit, offset); err != nil {
+		return nil, model.NewAppErr("PgReviewStore.GetAllByStatus", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetReviews, http.StatusInternalServerError, nil)
+	}
+
+	var reviews = make([]*model.ProductReview, 0)
+	for _, x := range rj {
+		reviews = append(reviews, x.ToReview())
+	}
+	return reviews, nil
+}
+
+// UpdateStatus sets the review moderation status
+func (s PgReviewStore) UpdateStatus(pid, rid int64, status string) *model.AppErr {
+	m := map[string]interface{}{"product_id": pid, "review_id": rid, "status": status}
+	if _, err := s.db.NamedExec("UPDATE product_review SET status=:status WHERE product_id=:product_id AND id=:review_id", m); err != nil {
+		return model.NewAppErr("PgReviewStore.UpdateStatus", model.ErrInternal, locale.GetUserLocalizer("en"), msgUpdateReviewStatus, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// IsVerifiedPurchase checks if the user has a successful order containing the product
+func (s PgReviewStore) IsVerifiedPurchase(uid, pid int64) (bool, *model.AppErr) {
+	q := `SELECT EXISTS (
+		SELECT 1 FROM public.order_detail od
+		LEFT JOIN public.order o ON od.order_id = o.id
+		WHERE o.user_id = $1 AND od.product_id = $2 AND o.status = $3
+	)`
+
+	var verified bool
+	if err := s.db.Get(&verified, q, uid, pid, model.OrderStatusSuccess.String()); err != nil {
+		return false, model.NewAppErr("PgReviewStore.IsVerifiedPurchase", model.ErrInternal, locale.GetUserLocalizer("en"), msgVerifiedPurchase, http.StatusInternalServerError, nil)
+	}
+	return verified, nil
+}
+
+// SaveVote marks the review as helpful for the user and bumps the helpful count
+func (s PgReviewStore) SaveVote(rid, uid int64) *model.AppErr {
+	q := `WITH v AS (
+		INSERT INTO public.product_review_vote(review_id, user_id, created_at) VALUES($1, $2, CURRENT_TIMESTAMP) RETURNING review_id
+	)
+	UPDATE public.product_review SET helpful_count = helpful_count + 1 WHERE id IN (SELECT review_id FROM v)`
+
+	if _, err := s.db.Exec(q, rid, uid); err != nil {
+		if IsUniqueConstraintViolationError(err) {
+			return model.NewAppErr("PgReviewStore.SaveVote", model.ErrConflict, locale.GetUserLocalizer("en"), msgUniqueConstraintVote, http.StatusConflict, nil)
+		}
+		return model.NewAppErr("PgReviewStore.SaveVote", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveReviewVote, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// DeleteVote removes the users helpful vote and decrements the helpful count
+func (s PgReviewStore) DeleteVote(rid, uid int64) *model.AppErr {
+	q := `WITH v AS (
+		DELETE FROM public.product_review_vote WHERE review_id = $1 AND user_id = $2 RETURNING review_id
+	)
+	UPDATE public.product_review SET helpful_count = helpful_count - 1 WHERE id IN (SELECT review_id FROM v)`
+
+	if _, err := s.db.Exec(q, rid, uid); err != nil {
+		return model.NewAppErr("PgReviewStore.DeleteVote", model.ErrInternal, locale.GetUserLocalizer("en"), msgDeleteReviewVote, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// SaveReport inserts the abuse report and bumps the review report count
+func (s PgReviewStore) SaveReport(report *model.ProductReviewReport) (*model.ProductReviewReport, *model.AppErr) {
+	q := `WITH rp AS (
+		INSERT INTO public.product_review_report(review_id, user_id, reason, created_at) VALUES($1, $2, $3, $4) RETURNING id, review_id
+	), up AS (
+		UPDATE public.product_review SET report_count = report_count + 1 WHERE id IN (SELECT review_id FROM rp)
+	)
+	SELECT id FROM rp`
+
+	var id int64
+	if err := s.db.Get(&id, q, report.ReviewID, report.UserID, report.Reason, report.CreatedAt); err != nil {
+		if IsUniqueConstraintViolationError(err) {
+			return nil, model.NewAppErr("PgReviewStore.SaveReport", model.ErrConflict, locale.GetUserLocalizer("en"), msgUniqueConstraintReport, http.StatusConflict, nil)
+		}
+		return nil, model.NewAppErr("PgReviewStore.SaveReport", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveReviewReport, http.StatusInternalServerError, nil)
+	}
+
+	report.ID = id
+	return report, nil
+}
+
+// GetReports gets all abuse reports for the review
+func (s PgReviewStore) GetReports(rid int64) ([]*model.ProductReviewReport, *model.AppErr) {
+	var reports = make([]*model.ProductReviewReport, 0)
+	if err := s.db.Select(&reports, "SELECT * FROM public.product_review_report WHERE review_id = $1 ORDER BY created_at DESC", rid); err != nil {
+		return nil, model.NewAppErr("PgReviewStore.GetReports", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetReviewReports, http.StatusInternalServerError, nil)
+	}
+	return reports, nil
+}
+
+func reviewsOrderBy(sort string) string {
+	switch sort {
+	case model.ReviewSortHelpful:
+		return "r.helpful_count DESC, r.created_at DESC"
+	case model.ReviewSortRatingHigh:
+		return "r.rating DESC, r.created_at DESC"
+	case model.ReviewSortRatingLow:
+		return "r.rating ASC, r.created_at DESC"
+	default:
+		return "r.created_at DESC"
+	}
+}
@@ -31,7 +31,7 @@ var (
 	msgGetProducts             = &i18n.Message{ID: "store.postgres.product.get_all.app_error", Other: "could not get products"}
 	msgUpdateProduct           = &i18n.Message{ID: "store.postgres.product.update.app_error", Other: "could not update product"}
 	msgDeleteProduct           = &i18n.Message{ID: "store.postgres.product.delete.app_error", Other: "could not delete product"}
-	msgInvalidColumn           = &i18n.Message{ID: "store.postgres.product.save.app_error", Other: "could not save product, invalid foreign key value"}
+	msgInvalidColumn           = &i18n.Message{ID: "store.postgres.product.save.foreign_key.app_error", Other: "could not save product, invalid foreign key value"}
 	msgGetPricing              = &i18n.Message{ID: "store.postgres.product.get_pricing.app_error", Other: "could not get latest pricing"}
 	msgSavePricing             = &i18n.Message{ID: "store.postgres.product.insert_pricing.app_error", Other: "could not insert pricing data"}
 	msgUpdatePricing           = &i18n.Message{ID: "store.postgres.product.update_pricing.app_error", Other: "could not update pricing data"}
@@ -57,21 +57,12 @@ func (s PgProductStore) BulkInsert(products []*model.Product) *model.AppErr {
 }
 
 // Save inserts the new product in the db
-func (s PgProductStore) Save(p *model.Product) (*model.Product, *model.AppErr) {
+func (s PgProductStore) Save(p *model.Product, events ...*model.Event) (*model.Product, *model.AppErr) {
 	q := `INSERT INTO public.product (name, brand_id, category_id, slug, image_url, image_public_id, description, in_stock, sku, is_featured, created_at, updated_at, properties)
 		VALUES (:name, :brand_id, :category_id, :slug, :image_url, :image_public_id, :description, :in_stock, :sku, :is_featured, :created_at, :updated_at, :properties) RETURNING id`
 
-	var id int64
-	rows, err := s.db.NamedQuery(q, p)
+	id, err := s.insertWithEvents(q, p, func(id int64) { p.ID = id }, events)
 	if err != nil {
-		return nil, model.NewAppErr("PgProductStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveProduct, http.StatusInternalServerError, nil)
-	}
-	defer rows.Close()
-	for rows.Next() {
-		rows.Scan(&id)
-	}
-
-	if err := rows.Err(); err != nil {
 		if IsForeignKeyConstraintViolationError(err) {
 			return nil, model.NewAppErr("PgProductStore.Save", model.ErrConflict, locale.GetUserLocalizer("en"), msgInvalidColumn, http.StatusInternalServerError, nil)
 		}
@@ -261,17 +252,17 @@ func (s PgProductStore) ListByIDS(ids []int64) ([]*model.Product, *model.AppErr)
 }
 
 // Update updates the product
-func (s PgProductStore) Update(id int64, p *model.Product) (*model.Product, *model.AppErr) {
+func (s PgProductStore) Update(id int64, p *model.Product, events ...*model.Event) (*model.Product, *model.AppErr) {
 	q := `UPDATE public.product SET brand_id=:brand_id, category_id=:category_id, name=:name, slug=:slug, image_url=:image_url, image_public_id=:image_public_id, description=:description, in_stock=:in_stock, sku=:sku, is_featured=:is_featured, updated_at=:updated_at, properties=:properties WHERE id=:id`
-	if _, err := s.db.NamedExec(q, p); err != nil {
+	if err := s.execWithEvents(q, p, id, events); err != nil {
 		return nil, model.NewAppErr("PgProductStore.Update", model.ErrInternal, locale.GetUserLocalizer("en"), msgUpdateProduct, http.StatusInternalServerError, nil)
 	}
 	return p, nil
 }
 
 // Delete hard deletes the product from db
-func (s PgProductStore) Delete(id int64) *model.AppErr {
-	if _, err := s.db.NamedExec(`DELETE FROM public.product WHERE id = :id`, map[string]interface{}{"id": id}); err != nil {
+func (s PgProductStore) Delete(id int64, events ...*model.Event) *model.AppErr {
+	if err := s.execWithEvents(`DELETE FROM public.product WHERE id = :id`, map[string]interface{}{"id": id}, id, events); err != nil {
 		return model.NewAppErr("PgProductStore.Delete", model.ErrInternal, locale.GetUserLocalizer("en"), msgDeleteProduct, http.StatusInternalServerError, nil)
 	}
 	return nil
@@ -449,12 +440,22 @@ func (s PgProductStore) GetReviews(id int64) ([]*model.ProductReview, *model.App
 	return reviews, nil
 }
 
-// Search returns all fulltext search product results
-func (s PgProductStore) Search(filter string) ([]*model.Product, *model.AppErr) {
-	q := `SELECT *, ts_rank(tsv, plainto_tsquery($1)) as rank FROM product_search_view WHERE tsv @@ plainto_tsquery($1) order by rank desc limit 200`
+// Search returns all fulltext search product results, the products also match by their translation to the locale
+func (s PgProductStore) Search(filter, lang string) ([]*model.Product, *model.AppErr) {
+	q := `SELECT v.*, GREATEST(ts_rank(v.tsv, query), COALESCE(ts_rank(t.tsv, query), 0)) AS rank
+	FROM product_search_view v
+	LEFT JOIN (
+		SELECT product_id, setweight(to_tsvector(name), 'A') || setweight(to_tsvector(coalesce(description, '')), 'B') AS tsv
+		FROM public.product_translation
+		WHERE locale = $2
+	) t ON t.product_id = v.id,
+	plainto_tsquery($1) query
+	WHERE v.tsv @@ query OR t.tsv @@ query
+	ORDER BY rank DESC
+	LIMIT 200`
 
 	var pj []productJoin
-	if err := s.db.Select(&pj, q, filter); err != nil {
+	if err := s.db.Select(&pj, q, filter, lang); err != nil {
 		return nil, model.NewAppErr("PgProductStore.Search", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetProducts, http.StatusInternalServerError, nil)
 	}
 
@@ -478,13 +479,16 @@ func (s PgProductStore) GetLatestPricing(pid int64) (*model.ProductPricing, *mod
 }
 
 // BulkDelete deletes products with given ids
-func (s PgProductStore) BulkDelete(ids []int) *model.AppErr {
+func (s PgProductStore) BulkDelete(ids []int, events ...*model.Event) *model.AppErr {
 	q, args, err := sqlx.In(`DELETE FROM product WHERE id IN (?)`, ids)
 	if err != nil {
 		return model.NewAppErr("PgProductStore.BulkDelete", model.ErrInternal, locale.GetUserLocalizer("en"), msgBulkDeleteProducts, http.StatusInternalServerError, nil)
 	}
 
-	if _, err := s.db.Exec(s.db.Rebind(q), args...); err != nil {
+	if err := s.inTxWithEvents(0, events, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(s.db.Rebind(q), args...)
+		return err
+	}); err != nil {
 		return model.NewAppErr("PgProductStore.BulkDelete", model.ErrInternal, locale.GetUserLocalizer("en"), msgBulkDeleteProducts, http.StatusInternalServerError, nil)
 	}
 
@@ -492,19 +496,17 @@ func (s PgProductStore) BulkDelete(ids []int) *model.AppErr {
 }
 
 // InsertPricing inserts the price info into product_pricing
-func (s PgProductStore) InsertPricing(pricing *model.ProductPricing) (*model.ProductPricing, *model.AppErr) {
+func (s PgProductStore) InsertPricing(pricing *model.ProductPricing, events ...*model.Event) (*model.ProductPricing, *model.AppErr) {
 	q := `INSERT INTO product_pricing(product_id, price, original_price, sale_starts, sale_ends) VALUES(:product_id, :price, :original_price, :sale_starts, :sale_ends) RETURNING id`
 
-	var id int64
-	rows, err := s.db.NamedQuery(q, pricing)
-	if err != nil {
-		return nil, model.NewAppErr("PgProductStore.InsertPricing", model.ErrInternal, locale.GetUserLocalizer("en"), msgSavePricing, http.StatusInternalServerError, nil)
-	}
-	defer rows.Close()
-	for rows.Next() {
-		rows.Scan(&id)
+	for _, e := range events {
+		if e.AggregateID == 0 {
+			e.AggregateID = pricing.ProductID
+		}
 	}
-	if err := rows.Err(); err != nil {
+
+	id, err := s.insertWithEvents(q, pricing, func(id int64) { pricing.PriceID = id }, events)
+	if err != nil {
 		if IsUniqueConstraintViolationError(err) {
 			return nil, model.NewAppErr("PgProductStore.InsertPricing", model.ErrConflict, locale.GetUserLocalizer("en"), msgUniqueConstraintProduct, http.StatusInternalServerError, nil)
 		}
@@ -566,7 +568,7 @@ func buildProductsFilterSearchQuery(queryString string, filters map[string][]str
 	specific := make(map[string][]string, 0)
 
 	for filter, val := range filters {
-		if filter == "page" || filter == "per_page" || filter == "category" || filter == "brand" || filter == "tag" || filter == "price_min" || filter == "price_max" {
+		if filter == "page" || filter == "per_page" || filter == "category" || filter == "brand" || filter == "tag" || filter == "price_min" || filter == "price_max" || filter == "rating_min" {
 			basic[filter] = val
 		} else {
 			specific[filter] = val
@@ -590,6 +592,12 @@ func buildProductsFilterSearchQuery(queryString string, filters map[string][]str
 		args = append(args, max[0])
 	}
 
+	// handle rating filter
+	if rating, ok := basic["rating_min"]; ok {
+		query += " AND p.rating_average >= ?\n"
+		args = append(args, rating[0])
+	}
+
 	// handle brand filters
 	if brand, ok := basic["brand"]; ok {
 		query += " AND b.slug IN (?)\n"
@@ -0,0 +1,150 @@
+package postgres
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/store"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// PgRoleStore is the postgres implementation
+type PgRoleStore struct {
+	PgStore
+}
+
+// NewPgRoleStore creates the new role store
+func NewPgRoleStore(pgst *PgStore) store.RoleStore {
+	return &PgRoleStore{*pgst}
+}
+
+var (
+	msgSaveRole             = &i18n.Message{ID: "store.postgres.role.save.app_error", Other: "could not save role"}
+	msgUniqueConstraintRole = &i18n.Message{ID: "store.postgres.role.save.unique_constraint.app_error", Other: "role with the given name already exists"}
+	msgGetRole              = &i18n.Message{ID: "store.postgres.role.get.app_error", Other: "could not get role"}
+	msgGetRoles             = &i18n.Message{ID: "store.postgres.role.get_all.app_error", Other: "could not get roles"}
+	msgUpdateRole           = &i18n.Message{ID: "store.postgres.role.update.app_error", Other: "could not update role"}
+	msgDeleteRole           = &i18n.Message{ID: "store.postgres.role.delete.app_error", Other: "could not delete role"}
+	msgGetUserRoles         = &i18n.Message{ID: "store.postgres.role.get_by_user.app_error", Other: "could not get user roles"}
+	msgAssignRole           = &i18n.Message{ID: "store.postgres.role.assign.app_error", Other: "could not assign role"}
+	msgAssignRoleForeignKey = &i18n.Message{ID: "store.postgres.role.assign.foreign_key.app_error", Other: "user or role does not exist"}
+	msgUnassignRole         = &i18n.Message{ID: "store.postgres.role.unassign.app_error", Other: "could not unassign role"}
+	msgGetUserPermissions   = &i18n.Message{ID: "store.postgres.role.get_user_permissions.app_error", Other: "could not get user permissions"}
+)
+
+// Save creates the new role
+func (s PgRoleStore) Save(r *model.Role) (*model.Role, *model.AppErr) {
+	q := `INSERT INTO public.role (name, description, permissions, created_at, updated_at) VALUES (:name, :description, :permissions, :created_at, :updated_at) RETURNING id`
+
+	var id int64
+	rows, err := s.db.NamedQuery(q, r)
+	if err != nil {
+		if IsUniqueConstraintViolationError(err) {
+			return nil, model.NewAppErr("PgRoleStore.Save", model.ErrConflict, locale.GetUserLocalizer("en"), msgUniqueConstraintRole, http.StatusConflict, nil)
+		}
+		return nil, model.NewAppErr("PgRoleStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveRole, http.StatusInternalServerError, nil)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		rows.Scan(&id)
+	}
+	if err := rows.Err(); err != nil {
+		if IsUniqueConstraintViolationError(err) {
+			return nil, model.NewAppErr("PgRoleStore.Save", model.ErrConflict, locale.GetUserLocalizer("en"), msgUniqueConstraintRole, http.StatusConflict, nil)
+		}
+		return nil, model.NewAppErr("PgRoleStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveRole, http.StatusInternalServerError, nil)
+	}
+
+	r.ID = id
+	return r, nil
+}
+
+// Get gets the role by id, nil is returned if the role doesn't exist
+func (s PgRoleStore) Get(id int64) (*model.Role, *model.AppErr) {
+	var r model.Role
+	if err := s.db.Get(&r, `SELECT * FROM public.role WHERE id = $1`, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, model.NewAppErr("PgRoleStore.Get", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetRole, http.StatusInternalServerError, nil)
+	}
+	return &r, nil
+}
+
+// GetAll gets all roles
+func (s PgRoleStore) GetAll(limit, offset int) ([]*model.Role, *model.AppErr) {
+	var roles = make([]*model.Role, 0)
+	if err := s.db.Select(&roles, `SELECT COUNT(*) OVER() AS total_count, * FROM public.role ORDER BY name LIMIT $1 OFFSET $2`, limit, offset); err != nil {
+		return nil, model.NewAppErr("PgRoleStore.GetAll", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetRoles, http.StatusInternalServerError, nil)
+	}
+	return roles, nil
+}
+
+// Update updates the role
+func (s PgRoleStore) Update(id int64, r *model.Role) (*model.Role, *model.AppErr) {
+	q := `UPDATE public.role SET name=:name, description=:description, permissions=:permissions, updated_at=:updated_at WHERE id=:id`
+	if _, err := s.db.NamedExec(q, r); err != nil {
+		if IsUniqueConstraintViolationError(err) {
+			return nil, model.NewAppErr("PgRoleStore.Update", model.ErrConflict, locale.GetUserLocalizer("en"), msgUniqueConstraintRole, http.StatusConflict, nil)
+		}
+		return nil, model.NewAppErr("PgRoleStore.Update", model.ErrInternal, locale.GetUserLocalizer("en"), msgUpdateRole, http.StatusInternalServerError, nil)
+	}
+	return r, nil
+}
+
+// Delete deletes the role together with its assignments
+func (s PgRoleStore) Delete(id int64) *model.AppErr {
+	if _, err := s.db.Exec(`DELETE FROM public.role WHERE id = $1`, id); err != nil {
+		return model.NewAppErr("PgRoleStore.Delete", model.ErrInternal, locale.GetUserLocalizer("en"), msgDeleteRole, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// GetByUserID gets the roles assigned to the user
+func (s PgRoleStore) GetByUserID(uid int64) ([]*model.Role, *model.AppErr) {
+	q := `SELECT r.* FROM public.role r JOIN public.user_role ur ON ur.role_id = r.id WHERE ur.user_id = $1 ORDER BY r.name`
+
+	var roles = make([]*model.Role, 0)
+	if err := s.db.Select(&roles, q, uid); err != nil {
+		return nil, model.NewAppErr("PgRoleStore.GetByUserID", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetUserRoles, http.StatusInternalServerError, nil)
+	}
+	return roles, nil
+}
+
+// Assign assigns the role to the user, assigning the same role again does nothing
+func (s PgRoleStore) Assign(uid, rid int64) *model.AppErr {
+	q := `INSERT INTO public.user_role (user_id, role_id, created_at) VALUES ($1, $2, $3) ON CONFLICT (user_id, role_id) DO NOTHING`
+	if _, err := s.db.Exec(q, uid, rid, time.Now()); err != nil {
+		if IsForeignKeyConstraintViolationError(err) {
+			return model.NewAppErr("PgRoleStore.Assign", model.ErrNotFound, locale.GetUserLocalizer("en"), msgAssignRoleForeignKey, http.StatusNotFound, nil)
+		}
+		return model.NewAppErr("PgRoleStore.Assign", model.ErrInternal, locale.GetUserLocalizer("en"), msgAssignRole, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// Unassign removes the role from the user
+func (s PgRoleStore) Unassign(uid, rid int64) *model.AppErr {
+	if _, err := s.db.Exec(`DELETE FROM public.user_role WHERE user_id = $1 AND role_id = $2`, uid, rid); err != nil {
+		return model.NewAppErr("PgRoleStore.Unassign", model.ErrInternal, locale.GetUserLocalizer("en"), msgUnassignRole, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// GetUserPermissions gets the permissions granted by all roles of the user
+func (s PgRoleStore) GetUserPermissions(uid int64) ([]string, *model.AppErr) {
+	q := `SELECT DISTINCT unnest(r.permissions) AS permission
+	FROM public.role r
+	JOIN public.user_role ur ON ur.role_id = r.id
+	WHERE ur.user_id = $1
+	ORDER BY permission`
+
+	var permissions = make([]string, 0)
+	if err := s.db.Select(&permissions, q, uid); err != nil {
+		return nil, model.NewAppErr("PgRoleStore.GetUserPermissions", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetUserPermissions, http.StatusInternalServerError, nil)
+	}
+	return permissions, nil
+}
@@ -73,6 +73,9 @@ func (pj *productJoin) ToProduct() *model.Product {
 		CreatedAt:         pj.CreatedAt,
 		UpdatedAt:         pj.UpdatedAt,
 		Properties:        pj.Properties,
+		RatingAverage:     pj.RatingAverage,
+		RatingCount:       pj.RatingCount,
+		RatingHistogram:   pj.RatingHistogram,
 		ProductPricing: &model.ProductPricing{
 			PriceID:       pj.PID,
 			ProductID:     pj.PProductID,
@@ -140,14 +143,19 @@ type reviewJoin struct {
 
 func (rj *reviewJoin) ToReview() *model.ProductReview {
 	return &model.ProductReview{
-		ID:        rj.ID,
-		UserID:    rj.UID,
-		ProductID: rj.ProductID,
-		Rating:    rj.Rating,
-		Title:     rj.Title,
-		Comment:   rj.Comment,
-		CreatedAt: rj.CreatedAt,
-		UpdatedAt: rj.UpdatedAt,
+		TotalRecordsCount:  rj.TotalRecordsCount,
+		ID:                 rj.ID,
+		UserID:             rj.UID,
+		ProductID:          rj.ProductID,
+		Rating:             rj.Rating,
+		Title:              rj.Title,
+		Comment:            rj.Comment,
+		IsVerifiedPurchase: rj.IsVerifiedPurchase,
+		Status:             rj.Status,
+		HelpfulCount:       rj.HelpfulCount,
+		ReportCount:        rj.ReportCount,
+		CreatedAt:          rj.CreatedAt,
+		UpdatedAt:          rj.UpdatedAt,
 		User: &model.User{
 			ID:             rj.UID,
 			FirstName:      rj.UFirstName,
@@ -168,3 +176,87 @@ type UserJoin struct {
 	UAvatarURL      *string `db:"user_avatar_url"`
 	UAvatarPublicID *string `db:"user_avatar_public_id"`
 }
+
+// questionJoin is temp join type
+type questionJoin struct {
+	*model.ProductQuestion
+	*UserJoin
+}
+
+func (qj *questionJoin) ToQuestion() *model.ProductQuestion {
+	return &model.ProductQuestion{
+		TotalRecordsCount: qj.TotalRecordsCount,
+		ID:                qj.ID,
+		ProductID:         qj.ProductID,
+		UserID:            qj.UID,
+		Body:              qj.Body,
+		Status:            qj.Status,
+		AnswerCount:       qj.AnswerCount,
+		CreatedAt:         qj.CreatedAt,
+		UpdatedAt:         qj.UpdatedAt,
+		User: &model.User{
+			ID:             qj.UID,
+			FirstName:      qj.UFirstName,
+			LastName:       qj.ULastName,
+			Username:       qj.UUsername,
+			AvatarURL:      qj.UAvatarURL,
+			AvatarPublicID: qj.UAvatarPublicID,
+		},
+	}
+}
+
+// answerJoin is temp join type
+type answerJoin struct {
+	*model.ProductAnswer
+	*UserJoin
+}
+
+func (aj *answerJoin) ToAnswer() *model.ProductAnswer {
+	return &model.ProductAnswer{
+		TotalRecordsCount:  aj.TotalRecordsCount,
+		ID:                 aj.ID,
+		QuestionID:         aj.QuestionID,
+		UserID:             aj.UID,
+		Body:               aj.Body,
+		IsAdmin:            aj.IsAdmin,
+		IsVerifiedPurchase: aj.IsVerifiedPurchase,
+		Status:             aj.Status,
+		UpvoteCount:        aj.UpvoteCount,
+		CreatedAt:          aj.CreatedAt,
+		UpdatedAt:          aj.UpdatedAt,
+		User: &model.User{
+			ID:             aj.UID,
+			FirstName:      aj.UFirstName,
+			LastName:       aj.ULastName,
+			Username:       aj.UUsername,
+			AvatarURL:      aj.UAvatarURL,
+			AvatarPublicID: aj.UAvatarPublicID,
+		},
+	}
+}
+
+// wishlistItemJoin is temp join type
+type wishlistItemJoin struct {
+	WIID                int64     `db:"wishlist_item_id"`
+	WIWishlistID        int64     `db:"wishlist_item_wishlist_id"`
+	WIQuantity          int       `db:"wishlist_item_quantity"`
+	WINote              *string   `db:"wishlist_item_note"`
+	WINotifyBackInStock bool      `db:"wishlist_item_notify_back_in_stock"`
+	WINotifyPriceDrop   bool      `db:"wishlist_item_notify_price_drop"`
+	WICreatedAt         time.Time `db:"wishlist_item_created_at"`
+	productJoin
+}
+
+func (wj *wishlistItemJoin) ToWishlistItem() *model.WishlistItem {
+	return &model.WishlistItem{
+		ID:                wj.WIID,
+		WishlistID:        wj.WIWishlistID,
+		ProductID:         wj.ID,
+		Quantity:          wj.WIQuantity,
+		Note:              wj.WINote,
+		NotifyBackInStock: wj.WINotifyBackInStock,
+		NotifyPriceDrop:   wj.WINotifyPriceDrop,
+		CreatedAt:         wj.WICreatedAt,
+		Product:           wj.ToProduct(),
+	}
+}
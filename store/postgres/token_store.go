@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/dankobgd/ecommerce-shop/model"
 	"github.com/dankobgd/ecommerce-shop/store"
@@ -16,6 +17,11 @@ var (
 	msgRemoveAllTokensType = &i18n.Message{ID: "store.postgres.token.RemoveAllTokensByType.app_error", Other: "could not remove all tokens by type"}
 )
 
+var (
+	msgCountExpiredTokens  = &i18n.Message{ID: "store.postgres.token.count_expired.app_error", Other: "could not count expired tokens"}
+	msgDeleteExpiredTokens = &i18n.Message{ID: "store.postgres.token.delete_expired.app_error", Other: "could not delete expired tokens"}
+)
+
 // PgTokenStore is the postgres implementation
 type PgTokenStore struct {
 	PgStore
@@ -67,3 +73,22 @@ func (s PgTokenStore) RemoveByType(tokenType model.TokenType) *model.AppErr {
 	}
 	return nil
 }
+
+// CountExpired counts the tokens that expired before the given time
+func (s PgTokenStore) CountExpired(before time.Time) (int64, *model.AppErr) {
+	var count int64
+	if err := s.db.Get(&count, "SELECT COUNT(*) FROM public.token WHERE expires_at < $1", before); err != nil {
+		return 0, model.NewAppErr("PgTokenStore.CountExpired", model.ErrInternal, locale.GetUserLocalizer("en"), msgCountExpiredTokens, http.StatusInternalServerError, nil)
+	}
+	return count, nil
+}
+
+// DeleteExpired deletes the tokens that expired before the given time
+func (s PgTokenStore) DeleteExpired(before time.Time) (int64, *model.AppErr) {
+	res, err := s.db.Exec("DELETE FROM public.token WHERE expires_at < $1", before)
+	if err != nil {
+		return 0, model.NewAppErr("PgTokenStore.DeleteExpired", model.ErrInternal, locale.GetUserLocalizer("en"), msgDeleteExpiredTokens, http.StatusInternalServerError, nil)
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
@@ -0,0 +1,166 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/store"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/jmoiron/sqlx"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// PgTranslationStore is the postgres implementation
+type PgTranslationStore struct {
+	PgStore
+}
+
+// NewPgTranslationStore creates the new translation store
+func NewPgTranslationStore(pgst *PgStore) store.TranslationStore {
+	return &PgTranslationStore{*pgst}
+}
+
+var (
+	msgSaveTranslation             = &i18n.Message{ID: "store.postgres.translation.save.app_error", Other: "could not save translation"}
+	msgUniqueConstraintTranslation = &i18n.Message{ID: "store.postgres.translation.save.unique_constraint.app_error", Other: "translation slug is already used by another resource"}
+	msgGetTranslation              = &i18n.Message{ID: "store.postgres.translation.get.app_error", Other: "could not get translation"}
+	msgGetTranslations             = &i18n.Message{ID: "store.postgres.translation.get_all.app_error", Other: "could not get translations"}
+	msgDeleteTranslation           = &i18n.Message{ID: "store.postgres.translation.delete.app_error", Other: "could not delete translation"}
+	msgResolveTranslationSlug      = &i18n.Message{ID: "store.postgres.translation.resolve_slug.app_error", Other: "could not resolve slug"}
+)
+
+// translationTable is the translation table of the resource and its foreign key column,
+// the resource is validated by the app so it's safe to put in the queries
+type translationTable struct {
+	resource string
+	table    string
+	column   string
+}
+
+func translationTableFor(resource string) translationTable {
+	return translationTable{
+		resource: resource,
+		table:    "public." + resource + "_translation",
+		column:   resource + "_id",
+	}
+}
+
+func (t translationTable) columns() string {
+	return t.column + ` AS resource_id, locale, name, slug, COALESCE(description, '') AS description, created_at, updated_at`
+}
+
+func (t translationTable) withResource(translations []*model.Translation) []*model.Translation {
+	for _, x := range translations {
+		x.Resource = t.resource
+	}
+	return translations
+}
+
+// Save creates the translation or replaces the existing one for the same locale
+func (s PgTranslationStore) Save(t *model.Translation) (*model.Translation, *model.AppErr) {
+	tt := translationTableFor(t.Resource)
+	q := fmt.Sprintf(`INSERT INTO %s (%s, locale, name, slug, description, created_at, updated_at)
+	VALUES (:resource_id, :locale, :name, :slug, :description, :created_at, :updated_at)
+	ON CONFLICT (%s, locale) DO UPDATE SET name = EXCLUDED.name, slug = EXCLUDED.slug, description = EXCLUDED.description, updated_at = EXCLUDED.updated_at
+	RETURNING created_at`, tt.table, tt.column, tt.column)
+
+	rows, err := s.db.NamedQuery(q, t)
+	if err != nil {
+		if IsUniqueConstraintViolationError(err) {
+			return nil, model.NewAppErr("PgTranslationStore.Save", model.ErrConflict, locale.GetUserLocalizer("en"), msgUniqueConstraintTranslation, http.StatusConflict, nil)
+		}
+		return nil, model.NewAppErr("PgTranslationStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveTranslation, http.StatusInternalServerError, nil)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		rows.Scan(&t.CreatedAt)
+	}
+	if err := rows.Err(); err != nil {
+		if IsUniqueConstraintViolationError(err) {
+			return nil, model.NewAppErr("PgTranslationStore.Save", model.ErrConflict, locale.GetUserLocalizer("en"), msgUniqueConstraintTranslation, http.StatusConflict, nil)
+		}
+		return nil, model.NewAppErr("PgTranslationStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveTranslation, http.StatusInternalServerError, nil)
+	}
+	return t, nil
+}
+
+// Get gets the resource translation, nil is returned if the resource is not translated to the locale
+func (s PgTranslationStore) Get(resource string, id int64, lang string) (*model.Translation, *model.AppErr) {
+	tt := translationTableFor(resource)
+	q := fmt.Sprintf(`SELECT %s FROM %s WHERE %s = $1 AND locale = $2`, tt.columns(), tt.table, tt.column)
+
+	var t model.Translation
+	if err := s.db.Get(&t, q, id, lang); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, model.NewAppErr("PgTranslationStore.Get", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetTranslation, http.StatusInternalServerError, nil)
+	}
+	t.Resource = resource
+	return &t, nil
+}
+
+// GetAll gets all translations of the resource
+func (s PgTranslationStore) GetAll(resource string, id int64) ([]*model.Translation, *model.AppErr) {
+	tt := translationTableFor(resource)
+	q := fmt.Sprintf(`SELECT %s FROM %s WHERE %s = $1 ORDER BY locale`, tt.columns(), tt.table, tt.column)
+
+	var translations = make([]*model.Translation, 0)
+	if err := s.db.Select(&translations, q, id); err != nil {
+		return nil, model.NewAppErr("PgTranslationStore.GetAll", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetTranslations, http.StatusInternalServerError, nil)
+	}
+	return tt.withResource(translations), nil
+}
+
+// GetForResources gets the translations of the resources to the locale
+func (s PgTranslationStore) GetForResources(resource string, ids []int64, lang string) ([]*model.Translation, *model.AppErr) {
+	var translations = make([]*model.Translation, 0)
+	if len(ids) == 0 {
+		return translations, nil
+	}
+
+	tt := translationTableFor(resource)
+	q, args, err := sqlx.In(fmt.Sprintf(`SELECT %s FROM %s WHERE %s IN (?) AND locale = ?`, tt.columns(), tt.table, tt.column), ids, lang)
+	if err != nil {
+		return nil, model.NewAppErr("PgTranslationStore.GetForResources", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetTranslations, http.StatusInternalServerError, nil)
+	}
+	if err := s.db.Select(&translations, s.db.Rebind(q), args...); err != nil {
+		return nil, model.NewAppErr("PgTranslationStore.GetForResources", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetTranslations, http.StatusInternalServerError, nil)
+	}
+	return tt.withResource(translations), nil
+}
+
+// Delete deletes the resource translation
+func (s PgTranslationStore) Delete(resource string, id int64, lang string) *model.AppErr {
+	tt := translationTableFor(resource)
+	q := fmt.Sprintf(`DELETE FROM %s WHERE %s = $1 AND locale = $2`, tt.table, tt.column)
+
+	if _, err := s.db.Exec(q, id, lang); err != nil {
+		return model.NewAppErr("PgTranslationStore.Delete", model.ErrInternal, locale.GetUserLocalizer("en"), msgDeleteTranslation, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// ResolveSlug finds the resource id by the slug, the slug of the locale wins over the default slug
+// and that one over the slugs of the other locales, 0 is returned if there is no match
+func (s PgTranslationStore) ResolveSlug(resource, slug, lang string) (int64, *model.AppErr) {
+	tt := translationTableFor(resource)
+	q := fmt.Sprintf(`SELECT id FROM (
+		SELECT %s AS id, CASE WHEN locale = $2 THEN 0 ELSE 2 END AS rank, locale FROM %s WHERE slug = $1
+		UNION ALL
+		SELECT id, 1 AS rank, '' AS locale FROM public.%s WHERE slug = $1
+	) s
+	ORDER BY rank, locale, id
+	LIMIT 1`, tt.column, tt.table, tt.resource)
+
+	var id int64
+	if err := s.db.Get(&id, q, slug, lang); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, model.NewAppErr("PgTranslationStore.ResolveSlug", model.ErrInternal, locale.GetUserLocalizer("en"), msgResolveTranslationSlug, http.StatusInternalServerError, nil)
+	}
+	return id, nil
+}
@@ -0,0 +1,159 @@
+package postgres
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/store"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/jmoiron/sqlx"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// PgTwoFactorStore is the postgres implementation
+type PgTwoFactorStore struct {
+	PgStore
+}
+
+// NewPgTwoFactorStore creates the new two factor store
+func NewPgTwoFactorStore(pgst *PgStore) store.TwoFactorStore {
+	return &PgTwoFactorStore{*pgst}
+}
+
+var (
+	msgSaveTwoFactor        = &i18n.Message{ID: "store.postgres.two_factor.save.app_error", Other: "could not save two-factor authentication"}
+	msgGetTwoFactor         = &i18n.Message{ID: "store.postgres.two_factor.get.app_error", Other: "could not get two-factor authentication"}
+	msgEnableTwoFactor      = &i18n.Message{ID: "store.postgres.two_factor.enable.app_error", Other: "could not enable two-factor authentication"}
+	msgDeleteTwoFactor      = &i18n.Message{ID: "store.postgres.two_factor.delete.app_error", Other: "could not disable two-factor authentication"}
+	msgUseTwoFactorStep     = &i18n.Message{ID: "store.postgres.two_factor.use_step.app_error", Other: "could not verify two-factor code"}
+	msgReplaceRecoveryCodes = &i18n.Message{ID: "store.postgres.two_factor.replace_recovery_codes.app_error", Other: "could not save recovery codes"}
+	msgUseRecoveryCode      = &i18n.Message{ID: "store.postgres.two_factor.use_recovery_code.app_error", Other: "could not verify recovery code"}
+	msgCountRecoveryCodes   = &i18n.Message{ID: "store.postgres.two_factor.count_recovery_codes.app_error", Other: "could not count recovery codes"}
+)
+
+// Save saves the new pending enrollment, the previous pending enrollment is replaced
+func (s PgTwoFactorStore) Save(tf *model.TwoFactor) *model.AppErr {
+	q := `INSERT INTO public.user_two_factor (user_id, secret, enabled, last_used_step, enabled_at, created_at, updated_at)
+	VALUES (:user_id, :secret, false, 0, NULL, :created_at, :updated_at)
+	ON CONFLICT (user_id) DO UPDATE SET secret = :secret, enabled = false, last_used_step = 0, enabled_at = NULL, updated_at = :updated_at`
+
+	if _, err := s.db.NamedExec(q, tf); err != nil {
+		return model.NewAppErr("PgTwoFactorStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveTwoFactor, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// Get gets the user's enrollment, nil is returned if the user never enrolled
+func (s PgTwoFactorStore) Get(userID int64) (*model.TwoFactor, *model.AppErr) {
+	var tf model.TwoFactor
+	if err := s.db.Get(&tf, `SELECT * FROM public.user_two_factor WHERE user_id = $1`, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, model.NewAppErr("PgTwoFactorStore.Get", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetTwoFactor, http.StatusInternalServerError, nil)
+	}
+	return &tf, nil
+}
+
+// Enable enables the enrollment and replaces the recovery codes
+func (s PgTwoFactorStore) Enable(userID, step int64, codeHashes []string) *model.AppErr {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return model.NewAppErr("PgTwoFactorStore.Enable", model.ErrInternal, locale.GetUserLocalizer("en"), msgEnableTwoFactor, http.StatusInternalServerError, nil)
+	}
+
+	now := time.Now()
+	if _, err := tx.Exec(`UPDATE public.user_two_factor SET enabled = true, enabled_at = $2, last_used_step = $3, updated_at = $2 WHERE user_id = $1`, userID, now, step); err != nil {
+		tx.Rollback()
+		return model.NewAppErr("PgTwoFactorStore.Enable", model.ErrInternal, locale.GetUserLocalizer("en"), msgEnableTwoFactor, http.StatusInternalServerError, nil)
+	}
+	if err := replaceRecoveryCodes(tx, userID, codeHashes); err != nil {
+		tx.Rollback()
+		return model.NewAppErr("PgTwoFactorStore.Enable", model.ErrInternal, locale.GetUserLocalizer("en"), msgEnableTwoFactor, http.StatusInternalServerError, nil)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return model.NewAppErr("PgTwoFactorStore.Enable", model.ErrInternal, locale.GetUserLocalizer("en"), msgEnableTwoFactor, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// Delete disables the two-factor authentication and removes the recovery codes
+func (s PgTwoFactorStore) Delete(userID int64) *model.AppErr {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return model.NewAppErr("PgTwoFactorStore.Delete", model.ErrInternal, locale.GetUserLocalizer("en"), msgDeleteTwoFactor, http.StatusInternalServerError, nil)
+	}
+	if _, err := tx.Exec(`DELETE FROM public.user_recovery_code WHERE user_id = $1`, userID); err != nil {
+		tx.Rollback()
+		return model.NewAppErr("PgTwoFactorStore.Delete", model.ErrInternal, locale.GetUserLocalizer("en"), msgDeleteTwoFactor, http.StatusInternalServerError, nil)
+	}
+	if _, err := tx.Exec(`DELETE FROM public.user_two_factor WHERE user_id = $1`, userID); err != nil {
+		tx.Rollback()
+		return model.NewAppErr("PgTwoFactorStore.Delete", model.ErrInternal, locale.GetUserLocalizer("en"), msgDeleteTwoFactor, http.StatusInternalServerError, nil)
+	}
+	if err := tx.Commit(); err != nil {
+		return model.NewAppErr("PgTwoFactorStore.Delete", model.ErrInternal, locale.GetUserLocalizer("en"), msgDeleteTwoFactor, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// UseStep records the time step of the accepted code, false is returned if the same or a later step was already used
+func (s PgTwoFactorStore) UseStep(userID, step int64) (bool, *model.AppErr) {
+	res, err := s.db.Exec(`UPDATE public.user_two_factor SET last_used_step = $2 WHERE user_id = $1 AND last_used_step < $2`, userID, step)
+	if err != nil {
+		return false, model.NewAppErr("PgTwoFactorStore.UseStep", model.ErrInternal, locale.GetUserLocalizer("en"), msgUseTwoFactorStep, http.StatusInternalServerError, nil)
+	}
+	n, _ := res.RowsAffected()
+	return n == 1, nil
+}
+
+// ReplaceRecoveryCodes replaces all recovery codes of the user
+func (s PgTwoFactorStore) ReplaceRecoveryCodes(userID int64, codeHashes []string) *model.AppErr {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return model.NewAppErr("PgTwoFactorStore.ReplaceRecoveryCodes", model.ErrInternal, locale.GetUserLocalizer("en"), msgReplaceRecoveryCodes, http.StatusInternalServerError, nil)
+	}
+	if err := replaceRecoveryCodes(tx, userID, codeHashes); err != nil {
+		tx.Rollback()
+		return model.NewAppErr("PgTwoFactorStore.ReplaceRecoveryCodes", model.ErrInternal, locale.GetUserLocalizer("en"), msgReplaceRecoveryCodes, http.StatusInternalServerError, nil)
+	}
+	if err := tx.Commit(); err != nil {
+		return model.NewAppErr("PgTwoFactorStore.ReplaceRecoveryCodes", model.ErrInternal, locale.GetUserLocalizer("en"), msgReplaceRecoveryCodes, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+func replaceRecoveryCodes(tx *sqlx.Tx, userID int64, codeHashes []string) error {
+	if _, err := tx.Exec(`DELETE FROM public.user_recovery_code WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, h := range codeHashes {
+		if _, err := tx.Exec(`INSERT INTO public.user_recovery_code (user_id, code_hash, created_at) VALUES ($1, $2, $3)`, userID, h, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UseRecoveryCode marks the unused recovery code as used, false is returned if there is no such unused code
+func (s PgTwoFactorStore) UseRecoveryCode(userID int64, codeHash string) (bool, *model.AppErr) {
+	res, err := s.db.Exec(`UPDATE public.user_recovery_code SET used_at = $3 WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`, userID, codeHash, time.Now())
+	if err != nil {
+		return false, model.NewAppErr("PgTwoFactorStore.UseRecoveryCode", model.ErrInternal, locale.GetUserLocalizer("en"), msgUseRecoveryCode, http.StatusInternalServerError, nil)
+	}
+	n, _ := res.RowsAffected()
+	return n == 1, nil
+}
+
+// CountRecoveryCodes counts the unused recovery codes of the user
+func (s PgTwoFactorStore) CountRecoveryCodes(userID int64) (int, *model.AppErr) {
+	var count int
+	if err := s.db.Get(&count, `SELECT COUNT(*) FROM public.user_recovery_code WHERE user_id = $1 AND used_at IS NULL`, userID); err != nil {
+		return 0, model.NewAppErr("PgTwoFactorStore.CountRecoveryCodes", model.ErrInternal, locale.GetUserLocalizer("en"), msgCountRecoveryCodes, http.StatusInternalServerError, nil)
+	}
+	return count, nil
+}
@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/store"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// PgUserIdentityStore is the postgres implementation
+type PgUserIdentityStore struct {
+	PgStore
+}
+
+// NewPgUserIdentityStore creates the new user identity store
+func NewPgUserIdentityStore(pgst *PgStore) store.UserIdentityStore {
+	return &PgUserIdentityStore{*pgst}
+}
+
+var (
+	msgSaveUserIdentity     = &i18n.Message{ID: "store.postgres.user_identity.save.app_error", Other: "could not link the account"}
+	msgUniqueUserIdentity   = &i18n.Message{ID: "store.postgres.user_identity.save.unique.app_error", Other: "the account is already linked"}
+	msgGetUserIdentity      = &i18n.Message{ID: "store.postgres.user_identity.get.app_error", Other: "could not get the linked account"}
+	msgGetUserIdentities    = &i18n.Message{ID: "store.postgres.user_identity.get_all.app_error", Other: "could not get the linked accounts"}
+	msgUpdateUserIdentity   = &i18n.Message{ID: "store.postgres.user_identity.update.app_error", Other: "could not update the linked account"}
+	msgDeleteUserIdentity   = &i18n.Message{ID: "store.postgres.user_identity.delete.app_error", Other: "could not unlink the account"}
+	msgUserIdentityNotFound = &i18n.Message{ID: "store.postgres.user_identity.not_found.app_error", Other: "the account is not linked"}
+)
+
+// Save links the provider account to the user
+func (s PgUserIdentityStore) Save(ui *model.UserIdentity) (*model.UserIdentity, *model.AppErr) {
+	q := `INSERT INTO public.user_identity (user_id, provider, subject, email, created_at, last_login_at) VALUES (:user_id, :provider, :subject, :email, :created_at, :last_login_at) RETURNING id`
+
+	var id int64
+	rows, err := s.db.NamedQuery(q, ui)
+	if err != nil {
+		if IsUniqueConstraintViolationError(err) {
+			return nil, model.NewAppErr("PgUserIdentityStore.Save", model.ErrConflict, locale.GetUserLocalizer("en"), msgUniqueUserIdentity, http.StatusConflict, nil)
+		}
+		return nil, model.NewAppErr("PgUserIdentityStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveUserIdentity, http.StatusInternalServerError, nil)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		rows.Scan(&id)
+	}
+	if err := rows.Err(); err != nil {
+		if IsUniqueConstraintViolationError(err) {
+			return nil, model.NewAppErr("PgUserIdentityStore.Save", model.ErrConflict, locale.GetUserLocalizer("en"), msgUniqueUserIdentity, http.StatusConflict, nil)
+		}
+		return nil, model.NewAppErr("PgUserIdentityStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveUserIdentity, http.StatusInternalServerError, nil)
+	}
+
+	ui.ID = id
+	return ui, nil
+}
+
+// GetByProvider gets the identity by the provider's subject, nil is returned if the account is not linked
+func (s PgUserIdentityStore) GetByProvider(provider, subject string) (*model.UserIdentity, *model.AppErr) {
+	var ui model.UserIdentity
+	if err := s.db.Get(&ui, `SELECT * FROM public.user_identity WHERE provider = $1 AND subject = $2`, provider, subject); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, model.NewAppErr("PgUserIdentityStore.GetByProvider", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetUserIdentity, http.StatusInternalServerError, nil)
+	}
+	return &ui, nil
+}
+
+// GetAll gets all identities linked to the user
+func (s PgUserIdentityStore) GetAll(userID int64) ([]*model.UserIdentity, *model.AppErr) {
+	var identities = make([]*model.UserIdentity, 0)
+	if err := s.db.Select(&identities, `SELECT * FROM public.user_identity WHERE user_id = $1 ORDER BY id`, userID); err != nil {
+		return nil, model.NewAppErr("PgUserIdentityStore.GetAll", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetUserIdentities, http.StatusInternalServerError, nil)
+	}
+	return identities, nil
+}
+
+// UpdateLastLogin records the login with the identity and keeps the email in sync with the provider
+func (s PgUserIdentityStore) UpdateLastLogin(id int64, email *string) *model.AppErr {
+	if _, err := s.db.Exec(`UPDATE public.user_identity SET last_login_at = $2, email = $3 WHERE id = $1`, id, time.Now(), email); err != nil {
+		return model.NewAppErr("PgUserIdentityStore.UpdateLastLogin", model.ErrInternal, locale.GetUserLocalizer("en"), msgUpdateUserIdentity, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// Delete unlinks the provider from the user
+func (s PgUserIdentityStore) Delete(userID int64, provider string) *model.AppErr {
+	res, err := s.db.Exec(`DELETE FROM public.user_identity WHERE user_id = $1 AND provider = $2`, userID, provider)
+	if err != nil {
+		return model.NewAppErr("PgUserIdentityStore.Delete", model.ErrInternal, locale.GetUserLocalizer("en"), msgDeleteUserIdentity, http.StatusInternalServerError, nil)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return model.NewAppErr("PgUserIdentityStore.Delete", model.ErrNotFound, locale.GetUserLocalizer("en"), msgUserIdentityNotFound, http.StatusNotFound, nil)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"database/sql"
 	"net/http"
 	"time"
 
@@ -27,6 +28,7 @@ var (
 	msgUpdateUserProfile    = &i18n.Message{ID: "store.postgres.user.update.app_error", Other: "could not update user"}
 	msgBulkInsertUsers      = &i18n.Message{ID: "store.postgres.user.bulk.insert.app_error", Other: "could not bulk insert users"}
 	msgGetUser              = &i18n.Message{ID: "store.postgres.user.get.app_error", Other: "could not get the user"}
+	msgUserNotFound         = &i18n.Message{ID: "store.postgres.user.get.not_found.app_error", Other: "user not found"}
 	msgGetUsers             = &i18n.Message{ID: "store.postgres.user.get_all.app_error", Other: "could not get users"}
 	msgVerifyEmail          = &i18n.Message{ID: "store.postgres.user.verify_email.app_error", Other: "could not verify email"}
 	msgDeleteToken          = &i18n.Message{ID: "store.postgres.user.verify_email.delete_token.app_error", Other: "could not delete verify token"}
@@ -35,11 +37,6 @@ var (
 	msgBulkDeleteUsers      = &i18n.Message{ID: "store.postgres.user.bulk_delete.app_error", Other: "could not bulk delete users"}
 	msgUpdateUserAvatar     = &i18n.Message{ID: "store.postgres.user.update_avatar.app_error", Other: "could not delete user avatar"}
 	msgDeleteUserAvatar     = &i18n.Message{ID: "store.postgres.user.delete_avatar.app_error", Other: "could not delete user avatar"}
-
-	msgCreateWishlist = &i18n.Message{ID: "store.postgres.user.create_wishlist.app_error", Other: "could not add product to wishlist"}
-	msgGetWishlist    = &i18n.Message{ID: "store.postgres.user.get_wishlist.app_error", Other: "could not get wishlist"}
-	msgDeleteWishlist = &i18n.Message{ID: "store.postgres.user.delete_wishlist.app_error", Other: "could not delete product from wishlist"}
-	msgClearWishlist  = &i18n.Message{ID: "store.postgres.user.clear_wishlist.app_error", Other: "could not delete all products from wishlist"}
 )
 
 // Count returns the total users count
@@ -60,21 +57,13 @@ func (s PgUserStore) BulkInsert(users []*model.User) *model.AppErr {
 	return nil
 }
 
-// Save inserts the new user in the db
-func (s PgUserStore) Save(user *model.User) (*model.User, *model.AppErr) {
+// Save inserts the new user in the db, the events are written to the outbox in the same transaction
+func (s PgUserStore) Save(user *model.User, events ...*model.Event) (*model.User, *model.AppErr) {
 	q := `INSERT INTO public.user (first_name, last_name, username, email, password, role, gender, locale, avatar_url, avatar_public_id, active, email_verified, failed_attempts, last_login_at, created_at, updated_at, deleted_at) 
 	VALUES (:first_name, :last_name, :username, :email, :password, :role, :gender, :locale, :avatar_url, :avatar_public_id, :active, :email_verified, :failed_attempts, :last_login_at, :created_at, :updated_at, :deleted_at) RETURNING id`
 
-	var id int64
-	rows, err := s.db.NamedQuery(q, user)
+	id, err := s.insertWithEvents(q, user, func(id int64) { user.ID = id }, events)
 	if err != nil {
-		return nil, model.NewAppErr("PgUserStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveUser, http.StatusInternalServerError, nil)
-	}
-	defer rows.Close()
-	for rows.Next() {
-		rows.Scan(&id)
-	}
-	if err := rows.Err(); err != nil {
 		if IsUniqueConstraintViolationError(err) {
 			return nil, model.NewAppErr("PgUserStore.Save", model.ErrConflict, locale.GetUserLocalizer("en"), msgUniqueConstraintUser, http.StatusInternalServerError, nil)
 		}
@@ -97,6 +86,9 @@ func (s PgUserStore) Update(id int64, u *model.User) (*model.User, *model.AppErr
 func (s PgUserStore) Get(id int64) (*model.User, *model.AppErr) {
 	var user model.User
 	if err := s.db.Get(&user, "SELECT * FROM public.user WHERE id = $1 AND deleted_at IS NULL", id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.NewAppErr("PgUserStore.Get", model.ErrNotFound, locale.GetUserLocalizer("en"), msgUserNotFound, http.StatusNotFound, nil)
+		}
 		return nil, model.NewAppErr("PgUserStore.Get", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetUser, http.StatusInternalServerError, nil)
 	}
 	return &user, nil
@@ -106,6 +98,9 @@ func (s PgUserStore) Get(id int64) (*model.User, *model.AppErr) {
 func (s PgUserStore) GetByEmail(email string) (*model.User, *model.AppErr) {
 	var user model.User
 	if err := s.db.Get(&user, "SELECT * FROM public.user WHERE email = $1 AND deleted_at IS NULL", email); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.NewAppErr("PgUserStore.GetByEmail", model.ErrNotFound, locale.GetUserLocalizer("en"), msgUserNotFound, http.StatusNotFound, nil)
+		}
 		return nil, model.NewAppErr("PgUserStore.GetByEmail", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetUser, http.StatusInternalServerError, nil)
 	}
 	return &user, nil
@@ -132,9 +127,9 @@ func (s PgUserStore) VerifyEmail(id int64) *model.AppErr {
 }
 
 // UpdatePassword updates the user's password
-func (s PgUserStore) UpdatePassword(userID int64, hashedPassword string) *model.AppErr {
+func (s PgUserStore) UpdatePassword(userID int64, hashedPassword string, events ...*model.Event) *model.AppErr {
 	m := map[string]interface{}{"id": userID, "password": hashedPassword, "updated_at": time.Now()}
-	if _, err := s.db.NamedExec("UPDATE public.user SET password = :password, updated_at = :updated_at WHERE id = :id", m); err != nil {
+	if err := s.execWithEvents("UPDATE public.user SET password = :password, updated_at = :updated_at WHERE id = :id", m, userID, events); err != nil {
 		return model.NewAppErr("PgUserStore.UpdatePassword", model.ErrInternal, locale.GetUserLocalizer("en"), msgUpdatePassword, http.StatusInternalServerError, nil)
 	}
 	return nil
@@ -190,79 +185,3 @@ func (s PgUserStore) GetAllOrders(uid int64, limit, offset int) ([]*model.Order,
 
 	return orders, nil
 }
-
-// CreateWishlist adds product to wishlist
-func (s PgUserStore) CreateWishlist(userID, productID int64) *model.AppErr {
-	q := `INSERT INTO public.product_wishlist (user_id, product_id) VALUES (:user_id, :product_id)`
-
-	if _, err := s.db.NamedExec(q, map[string]interface{}{"user_id": userID, "product_id": productID}); err != nil {
-		return model.NewAppErr("PgUserStore.CreateWishlist", model.ErrInternal, locale.GetUserLocalizer("en"), msgCreateWishlist, http.StatusInternalServerError, nil)
-	}
-	return nil
-}
-
-// GetWishlist gets users wishlist products
-func (s PgUserStore) GetWishlist(userID int64) ([]*model.Product, *model.AppErr) {
-	q := `SELECT DISTINCT ON (p.id)
-	p.*,
-	b.name AS brand_name,
-	b.slug AS brand_slug,
-	b.type AS brand_type,
-	b.description AS brand_description,
-	b.email AS brand_email,
-	b.logo AS brand_logo,
-	b.website_url AS brand_website_url,
-	b.created_at AS brand_created_at,
-	b.updated_at AS brand_updated_at,
-	c.name AS category_name,
-	c.slug AS category_slug,
-	c.description AS category_description,
-	c.logo AS category_logo,
-	c.properties AS category_properties,
-	c.created_at AS category_created_at,
-	c.updated_at AS category_updated_at,
-	pp.id AS pricing_id,
-  pp.product_id AS pricing_product_id,
-	pp.price AS pricing_price,
-	pp.original_price AS pricing_original_price,
-  pp.sale_starts AS pricing_sale_starts,
-  pp.sale_ends AS pricing_sale_ends
-	FROM public.product p
-	LEFT JOIN product_pricing pp ON p.id = pp.product_id
-	LEFT JOIN brand b ON p.brand_id = b.id
-	LEFT JOIN category c ON p.category_id = c.id	
-	LEFT JOIN product_wishlist w ON p.id = w.product_id
-	LEFT JOIN public.user u ON u.id = w.user_id
-	WHERE CURRENT_TIMESTAMP BETWEEN pp.sale_starts AND pp.sale_ends
-	AND u.id = $1
-	ORDER BY p.id, pp.id DESC`
-
-	var pj []productJoin
-	if err := s.db.Select(&pj, q, userID); err != nil {
-		return nil, model.NewAppErr("PgUserStore.GetWishlist", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetWishlist, http.StatusInternalServerError, nil)
-	}
-
-	wishlist := make([]*model.Product, 0)
-	for _, x := range pj {
-		wishlist = append(wishlist, x.ToProduct())
-	}
-
-	return wishlist, nil
-}
-
-// DeleteWishlist deletes product from wishlist
-func (s PgUserStore) DeleteWishlist(userID, productID int64) *model.AppErr {
-	m := map[string]interface{}{"user_id": userID, "product_id": productID}
-	if _, err := s.db.NamedExec("DELETE from public.product_wishlist WHERE user_id = :user_id AND product_id = :product_id", m); err != nil {
-		return model.NewAppErr("PgUserStore.DeleteWishlist", model.ErrInternal, locale.GetUserLocalizer("en"), msgDeleteWishlist, http.StatusInternalServerError, nil)
-	}
-	return nil
-}
-
-// ClearWishlist clears whole wishlist
-func (s PgUserStore) ClearWishlist(userID int64) *model.AppErr {
-	if _, err := s.db.NamedExec("DELETE from public.product_wishlist WHERE user_id = :user_id", map[string]interface{}{"user_id": userID}); err != nil {
-		return model.NewAppErr("PgUserStore.ClearWishlist", model.ErrInternal, locale.GetUserLocalizer("en"), msgClearWishlist, http.StatusInternalServerError, nil)
-	}
-	return nil
-}
@@ -0,0 +1,154 @@
+package postgres
+
+import (
+	"net/http"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/store"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// PgWebhookStore is the postgres implementation
+type PgWebhookStore struct {
+	PgStore
+}
+
+// NewPgWebhookStore creates the new webhook store
+func NewPgWebhookStore(pgst *PgStore) store.WebhookStore {
+	return &PgWebhookStore{*pgst}
+}
+
+var (
+	msgSaveWebhook           = &i18n.Message{ID: "store.postgres.webhook.save.app_error", Other: "could not save webhook"}
+	msgGetWebhook            = &i18n.Message{ID: "store.postgres.webhook.get.app_error", Other: "could not get webhook"}
+	msgGetWebhooks           = &i18n.Message{ID: "store.postgres.webhook.get_all.app_error", Other: "could not get webhooks"}
+	msgUpdateWebhook         = &i18n.Message{ID: "store.postgres.webhook.update.app_error", Other: "could not update webhook"}
+	msgDeleteWebhook         = &i18n.Message{ID: "store.postgres.webhook.delete.app_error", Other: "could not delete webhook"}
+	msgSaveWebhookDelivery   = &i18n.Message{ID: "store.postgres.webhook.save_delivery.app_error", Other: "could not save webhook delivery"}
+	msgGetWebhookDelivery    = &i18n.Message{ID: "store.postgres.webhook.get_delivery.app_error", Other: "could not get webhook delivery"}
+	msgGetWebhookDeliveries  = &i18n.Message{ID: "store.postgres.webhook.get_deliveries.app_error", Other: "could not get webhook deliveries"}
+	msgUpdateWebhookDelivery = &i18n.Message{ID: "store.postgres.webhook.update_delivery.app_error", Other: "could not update webhook delivery"}
+)
+
+// Save creates the new webhook
+func (s PgWebhookStore) Save(wh *model.Webhook) (*model.Webhook, *model.AppErr) {
+	q := `INSERT INTO public.webhook (url, secret, event_types, description, active, created_at, updated_at) VALUES (:url, :secret, :event_types, :description, :active, :created_at, :updated_at) RETURNING id`
+
+	var id int64
+	rows, err := s.db.NamedQuery(q, wh)
+	if err != nil {
+		return nil, model.NewAppErr("PgWebhookStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveWebhook, http.StatusInternalServerError, nil)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		rows.Scan(&id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, model.NewAppErr("PgWebhookStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveWebhook, http.StatusInternalServerError, nil)
+	}
+
+	wh.ID = id
+	return wh, nil
+}
+
+// Get gets the webhook by id
+func (s PgWebhookStore) Get(id int64) (*model.Webhook, *model.AppErr) {
+	var wh model.Webhook
+	if err := s.db.Get(&wh, `SELECT * FROM public.webhook WHERE id = $1`, id); err != nil {
+		return nil, model.NewAppErr("PgWebhookStore.Get", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetWebhook, http.StatusInternalServerError, nil)
+	}
+	return &wh, nil
+}
+
+// GetAll gets all webhooks
+func (s PgWebhookStore) GetAll(limit, offset int) ([]*model.Webhook, *model.AppErr) {
+	var webhooks = make([]*model.Webhook, 0)
+	if err := s.db.Select(&webhooks, `SELECT COUNT(*) OVER() AS total_count, * FROM public.webhook ORDER BY id LIMIT $1 OFFSET $2`, limit, offset); err != nil {
+		return nil, model.NewAppErr("PgWebhookStore.GetAll", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetWebhooks, http.StatusInternalServerError, nil)
+	}
+	return webhooks, nil
+}
+
+// GetActiveByEventType gets the active webhooks subscribed to the event type
+func (s PgWebhookStore) GetActiveByEventType(eventType string) ([]*model.Webhook, *model.AppErr) {
+	var webhooks = make([]*model.Webhook, 0)
+	if err := s.db.Select(&webhooks, `SELECT * FROM public.webhook WHERE active = true AND $1 = ANY(event_types) ORDER BY id`, eventType); err != nil {
+		return nil, model.NewAppErr("PgWebhookStore.GetActiveByEventType", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetWebhooks, http.StatusInternalServerError, nil)
+	}
+	return webhooks, nil
+}
+
+// Update updates the webhook
+func (s PgWebhookStore) Update(id int64, wh *model.Webhook) (*model.Webhook, *model.AppErr) {
+	q := `UPDATE public.webhook SET url=:url, secret=:secret, event_types=:event_types, description=:description, active=:active, updated_at=:updated_at WHERE id=:id`
+	if _, err := s.db.NamedExec(q, wh); err != nil {
+		return nil, model.NewAppErr("PgWebhookStore.Update", model.ErrInternal, locale.GetUserLocalizer("en"), msgUpdateWebhook, http.StatusInternalServerError, nil)
+	}
+	return wh, nil
+}
+
+// Delete deletes the webhook together with its delivery log
+func (s PgWebhookStore) Delete(id int64) *model.AppErr {
+	if _, err := s.db.Exec(`DELETE FROM public.webhook WHERE id = $1`, id); err != nil {
+		return model.NewAppErr("PgWebhookStore.Delete", model.ErrInternal, locale.GetUserLocalizer("en"), msgDeleteWebhook, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// SaveDelivery creates the new delivery, the event is only saved once per webhook
+// and the existing delivery is returned if it was already created. Replays are always saved
+func (s PgWebhookStore) SaveDelivery(d *model.WebhookDelivery) (*model.WebhookDelivery, *model.AppErr) {
+	q := `INSERT INTO public.webhook_delivery (webhook_id, event_id, event_type, payload, status, replay_of, created_at)
+	VALUES (:webhook_id, :event_id, :event_type, :payload, :status, :replay_of, :created_at)
+	ON CONFLICT (webhook_id, event_id) WHERE replay_of IS NULL DO UPDATE SET webhook_id = EXCLUDED.webhook_id
+	RETURNING id`
+
+	var id int64
+	rows, err := s.db.NamedQuery(q, d)
+	if err != nil {
+		return nil, model.NewAppErr("PgWebhookStore.SaveDelivery", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveWebhookDelivery, http.StatusInternalServerError, nil)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		rows.Scan(&id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, model.NewAppErr("PgWebhookStore.SaveDelivery", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveWebhookDelivery, http.StatusInternalServerError, nil)
+	}
+
+	d.ID = id
+	return d, nil
+}
+
+// GetDelivery gets the webhook delivery
+func (s PgWebhookStore) GetDelivery(webhookID, id int64) (*model.WebhookDelivery, *model.AppErr) {
+	var d model.WebhookDelivery
+	if err := s.db.Get(&d, `SELECT * FROM public.webhook_delivery WHERE webhook_id = $1 AND id = $2`, webhookID, id); err != nil {
+		return nil, model.NewAppErr("PgWebhookStore.GetDelivery", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetWebhookDelivery, http.StatusInternalServerError, nil)
+	}
+	return &d, nil
+}
+
+// GetDeliveries gets the webhook deliveries optionally filtered by status, newest first
+func (s PgWebhookStore) GetDeliveries(webhookID int64, status string, limit, offset int) ([]*model.WebhookDelivery, *model.AppErr) {
+	q := `SELECT COUNT(*) OVER() AS total_count, * FROM public.webhook_delivery
+	WHERE webhook_id = $1 AND ($2 = '' OR status = $2)
+	ORDER BY created_at DESC
+	LIMIT $3 OFFSET $4`
+
+	var deliveries = make([]*model.WebhookDelivery, 0)
+	if err := s.db.Select(&deliveries, q, webhookID, status, limit, offset); err != nil {
+		return nil, model.NewAppErr("PgWebhookStore.GetDeliveries", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetWebhookDeliveries, http.StatusInternalServerError, nil)
+	}
+	return deliveries, nil
+}
+
+// UpdateDelivery records the result of the delivery attempt
+func (s PgWebhookStore) UpdateDelivery(d *model.WebhookDelivery) *model.AppErr {
+	q := `UPDATE public.webhook_delivery SET status=:status, attempts=:attempts, response_code=:response_code, response_body=:response_body, error=:error, duration_ms=:duration_ms, last_attempt_at=:last_attempt_at WHERE id=:id`
+	if _, err := s.db.NamedExec(q, d); err != nil {
+		return model.NewAppErr("PgWebhookStore.UpdateDelivery", model.ErrInternal, locale.GetUserLocalizer("en"), msgUpdateWebhookDelivery, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
@@ -0,0 +1,370 @@
+package postgres
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/store"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// PgWishlistStore is the postgres implementation
+type PgWishlistStore struct {
+	PgStore
+}
+
+// NewPgWishlistStore creates the new wishlist store
+func NewPgWishlistStore(pgst *PgStore) store.WishlistStore {
+	return &PgWishlistStore{*pgst}
+}
+
+var (
+	msgSaveWishlist             = &i18n.Message{ID: "store.postgres.wishlist.save.app_error", Other: "could not save wishlist"}
+	msgGetWishlist              = &i18n.Message{ID: "store.postgres.wishlist.get.app_error", Other: "could not get wishlist"}
+	msgGetWishlists             = &i18n.Message{ID: "store.postgres.wishlist.get_all.app_error", Other: "could not get wishlists"}
+	msgUpdateWishlist           = &i18n.Message{ID: "store.postgres.wishlist.update.app_error", Other: "could not update wishlist"}
+	msgDeleteWishlist           = &i18n.Message{ID: "store.postgres.wishlist.delete.app_error", Other: "could not delete wishlist"}
+	msgSaveWishlistItem         = &i18n.Message{ID: "store.postgres.wishlist.save_item.app_error", Other: "could not add product to wishlist"}
+	msgUniqueConstraintWishlist = &i18n.Message{ID: "store.postgres.wishlist.save_item.unique_constraint.app_error", Other: "product is already in the wishlist"}
+	msgGetWishlistItem          = &i18n.Message{ID: "store.postgres.wishlist.get_item.app_error", Other: "could not get wishlist item"}
+	msgGetWishlistItems         = &i18n.Message{ID: "store.postgres.wishlist.get_items.app_error", Other: "could not get wishlist items"}
+	msgUpdateWishlistItem       = &i18n.Message{ID: "store.postgres.wishlist.update_item.app_error", Other: "could not update wishlist item"}
+	msgDeleteWishlistItem       = &i18n.Message{ID: "store.postgres.wishlist.delete_item.app_error", Other: "could not delete product from wishlist"}
+	msgClearWishlist            = &i18n.Message{ID: "store.postgres.wishlist.clear.app_error", Other: "could not delete all products from wishlist"}
+	msgUpdateWishlistAlerts     = &i18n.Message{ID: "store.postgres.wishlist.update_alerts.app_error", Other: "could not update wishlist alerts"}
+	msgGetWishlistAlerts        = &i18n.Message{ID: "store.postgres.wishlist.get_alerts.app_error", Other: "could not get pending wishlist alerts"}
+	msgSyncWishlistAlerts       = &i18n.Message{ID: "store.postgres.wishlist.sync_alerts.app_error", Other: "could not sync wishlist alerts"}
+	msgMarkWishlistAlertsSent   = &i18n.Message{ID: "store.postgres.wishlist.mark_alerts_sent.app_error", Other: "could not mark wishlist alerts as sent"}
+	msgCountWishlistAlertEmails = &i18n.Message{ID: "store.postgres.wishlist.count_alert_emails.app_error", Other: "could not count wishlist alert emails"}
+)
+
+const wishlistSelect = `SELECT w.*, (SELECT COUNT(*) FROM public.product_wishlist pw WHERE pw.wishlist_id = w.id) AS items_count FROM public.wishlist w`
+
+// Save creates the new wishlist
+func (s PgWishlistStore) Save(w *model.Wishlist) (*model.Wishlist, *model.AppErr) {
+	q := `INSERT INTO public.wishlist (user_id, name, is_default, created_at, updated_at) VALUES (:user_id, :name, :is_default, :created_at, :updated_at) RETURNING id`
+
+	var id int64
+	rows, err := s.db.NamedQuery(q, w)
+	if err != nil {
+		return nil, model.NewAppErr("PgWishlistStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveWishlist, http.StatusInternalServerError, nil)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		rows.Scan(&id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, model.NewAppErr("PgWishlistStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveWishlist, http.StatusInternalServerError, nil)
+	}
+
+	w.ID = id
+	return w, nil
+}
+
+// GetDefault gets the user's default wishlist, creating it if it doesn't exist yet
+func (s PgWishlistStore) GetDefault(userID int64) (*model.Wishlist, *model.AppErr) {
+	q := `INSERT INTO public.wishlist (user_id, name, is_default, created_at, updated_at)
+	VALUES ($1, $2, true, NOW(), NOW())
+	ON CONFLICT (user_id) WHERE is_default DO NOTHING`
+
+	if _, err := s.db.Exec(q, userID, model.WishlistDefaultName); err != nil {
+		return nil, model.NewAppErr("PgWishlistStore.GetDefault", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetWishlist, http.StatusInternalServerError, nil)
+	}
+
+	var w model.Wishlist
+	if err := s.db.Get(&w, wishlistSelect+` WHERE w.user_id = $1 AND w.is_default = true`, userID); err != nil {
+		return nil, model.NewAppErr("PgWishlistStore.GetDefault", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetWishlist, http.StatusInternalServerError, nil)
+	}
+	return &w, nil
+}
+
+// Get gets the user's wishlist
+func (s PgWishlistStore) Get(userID, id int64) (*model.Wishlist, *model.AppErr) {
+	var w model.Wishlist
+	if err := s.db.Get(&w, wishlistSelect+` WHERE w.user_id = $1 AND w.id = $2`, userID, id); err != nil {
+		return nil, model.NewAppErr("PgWishlistStore.Get", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetWishlist, http.StatusInternalServerError, nil)
+	}
+	return &w, nil
+}
+
+// GetAll gets all of the user's wishlists
+func (s PgWishlistStore) GetAll(userID int64) ([]*model.Wishlist, *model.AppErr) {
+	var wishlists = make([]*model.Wishlist, 0)
+	if err := s.db.Select(&wishlists, wishlistSelect+` WHERE w.user_id = $1 ORDER BY w.is_default DESC, w.id`, userID); err != nil {
+		return nil, model.NewAppErr("PgWishlistStore.GetAll", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetWishlists, http.StatusInternalServerError, nil)
+	}
+	return wishlists, nil
+}
+
+// GetByShareToken gets the shared wishlist
+func (s PgWishlistStore) GetByShareToken(token string) (*model.Wishlist, *model.AppErr) {
+	var w model.Wishlist
+	if err := s.db.Get(&w, wishlistSelect+` WHERE w.share_token = $1`, token); err != nil {
+		return nil, model.NewAppErr("PgWishlistStore.GetByShareToken", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetWishlist, http.StatusInternalServerError, nil)
+	}
+	return &w, nil
+}
+
+// Update updates the wishlist
+func (s PgWishlistStore) Update(id int64, w *model.Wishlist) (*model.Wishlist, *model.AppErr) {
+	q := `UPDATE public.wishlist SET name=:name, share_token=:share_token, updated_at=:updated_at WHERE id=:id`
+	if _, err := s.db.NamedExec(q, w); err != nil {
+		return nil, model.NewAppErr("PgWishlistStore.Update", model.ErrInternal, locale.GetUserLocalizer("en"), msgUpdateWishlist, http.StatusInternalServerError, nil)
+	}
+	return w, nil
+}
+
+// Delete deletes the user's wishlist together with its items
+func (s PgWishlistStore) Delete(userID, id int64) *model.AppErr {
+	m := map[string]interface{}{"user_id": userID, "id": id}
+	if _, err := s.db.NamedExec(`DELETE FROM public.wishlist WHERE user_id = :user_id AND id = :id`, m); err != nil {
+		return model.NewAppErr("PgWishlistStore.Delete", model.ErrInternal, locale.GetUserLocalizer("en"), msgDeleteWishlist, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// SaveItem adds the product to the wishlist
+func (s PgWishlistStore) SaveItem(item *model.WishlistItem) (*model.WishlistItem, *model.AppErr) {
+	q := `INSERT INTO public.product_wishlist (wishlist_id, user_id, product_id, quantity, note, created_at) VALUES (:wishlist_id, :user_id, :product_id, :quantity, :note, :created_at) RETURNING id`
+
+	var id int64
+	rows, err := s.db.NamedQuery(q, item)
+	if err != nil {
+		return nil, model.NewAppErr("PgWishlistStore.SaveItem", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveWishlistItem, http.StatusInternalServerError, nil)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		rows.Scan(&id)
+	}
+	if err := rows.Err(); err != nil {
+		if IsUniqueConstraintViolationError(err) {
+			return nil, model.NewAppErr("PgWishlistStore.SaveItem", model.ErrConflict, locale.GetUserLocalizer("en"), msgUniqueConstraintWishlist, http.StatusInternalServerError, nil)
+		}
+		if IsForeignKeyConstraintViolationError(err) {
+			return nil, model.NewAppErr("PgWishlistStore.SaveItem", model.ErrConflict, locale.GetUserLocalizer("en"), msgInvalidColumn, http.StatusInternalServerError, nil)
+		}
+		return nil, model.NewAppErr("PgWishlistStore.SaveItem", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveWishlistItem, http.StatusInternalServerError, nil)
+	}
+
+	item.ID = id
+	return item, nil
+}
+
+// GetItem gets the wishlist item
+func (s PgWishlistStore) GetItem(wishlistID, productID int64) (*model.WishlistItem, *model.AppErr) {
+	q := `SELECT id, wishlist_id, user_id, product_id, quantity, note, notify_back_in_stock, notify_price_drop, created_at FROM public.product_wishlist WHERE wishlist_id = $1 AND product_id = $2`
+
+	var item model.WishlistItem
+	if err := s.db.Get(&item, q, wishlistID, productID); err != nil {
+		return nil, model.NewAppErr("PgWishlistStore.GetItem", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetWishlistItem, http.StatusInternalServerError, nil)
+	}
+	return &item, nil
+}
+
+// GetItems gets the wishlist items with their products
+func (s PgWishlistStore) GetItems(wishlistID int64) ([]*model.WishlistItem, *model.AppErr) {
+	q := `SELECT DISTINCT ON (w.id)
+	w.id AS wishlist_item_id,
+	w.wishlist_id AS wishlist_item_wishlist_id,
+	w.quantity AS wishlist_item_quantity,
+	w.note AS wishlist_item_note,
+	w.notify_back_in_stock AS wishlist_item_notify_back_in_stock,
+	w.notify_price_drop AS wishlist_item_notify_price_drop,
+	w.created_at AS wishlist_item_created_at,
+	p.*,
+	b.name AS brand_name,
+	b.slug AS brand_slug,
+	b.type AS brand_type,
+	b.description AS brand_description,
+	b.email AS brand_email,
+	b.logo AS brand_logo,
+	b.website_url AS brand_website_url,
+	b.created_at AS brand_created_at,
+	b.updated_at AS brand_updated_at,
+	c.name AS category_name,
+	c.slug AS category_slug,
+	c.description AS category_description,
+	c.logo AS category_logo,
+	c.properties AS category_properties,
+	c.created_at AS category_created_at,
+	c.updated_at AS category_updated_at,
+	pp.id AS pricing_id,
+	pp.product_id AS pricing_product_id,
+	pp.price AS pricing_price,
+	pp.original_price AS pricing_original_price,
+	pp.sale_starts AS pricing_sale_starts,
+	pp.sale_ends AS pricing_sale_ends
+	FROM public.product_wishlist w
+	JOIN public.product p ON p.id = w.product_id
+	LEFT JOIN product_pricing pp ON p.id = pp.product_id
+	LEFT JOIN brand b ON p.brand_id = b.id
+	LEFT JOIN category c ON p.category_id = c.id
+	WHERE CURRENT_TIMESTAMP BETWEEN pp.sale_starts AND pp.sale_ends
+	AND w.wishlist_id = $1
+	ORDER BY w.id, pp.id DESC`
+
+	var wj []wishlistItemJoin
+	if err := s.db.Select(&wj, q, wishlistID); err != nil {
+		return nil, model.NewAppErr("PgWishlistStore.GetItems", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetWishlistItems, http.StatusInternalServerError, nil)
+	}
+
+	items := make([]*model.WishlistItem, 0)
+	for _, x := range wj {
+		items = append(items, x.ToWishlistItem())
+	}
+	return items, nil
+}
+
+// UpdateItem updates the wishlist item quantity and note
+func (s PgWishlistStore) UpdateItem(item *model.WishlistItem) (*model.WishlistItem, *model.AppErr) {
+	q := `UPDATE public.product_wishlist SET quantity=:quantity, note=:note WHERE id=:id`
+	if _, err := s.db.NamedExec(q, item); err != nil {
+		return nil, model.NewAppErr("PgWishlistStore.UpdateItem", model.ErrInternal, locale.GetUserLocalizer("en"), msgUpdateWishlistItem, http.StatusInternalServerError, nil)
+	}
+	return item, nil
+}
+
+// DeleteItem deletes the product from the wishlist
+func (s PgWishlistStore) DeleteItem(wishlistID, productID int64) *model.AppErr {
+	m := map[string]interface{}{"wishlist_id": wishlistID, "product_id": productID}
+	if _, err := s.db.NamedExec(`DELETE FROM public.product_wishlist WHERE wishlist_id = :wishlist_id AND product_id = :product_id`, m); err != nil {
+		return model.NewAppErr("PgWishlistStore.DeleteItem", model.ErrInternal, locale.GetUserLocalizer("en"), msgDeleteWishlistItem, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// ClearItems deletes all products from the wishlist
+func (s PgWishlistStore) ClearItems(wishlistID int64) *model.AppErr {
+	if _, err := s.db.NamedExec(`DELETE FROM public.product_wishlist WHERE wishlist_id = :wishlist_id`, map[string]interface{}{"wishlist_id": wishlistID}); err != nil {
+		return model.NewAppErr("PgWishlistStore.ClearItems", model.ErrInternal, locale.GetUserLocalizer("en"), msgClearWishlist, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// UpdateAlerts toggles the wishlist item alerts and snapshots the current product price and stock
+func (s PgWishlistStore) UpdateAlerts(wishlistID, productID int64, sub *model.WishlistAlertSubscription) *model.AppErr {
+	q := `UPDATE public.product_wishlist w SET
+	notify_back_in_stock = :back_in_stock,
+	notify_price_drop = :price_drop,
+	last_seen_in_stock = (SELECT p.in_stock FROM public.product p WHERE p.id = w.product_id),
+	last_seen_price = (
+		SELECT pp.price FROM public.product_pricing pp
+		WHERE pp.product_id = w.product_id AND CURRENT_TIMESTAMP BETWEEN pp.sale_starts AND pp.sale_ends
+		ORDER BY pp.id DESC LIMIT 1
+	)
+	WHERE w.wishlist_id = :wishlist_id AND w.product_id = :product_id`
+
+	m := map[string]interface{}{"wishlist_id": wishlistID, "product_id": productID, "back_in_stock": sub.BackInStock, "price_drop": sub.PriceDrop}
+	if _, err := s.db.NamedExec(q, m); err != nil {
+		return model.NewAppErr("PgWishlistStore.UpdateAlerts", model.ErrInternal, locale.GetUserLocalizer("en"), msgUpdateWishlistAlerts, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// GetPendingAlerts gets the wishlisted products that came back in stock or dropped in price since the user last saw them
+func (s PgWishlistStore) GetPendingAlerts() ([]*model.WishlistAlert, *model.AppErr) {
+	q := `WITH cur AS (
+		SELECT DISTINCT ON (w.id)
+		w.user_id,
+		w.product_id,
+		w.notify_back_in_stock,
+		w.notify_price_drop,
+		w.last_seen_in_stock,
+		w.last_seen_price,
+		p.name AS product_name,
+		p.slug,
+		p.in_stock,
+		pp.price
+		FROM public.product_wishlist w
+		JOIN public.product p ON p.id = w.product_id
+		JOIN public.product_pricing pp ON pp.product_id = p.id
+		WHERE (w.notify_back_in_stock OR w.notify_price_drop)
+		AND CURRENT_TIMESTAMP BETWEEN pp.sale_starts AND pp.sale_ends
+		ORDER BY w.id, pp.id DESC
+	)
+	SELECT DISTINCT ON (c.user_id, c.product_id)
+	c.user_id,
+	c.product_id,
+	u.email,
+	COALESCE(u.username, '') AS username,
+	u.locale,
+	c.product_name,
+	c.slug,
+	c.price,
+	c.last_seen_price,
+	CASE WHEN c.notify_back_in_stock AND c.last_seen_in_stock = false THEN 'back_in_stock' ELSE 'price_drop' END AS kind
+	FROM cur c
+	JOIN public.user u ON u.id = c.user_id
+	WHERE u.deleted_at IS NULL AND c.in_stock = true
+	AND (
+		(c.notify_back_in_stock AND c.last_seen_in_stock = false) OR
+		(c.notify_price_drop AND c.price < c.last_seen_price)
+	)
+	ORDER BY c.user_id, c.product_id`
+
+	var alerts = make([]*model.WishlistAlert, 0)
+	if err := s.db.Select(&alerts, q); err != nil {
+		return nil, model.NewAppErr("PgWishlistStore.GetPendingAlerts", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetWishlistAlerts, http.StatusInternalServerError, nil)
+	}
+	return alerts, nil
+}
+
+// SyncAlerts records the changes that don't trigger the alert (going out of stock, price increase)
+// so the next restock or price drop is compared against them
+func (s PgWishlistStore) SyncAlerts() *model.AppErr {
+	q := `UPDATE public.product_wishlist w SET
+	last_seen_in_stock = COALESCE(w.last_seen_in_stock, true) AND c.in_stock,
+	last_seen_price = GREATEST(w.last_seen_price, c.price)
+	FROM (
+		SELECT DISTINCT ON (p.id) p.id AS product_id, p.in_stock, pp.price
+		FROM public.product p
+		JOIN public.product_pricing pp ON pp.product_id = p.id
+		WHERE CURRENT_TIMESTAMP BETWEEN pp.sale_starts AND pp.sale_ends
+		ORDER BY p.id, pp.id DESC
+	) c
+	WHERE c.product_id = w.product_id AND (w.notify_back_in_stock OR w.notify_price_drop)`
+
+	if _, err := s.db.Exec(q); err != nil {
+		return model.NewAppErr("PgWishlistStore.SyncAlerts", model.ErrInternal, locale.GetUserLocalizer("en"), msgSyncWishlistAlerts, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// MarkAlertsSent updates the seen price and stock for the sent alerts and logs them
+func (s PgWishlistStore) MarkAlertsSent(alerts []*model.WishlistAlert) *model.AppErr {
+	now := time.Now()
+
+	tx, txErr := s.db.Beginx()
+	if txErr != nil {
+		return model.NewAppErr("PgWishlistStore.MarkAlertsSent", model.ErrInternal, locale.GetUserLocalizer("en"), msgMarkWishlistAlertsSent, http.StatusInternalServerError, nil)
+	}
+
+	for _, x := range alerts {
+		m := map[string]interface{}{"user_id": x.UserID, "product_id": x.ProductID, "kind": x.Kind, "price": x.Price, "sent_at": now}
+
+		if _, err := tx.NamedExec(`UPDATE public.product_wishlist SET last_seen_in_stock = true, last_seen_price = :price, last_notified_at = :sent_at WHERE user_id = :user_id AND product_id = :product_id`, m); err != nil {
+			tx.Rollback()
+			return model.NewAppErr("PgWishlistStore.MarkAlertsSent", model.ErrInternal, locale.GetUserLocalizer("en"), msgMarkWishlistAlertsSent, http.StatusInternalServerError, nil)
+		}
+		if _, err := tx.NamedExec(`INSERT INTO public.wishlist_alert_log (user_id, product_id, kind, price, sent_at) VALUES (:user_id, :product_id, :kind, :price, :sent_at)`, m); err != nil {
+			tx.Rollback()
+			return model.NewAppErr("PgWishlistStore.MarkAlertsSent", model.ErrInternal, locale.GetUserLocalizer("en"), msgMarkWishlistAlertsSent, http.StatusInternalServerError, nil)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return model.NewAppErr("PgWishlistStore.MarkAlertsSent", model.ErrInternal, locale.GetUserLocalizer("en"), msgMarkWishlistAlertsSent, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// CountAlertEmails counts the alert emails sent to the user since the given time
+func (s PgWishlistStore) CountAlertEmails(userID int64, since time.Time) (int, *model.AppErr) {
+	var n int
+	if err := s.db.Get(&n, `SELECT COUNT(DISTINCT sent_at) FROM public.wishlist_alert_log WHERE user_id = $1 AND sent_at >= $2`, userID, since); err != nil {
+		return 0, model.NewAppErr("PgWishlistStore.CountAlertEmails", model.ErrInternal, locale.GetUserLocalizer("en"), msgCountWishlistAlertEmails, http.StatusInternalServerError, nil)
+	}
+	return n, nil
+}
@@ -0,0 +1,98 @@
+package redis
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/store"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/go-redis/redis/v8"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgIncrAttempts  = &i18n.Message{ID: "store.redis.attempt.incr.app_error", Other: "could not record attempt"}
+	msgResetAttempts = &i18n.Message{ID: "store.redis.attempt.reset.app_error", Other: "could not reset attempts"}
+	msgLockAttempts  = &i18n.Message{ID: "store.redis.attempt.lock.app_error", Other: "could not save lock"}
+	msgGetLock       = &i18n.Message{ID: "store.redis.attempt.get_lock.app_error", Other: "could not get lock"}
+)
+
+// incrAttempts counts the attempt, the window starts with the first attempt so the counter is not kept alive by new attempts
+var incrAttempts = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return {count, redis.call("PTTL", KEYS[1])}
+`)
+
+// RdAttemptStore is the redis implementation
+type RdAttemptStore struct {
+	RdStore
+}
+
+// NewRedisAttemptStore creates the new attempt store
+func NewRedisAttemptStore(rdst *RdStore) store.AttemptStore {
+	return &RdAttemptStore{*rdst}
+}
+
+func attemptsKey(key string) string {
+	return "attempts:" + key
+}
+
+func lockKey(key string) string {
+	return "lock:" + key
+}
+
+// Incr counts the attempt in the window and returns the number of attempts and the time left until the window resets
+func (s RdAttemptStore) Incr(key string, window time.Duration) (int64, time.Duration, *model.AppErr) {
+	res, err := incrAttempts.Run(context.TODO(), s.client, []string{attemptsKey(key)}, window.Milliseconds()).Result()
+	if err != nil {
+		return 0, 0, model.NewAppErr("RdAttemptStore.Incr", model.ErrInternal, locale.GetUserLocalizer("en"), msgIncrAttempts, http.StatusInternalServerError, nil)
+	}
+	vals, ok := res.([]interface{})
+	if !ok || len(vals) != 2 {
+		return 0, 0, model.NewAppErr("RdAttemptStore.Incr", model.ErrInternal, locale.GetUserLocalizer("en"), msgIncrAttempts, http.StatusInternalServerError, nil)
+	}
+	count, _ := vals[0].(int64)
+	ttl, _ := vals[1].(int64)
+	return count, time.Duration(ttl) * time.Millisecond, nil
+}
+
+// Reset clears the attempts counted in the window
+func (s RdAttemptStore) Reset(key string) *model.AppErr {
+	if err := s.client.Del(context.TODO(), attemptsKey(key)).Err(); err != nil {
+		return model.NewAppErr("RdAttemptStore.Reset", model.ErrInternal, locale.GetUserLocalizer("en"), msgResetAttempts, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// Lock blocks the key for the duration, the longer existing lock is not shortened
+func (s RdAttemptStore) Lock(key string, d time.Duration) *model.AppErr {
+	c := context.TODO()
+	left, err := s.client.PTTL(c, lockKey(key)).Result()
+	if err != nil {
+		return model.NewAppErr("RdAttemptStore.Lock", model.ErrInternal, locale.GetUserLocalizer("en"), msgLockAttempts, http.StatusInternalServerError, nil)
+	}
+	if left >= d {
+		return nil
+	}
+	if err := s.client.Set(c, lockKey(key), time.Now().Add(d).Unix(), d).Err(); err != nil {
+		return model.NewAppErr("RdAttemptStore.Lock", model.ErrInternal, locale.GetUserLocalizer("en"), msgLockAttempts, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// LockedFor returns how long the key stays blocked, zero is returned if the key is not blocked
+func (s RdAttemptStore) LockedFor(key string) (time.Duration, *model.AppErr) {
+	left, err := s.client.PTTL(context.TODO(), lockKey(key)).Result()
+	if err != nil {
+		return 0, model.NewAppErr("RdAttemptStore.LockedFor", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetLock, http.StatusInternalServerError, nil)
+	}
+	if left < 0 {
+		return 0, nil
+	}
+	return left, nil
+}
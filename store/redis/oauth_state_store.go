@@ -0,0 +1,70 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/store"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/go-redis/redis/v8"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgSaveOAuthState = &i18n.Message{ID: "store.redis.oauth_state.save.app_error", Other: "could not save oauth state"}
+	msgTakeOAuthState = &i18n.Message{ID: "store.redis.oauth_state.take.app_error", Other: "could not get oauth state"}
+)
+
+// RdOAuthStateStore is the redis implementation
+type RdOAuthStateStore struct {
+	RdStore
+}
+
+// NewRedisOAuthStateStore creates the new oauth state store
+func NewRedisOAuthStateStore(rdst *RdStore) store.OAuthStateStore {
+	return &RdOAuthStateStore{*rdst}
+}
+
+func oauthStateKey(key string) string {
+	return "oauth_state:" + key
+}
+
+// Save saves the state until the user comes back from the provider
+func (s RdOAuthStateStore) Save(key string, state *model.OAuthState, expiry time.Duration) *model.AppErr {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return model.NewAppErr("RdOAuthStateStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveOAuthState, http.StatusInternalServerError, nil)
+	}
+	if err := s.client.Set(context.TODO(), oauthStateKey(key), b, expiry).Err(); err != nil {
+		return model.NewAppErr("RdOAuthStateStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveOAuthState, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// Take gets and deletes the state so it can be used only once, nil is returned if the state expired or was already used
+func (s RdOAuthStateStore) Take(key string) (*model.OAuthState, *model.AppErr) {
+	c := context.TODO()
+	pipe := s.client.TxPipeline()
+	get := pipe.Get(c, oauthStateKey(key))
+	pipe.Del(c, oauthStateKey(key))
+	if _, err := pipe.Exec(c); err != nil && err != redis.Nil {
+		return nil, model.NewAppErr("RdOAuthStateStore.Take", model.ErrInternal, locale.GetUserLocalizer("en"), msgTakeOAuthState, http.StatusInternalServerError, nil)
+	}
+
+	b, err := get.Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, model.NewAppErr("RdOAuthStateStore.Take", model.ErrInternal, locale.GetUserLocalizer("en"), msgTakeOAuthState, http.StatusInternalServerError, nil)
+	}
+
+	var state model.OAuthState
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, model.NewAppErr("RdOAuthStateStore.Take", model.ErrInternal, locale.GetUserLocalizer("en"), msgTakeOAuthState, http.StatusInternalServerError, nil)
+	}
+	return &state, nil
+}
@@ -0,0 +1,99 @@
+package redis
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/store"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/go-redis/redis/v8"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgTakeRateLimit = &i18n.Message{ID: "store.redis.rate_limit.take.app_error", Other: "could not check rate limit"}
+)
+
+// takeToken refills the bucket for the time passed since the last refill and takes one token out of it,
+// the redis clock is used so all app instances see the same time, the times are in microseconds
+var takeToken = redis.NewScript(`
+redis.replicate_commands()
+local capacity = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+
+local refill = math.floor((now - ts) / interval)
+if refill > 0 then
+	tokens = math.min(capacity, tokens + refill)
+	ts = ts + refill * interval
+end
+if tokens == capacity then
+	ts = now
+end
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+local elapsed = now - ts
+local full = (capacity - tokens) * interval - elapsed
+local retry = 0
+if allowed == 0 then
+	retry = interval - elapsed
+end
+
+redis.call("HSET", KEYS[1], "tokens", tokens, "ts", ts)
+redis.call("PEXPIRE", KEYS[1], math.ceil(full / 1000) + 1000)
+return {allowed, tokens, full, retry}
+`)
+
+// RdRateLimitStore is the redis implementation
+type RdRateLimitStore struct {
+	RdStore
+}
+
+// NewRedisRateLimitStore creates the new rate limit store
+func NewRedisRateLimitStore(rdst *RdStore) store.RateLimitStore {
+	return &RdRateLimitStore{*rdst}
+}
+
+func rateLimitKey(name, key string) string {
+	return "rate_limit:" + name + ":" + key
+}
+
+// Take takes the token from the client's bucket
+func (s RdRateLimitStore) Take(key string, limit *model.RateLimit) (*model.RateLimitResult, *model.AppErr) {
+	res, err := takeToken.Run(context.TODO(), s.client, []string{rateLimitKey(limit.Name, key)}, limit.Capacity(), limit.Interval().Microseconds()).Result()
+	if err != nil {
+		return nil, model.NewAppErr("RdRateLimitStore.Take", model.ErrInternal, locale.GetUserLocalizer("en"), msgTakeRateLimit, http.StatusInternalServerError, nil)
+	}
+	vals, ok := res.([]interface{})
+	if !ok || len(vals) != 4 {
+		return nil, model.NewAppErr("RdRateLimitStore.Take", model.ErrInternal, locale.GetUserLocalizer("en"), msgTakeRateLimit, http.StatusInternalServerError, nil)
+	}
+
+	allowed, _ := vals[0].(int64)
+	remaining, _ := vals[1].(int64)
+	full, _ := vals[2].(int64)
+	retry, _ := vals[3].(int64)
+	return &model.RateLimitResult{
+		Allowed:    allowed == 1,
+		Limit:      limit.Capacity(),
+		Remaining:  int(remaining),
+		ResetAfter: time.Duration(full) * time.Microsecond,
+		RetryAfter: time.Duration(retry) * time.Microsecond,
+	}, nil
+}
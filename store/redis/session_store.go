@@ -0,0 +1,240 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dankobgd/ecommerce-shop/model"
+	"github.com/dankobgd/ecommerce-shop/store"
+	"github.com/dankobgd/ecommerce-shop/utils/locale"
+	"github.com/go-redis/redis/v8"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	msgSaveSession    = &i18n.Message{ID: "store.redis.session.save.app_error", Other: "could not save session"}
+	msgUpdateSession  = &i18n.Message{ID: "store.redis.session.update.app_error", Other: "could not update session"}
+	msgGetSession     = &i18n.Message{ID: "store.redis.session.get.app_error", Other: "could not get session"}
+	msgGetSessions    = &i18n.Message{ID: "store.redis.session.get_all.app_error", Other: "could not get sessions"}
+	msgDeleteSession  = &i18n.Message{ID: "store.redis.session.delete.app_error", Other: "could not delete session"}
+	msgDeleteSessions = &i18n.Message{ID: "store.redis.session.delete_all.app_error", Other: "could not delete sessions"}
+)
+
+// hsetIfExists only updates the hash that still exists, so the revoked or expired session is not recreated without its ttl
+var hsetIfExists = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+redis.call("HSET", KEYS[1], unpack(ARGV))
+return 1
+`)
+
+// rotateRefreshToken moves the session to the new token pair only if the presented refresh token is still the current one,
+// the rotated out refresh token is remembered so its reuse can be detected
+var rotateRefreshToken = redis.NewScript(`
+if redis.call("HGET", KEYS[1], "refresh_uuid") ~= ARGV[1] then
+	return 0
+end
+redis.call("HSET", KEYS[1], "access_uuid", ARGV[2], "refresh_uuid", ARGV[3], "expires_at", ARGV[4], "last_activity_at", ARGV[5])
+redis.call("SADD", KEYS[2], ARGV[1])
+return 1
+`)
+
+// RdSessionStore is the redis implementation
+type RdSessionStore struct {
+	RdStore
+}
+
+// NewRedisSessionStore creates the new session store
+func NewRedisSessionStore(rdst *RdStore) store.SessionStore {
+	return &RdSessionStore{*rdst}
+}
+
+func sessionKey(id string) string {
+	return "session:" + id
+}
+
+func userSessionsKey(userID int64) string {
+	return fmt.Sprintf("user_sessions:%d", userID)
+}
+
+func rotatedRefreshTokensKey(sessionID string) string {
+	return "session:" + sessionID + ":rotated"
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func parseTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, s)
+	return t
+}
+
+func sessionFromHash(m map[string]string) *model.Session {
+	userID, _ := strconv.ParseInt(m["user_id"], 10, 64)
+	return &model.Session{
+		ID:             m["id"],
+		UserID:         userID,
+		DeviceID:       m["device_id"],
+		UserAgent:      m["user_agent"],
+		IP:             m["ip"],
+		AccessUUID:     m["access_uuid"],
+		RefreshUUID:    m["refresh_uuid"],
+		CreatedAt:      parseTime(m["created_at"]),
+		ExpiresAt:      parseTime(m["expires_at"]),
+		LastActivityAt: parseTime(m["last_activity_at"]),
+	}
+}
+
+// Save saves the new session, the session expires together with its refresh token
+func (s RdSessionStore) Save(session *model.Session) *model.AppErr {
+	c := context.TODO()
+	key := sessionKey(session.ID)
+	fields := map[string]interface{}{
+		"id":               session.ID,
+		"user_id":          session.UserID,
+		"device_id":        session.DeviceID,
+		"user_agent":       session.UserAgent,
+		"ip":               session.IP,
+		"access_uuid":      session.AccessUUID,
+		"refresh_uuid":     session.RefreshUUID,
+		"created_at":       formatTime(session.CreatedAt),
+		"expires_at":       formatTime(session.ExpiresAt),
+		"last_activity_at": formatTime(session.LastActivityAt),
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.HSet(c, key, fields)
+	pipe.ExpireAt(c, key, session.ExpiresAt)
+	pipe.SAdd(c, userSessionsKey(session.UserID), session.ID)
+	pipe.ExpireAt(c, userSessionsKey(session.UserID), session.ExpiresAt)
+	if _, err := pipe.Exec(c); err != nil {
+		return model.NewAppErr("RdSessionStore.Save", model.ErrInternal, locale.GetUserLocalizer("en"), msgSaveSession, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// Rotate points the session to the newly issued token pair, false is returned if the session doesn't exist anymore
+// or the refresh token was already rotated out
+func (s RdSessionStore) Rotate(userID int64, sessionID, refreshUUID string, meta *model.TokenMetadata) (bool, *model.AppErr) {
+	c := context.TODO()
+	key := sessionKey(sessionID)
+	rotatedKey := rotatedRefreshTokensKey(sessionID)
+
+	ok, err := rotateRefreshToken.Run(c, s.client, []string{key, rotatedKey}, refreshUUID, meta.AccessUUID, meta.RefreshUUID, formatTime(meta.RefreshExpires), formatTime(time.Now())).Int()
+	if err != nil {
+		return false, model.NewAppErr("RdSessionStore.Rotate", model.ErrInternal, locale.GetUserLocalizer("en"), msgUpdateSession, http.StatusInternalServerError, nil)
+	}
+	if ok == 0 {
+		return false, nil
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.ExpireAt(c, key, meta.RefreshExpires)
+	pipe.ExpireAt(c, rotatedKey, meta.RefreshExpires)
+	pipe.ExpireAt(c, userSessionsKey(userID), meta.RefreshExpires)
+	if _, err := pipe.Exec(c); err != nil {
+		return false, model.NewAppErr("RdSessionStore.Rotate", model.ErrInternal, locale.GetUserLocalizer("en"), msgUpdateSession, http.StatusInternalServerError, nil)
+	}
+	return true, nil
+}
+
+// IsRotated checks if the refresh token was already rotated out of the session
+func (s RdSessionStore) IsRotated(sessionID, refreshUUID string) (bool, *model.AppErr) {
+	rotated, err := s.client.SIsMember(context.TODO(), rotatedRefreshTokensKey(sessionID), refreshUUID).Result()
+	if err != nil {
+		return false, model.NewAppErr("RdSessionStore.IsRotated", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetSession, http.StatusInternalServerError, nil)
+	}
+	return rotated, nil
+}
+
+// Touch updates the last activity of the session, false is returned if the session doesn't exist anymore
+func (s RdSessionStore) Touch(sessionID string) (bool, *model.AppErr) {
+	ok, err := hsetIfExists.Run(context.TODO(), s.client, []string{sessionKey(sessionID)}, "last_activity_at", formatTime(time.Now())).Int()
+	if err != nil {
+		return false, model.NewAppErr("RdSessionStore.Touch", model.ErrInternal, locale.GetUserLocalizer("en"), msgUpdateSession, http.StatusInternalServerError, nil)
+	}
+	return ok == 1, nil
+}
+
+// Get gets the user session, nil is returned if the session doesn't exist or belongs to another user
+func (s RdSessionStore) Get(userID int64, sessionID string) (*model.Session, *model.AppErr) {
+	m, err := s.client.HGetAll(context.TODO(), sessionKey(sessionID)).Result()
+	if err != nil {
+		return nil, model.NewAppErr("RdSessionStore.Get", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetSession, http.StatusInternalServerError, nil)
+	}
+	if len(m) == 0 {
+		return nil, nil
+	}
+
+	session := sessionFromHash(m)
+	if session.UserID != userID {
+		return nil, nil
+	}
+	return session, nil
+}
+
+// GetAll gets all active user sessions, the expired sessions are removed from the user's set
+func (s RdSessionStore) GetAll(userID int64) ([]*model.Session, *model.AppErr) {
+	c := context.TODO()
+	ids, err := s.client.SMembers(c, userSessionsKey(userID)).Result()
+	if err != nil {
+		return nil, model.NewAppErr("RdSessionStore.GetAll", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetSessions, http.StatusInternalServerError, nil)
+	}
+
+	sessions := make([]*model.Session, 0, len(ids))
+	for _, id := range ids {
+		m, err := s.client.HGetAll(c, sessionKey(id)).Result()
+		if err != nil {
+			return nil, model.NewAppErr("RdSessionStore.GetAll", model.ErrInternal, locale.GetUserLocalizer("en"), msgGetSessions, http.StatusInternalServerError, nil)
+		}
+		if len(m) == 0 {
+			s.client.SRem(c, userSessionsKey(userID), id)
+			continue
+		}
+		sessions = append(sessions, sessionFromHash(m))
+	}
+	return sessions, nil
+}
+
+// Delete deletes the session together with its tokens, the whole refresh token family is revoked
+func (s RdSessionStore) Delete(userID int64, sessionID string) *model.AppErr {
+	session, err := s.Get(userID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	c := context.TODO()
+	pipe := s.client.TxPipeline()
+	if session != nil {
+		pipe.Del(c, sessionKey(session.ID), rotatedRefreshTokensKey(session.ID), session.AccessUUID, session.RefreshUUID)
+	}
+	pipe.SRem(c, userSessionsKey(userID), sessionID)
+	if _, err := pipe.Exec(c); err != nil {
+		return model.NewAppErr("RdSessionStore.Delete", model.ErrInternal, locale.GetUserLocalizer("en"), msgDeleteSession, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
+
+// DeleteAll deletes all user sessions together with their tokens
+func (s RdSessionStore) DeleteAll(userID int64) *model.AppErr {
+	sessions, err := s.GetAll(userID)
+	if err != nil {
+		return err
+	}
+
+	c := context.TODO()
+	pipe := s.client.TxPipeline()
+	for _, session := range sessions {
+		pipe.Del(c, sessionKey(session.ID), rotatedRefreshTokensKey(session.ID), session.AccessUUID, session.RefreshUUID)
+	}
+	pipe.Del(c, userSessionsKey(userID))
+	if _, err := pipe.Exec(c); err != nil {
+		return model.NewAppErr("RdSessionStore.DeleteAll", model.ErrInternal, locale.GetUserLocalizer("en"), msgDeleteSessions, http.StatusInternalServerError, nil)
+	}
+	return nil
+}
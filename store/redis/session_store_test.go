@@ -0,0 +1,180 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/dankobgd/ecommerce-shop/model"
+	goredis "github.com/go-redis/redis/v8"
+)
+
+// newTestStore runs the store against the in memory redis server
+func newTestStore(t *testing.T) (*RdStore, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("could not start the redis server: %v", err)
+	}
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		client.Close()
+		mr.Close()
+	})
+	return NewStore(client), mr
+}
+
+func testSession(expiresAt time.Time) *model.Session {
+	now := time.Now()
+	return &model.Session{
+		ID:             "session-1",
+		UserID:         7,
+		AccessUUID:     "access-1",
+		RefreshUUID:    "refresh-1",
+		CreatedAt:      now,
+		ExpiresAt:      expiresAt,
+		LastActivityAt: now,
+	}
+}
+
+// assertTTL checks the key expires within a second of the wanted ttl
+func assertTTL(t *testing.T, mr *miniredis.Miniredis, key string, want time.Duration) {
+	t.Helper()
+
+	if !mr.Exists(key) {
+		t.Errorf("%s does not exist", key)
+		return
+	}
+	got := mr.TTL(key)
+	if d := got - want; d < -time.Second || d > time.Second {
+		t.Errorf("TTL(%s) = %v, want %v", key, got, want)
+	}
+}
+
+func TestSessionStoreSaveExpiresWithTheRefreshToken(t *testing.T) {
+	rdst, mr := newTestStore(t)
+	ss := NewRedisSessionStore(rdst)
+
+	if err := ss.Save(testSession(time.Now().Add(2 * time.Hour))); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	assertTTL(t, mr, sessionKey("session-1"), 2*time.Hour)
+	assertTTL(t, mr, userSessionsKey(7), 2*time.Hour)
+
+	mr.FastForward(2*time.Hour + time.Second)
+	if s, err := ss.Get(7, "session-1"); err != nil || s != nil {
+		t.Errorf("Get() after the expiry = %v, %v, want no session", s, err)
+	}
+	if ok, err := ss.Touch("session-1"); err != nil || ok {
+		t.Errorf("Touch() after the expiry = %v, %v, want false", ok, err)
+	}
+	if mr.Exists(sessionKey("session-1")) {
+		t.Error("Touch() recreated the expired session")
+	}
+}
+
+func TestSessionStoreRotate(t *testing.T) {
+	rdst, mr := newTestStore(t)
+	ss := NewRedisSessionStore(rdst)
+
+	if err := ss.Save(testSession(time.Now().Add(time.Hour))); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	meta := &model.TokenMetadata{AccessUUID: "access-2", RefreshUUID: "refresh-2", RefreshExpires: time.Now().Add(3 * time.Hour)}
+	ok, err := ss.Rotate(7, "session-1", "refresh-1", meta)
+	if err != nil || !ok {
+		t.Fatalf("Rotate() with the current refresh token = %v, %v, want true", ok, err)
+	}
+
+	s, err := ss.Get(7, "session-1")
+	if err != nil || s == nil {
+		t.Fatalf("Get() after the rotation = %v, %v", s, err)
+	}
+	if s.AccessUUID != "access-2" || s.RefreshUUID != "refresh-2" {
+		t.Errorf("the session points to %s/%s, want access-2/refresh-2", s.AccessUUID, s.RefreshUUID)
+	}
+	assertTTL(t, mr, sessionKey("session-1"), 3*time.Hour)
+	assertTTL(t, mr, rotatedRefreshTokensKey("session-1"), 3*time.Hour)
+	assertTTL(t, mr, userSessionsKey(7), 3*time.Hour)
+
+	if rotated, err := ss.IsRotated("session-1", "refresh-1"); err != nil || !rotated {
+		t.Errorf("IsRotated(refresh-1) = %v, %v, want true", rotated, err)
+	}
+	if rotated, err := ss.IsRotated("session-1", "refresh-2"); err != nil || rotated {
+		t.Errorf("IsRotated(refresh-2) = %v, %v, want false", rotated, err)
+	}
+
+	reused := &model.TokenMetadata{AccessUUID: "access-3", RefreshUUID: "refresh-3", RefreshExpires: time.Now().Add(3 * time.Hour)}
+	if ok, err := ss.Rotate(7, "session-1", "refresh-1", reused); err != nil || ok {
+		t.Errorf("Rotate() with the rotated out refresh token = %v, %v, want false", ok, err)
+	}
+	if s, _ := ss.Get(7, "session-1"); s == nil || s.RefreshUUID != "refresh-2" {
+		t.Error("the rotated out refresh token moved the session")
+	}
+}
+
+func TestSessionStoreDeleteRevokesTheFamily(t *testing.T) {
+	rdst, mr := newTestStore(t)
+	ss := NewRedisSessionStore(rdst)
+	ats := NewRedisAccessTokenStore(rdst)
+
+	if err := ss.Save(testSession(time.Now().Add(time.Hour))); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	meta := &model.TokenMetadata{AccessUUID: "access-2", RefreshUUID: "refresh-2", AccessExpires: time.Now().Add(time.Minute), RefreshExpires: time.Now().Add(time.Hour)}
+	if ok, err := ss.Rotate(7, "session-1", "refresh-1", meta); err != nil || !ok {
+		t.Fatalf("Rotate: %v, %v", ok, err)
+	}
+	if err := ats.SaveAuth(7, meta); err != nil {
+		t.Fatalf("SaveAuth: %v", err)
+	}
+
+	if err := ss.Delete(7, "session-1"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	for _, key := range []string{sessionKey("session-1"), rotatedRefreshTokensKey("session-1"), "access-2", "refresh-2"} {
+		if mr.Exists(key) {
+			t.Errorf("%s still exists after the session was revoked", key)
+		}
+	}
+	if ok, err := ss.Touch("session-1"); err != nil || ok {
+		t.Errorf("Touch() after Delete() = %v, %v, want false", ok, err)
+	}
+	if ok, err := ss.Rotate(7, "session-1", "refresh-2", meta); err != nil || ok {
+		t.Errorf("Rotate() after Delete() = %v, %v, want false", ok, err)
+	}
+}
+
+func TestAccessTokenStoreSaveAuthExpiry(t *testing.T) {
+	rdst, mr := newTestStore(t)
+	ats := NewRedisAccessTokenStore(rdst)
+
+	meta := &model.TokenMetadata{
+		AccessUUID:     "access-1",
+		RefreshUUID:    "refresh-1",
+		AccessExpires:  time.Now().Add(15 * time.Minute),
+		RefreshExpires: time.Now().Add(24 * time.Hour),
+	}
+	if err := ats.SaveAuth(7, meta); err != nil {
+		t.Fatalf("SaveAuth: %v", err)
+	}
+
+	assertTTL(t, mr, "access-1", 15*time.Minute)
+	assertTTL(t, mr, "refresh-1", 24*time.Hour)
+
+	if uid, err := ats.GetAuth(&model.AccessData{AccessUUID: "access-1"}); err != nil || uid != 7 {
+		t.Errorf("GetAuth() = %d, %v, want 7", uid, err)
+	}
+
+	mr.FastForward(15*time.Minute + time.Second)
+	if mr.Exists("access-1") {
+		t.Error("the access token outlived its expiry")
+	}
+	if !mr.Exists("refresh-1") {
+		t.Error("the refresh token expired together with the access token")
+	}
+}
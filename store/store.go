@@ -1,18 +1,25 @@
 package store
 
 import (
+	"time"
+
 	"github.com/dankobgd/ecommerce-shop/model"
 )
 
 // Store represents all stores
 type Store interface {
 	AccessToken() AccessTokenStore
+	Session() SessionStore
 	User() UserStore
 	Token() TokenStore
 	Product() ProductStore
 	ProductTag() ProductTagStore
 	ProductImage() ProductImageStore
 	ProductReview() ProductReviewStore
+	ProductReviewMedia() ProductReviewMediaStore
+	ProductQuestion() ProductQuestionStore
+	Wishlist() WishlistStore
+	Job() JobStore
 	Order() OrderStore
 	OrderDetail() OrderDetailStore
 	Address() AddressStore
@@ -20,13 +27,26 @@ type Store interface {
 	Brand() BrandStore
 	Tag() TagStore
 	Promotion() PromotionStore
+	Asset() AssetStore
+	Event() EventStore
+	Webhook() WebhookStore
+	EmailTemplate() EmailTemplateStore
+	Translation() TranslationStore
+	Role() RoleStore
+	TwoFactor() TwoFactorStore
+	Attempt() AttemptStore
+	RateLimit() RateLimitStore
+	UserIdentity() UserIdentityStore
+	OAuthState() OAuthStateStore
+	APIKey() APIKeyStore
+	Audit() AuditStore
 }
 
 // UserStore ris the user store
 type UserStore interface {
 	Count() int
 	BulkInsert([]*model.User) *model.AppErr
-	Save(*model.User) (*model.User, *model.AppErr)
+	Save(user *model.User, events ...*model.Event) (*model.User, *model.AppErr)
 	Get(id int64) (*model.User, *model.AppErr)
 	GetAll(limit, offset int) ([]*model.User, *model.AppErr)
 	GetByEmail(email string) (*model.User, *model.AppErr)
@@ -36,12 +56,8 @@ type UserStore interface {
 	UpdateAvatar(id int64, url *string, publicID *string) (*string, *string, *model.AppErr)
 	DeleteAvatar(id int64) *model.AppErr
 	VerifyEmail(userID int64) *model.AppErr
-	UpdatePassword(userID int64, hashedPassword string) *model.AppErr
+	UpdatePassword(userID int64, hashedPassword string, events ...*model.Event) *model.AppErr
 	GetAllOrders(userID int64, limit, offset int) ([]*model.Order, *model.AppErr)
-	CreateWishlist(userID, productID int64) *model.AppErr
-	GetWishlist(userID int64) ([]*model.Product, *model.AppErr)
-	DeleteWishlist(userID, productID int64) *model.AppErr
-	ClearWishlist(userID int64) *model.AppErr
 }
 
 // AccessTokenStore is the access token store
@@ -51,6 +67,18 @@ type AccessTokenStore interface {
 	DeleteAuth(uuid string) (int64, *model.AppErr)
 }
 
+// SessionStore is the user session store
+type SessionStore interface {
+	Save(session *model.Session) *model.AppErr
+	Rotate(userID int64, sessionID, refreshUUID string, meta *model.TokenMetadata) (bool, *model.AppErr)
+	IsRotated(sessionID, refreshUUID string) (bool, *model.AppErr)
+	Touch(sessionID string) (bool, *model.AppErr)
+	Get(userID int64, sessionID string) (*model.Session, *model.AppErr)
+	GetAll(userID int64) ([]*model.Session, *model.AppErr)
+	Delete(userID int64, sessionID string) *model.AppErr
+	DeleteAll(userID int64) *model.AppErr
+}
+
 // TokenStore is the access token store
 type TokenStore interface {
 	Save(token *model.Token) *model.AppErr
@@ -58,27 +86,29 @@ type TokenStore interface {
 	Delete(token string) *model.AppErr
 	Cleanup() *model.AppErr
 	RemoveByType(tokenType model.TokenType) *model.AppErr
+	CountExpired(before time.Time) (int64, *model.AppErr)
+	DeleteExpired(before time.Time) (int64, *model.AppErr)
 }
 
 // ProductStore is the product store
 type ProductStore interface {
 	Count() int
 	BulkInsert([]*model.Product) *model.AppErr
-	Save(p *model.Product) (*model.Product, *model.AppErr)
+	Save(p *model.Product, events ...*model.Event) (*model.Product, *model.AppErr)
 	Get(id int64) (*model.Product, *model.AppErr)
 	ListByIDS(ids []int64) ([]*model.Product, *model.AppErr)
 	GetAll(filters map[string][]string, limit, offset int) ([]*model.Product, *model.AppErr)
 	GetFeatured(limit, offset int) ([]*model.Product, *model.AppErr)
 	GetMostSold(limit, offset int) ([]*model.Product, *model.AppErr)
 	GetBestDeals(limit, offset int) ([]*model.Product, *model.AppErr)
-	Update(id int64, u *model.Product) (*model.Product, *model.AppErr)
-	Delete(id int64) *model.AppErr
-	BulkDelete(ids []int) *model.AppErr
+	Update(id int64, p *model.Product, events ...*model.Event) (*model.Product, *model.AppErr)
+	Delete(id int64, events ...*model.Event) *model.AppErr
+	BulkDelete(ids []int, events ...*model.Event) *model.AppErr
 	GetReviews(id int64) ([]*model.ProductReview, *model.AppErr)
-	Search(query string) ([]*model.Product, *model.AppErr)
+	Search(query, locale string) ([]*model.Product, *model.AppErr)
 	GetLatestPricing(pid int64) (*model.ProductPricing, *model.AppErr)
 	InsertPricingBulk(pricing []*model.ProductPricing) *model.AppErr
-	InsertPricing(pricing *model.ProductPricing) (*model.ProductPricing, *model.AppErr)
+	InsertPricing(pricing *model.ProductPricing, events ...*model.Event) (*model.ProductPricing, *model.AppErr)
 	UpdatePricing(pricing *model.ProductPricing) (*model.ProductPricing, *model.AppErr)
 }
 
@@ -110,20 +140,59 @@ type ProductReviewStore interface {
 	BulkInsert(reviews []*model.ProductReview) *model.AppErr
 	Save(pid int64, review *model.ProductReview) (*model.ProductReview, *model.AppErr)
 	Get(pid, rid int64) (*model.ProductReview, *model.AppErr)
-	GetAll(pid int64) ([]*model.ProductReview, *model.AppErr)
+	GetAll(pid int64, sort string) ([]*model.ProductReview, *model.AppErr)
+	GetAllByStatus(status string, limit, offset int) ([]*model.ProductReview, *model.AppErr)
 	Update(pid, rid int64, rev *model.ProductReview) (*model.ProductReview, *model.AppErr)
+	UpdateStatus(pid, rid int64, status string) *model.AppErr
 	Delete(pid, rid int64) *model.AppErr
 	BulkDelete(pid int64, ids []int) *model.AppErr
+	RefreshRating(pid int64) *model.AppErr
+	IsVerifiedPurchase(uid, pid int64) (bool, *model.AppErr)
+	SaveVote(rid, uid int64) *model.AppErr
+	DeleteVote(rid, uid int64) *model.AppErr
+	SaveReport(report *model.ProductReviewReport) (*model.ProductReviewReport, *model.AppErr)
+	GetReports(rid int64) ([]*model.ProductReviewReport, *model.AppErr)
+}
+
+// ProductReviewMediaStore is the review media store
+type ProductReviewMediaStore interface {
+	BulkInsert(media []*model.ProductReviewMedia) *model.AppErr
+	Get(rid, id int64) (*model.ProductReviewMedia, *model.AppErr)
+	GetAll(rid int64) ([]*model.ProductReviewMedia, *model.AppErr)
+	GetAllForReviews(rids []int64) ([]*model.ProductReviewMedia, *model.AppErr)
+	GetAllForProductReviews(pid int64, rids []int64) ([]*model.ProductReviewMedia, *model.AppErr)
+	Delete(rid, id int64) *model.AppErr
+}
+
+// ProductQuestionStore is the product Q&A store
+type ProductQuestionStore interface {
+	Save(q *model.ProductQuestion) (*model.ProductQuestion, *model.AppErr)
+	Get(pid, qid int64) (*model.ProductQuestion, *model.AppErr)
+	GetAll(pid int64, limit, offset int) ([]*model.ProductQuestion, *model.AppErr)
+	GetAllByStatus(status string, limit, offset int) ([]*model.ProductQuestion, *model.AppErr)
+	UpdateStatus(pid, qid int64, status string) *model.AppErr
+	Delete(pid, qid int64) *model.AppErr
+	SaveAnswer(ans *model.ProductAnswer) (*model.ProductAnswer, *model.AppErr)
+	GetAnswer(qid, aid int64) (*model.ProductAnswer, *model.AppErr)
+	GetAnswers(qids []int64) ([]*model.ProductAnswer, *model.AppErr)
+	GetAnswersByStatus(status string, limit, offset int) ([]*model.ProductAnswer, *model.AppErr)
+	UpdateAnswerStatus(qid, aid int64, status string) *model.AppErr
+	DeleteAnswer(qid, aid int64) *model.AppErr
+	RefreshAnswerCount(qid int64) *model.AppErr
+	SaveAnswerUpvote(aid, uid int64) *model.AppErr
+	DeleteAnswerUpvote(aid, uid int64) *model.AppErr
 }
 
 // OrderStore is the order store
 type OrderStore interface {
 	Count() int
-	Save(order *model.Order) (*model.Order, *model.AppErr)
+	Save(order *model.Order, details []*model.OrderDetail, promo *model.PromotionDetail, events ...*model.Event) (*model.Order, *model.AppErr)
 	Get(id int64) (*model.Order, *model.AppErr)
 	GetAll(limit, offset int) ([]*model.Order, *model.AppErr)
-	Update(id int64, order *model.Order) (*model.Order, *model.AppErr)
+	Update(id int64, order *model.Order, events ...*model.Event) (*model.Order, *model.AppErr)
 	Delete(id int64) *model.AppErr
+	CountAbandoned(before time.Time) (int64, *model.AppErr)
+	ExpireAbandoned(before time.Time) (int64, *model.AppErr)
 }
 
 // OrderDetailStore is the order detail store
@@ -133,6 +202,43 @@ type OrderDetailStore interface {
 	GetAll(orderID int64) ([]*model.OrderInfo, *model.AppErr)
 }
 
+// WishlistStore is the wishlist store
+type WishlistStore interface {
+	Save(w *model.Wishlist) (*model.Wishlist, *model.AppErr)
+	GetDefault(userID int64) (*model.Wishlist, *model.AppErr)
+	Get(userID, id int64) (*model.Wishlist, *model.AppErr)
+	GetAll(userID int64) ([]*model.Wishlist, *model.AppErr)
+	GetByShareToken(token string) (*model.Wishlist, *model.AppErr)
+	Update(id int64, w *model.Wishlist) (*model.Wishlist, *model.AppErr)
+	Delete(userID, id int64) *model.AppErr
+	SaveItem(item *model.WishlistItem) (*model.WishlistItem, *model.AppErr)
+	GetItem(wishlistID, productID int64) (*model.WishlistItem, *model.AppErr)
+	GetItems(wishlistID int64) ([]*model.WishlistItem, *model.AppErr)
+	UpdateItem(item *model.WishlistItem) (*model.WishlistItem, *model.AppErr)
+	DeleteItem(wishlistID, productID int64) *model.AppErr
+	ClearItems(wishlistID int64) *model.AppErr
+	UpdateAlerts(wishlistID, productID int64, sub *model.WishlistAlertSubscription) *model.AppErr
+	GetPendingAlerts() ([]*model.WishlistAlert, *model.AppErr)
+	SyncAlerts() *model.AppErr
+	MarkAlertsSent(alerts []*model.WishlistAlert) *model.AppErr
+	CountAlertEmails(userID int64, since time.Time) (int, *model.AppErr)
+}
+
+// JobStore is the background job queue store
+type JobStore interface {
+	Save(job *model.Job) (*model.Job, *model.AppErr)
+	Get(id int64) (*model.Job, *model.AppErr)
+	GetAll(status, jobType string, limit, offset int) ([]*model.Job, *model.AppErr)
+	GetStats() ([]*model.JobStats, *model.AppErr)
+	ClaimNext() (*model.Job, *model.AppErr)
+	Complete(id int64) *model.AppErr
+	Fail(id int64, errMsg string, runAt time.Time) *model.AppErr
+	Kill(id int64, errMsg string) *model.AppErr
+	Retry(id int64) *model.AppErr
+	RequeueStale(before time.Time) *model.AppErr
+	DeleteCompleted(before time.Time) *model.AppErr
+}
+
 // AddressStore is the contact address store
 type AddressStore interface {
 	Save(addr *model.Address, userID int64) (*model.Address, *model.AppErr)
@@ -193,3 +299,118 @@ type PromotionStore interface {
 	IsValid(code string) *model.AppErr
 	IsUsed(code string, userID int64) *model.AppErr
 }
+
+// AssetStore is the uploaded asset references store
+type AssetStore interface {
+	GetReferencedPublicIDs() ([]string, *model.AppErr)
+}
+
+// EventStore is the domain events outbox store
+type EventStore interface {
+	Get(id int64) (*model.Event, *model.AppErr)
+	GetUndispatched(limit int) ([]*model.Event, *model.AppErr)
+	MarkDispatched(ids []int64) *model.AppErr
+	DeleteDispatched(before time.Time) *model.AppErr
+}
+
+// WebhookStore is the outbound webhook store
+type WebhookStore interface {
+	Save(wh *model.Webhook) (*model.Webhook, *model.AppErr)
+	Get(id int64) (*model.Webhook, *model.AppErr)
+	GetAll(limit, offset int) ([]*model.Webhook, *model.AppErr)
+	GetActiveByEventType(eventType string) ([]*model.Webhook, *model.AppErr)
+	Update(id int64, wh *model.Webhook) (*model.Webhook, *model.AppErr)
+	Delete(id int64) *model.AppErr
+	SaveDelivery(d *model.WebhookDelivery) (*model.WebhookDelivery, *model.AppErr)
+	GetDelivery(webhookID, id int64) (*model.WebhookDelivery, *model.AppErr)
+	GetDeliveries(webhookID int64, status string, limit, offset int) ([]*model.WebhookDelivery, *model.AppErr)
+	UpdateDelivery(d *model.WebhookDelivery) *model.AppErr
+}
+
+// EmailTemplateStore is the email template override store
+type EmailTemplateStore interface {
+	Save(et *model.EmailTemplate) (*model.EmailTemplate, *model.AppErr)
+	Get(name, locale string) (*model.EmailTemplate, *model.AppErr)
+	GetAll() ([]*model.EmailTemplate, *model.AppErr)
+	Delete(name, locale string) *model.AppErr
+}
+
+// TranslationStore is the catalog translation store
+type TranslationStore interface {
+	Save(t *model.Translation) (*model.Translation, *model.AppErr)
+	Get(resource string, id int64, locale string) (*model.Translation, *model.AppErr)
+	GetAll(resource string, id int64) ([]*model.Translation, *model.AppErr)
+	GetForResources(resource string, ids []int64, locale string) ([]*model.Translation, *model.AppErr)
+	Delete(resource string, id int64, locale string) *model.AppErr
+	ResolveSlug(resource, slug, lang string) (int64, *model.AppErr)
+}
+
+// RoleStore is the staff role store
+type RoleStore interface {
+	Save(r *model.Role) (*model.Role, *model.AppErr)
+	Get(id int64) (*model.Role, *model.AppErr)
+	GetAll(limit, offset int) ([]*model.Role, *model.AppErr)
+	Update(id int64, r *model.Role) (*model.Role, *model.AppErr)
+	Delete(id int64) *model.AppErr
+	GetByUserID(uid int64) ([]*model.Role, *model.AppErr)
+	Assign(uid, rid int64) *model.AppErr
+	Unassign(uid, rid int64) *model.AppErr
+	GetUserPermissions(uid int64) ([]string, *model.AppErr)
+}
+
+// TwoFactorStore is the TOTP two-factor authentication store
+type TwoFactorStore interface {
+	Save(tf *model.TwoFactor) *model.AppErr
+	Get(userID int64) (*model.TwoFactor, *model.AppErr)
+	Enable(userID, step int64, codeHashes []string) *model.AppErr
+	Delete(userID int64) *model.AppErr
+	UseStep(userID, step int64) (bool, *model.AppErr)
+	ReplaceRecoveryCodes(userID int64, codeHashes []string) *model.AppErr
+	UseRecoveryCode(userID int64, codeHash string) (bool, *model.AppErr)
+	CountRecoveryCodes(userID int64) (int, *model.AppErr)
+}
+
+// AttemptStore counts the attempts and blocks the keys that made too many of them
+type AttemptStore interface {
+	Incr(key string, window time.Duration) (int64, time.Duration, *model.AppErr)
+	Reset(key string) *model.AppErr
+	Lock(key string, d time.Duration) *model.AppErr
+	LockedFor(key string) (time.Duration, *model.AppErr)
+}
+
+// RateLimitStore is the token bucket rate limit store
+type RateLimitStore interface {
+	Take(key string, limit *model.RateLimit) (*model.RateLimitResult, *model.AppErr)
+}
+
+// UserIdentityStore is the store of the provider accounts linked to the users
+type UserIdentityStore interface {
+	Save(ui *model.UserIdentity) (*model.UserIdentity, *model.AppErr)
+	GetByProvider(provider, subject string) (*model.UserIdentity, *model.AppErr)
+	GetAll(userID int64) ([]*model.UserIdentity, *model.AppErr)
+	UpdateLastLogin(id int64, email *string) *model.AppErr
+	Delete(userID int64, provider string) *model.AppErr
+}
+
+// OAuthStateStore keeps the state of the started oauth logins
+type OAuthStateStore interface {
+	Save(key string, state *model.OAuthState, expiry time.Duration) *model.AppErr
+	Take(key string) (*model.OAuthState, *model.AppErr)
+}
+
+// APIKeyStore is the store of the integration api keys and their usage
+type APIKeyStore interface {
+	Save(k *model.APIKey) (*model.APIKey, *model.AppErr)
+	Get(id int64) (*model.APIKey, *model.AppErr)
+	GetByHash(hash string) (*model.APIKey, *model.AppErr)
+	GetAll(limit, offset int) ([]*model.APIKey, *model.AppErr)
+	Revoke(id int64) *model.AppErr
+	RecordUsage(u *model.APIKeyUsage) *model.AppErr
+	GetUsage(id int64, limit, offset int) ([]*model.APIKeyUsage, *model.AppErr)
+}
+
+// AuditStore is the append only log of the admin changes
+type AuditStore interface {
+	Save(e *model.AuditEntry) (*model.AuditEntry, *model.AppErr)
+	GetAll(f *model.AuditFilter, limit, offset int) ([]*model.AuditEntry, *model.AppErr)
+}
@@ -17,6 +17,11 @@ func (s *Supplier) AccessToken() store.AccessTokenStore {
 	return redis.NewRedisAccessTokenStore(s.Rdst)
 }
 
+// Session returns the Session store implementation
+func (s *Supplier) Session() store.SessionStore {
+	return redis.NewRedisSessionStore(s.Rdst)
+}
+
 // User returns the User store implementation
 func (s *Supplier) User() store.UserStore {
 	return postgres.NewPgUserStore(s.Pgst)
@@ -47,6 +52,21 @@ func (s *Supplier) ProductReview() store.ProductReviewStore {
 	return postgres.NewPgReviewStore(s.Pgst)
 }
 
+// ProductReviewMedia returns the ReviewMedia store implementation
+func (s *Supplier) ProductReviewMedia() store.ProductReviewMediaStore {
+	return postgres.NewPgReviewMediaStore(s.Pgst)
+}
+
+// ProductQuestion returns the Question store implementation
+func (s *Supplier) ProductQuestion() store.ProductQuestionStore {
+	return postgres.NewPgQuestionStore(s.Pgst)
+}
+
+// Wishlist returns the Wishlist store implementation
+func (s *Supplier) Wishlist() store.WishlistStore {
+	return postgres.NewPgWishlistStore(s.Pgst)
+}
+
 // Order returns the Order store implementation
 func (s *Supplier) Order() store.OrderStore {
 	return postgres.NewPgOrderStore(s.Pgst)
@@ -81,3 +101,73 @@ func (s *Supplier) Tag() store.TagStore {
 func (s *Supplier) Promotion() store.PromotionStore {
 	return postgres.NewPgPromotionStore(s.Pgst)
 }
+
+// Job returns the Job store implementation
+func (s *Supplier) Job() store.JobStore {
+	return postgres.NewPgJobStore(s.Pgst)
+}
+
+// Asset returns the Asset store implementation
+func (s *Supplier) Asset() store.AssetStore {
+	return postgres.NewPgAssetStore(s.Pgst)
+}
+
+// Event returns the Event store implementation
+func (s *Supplier) Event() store.EventStore {
+	return postgres.NewPgEventStore(s.Pgst)
+}
+
+// Webhook returns the Webhook store implementation
+func (s *Supplier) Webhook() store.WebhookStore {
+	return postgres.NewPgWebhookStore(s.Pgst)
+}
+
+// EmailTemplate returns the EmailTemplate store implementation
+func (s *Supplier) EmailTemplate() store.EmailTemplateStore {
+	return postgres.NewPgEmailTemplateStore(s.Pgst)
+}
+
+// Translation returns the Translation store implementation
+func (s *Supplier) Translation() store.TranslationStore {
+	return postgres.NewPgTranslationStore(s.Pgst)
+}
+
+// Role returns the Role store implementation
+func (s *Supplier) Role() store.RoleStore {
+	return postgres.NewPgRoleStore(s.Pgst)
+}
+
+// TwoFactor returns the TwoFactor store implementation
+func (s *Supplier) TwoFactor() store.TwoFactorStore {
+	return postgres.NewPgTwoFactorStore(s.Pgst)
+}
+
+// Attempt returns the Attempt store implementation
+func (s *Supplier) Attempt() store.AttemptStore {
+	return redis.NewRedisAttemptStore(s.Rdst)
+}
+
+// RateLimit returns the RateLimit store implementation
+func (s *Supplier) RateLimit() store.RateLimitStore {
+	return redis.NewRedisRateLimitStore(s.Rdst)
+}
+
+// UserIdentity returns the UserIdentity store implementation
+func (s *Supplier) UserIdentity() store.UserIdentityStore {
+	return postgres.NewPgUserIdentityStore(s.Pgst)
+}
+
+// OAuthState returns the OAuthState store implementation
+func (s *Supplier) OAuthState() store.OAuthStateStore {
+	return redis.NewRedisOAuthStateStore(s.Rdst)
+}
+
+// APIKey returns the APIKey store implementation
+func (s *Supplier) APIKey() store.APIKeyStore {
+	return postgres.NewPgAPIKeyStore(s.Pgst)
+}
+
+// Audit returns the Audit store implementation
+func (s *Supplier) Audit() store.AuditStore {
+	return postgres.NewPgAuditStore(s.Pgst)
+}
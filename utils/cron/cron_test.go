@@ -0,0 +1,85 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		from string
+		want string
+	}{
+		{"every minute", "* * * * *", "2021-03-10 10:15", "2021-03-10 10:16"},
+		{"hourly", "@hourly", "2021-03-10 10:15", "2021-03-10 11:00"},
+		{"daily", "@daily", "2021-03-10 10:15", "2021-03-11 00:00"},
+		{"monthly", "@monthly", "2021-12-10 10:15", "2022-01-01 00:00"},
+		{"weekly on sunday", "@weekly", "2021-03-10 10:15", "2021-03-14 00:00"},
+		{"hour range", "0 9-17 * * *", "2021-03-10 17:30", "2021-03-11 09:00"},
+		{"minute list", "5,35 * * * *", "2021-03-10 10:15", "2021-03-10 10:35"},
+		{"minute step", "*/20 * * * *", "2021-03-10 10:41", "2021-03-10 11:00"},
+		{"step from value", "10/25 * * * *", "2021-03-10 10:36", "2021-03-10 11:10"},
+		{"range step", "0 8-18/4 * * *", "2021-03-10 12:01", "2021-03-10 16:00"},
+		{"day of month", "0 0 15 * *", "2021-03-16 00:00", "2021-04-15 00:00"},
+		{"day of week", "0 12 * * 1-5", "2021-03-12 13:00", "2021-03-15 12:00"},
+		{"sunday as 7", "0 0 * * 7", "2021-03-10 10:15", "2021-03-14 00:00"},
+		{"day of month or day of week", "0 0 1 * 1", "2021-03-02 00:00", "2021-03-08 00:00"},
+		{"day of month or day of week, month start first", "0 0 1 * 1", "2021-03-29 00:00", "2021-04-01 00:00"},
+		{"day of week restricted, day of month any", "0 0 * * 3", "2021-03-10 00:00", "2021-03-17 00:00"},
+		{"month range", "0 0 1 6-8 *", "2021-08-02 00:00", "2022-06-01 00:00"},
+		{"feb 29", "0 0 29 2 *", "2021-03-01 00:00", "2024-02-29 00:00"},
+		{"31st skips short months", "0 0 31 * *", "2021-03-31 10:00", "2021-05-31 00:00"},
+		{"every duration", "@every 15m", "2021-03-10 10:15", "2021-03-10 10:30"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.spec, err)
+			}
+			if got := s.Next(date(tt.from)); !got.Equal(date(tt.want)) {
+				t.Errorf("Next(%s) = %s, want %s", tt.from, got.Format("2006-01-02 15:04"), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+	}{
+		{"too few fields", "* * * *"},
+		{"minute out of range", "60 * * * *"},
+		{"hour out of range", "0 24 * * *"},
+		{"day of month zero", "0 0 0 * *"},
+		{"month out of range", "0 0 1 13 *"},
+		{"day of week out of range", "0 0 * * 8"},
+		{"reversed range", "0 17-9 * * *"},
+		{"zero step", "*/0 * * * *"},
+		{"not a number", "a * * * *"},
+		{"never fires on feb 30", "0 0 30 2 *"},
+		{"never fires on apr 31", "0 0 31 4 *"},
+		{"short every", "@every 500ms"},
+		{"bad every", "@every soon"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.spec); err == nil {
+				t.Errorf("Parse(%q) expected an error", tt.spec)
+			}
+		})
+	}
+}
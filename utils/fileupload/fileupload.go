@@ -4,6 +4,7 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/dankobgd/ecommerce-shop/gocloudinary"
@@ -18,6 +19,7 @@ var (
 	msgCloudinaryDial           = &i18n.Message{ID: "cloudinary.dial.app_error", Other: "could not connect to cloudinary service"}
 	msgCloudinaryUploadImage    = &i18n.Message{ID: "cloudinary.upload.image.app_error", Other: "could not upload image"}
 	msgCloudinaryRecieveDetails = &i18n.Message{ID: "cloudinary.resource.details.app_error", Other: "could not get resource details"}
+	msgCloudinaryListImages     = &i18n.Message{ID: "cloudinary.resource.list.app_error", Other: "could not list images"}
 )
 
 // UploadImageToCloudinary uploads the image and returns the preview url
@@ -54,3 +56,24 @@ func DeleteImageFromCloudinary(publicID string, cloudEnvURI string) *model.AppEr
 
 	return nil
 }
+
+// ListCloudinaryImages lists all the images uploaded by the app
+func ListCloudinaryImages(cloudEnvURI string) ([]*gocloudinary.Resource, *model.AppErr) {
+	cloudinary, err := gocloudinary.Dial(cloudEnvURI)
+	if err != nil {
+		return nil, model.NewAppErr("ListCloudinaryImages", model.ErrInternal, locale.GetUserLocalizer("en"), msgCloudinaryDial, http.StatusInternalServerError, "")
+	}
+
+	resources, err := cloudinary.Resources(gocloudinary.ImageType)
+	if err != nil {
+		return nil, model.NewAppErr("ListCloudinaryImages", model.ErrInternal, locale.GetUserLocalizer("en"), msgCloudinaryListImages, http.StatusInternalServerError, "")
+	}
+
+	images := make([]*gocloudinary.Resource, 0)
+	for _, res := range resources {
+		if strings.HasPrefix(res.PublicID, baseCloudinaryDir+"/") {
+			images = append(images, res)
+		}
+	}
+	return images, nil
+}
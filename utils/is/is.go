@@ -1,6 +1,9 @@
 package is
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"net/url"
+)
 
 // ValidEmail checks if the email format is valid
 func ValidEmail(email string) bool {
@@ -23,3 +26,12 @@ func ValidJSON(str string) bool {
 	var js json.RawMessage
 	return json.Unmarshal([]byte(str), &js) == nil
 }
+
+// ValidHTTPURL checks if the string is an absolute http or https url
+func ValidHTTPURL(str string) bool {
+	u, err := url.Parse(str)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -1,6 +1,7 @@
 package locale
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -16,6 +17,21 @@ import (
 // localizers holds the list of all i18n localizers that can be fetched at each request
 var localizers map[string]*i18n.Localizer = make(map[string]*i18n.Localizer)
 
+// supportedTags are the languages that have the translations, the first one is the default
+var supportedTags = []language.Tag{language.English}
+
+var matcher = language.NewMatcher(supportedTags)
+
+// DefaultLocale is the locale of the messages in the source and of the untranslated content
+const DefaultLocale = "en"
+
+type contextKey string
+
+const (
+	localizerCtxKey contextKey = "localizer"
+	localeCtxKey    contextKey = "locale"
+)
+
 // InitTranslations loads the translation files from the locales directory
 func InitTranslations() {
 	bundle := i18n.NewBundle(language.English)
@@ -32,12 +48,63 @@ func InitTranslations() {
 			if f.Name() == "active.en.json" {
 				continue
 			}
-			bundle.MustLoadMessageFile(fmt.Sprintf("./") + filepath.Join(localesDir, f.Name()))
+			mf, err := bundle.LoadMessageFile(fmt.Sprintf("./") + filepath.Join(localesDir, f.Name()))
+			if err != nil {
+				panic(err)
+			}
+			supportedTags = append(supportedTags, mf.Tag)
 		}
 	}
 
-	localizers[language.English.String()] = i18n.NewLocalizer(bundle, language.English.String())
-	localizers[language.Serbian.String()] = i18n.NewLocalizer(bundle, language.Serbian.String())
+	for _, tag := range supportedTags {
+		localizers[tag.String()] = i18n.NewLocalizer(bundle, tag.String())
+	}
+	matcher = language.NewMatcher(supportedTags)
+}
+
+// IsSupported checks if there are translations for the locale
+func IsSupported(locale string) bool {
+	_, ok := localizers[locale]
+	return ok
+}
+
+// MatchAcceptLanguage returns the best supported locale for the Accept-Language header value
+func MatchAcceptLanguage(header string) (string, bool) {
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil || len(tags) == 0 {
+		return "", false
+	}
+	_, idx, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return "", false
+	}
+	return supportedTags[idx].String(), true
+}
+
+// WithLocalizer returns the copy of the context with the localizer
+func WithLocalizer(ctx context.Context, l *i18n.Localizer) context.Context {
+	return context.WithValue(ctx, localizerCtxKey, l)
+}
+
+// FromContext gets the request localizer from the context, english is used if there is none
+func FromContext(ctx context.Context) *i18n.Localizer {
+	if l, ok := ctx.Value(localizerCtxKey).(*i18n.Localizer); ok && l != nil {
+		return l
+	}
+	return GetUserLocalizer(language.English.String())
+}
+
+// WithLocale returns the copy of the context with the request locale
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeCtxKey, locale)
+}
+
+// LocaleFromContext gets the request locale from the context, the default locale is used if there is none
+func LocaleFromContext(ctx context.Context) string {
+	if l, ok := ctx.Value(localeCtxKey).(string); ok && l != "" {
+		return l
+	}
+	return DefaultLocale
 }
 
 // GetLocalizer gets the localizer by the specified language key
@@ -11,11 +11,14 @@ func ParseTemplate(filename string, data interface{}) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	return Render(t, data)
+}
 
+// Render executes the parsed template and returns the string result
+func Render(t *template.Template, data interface{}) (string, error) {
 	buf := new(bytes.Buffer)
-	if err = t.Execute(buf, data); err != nil {
+	if err := t.Execute(buf, data); err != nil {
 		return "", err
 	}
-
 	return buf.String(), nil
 }
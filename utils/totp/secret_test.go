@@ -0,0 +1,31 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncryptSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted, err := EncryptSecret(secret, "key")
+	if err != nil {
+		t.Fatalf("EncryptSecret: %v", err)
+	}
+	if !strings.HasPrefix(encrypted, encryptedPrefix) || strings.Contains(encrypted, secret) {
+		t.Fatalf("EncryptSecret() = %q, want the encrypted secret", encrypted)
+	}
+
+	if got, err := DecryptSecret(encrypted, "key"); err != nil || got != secret {
+		t.Errorf("DecryptSecret() = %q, %v, want %q", got, err, secret)
+	}
+	if _, err := DecryptSecret(encrypted, "other key"); err != ErrInvalidCiphertext {
+		t.Errorf("DecryptSecret() with the wrong key = %v, want %v", err, ErrInvalidCiphertext)
+	}
+	if _, err := DecryptSecret(secret, "key"); err != ErrInvalidCiphertext {
+		t.Errorf("DecryptSecret() of the plain secret = %v, want %v", err, ErrInvalidCiphertext)
+	}
+}